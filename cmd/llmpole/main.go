@@ -13,7 +13,9 @@ var Version = "dev"
 func main() {
 	cli.Version = Version
 	if err := cli.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		if msg := err.Error(); msg != "" {
+			fmt.Fprintln(os.Stderr, msg)
+		}
+		os.Exit(cli.ExitCode(err))
 	}
 }
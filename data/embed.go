@@ -5,3 +5,10 @@ import _ "embed"
 
 //go:embed hf_models.json
 var HFModelsJSON []byte
+
+// HFModelsMetaJSON holds metadata for HFModelsJSON (e.g. generated_at date). After regenerating
+// the list, run go generate ./data to stamp it with today's date.
+//
+//go:generate go run gen_meta.go
+//go:embed hf_models_meta.json
+var HFModelsMetaJSON []byte
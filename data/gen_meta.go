@@ -0,0 +1,25 @@
+//go:build ignore
+
+// gen_meta writes hf_models_meta.json with today's date as generated_at. Run it through
+// go generate whenever hf_models.json is regenerated.
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+)
+
+func main() {
+	meta := struct {
+		GeneratedAt string `json:"generated_at"`
+	}{time.Now().UTC().Format("2006-01-02")}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("hf_models_meta.json", append(data, '\n'), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/shayne-snap/llmpole/internal/display"
+	"github.com/shayne-snap/llmpole/internal/pole"
+
+	"github.com/spf13/cobra"
+)
+
+var adviseCmd = &cobra.Command{
+	Use:   "advise",
+	Short: "Show how many more models an upgrade would make runnable",
+	RunE:  runAdvise,
+}
+
+func init() {
+	adviseCmd.Flags().Float64("extra-ram", 0, "System RAM to add (GB)")
+	adviseCmd.Flags().Float64("extra-vram", 0, "VRAM to add (GB); adds a GPU if none is detected")
+	adviseCmd.Flags().UintP("limit", "n", 10, "Limit newly runnable models listed")
+}
+
+func runAdvise(cmd *cobra.Command, args []string) error {
+	extraRAM, _ := cmd.Flags().GetFloat64("extra-ram")
+	extraVRAM, _ := cmd.Flags().GetFloat64("extra-vram")
+	if extraRAM < 0 || extraVRAM < 0 || extraRAM+extraVRAM == 0 {
+		return usageErrorf("advise needs a positive --extra-ram and/or --extra-vram")
+	}
+	limit, _ := cmd.Flags().GetUint("limit")
+	specs, err := detectSpecs()
+	if err != nil {
+		return err
+	}
+	db, err := loadDB()
+	if err != nil {
+		return err
+	}
+	all := catalogModels(db)
+	opts := analyzeOptions()
+	before := pole.AnalyzeAllWithOptions(all, specs, opts)
+	after := pole.AnalyzeAllWithOptions(all, specs.WithExtraMemory(extraRAM, extraVRAM), opts)
+	newly := pole.NewlyRunnable(before, after)
+	if !globalVariants {
+		newly = pole.CollapseDuplicates(newly)
+	}
+	display.Advise(os.Stdout, extraRAM, extraVRAM, pole.CountFits(before), pole.CountFits(after), newly, int(limit), globalJSON)
+	return nil
+}
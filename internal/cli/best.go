@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/shayne-snap/llmpole/internal/display"
+	"github.com/shayne-snap/llmpole/internal/models"
+	"github.com/shayne-snap/llmpole/internal/pole"
+
+	"github.com/spf13/cobra"
+)
+
+var bestCmd = &cobra.Command{
+	Use:   "best",
+	Short: "Show the single best model to download for your hardware",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE:  runBest,
+}
+
+func init() {
+	bestCmd.Flags().String("use-case", "", "Filter by use case: general, coding, reasoning, chat, multimodal, embedding")
+}
+
+func runBest(cmd *cobra.Command, args []string) error {
+	specs, err := detectSpecs()
+	if err != nil {
+		return err
+	}
+	db, err := loadDB()
+	if err != nil {
+		return err
+	}
+	useCase, _ := cmd.Flags().GetString("use-case")
+	fits := pole.AnalyzeAllWithOptions(catalogModels(db), specs, analyzeOptions())
+	if useCase != "" {
+		fits = pole.FilterByUseCase(fits, useCase)
+	} else {
+		// Embedding models rank high on speed but are not what "what should I download?" means.
+		var chatty []*pole.ModelFit
+		for _, f := range fits {
+			if f.UseCase != models.UseCaseEmbedding {
+				chatty = append(chatty, f)
+			}
+		}
+		fits = chatty
+	}
+	best := pole.BestRunnable(fits)
+	display.Best(os.Stdout, specs, best, useCase, globalJSON)
+	if best == nil {
+		return withExit(ExitNoModels, nil)
+	}
+	return nil
+}
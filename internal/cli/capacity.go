@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/shayne-snap/llmpole/internal/display"
+	"github.com/shayne-snap/llmpole/internal/pole"
+
+	"github.com/spf13/cobra"
+)
+
+var capacityCmd = &cobra.Command{
+	Use:   "capacity",
+	Short: "Estimate how many concurrent requests of a context length fit beside a model's weights",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE:  runCapacity,
+}
+
+func init() {
+	capacityCmd.Flags().String("model", "", "Model to size (name, provider, or size substring; must match one model)")
+	capacityCmd.Flags().Uint32("context", 4096, "Context length in tokens of each concurrent request")
+}
+
+func runCapacity(cmd *cobra.Command, args []string) error {
+	query, _ := cmd.Flags().GetString("model")
+	ctx, _ := cmd.Flags().GetUint32("context")
+	if query == "" {
+		return usageErrorf("--model is required")
+	}
+	if ctx == 0 {
+		return usageErrorf("--context must be greater than 0")
+	}
+	db, err := loadDB()
+	if err != nil {
+		return err
+	}
+	m, err := singleMatch(query, db.FindModel(query))
+	if err != nil {
+		return err
+	}
+	specs, err := detectSpecs()
+	if err != nil {
+		return err
+	}
+	opts := analyzeOptions()
+	fit := pole.AnalyzeWithOptions(m, specs, opts)
+	c := pole.EstimateCapacity(fit, ctx, opts)
+	display.Capacity(os.Stdout, fit, c, globalJSON)
+	if c.Concurrent == 0 {
+		return withExit(ExitNoModels, nil)
+	}
+	return nil
+}
@@ -1,18 +1,26 @@
 package cli
 
 import (
+	"strings"
 	"testing"
 )
 
 func TestRootCmd_HasSubcommands(t *testing.T) {
 	want := map[string]bool{
-		"pole":       true,
-		"recommend":  true,
-		"system":     true,
-		"list":       true,
-		"search":     true,
-		"info":       true,
+		"pole":        true,
+		"recommend":   true,
+		"system":      true,
+		"list":        true,
+		"search":      true,
+		"info":        true,
+		"best":        true,
+		"compare":     true,
+		"capacity":    true,
+		"plan":        true,
+		"metrics":     true,
 		"update-list": true,
+		"fetch-log":   true,
+		"config":      true,
 	}
 	cmds := rootCmd.Commands()
 	if len(cmds) < len(want) {
@@ -50,3 +58,19 @@ func TestRecommendCmd_Flags(t *testing.T) {
 		t.Error("recommend command missing --use-case flag")
 	}
 }
+
+// pflag shows backquoted text in a usage string as the flag's value placeholder.
+func TestFlagUsage_NoBackquotes(t *testing.T) {
+	for _, name := range []string{"remote", "show-unrunnable"} {
+		f := rootCmd.PersistentFlags().Lookup(name)
+		if f == nil {
+			t.Fatalf("root missing --%s", name)
+		}
+		if strings.Contains(f.Usage, "`") {
+			t.Errorf("--%s usage has backquotes, which pflag shows as the value name: %q", name, f.Usage)
+		}
+	}
+	if usage := rootCmd.PersistentFlags().FlagUsages(); !strings.Contains(usage, "--remote string") {
+		t.Errorf("--remote should show a string placeholder:\n%s", usage)
+	}
+}
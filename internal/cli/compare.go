@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/shayne-snap/llmpole/internal/display"
+	"github.com/shayne-snap/llmpole/internal/pole"
+
+	"github.com/spf13/cobra"
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <model-a> <model-b> [model...]",
+	Short: "Compare models on this hardware, with the winner of each score dimension for two",
+	Args:  usageArgs(cobra.MinimumNArgs(2)),
+	RunE:  runCompare,
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	db, err := loadDB()
+	if err != nil {
+		return err
+	}
+	specs, err := detectSpecs()
+	if err != nil {
+		return err
+	}
+	found, err := findModels(args, db)
+	if err != nil {
+		return err
+	}
+	fits := make([]*pole.ModelFit, 0, len(args))
+	seen := map[string]bool{}
+	for i, m := range found {
+		if seen[m.Name] {
+			return usageErrorf("%q names %s, which is already being compared", args[i], m.Name)
+		}
+		seen[m.Name] = true
+		fits = append(fits, pole.AnalyzeWithOptions(m, specs, analyzeOptions()))
+	}
+	if len(fits) == 2 {
+		display.Compare(os.Stdout, fits[0], fits[1], globalJSON)
+		return nil
+	}
+	display.CompareMany(os.Stdout, fits, globalJSON)
+	return nil
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/shayne-snap/llmpole/internal/hardware"
+	"github.com/shayne-snap/llmpole/internal/models"
+	"github.com/shayne-snap/llmpole/internal/pole"
+
+	"github.com/spf13/cobra"
+)
+
+// settings are the preferences kept between runs in settings.json, in the config dir next to the
+// model cache. The config dir is per user and machine, so they describe this machine.
+type settings struct {
+	// HideUnrunnable drops Too Tight models from list, pole, and recommend output and starts the
+	// TUI on the Runnable fit filter. --show-unrunnable overrides it for one run.
+	HideUnrunnable bool `json:"hide_unrunnable"`
+}
+
+// savedSettings holds settings.json as loaded in PersistentPreRunE.
+var savedSettings settings
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show or change the preferences saved for this machine",
+	Long: `Show or change the preferences saved for this machine in settings.json, in the llmpole config dir.
+
+  llmpole config --hide-unrunnable          hide Too Tight models from now on
+  llmpole config --hide-unrunnable=false    show them again
+
+Without flags, config prints the saved preferences. --show-unrunnable shows Too Tight models for one run without changing them.`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: runConfig,
+}
+
+func init() {
+	configCmd.Flags().Bool("hide-unrunnable", false, "Save whether list, pole, recommend, and the TUI hide models too big for this machine (Too Tight)")
+}
+
+func runConfig(cmd *cobra.Command, args []string) error {
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+	s := savedSettings
+	if cmd.Flags().Changed("hide-unrunnable") {
+		s.HideUnrunnable, _ = cmd.Flags().GetBool("hide-unrunnable")
+		if err := saveSettings(s); err != nil {
+			return err
+		}
+		savedSettings = s
+		if !globalQuiet {
+			fmt.Fprintf(os.Stderr, "Saved to %s\n", path)
+		}
+	}
+	if globalJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+	fmt.Printf("hide-unrunnable: %s\n", onOff(s.HideUnrunnable))
+	return nil
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+// settingsPath returns the settings file path, next to the model cache (config dir/llmpole/settings.json).
+func settingsPath() (string, error) {
+	cachePath, err := models.CachePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(cachePath), "settings.json"), nil
+}
+
+// loadSettings reads settings.json; without the file (or a config dir) every preference is off.
+// On an unreadable or malformed file it returns the defaults along with the error.
+func loadSettings() (settings, error) {
+	var s settings
+	path, err := settingsPath()
+	if err != nil {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return settings{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// saveSettings writes s to settings.json, creating the config dir if needed.
+func saveSettings(s settings) error {
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// hideUnrunnable reports whether Too Tight models are hidden this run: saved with
+// `config --hide-unrunnable` and not overridden by --show-unrunnable.
+func hideUnrunnable() bool {
+	return savedSettings.HideUnrunnable && !globalUnrunnable
+}
+
+// runnableFits drops Too Tight fits when hideUnrunnable is in effect.
+func runnableFits(fits []*pole.ModelFit) []*pole.ModelFit {
+	if !hideUnrunnable() {
+		return fits
+	}
+	return pole.FilterRunnable(fits)
+}
+
+// runnableModels keeps the models of all that are not Too Tight on specs, in order.
+func runnableModels(all []*models.LlmModel, specs *hardware.SystemSpecs) []*models.LlmModel {
+	var out []*models.LlmModel
+	for _, f := range pole.FilterRunnable(pole.AnalyzeAllWithOptions(all, specs, analyzeOptions())) {
+		out = append(out, f.Model)
+	}
+	return out
+}
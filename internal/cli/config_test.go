@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shayne-snap/llmpole/internal/hardware"
+	"github.com/shayne-snap/llmpole/internal/models"
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+func TestHideUnrunnable_SavedAndOverridden(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Cleanup(func() { savedSettings, globalUnrunnable = settings{}, false })
+	if s, err := loadSettings(); err != nil || s.HideUnrunnable {
+		t.Fatalf("loadSettings without a file = %+v, %v; want everything off", s, err)
+	}
+	if err := saveSettings(settings{HideUnrunnable: true}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := loadSettings()
+	if err != nil || !s.HideUnrunnable {
+		t.Fatalf("loadSettings after saving = %+v, %v; want hide_unrunnable on", s, err)
+	}
+	savedSettings = s
+
+	vram := 4.0
+	catalog := []*models.LlmModel{
+		{Name: "small-1.5b", ParameterCount: "1.5B", MinRAMGB: 3, RecommendedRAMGB: 4, MinVRAMGB: &vram, Quantization: "Q4_K_M", ContextLength: 8192, UseCase: "general"},
+		{Name: "huge-70b", ParameterCount: "70B", MinRAMGB: 48, RecommendedRAMGB: 64, Quantization: "Q4_K_M", ContextLength: 8192, UseCase: "general"},
+	}
+	specs, err := hardware.BudgetSpecs(8, "ram", hardware.BackendCpuX86)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := runnableModels(catalog, specs); len(got) != 1 || got[0].Name != "small-1.5b" {
+		t.Errorf("runnableModels with hide_unrunnable = %v, want only small-1.5b", got)
+	}
+
+	globalUnrunnable = true // --show-unrunnable
+	if hideUnrunnable() {
+		t.Error("hideUnrunnable with --show-unrunnable = true, want false")
+	}
+	fits := pole.AnalyzeAllWithOptions(catalog, specs, analyzeOptions())
+	if got := runnableFits(fits); len(got) != len(catalog) {
+		t.Errorf("runnableFits with --show-unrunnable kept %d fits, want all %d", len(got), len(catalog))
+	}
+	globalUnrunnable = false
+	if got := runnableFits(fits); len(got) != 1 {
+		t.Errorf("runnableFits with hide_unrunnable kept %d fits, want 1", len(got))
+	}
+
+	// config --hide-unrunnable=false restores them for good.
+	if err := saveSettings(settings{}); err != nil {
+		t.Fatal(err)
+	}
+	if savedSettings, err = loadSettings(); err != nil || hideUnrunnable() {
+		t.Errorf("after turning hide_unrunnable off: hideUnrunnable = %v, err %v; want false", hideUnrunnable(), err)
+	}
+}
+
+func TestLoadSettings_MalformedFileFallsBackAndIsRepaired(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := settingsPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"hide_unrunnable": "yes"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := loadSettings(); err == nil || s.HideUnrunnable {
+		t.Errorf("loadSettings on a malformed file = %+v, %v; want the defaults and an error", s, err)
+	}
+	if err := saveSettings(settings{HideUnrunnable: true}); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := loadSettings(); err != nil || !s.HideUnrunnable {
+		t.Errorf("loadSettings after config rewrote the file = %+v, %v; want hide_unrunnable on", s, err)
+	}
+}
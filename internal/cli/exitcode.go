@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes returned by the llmpole binary. They are stable: scripts may rely on them.
+const (
+	ExitOK        = 0 // success
+	ExitError     = 1 // unclassified failure
+	ExitUsage     = 2 // invalid flags or arguments, including a model query matching several models
+	ExitNoModels  = 3 // no model matched, or nothing left to show after filtering
+	ExitDetection = 4 // hardware detection or --remote specs failed
+	ExitFetch     = 5 // fetching from HuggingFace or the model list URL failed
+	ExitInvalid   = 6 // models validate found problems in the catalog
+)
+
+// exitError attaches an exit code to an error. A nil err means the command already printed
+// what it had to say and only the exit code matters.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return ""
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// withExit wraps err with an exit code; a nil err yields a silent exit with that code.
+func withExit(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// usageErrorf returns an ExitUsage error.
+func usageErrorf(format string, args ...interface{}) error {
+	return withExit(ExitUsage, fmt.Errorf(format, args...))
+}
+
+// ExitCode maps an error returned by Execute to the process exit code.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return ExitError
+}
+
+// usageArgs wraps a cobra argument validator so its errors exit with ExitUsage.
+func usageArgs(v func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := v(cmd, args); err != nil {
+			return withExit(ExitUsage, err)
+		}
+		return nil
+	}
+}
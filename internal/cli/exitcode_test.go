@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shayne-snap/llmpole/internal/models"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitOK},
+		{errors.New("boom"), ExitError},
+		{withExit(ExitFetch, errors.New("network")), ExitFetch},
+		{fmt.Errorf("wrapped: %w", withExit(ExitDetection, errors.New("mem"))), ExitDetection},
+		{withExit(ExitNoModels, nil), ExitNoModels},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+	if msg := withExit(ExitNoModels, nil).Error(); msg != "" {
+		t.Errorf("silent exit error message = %q, want empty", msg)
+	}
+}
+
+func TestCommandExitCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(remoteSystemJSON))
+	}))
+	defer server.Close()
+	globalRemote = server.URL
+	globalNoFetch = true
+	defer func() { globalRemote, globalNoFetch = "", false }()
+
+	if got := ExitCode(runAdvise(adviseCmd, nil)); got != ExitUsage {
+		t.Errorf("advise without extras: exit %d, want %d (usage)", got, ExitUsage)
+	}
+	if got := ExitCode(infoCmd.Args(infoCmd, nil)); got != ExitUsage {
+		t.Errorf("info without a model: exit %d, want %d (usage)", got, ExitUsage)
+	}
+	if got := ExitCode(runInfo(infoCmd, []string{"no-such-model-zzz"})); got != ExitNoModels {
+		t.Errorf("info on an unknown model: exit %d, want %d (no models)", got, ExitNoModels)
+	}
+	ambiguous := []*models.LlmModel{{Name: "org/Model-7B"}, {Name: "org/Model-7B-Instruct"}}
+	if _, err := singleMatch("model-7b", ambiguous); ExitCode(err) != ExitUsage || !strings.Contains(err.Error(), "org/Model-7B-Instruct") {
+		t.Errorf("ambiguous query: err %v (exit %d), want the candidates and exit %d (usage)", err, ExitCode(err), ExitUsage)
+	}
+
+	db, err := models.NewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := db.GetAllModels()[0].Name
+	if err := runCompare(compareCmd, []string{name, name}); ExitCode(err) != ExitUsage || !strings.Contains(fmt.Sprint(err), "already being compared") {
+		t.Errorf("compare of %s with itself: err %v (exit %d), want a duplicate-model usage error", name, err, ExitCode(err))
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	globalRemote = failing.URL
+	if got := ExitCode(runBest(bestCmd, nil)); got != ExitDetection {
+		t.Errorf("best with failing --remote: exit %d, want %d (detection)", got, ExitDetection)
+	}
+}
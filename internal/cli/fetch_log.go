@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/shayne-snap/llmpole/internal/display"
+	"github.com/shayne-snap/llmpole/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+var fetchLogCmd = &cobra.Command{
+	Use:   "fetch-log",
+	Short: "Show the models fetched from HuggingFace into the cache: when, from where, and what they resolved to",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE:  runFetchLog,
+}
+
+func runFetchLog(cmd *cobra.Command, args []string) error {
+	entries, err := models.ReadFetchLog()
+	if err != nil {
+		return err
+	}
+	display.FetchLog(os.Stdout, entries, globalJSON)
+	return nil
+}
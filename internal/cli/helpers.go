@@ -2,9 +2,22 @@ package cli
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/shayne-snap/llmpole/internal/display"
+	"github.com/shayne-snap/llmpole/internal/fetch"
+	"github.com/shayne-snap/llmpole/internal/hardware"
+	"github.com/shayne-snap/llmpole/internal/models"
+	"github.com/shayne-snap/llmpole/internal/pole"
+
+	"github.com/spf13/cobra"
 )
 
 func looksLikeRepoID(s string) bool {
@@ -19,12 +32,486 @@ func looksLikeRepoID(s string) bool {
 	return len(parts[0]) > 0 && len(parts[1]) > 0 && !strings.ContainsAny(s, " \t\n")
 }
 
+// limitValue is the pflag.Value behind --limit: a count ("5") or a share of runnable models ("20%").
+type limitValue struct{ limit *pole.Limit }
+
+func (v limitValue) String() string { return v.limit.String() }
+func (v limitValue) Type() string   { return "count|percent" }
+
+func (v limitValue) Set(s string) error {
+	l, err := pole.ParseLimit(s)
+	if err != nil {
+		return err
+	}
+	*v.limit = l
+	return nil
+}
+
+// limitFlag adds a command-local --limit/-n with the given default count.
+func limitFlag(cmd *cobra.Command, def uint, usage string) {
+	cmd.Flags().VarP(limitValue{&pole.Limit{Count: def}}, "limit", "n", usage)
+}
+
+// flagLimit returns the value of cmd's local --limit flag added by limitFlag.
+func flagLimit(cmd *cobra.Command) pole.Limit {
+	return *cmd.Flags().Lookup("limit").Value.(limitValue).limit
+}
+
+// detectSpecs returns the specs to analyze against: the --remote machine's if set, else this machine's.
+func detectSpecs() (*hardware.SystemSpecs, error) {
+	return detectSpecsRefresh(globalRefreshHW)
+}
+
+// detectSpecsRefresh is detectSpecs, bypassing the detection cache when refresh is set.
+func detectSpecsRefresh(refresh bool) (*hardware.SystemSpecs, error) {
+	var specs *hardware.SystemSpecs
+	var err error
+	if globalRemote != "" {
+		specs, err = hardware.DetectRemote(globalRemote)
+	} else {
+		specs, err = hardware.DetectCached(specsCachePath(), refresh)
+	}
+	if err != nil {
+		return nil, withExit(ExitDetection, err)
+	}
+	return specs, nil
+}
+
+// specsCachePath returns the hardware detection cache path, next to the model cache (config
+// dir/llmpole/specs.json), or "" when there is no config dir, which disables the cache.
+func specsCachePath() string {
+	cachePath, err := models.CachePath()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(cachePath), "specs.json")
+}
+
+// loadDB loads the model database and merges in custom models from --models-file (or $LLMPOLE_MODELS_FILE).
+// Invalid custom entries are reported on stderr and skipped.
+func loadDB() (*models.ModelDatabase, error) {
+	db, err := models.NewDB()
+	if err != nil {
+		return nil, err
+	}
+	path := globalModelsFile
+	if path == "" {
+		path = os.Getenv(models.CustomModelsEnv)
+	}
+	if path == "" {
+		return db, nil
+	}
+	custom, bad, err := models.LoadCustomModels(path)
+	if err != nil {
+		return nil, fmt.Errorf("models file: %w", err)
+	}
+	for _, e := range bad {
+		fmt.Fprintf(os.Stderr, "llmpole: skipping custom model %v\n", e)
+	}
+	db.MergeCustom(custom)
+	return db, nil
+}
+
+// warnIfNoLicenseData tells w that --license cannot match anything when no model in ms has license
+// data. The built-in list records none; only models fetched from HuggingFace carry a license.
+func warnIfNoLicenseData(w io.Writer, ms []*models.LlmModel) {
+	if slices.ContainsFunc(ms, func(m *models.LlmModel) bool { return m.License != "" }) {
+		return
+	}
+	fmt.Fprintln(w, "llmpole: --license matches nothing: no model in the list has license data (only models fetched from HuggingFace record one)")
+}
+
+// catalogModels returns the database models after the --license and global --moe/--dense filters.
+func catalogModels(db *models.ModelDatabase) []*models.LlmModel {
+	all := db.GetAllModels()
+	if len(globalLicenses) > 0 {
+		warnIfNoLicenseData(os.Stderr, all)
+		all = models.FilterByLicense(all, globalLicenses)
+	}
+	all = models.FilterByMinContext(all, globalMinContext)
+	switch {
+	case globalMoE:
+		return models.FilterByMoE(all, true)
+	case globalDense:
+		return models.FilterByMoE(all, false)
+	}
+	return all
+}
+
+// analysisOptions holds the pole options PersistentPreRunE builds from the flags (buildOptions).
+var analysisOptions = pole.DefaultOptions()
+
+// analyzeOptions returns the pole options for this run, built once from the flags.
+func analyzeOptions() pole.Options {
+	return analysisOptions
+}
+
+// buildOptions applies the analysis flags to the default pole options, failing on the first
+// invalid value. Score weights come from weights.json and are set by the caller.
+func buildOptions() (pole.Options, error) {
+	opts, err := pole.DefaultOptions().WithSafetyMargin(globalMargin / 100)
+	if err != nil {
+		return opts, err
+	}
+	if opts, err = opts.WithWorkload(globalWorkload); err != nil {
+		return opts, err
+	}
+	if opts, err = opts.WithFitThresholds(globalGoodRoom, globalMarginRoom); err != nil {
+		return opts, err
+	}
+	if opts, err = opts.WithMaxQuant(globalMaxQuant); err != nil {
+		return opts, err
+	}
+	if opts, err = opts.WithRuntime(globalRuntime); err != nil {
+		return opts, err
+	}
+	if opts, err = opts.WithKVCache(globalKVCache); err != nil {
+		return opts, err
+	}
+	if opts, err = opts.WithUsabilityPenalty(globalUsability / 100); err != nil {
+		return opts, err
+	}
+	if opts, err = opts.WithPromptTokens(globalPromptTokens); err != nil {
+		return opts, err
+	}
+	if globalContext != 0 {
+		if opts, err = opts.WithContext(globalContext); err != nil {
+			return opts, err
+		}
+	}
+	switch {
+	case globalPreferGPU:
+		opts.Prefer = pole.PreferGPU
+	case globalPreferCPU:
+		opts.Prefer = pole.PreferCPU
+	}
+	opts.SuggestQuants = globalSuggestQuants
+	opts.DenseOnlyScore = globalDenseOnly
+	return opts, nil
+}
+
+// loadScoreWeights reads weights.json from the config dir, next to the model cache. Without the
+// file it returns nil (the built-in weights); weights that do not sum to about 1 are warned about.
+func loadScoreWeights() (*pole.ScoreWeights, error) {
+	cachePath, err := models.CachePath()
+	if err != nil {
+		return nil, nil
+	}
+	path := filepath.Join(filepath.Dir(cachePath), "weights.json")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sw, warnings, err := pole.ParseScoreWeights(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "llmpole: %s: %s\n", path, w)
+	}
+	return &sw, nil
+}
+
+// rankFits orders fits by --rank-by (validated in PersistentPreRunE), Too Tight last.
+func rankFits(fits []*pole.ModelFit) []*pole.ModelFit {
+	by, _ := pole.ParseRankBy(globalRankBy)
+	return pole.RankModelsBy(fits, by)
+}
+
+// fetchMode controls what happens when a HuggingFace repo ID is not in the catalog.
+type fetchMode int
+
+const (
+	fetchPrompt fetchMode = iota // ask on stdin
+	fetchAuto                    // fetch without asking (--fetch)
+	fetchSkip                    // report not found (--no-fetch, or stdin is not a terminal)
+)
+
+// stdinIsTTY reports whether stdin is an interactive terminal. Overridden in tests.
+var stdinIsTTY = func() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// currentFetchMode resolves --fetch/--no-fetch; without either, a non-TTY stdin means skip.
+func currentFetchMode() fetchMode {
+	switch {
+	case globalFetch:
+		return fetchAuto
+	case globalNoFetch || globalQuiet || !stdinIsTTY():
+		return fetchSkip
+	}
+	return fetchPrompt
+}
+
+// globalLicenses is the --license filter shared by the commands licenseFlag is added to.
+var globalLicenses []string
+
+// licenseFlag registers --license on a catalog-listing command; catalogModels applies it.
+func licenseFlag(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&globalLicenses, "license", nil, "Keep only models under one of these licenses, e.g. apache-2.0,mit; models without license data are excluded")
+}
+
+// globalMinContext is the --min-context filter shared by the commands minContextFlag is added to.
+var globalMinContext uint32
+
+// minContextFlag registers --min-context on a catalog-listing command; catalogModels applies it.
+func minContextFlag(cmd *cobra.Command) {
+	cmd.Flags().Uint32Var(&globalMinContext, "min-context", 0, "Keep only models with a context length of at least this many tokens, e.g. 32768")
+}
+
+// globalContext is the --context override shared by the commands contextFlag is added to; 0 keeps each model's own.
+var globalContext int
+
+// contextFlag registers --context on an analyzing command; analyzeOptions applies it.
+func contextFlag(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&globalContext, "context", 0, fmt.Sprintf("Estimate memory at this context length in tokens instead of each model's own, e.g. 32768 (at least %d, at most %d)", pole.MinContext, pole.MaxContext))
+}
+
+// assumeFlags registers --assume-vram/--assume-free-vram/--assume-ram/--assume-backend, which
+// patch the detected specs for this run only (see hardware.Assumptions).
+func assumeFlags(cmd *cobra.Command) {
+	cmd.Flags().Float64("assume-vram", 0, "Analyze as if the GPU had this much VRAM (GB) for this run; 0 means no GPU")
+	cmd.Flags().Float64("assume-free-vram", 0, "Analyze as if this much of the GPU's VRAM (GB) were free for this run, instead of the free VRAM detected")
+	cmd.Flags().Float64("assume-ram", 0, "Analyze as if the system had this much RAM (GB), all available, for this run")
+	cmd.Flags().String("assume-backend", "", "Analyze as if on this backend for this run: cuda, metal, rocm, vulkan, sycl, cpu, cpu-arm")
+}
+
+// applyAssumeFlags patches specs with cmd's --assume-* flags; it returns specs unchanged when none is set.
+func applyAssumeFlags(cmd *cobra.Command, specs *hardware.SystemSpecs) (*hardware.SystemSpecs, error) {
+	var a hardware.Assumptions
+	set := false
+	if cmd.Flags().Changed("assume-vram") {
+		v, _ := cmd.Flags().GetFloat64("assume-vram")
+		a.VRAMGB, set = &v, true
+	}
+	if cmd.Flags().Changed("assume-free-vram") {
+		v, _ := cmd.Flags().GetFloat64("assume-free-vram")
+		a.FreeVRAMGB, set = &v, true
+	}
+	if cmd.Flags().Changed("assume-ram") {
+		v, _ := cmd.Flags().GetFloat64("assume-ram")
+		a.RAMGB, set = &v, true
+	}
+	if cmd.Flags().Changed("assume-backend") {
+		name, _ := cmd.Flags().GetString("assume-backend")
+		b, ok := hardware.ParseBackend(name)
+		if !ok {
+			return nil, withExit(ExitUsage, fmt.Errorf("unknown backend %q", name))
+		}
+		a.Backend, set = &b, true
+	}
+	if !set {
+		return specs, nil
+	}
+	patched, err := specs.WithAssumptions(a)
+	if err != nil {
+		return nil, withExit(ExitUsage, err)
+	}
+	return patched, nil
+}
+
+// summaryFlags registers --summary/--summary-only on a JSON-producing command.
+func summaryFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("summary", false, "Add a summary object (counts by provider, fit level, use case) to JSON output")
+	cmd.Flags().Bool("summary-only", false, "Output only the JSON summary, without the models array")
+	cmd.MarkFlagsMutuallyExclusive("summary", "summary-only")
+}
+
+// fullFlag registers --full on a table-printing command: the system specs block comes first,
+// matching the "system" object its JSON output always has.
+func fullFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("full", false, "Start table output with the system specs block (JSON always includes it)")
+}
+
+// applyFullFlag sets the display system-block mode from cmd's --full flag.
+func applyFullFlag(cmd *cobra.Command) {
+	full, _ := cmd.Flags().GetBool("full")
+	display.SetShowSystem(full)
+}
+
+// applySummaryFlags sets the display summary mode; it reports whether a summary was requested
+// (which implies JSON output).
+func applySummaryFlags(cmd *cobra.Command) bool {
+	if only, _ := cmd.Flags().GetBool("summary-only"); only {
+		display.SetSummary(display.SummaryOnly)
+		return true
+	}
+	if sum, _ := cmd.Flags().GetBool("summary"); sum {
+		display.SetSummary(display.SummaryInclude)
+		return true
+	}
+	display.SetSummary(display.SummaryOff)
+	return false
+}
+
+// defaultPageSize is the --page size when --page-size is not given.
+const defaultPageSize = 20
+
+// showPole prints ranked fits via --output-template, one --page, or the full table/JSON.
+// An empty result still prints (an empty table or JSON list) and then exits with ExitNoModels.
+func showPole(specs *hardware.SystemSpecs, fits []*pole.ModelFit, useJSON bool) error {
+	if err := printPole(specs, fits, useJSON); err != nil {
+		return err
+	}
+	if len(fits) == 0 {
+		return withExit(ExitNoModels, nil)
+	}
+	return nil
+}
+
+// applyFormat applies --format: json turns on --json, html the HTML report of model lists.
+func applyFormat(format string) error {
+	htmlReport = false
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "table":
+	case "json":
+		globalJSON = true
+	case "html":
+		if globalJSON {
+			return fmt.Errorf("--format html cannot be combined with --json")
+		}
+		htmlReport = true
+	default:
+		return fmt.Errorf("invalid format %q (use table, json, or html)", format)
+	}
+	return nil
+}
+
+func printPole(specs *hardware.SystemSpecs, fits []*pole.ModelFit, useJSON bool) error {
+	paged := globalPage > 0 || globalPageSize > 0
+	size := globalPageSize
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	if outputTemplate != nil {
+		if paged {
+			start, end := display.PageBounds(len(fits), globalPage, size)
+			fits = fits[start:end]
+		}
+		return display.FitsTemplate(os.Stdout, outputTemplate, fits)
+	}
+	if htmlReport {
+		if paged {
+			start, end := display.PageBounds(len(fits), globalPage, size)
+			fits = fits[start:end]
+		}
+		return display.PoleHTML(os.Stdout, specs, fits)
+	}
+	if paged {
+		return display.PolePage(os.Stdout, specs, fits, globalPage, size, useJSON)
+	}
+	display.Pole(os.Stdout, specs, fits, useJSON)
+	return nil
+}
+
+// cacheFetchedModel adds m, fetched for repoID, to the cache and records the fetch in the fetch
+// log. Logging is best-effort: a log failure is reported on stderr but does not fail the fetch.
+func cacheFetchedModel(repoID string, m *models.LlmModel) error {
+	if err := models.AppendModelToCache(m); err != nil {
+		return err
+	}
+	if err := models.AppendFetchLog(models.NewFetchLogEntry(m, fetch.ModelURL(repoID))); err != nil && !globalQuiet {
+		fmt.Fprintf(os.Stderr, "Could not write the fetch log: %v\n", err)
+	}
+	return nil
+}
+
+// fetchAndCache fetches repoIDs from HuggingFace in one rate-limited batch (fetch.FetchModels)
+// and caches each model fetched. Every repo is tried; the first failure is returned.
+func fetchAndCache(repoIDs []string) error {
+	var first error
+	for _, r := range fetch.FetchModels(repoIDs, fetch.BatchOptions{Options: fetch.Options{Thorough: globalThorough}}) {
+		var err error
+		if r.Err != nil {
+			err = withExit(ExitFetch, fmt.Errorf("could not fetch %s: %w", r.RepoID, r.Err))
+		} else if cerr := cacheFetchedModel(r.RepoID, r.Model); cerr != nil {
+			err = fmt.Errorf("could not save %s to cache: %w", r.RepoID, cerr)
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// droppedFetches returns the repos in the fetch log that list leaves out, each once in log order:
+// the fetched models that writing list over the cache would lose.
+func droppedFetches(logged []models.FetchLogEntry, list []models.LlmModel) []string {
+	listed := make(map[string]bool, len(list))
+	for _, m := range list {
+		listed[m.Name] = true
+	}
+	var repos []string
+	for _, e := range logged {
+		if !listed[e.RepoID] && !slices.Contains(repos, e.RepoID) {
+			repos = append(repos, e.RepoID)
+		}
+	}
+	return repos
+}
+
 func confirmFetch(query string) bool {
-	fmt.Printf("%s not in list. Fetch from HuggingFace? [y/N] ", query)
-	scanner := bufio.NewScanner(os.Stdin)
+	return shouldFetch(query, currentFetchMode(), os.Stdin, os.Stdout)
+}
+
+// shouldFetch decides whether to fetch query from HuggingFace, prompting on in/out in fetchPrompt mode.
+func shouldFetch(query string, mode fetchMode, in io.Reader, out io.Writer) bool {
+	switch mode {
+	case fetchAuto:
+		return true
+	case fetchSkip:
+		return false
+	}
+	fmt.Fprintf(out, "%s not in list. Fetch from HuggingFace? [y/N] ", query)
+	scanner := bufio.NewScanner(in)
 	if !scanner.Scan() {
 		return false
 	}
 	line := strings.TrimSpace(strings.ToLower(scanner.Text()))
 	return line == "y" || line == "yes"
 }
+
+// warnIfStaleList prints a one-line update-list suggestion to stderr when the model list is old,
+// once per embedded list (see staleListWarning).
+func warnIfStaleList() {
+	generated, err := models.ListGeneratedAt()
+	if err != nil {
+		return
+	}
+	staleListWarning(os.Stderr, staleMarkerPath(), generated, models.CacheModTime(), time.Now())
+}
+
+// staleMarkerPath is where staleListWarning records the list it warned about, next to the model
+// cache; "" when there is no config dir.
+func staleMarkerPath() string {
+	cachePath, err := models.CachePath()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(cachePath), "stale-warned")
+}
+
+// staleListWarning writes the update-list suggestion to w when the list generated at generated
+// is stale, unless the marker file says this list was already warned about. It then writes the
+// marker, so later runs stay quiet until a newer binary's list goes stale in turn. Without a
+// marker path, or when the marker cannot be written, the warning repeats on each run.
+func staleListWarning(w io.Writer, marker string, generated, cacheModTime, now time.Time) {
+	if !models.IsListStale(generated, cacheModTime, now, models.StaleListAge) {
+		return
+	}
+	stamp := generated.Format("2006-01-02")
+	if marker != "" {
+		if data, err := os.ReadFile(marker); err == nil && strings.TrimSpace(string(data)) == stamp {
+			return
+		}
+	}
+	days := int(now.Sub(generated).Hours() / 24)
+	fmt.Fprintf(w, "llmpole: the model list is %d days old; run `llmpole update-list` for fresher recommendations.\n", days)
+	if marker != "" && os.MkdirAll(filepath.Dir(marker), 0755) == nil {
+		_ = os.WriteFile(marker, []byte(stamp+"\n"), 0644)
+	}
+}
@@ -1,6 +1,23 @@
 package cli
 
-import "testing"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shayne-snap/llmpole/internal/fetch"
+	"github.com/shayne-snap/llmpole/internal/hardware"
+	"github.com/shayne-snap/llmpole/internal/models"
+	"github.com/shayne-snap/llmpole/internal/pole"
+
+	"github.com/spf13/cobra"
+)
 
 func TestLooksLikeRepoID(t *testing.T) {
 	tests := []struct {
@@ -28,3 +45,323 @@ func TestLooksLikeRepoID(t *testing.T) {
 		})
 	}
 }
+
+func TestStaleListWarning_OncePerList(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "llmpole", "stale-warned")
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -200)
+	var buf bytes.Buffer
+	staleListWarning(&buf, marker, old, time.Time{}, now)
+	if !strings.Contains(buf.String(), "200 days old") {
+		t.Fatalf("first run: %q, want the update-list suggestion", buf.String())
+	}
+	buf.Reset()
+	staleListWarning(&buf, marker, old, time.Time{}, now.Add(time.Hour))
+	if buf.Len() != 0 {
+		t.Errorf("second run: %q, want no repeat for the same list", buf.String())
+	}
+	staleListWarning(&buf, marker, old.AddDate(0, 0, 50), time.Time{}, now)
+	if !strings.Contains(buf.String(), "150 days old") {
+		t.Errorf("newer stale list: %q, want a fresh warning", buf.String())
+	}
+	buf.Reset()
+	staleListWarning(&buf, "", old, time.Time{}, now)
+	staleListWarning(&buf, "", old, time.Time{}, now)
+	if strings.Count(buf.String(), "days old") != 2 {
+		t.Errorf("without a marker path: %q, want the warning each run", buf.String())
+	}
+}
+
+func TestWarnIfNoLicenseData(t *testing.T) {
+	var buf bytes.Buffer
+	warnIfNoLicenseData(&buf, []*models.LlmModel{{Name: "org/a"}, {Name: "org/b"}})
+	if !strings.Contains(buf.String(), "no model in the list has license data") {
+		t.Errorf("no licensed models: %q, want a warning", buf.String())
+	}
+	buf.Reset()
+	warnIfNoLicenseData(&buf, []*models.LlmModel{{Name: "org/a"}, {Name: "org/fetched", License: "mit"}})
+	if buf.Len() != 0 {
+		t.Errorf("one licensed model: %q, want no warning", buf.String())
+	}
+}
+
+func TestShouldFetch(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       fetchMode
+		input      string
+		want       bool
+		wantPrompt bool
+	}{
+		{"prompt yes", fetchPrompt, "y\n", true, true},
+		{"prompt no", fetchPrompt, "n\n", false, true},
+		{"prompt EOF", fetchPrompt, "", false, true},
+		{"auto", fetchAuto, "", true, false},
+		{"skip", fetchSkip, "y\n", false, false},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		got := shouldFetch("org/model", tt.mode, strings.NewReader(tt.input), &out)
+		if got != tt.want {
+			t.Errorf("%s: shouldFetch = %v, want %v", tt.name, got, tt.want)
+		}
+		if prompted := strings.Contains(out.String(), "Fetch from HuggingFace?"); prompted != tt.wantPrompt {
+			t.Errorf("%s: prompted = %v, want %v", tt.name, prompted, tt.wantPrompt)
+		}
+	}
+}
+
+func TestCurrentFetchMode(t *testing.T) {
+	origTTY, origFetch, origNoFetch := stdinIsTTY, globalFetch, globalNoFetch
+	defer func() { stdinIsTTY, globalFetch, globalNoFetch = origTTY, origFetch, origNoFetch }()
+
+	tests := []struct {
+		tty, fetch, noFetch bool
+		want                fetchMode
+	}{
+		{true, false, false, fetchPrompt},
+		{false, false, false, fetchSkip},
+		{true, false, true, fetchSkip},
+		{false, true, false, fetchAuto},
+	}
+	for _, tt := range tests {
+		tty := tt.tty
+		stdinIsTTY = func() bool { return tty }
+		globalFetch, globalNoFetch = tt.fetch, tt.noFetch
+		if got := currentFetchMode(); got != tt.want {
+			t.Errorf("currentFetchMode(tty=%v, fetch=%v, no-fetch=%v) = %v, want %v", tt.tty, tt.fetch, tt.noFetch, got, tt.want)
+		}
+	}
+}
+
+func TestLoadDB_CustomModelsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.json")
+	body := `[{"name": "acme/internal-13b", "provider": "Acme", "parameter_count": "13B", "min_ram_gb": 10, "recommended_ram_gb": 16, "context_length": 8192}]`
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	orig := globalModelsFile
+	defer func() { globalModelsFile = orig }()
+	globalModelsFile = path
+
+	db, err := loadDB()
+	if err != nil {
+		t.Fatalf("loadDB: %v", err)
+	}
+	found := false
+	for _, f := range pole.AnalyzeAll(db.GetAllModels(), &hardware.SystemSpecs{TotalRAMGB: 64, AvailableRAMGB: 48, TotalCPUCores: 8, Backend: hardware.BackendCpuX86}) {
+		if f.Model.Name == "acme/internal-13b" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("custom model acme/internal-13b missing from analysis")
+	}
+}
+
+func TestApplyAssumeFlags_ChangesFitForOneRun(t *testing.T) {
+	vram := 6.0
+	detected := &hardware.SystemSpecs{
+		TotalRAMGB: 16, AvailableRAMGB: 12, TotalCPUCores: 8, CPUName: "Test CPU",
+		HasGPU: true, GpuVRAMGB: &vram, GpuCount: 1, Backend: hardware.BackendCuda,
+		Gpus: []hardware.GpuInfo{{Name: "Test GPU", VRAMGB: &vram, Backend: hardware.BackendCuda, Count: 1}},
+	}
+	minVRAM := 14.0
+	m := &models.LlmModel{Name: "test-14b", ParameterCount: "14B", MinRAMGB: 16, RecommendedRAMGB: 20, MinVRAMGB: &minVRAM, Quantization: "Q4_K_M", ContextLength: 8192, UseCase: "general"}
+	run := func(args ...string) (*hardware.SystemSpecs, *pole.ModelFit) {
+		t.Helper()
+		cmd := &cobra.Command{Use: "test"}
+		assumeFlags(cmd)
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Fatalf("ParseFlags(%v): %v", args, err)
+		}
+		specs, err := applyAssumeFlags(cmd, detected)
+		if err != nil {
+			t.Fatalf("applyAssumeFlags(%v): %v", args, err)
+		}
+		return specs, pole.Analyze(m, specs)
+	}
+
+	specs, fit := run()
+	if specs != detected || fit.FitLevel != pole.FitTooTight {
+		t.Errorf("no flags: fit %s, want the detected specs and Too Tight", fit.FitLevel)
+	}
+	specs, fit = run("--assume-vram", "24")
+	if fit.RunMode != pole.RunModeGpu || fit.FitLevel == pole.FitTooTight {
+		t.Errorf("--assume-vram 24: %s / %s, want a GPU fit", fit.RunMode, fit.FitLevel)
+	}
+	if specs.Gpus[0].Name != "Test GPU" || specs.Gpus[0].VRAMSource != hardware.AssumedSource {
+		t.Errorf("--assume-vram 24: gpu = %+v, want Test GPU with assumed VRAM", specs.Gpus[0])
+	}
+	_, fit = run("--assume-vram", "0", "--assume-ram", "64")
+	if fit.RunMode != pole.RunModeCpuOnly || fit.FitLevel == pole.FitTooTight {
+		t.Errorf("--assume-vram 0 --assume-ram 64: %s / %s, want a CPU-only fit", fit.RunMode, fit.FitLevel)
+	}
+	_, fit = run("--assume-backend", "metal", "--assume-ram", "32")
+	if fit.RunMode != pole.RunModeGpu {
+		t.Errorf("--assume-backend metal --assume-ram 32: run mode %s, want GPU on unified memory", fit.RunMode)
+	}
+	specs, fit = run("--assume-vram", "24", "--assume-free-vram", "12")
+	if specs.GpuFreeVRAMGB == nil || *specs.GpuFreeVRAMGB != 12 || fit.FitLevel != pole.FitTooTight {
+		t.Errorf("--assume-vram 24 --assume-free-vram 12: free %v, fit %s; want 12 GB free, too little to load 14 GB", specs.GpuFreeVRAMGB, fit.FitLevel)
+	}
+	if detected.TotalRAMGB != 16 || *detected.GpuVRAMGB != 6 || detected.Gpus[0].VRAMSource != "" {
+		t.Errorf("detected specs were modified: %+v", detected)
+	}
+
+	for _, args := range [][]string{
+		{"--assume-ram", "0"},
+		{"--assume-vram", "-1"},
+		{"--assume-backend", "tpu"},
+		{"--assume-vram", "8", "--assume-backend", "cpu"},
+		{"--assume-free-vram", "8"},
+		{"--assume-free-vram", "-1"},
+		{"--assume-vram", "0", "--assume-free-vram", "1"},
+	} {
+		cmd := &cobra.Command{Use: "test"}
+		assumeFlags(cmd)
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Fatalf("ParseFlags(%v): %v", args, err)
+		}
+		if _, err := applyAssumeFlags(cmd, detected); ExitCode(err) != ExitUsage {
+			t.Errorf("applyAssumeFlags(%v) exit = %d, want usage error", args, ExitCode(err))
+		}
+	}
+}
+
+func TestCacheFetchedModel_AppendsFetchLog(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	params := uint64(7_600_000_000)
+	for _, name := range []string{"org/first-7b", "org/second-7b"} {
+		m := &models.LlmModel{Name: name, ParameterCount: "7.6B", ParametersRaw: &params, Quantization: "Q4_K_M", ContextLength: 32768}
+		if err := cacheFetchedModel(name, m); err != nil {
+			t.Fatalf("cacheFetchedModel(%s): %v", name, err)
+		}
+	}
+	path, err := models.FetchLogPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("fetch log not written: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("fetch log has %d lines, want one per fetch:\n%s", len(lines), body)
+	}
+	var e models.FetchLogEntry
+	if err := json.Unmarshal([]byte(lines[1]), &e); err != nil {
+		t.Fatalf("log line %q is not JSON: %v", lines[1], err)
+	}
+	if e.RepoID != "org/second-7b" || e.Source != fetch.ModelURL("org/second-7b") || e.ParametersRaw == nil || *e.ParametersRaw != params ||
+		e.Quantization != "Q4_K_M" || e.ContextLength != 32768 || e.Time.IsZero() {
+		t.Errorf("log entry = %+v, want the repo, its source URL, time, and resolved params", e)
+	}
+	if entries, err := models.ReadFetchLog(); err != nil || len(entries) != 2 || entries[0].RepoID != "org/first-7b" {
+		t.Errorf("ReadFetchLog = %v, %v; want both fetches oldest first", entries, err)
+	}
+}
+
+func TestLoadScoreWeights_FromConfigDir(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if sw, err := loadScoreWeights(); err != nil || sw != nil {
+		t.Fatalf("loadScoreWeights without a file = %v, %v; want nil (built-in weights)", sw, err)
+	}
+	cachePath, err := models.CachePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(filepath.Dir(cachePath), "weights.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"chat": {"quality": 0.7, "speed": 0.1, "fit": 0.1, "context": 0.1}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sw, err := loadScoreWeights()
+	if err != nil || sw == nil {
+		t.Fatalf("loadScoreWeights = %v, %v", sw, err)
+	}
+	if want := (pole.Weights{Quality: 0.7, Speed: 0.1, Fit: 0.1, Context: 0.1}); sw.Chat != want {
+		t.Errorf("chat weights = %+v, want %+v", sw.Chat, want)
+	}
+	if sw.General != pole.DefaultScoreWeights().General {
+		t.Errorf("general weights = %+v, want the defaults", sw.General)
+	}
+	if err := os.WriteFile(path, []byte(`{"chat": [1, 2]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadScoreWeights(); err == nil || !strings.Contains(err.Error(), "weights.json") {
+		t.Errorf("malformed weights.json: err = %v, want an error naming the file", err)
+	}
+}
+
+func TestDetectSpecsRefresh_BypassesCache(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, name := range []string{"NVIDIA_VISIBLE_DEVICES", "CUDA_VISIBLE_DEVICES", "HIP_VISIBLE_DEVICES"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	path := specsCachePath()
+	if path == "" {
+		t.Skip("no config dir")
+	}
+	cached := fmt.Sprintf(`{"detected_at": %q, "specs": {"cpu_name": "x", "gpus": [{"name": "Cached Test GPU", "vram_source": "nvidia-smi memory.total"}]}}`, time.Now().Format(time.RFC3339Nano))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(cached), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	specs, err := detectSpecsRefresh(false)
+	if err != nil || len(specs.Gpus) != 1 || specs.Gpus[0].Name != "Cached Test GPU" {
+		t.Fatalf("detectSpecsRefresh(false) = %+v, %v; want the cached GPU", specs, err)
+	}
+	if !strings.Contains(specs.Gpus[0].VRAMSource, "cached") {
+		t.Errorf("cached VRAM source = %q, want it marked as cached", specs.Gpus[0].VRAMSource)
+	}
+	specs, err = detectSpecsRefresh(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, g := range specs.Gpus {
+		if g.Name == "Cached Test GPU" {
+			t.Errorf("detectSpecsRefresh(true) returned the cached GPU, want a fresh detection")
+		}
+	}
+}
+
+func TestDroppedFetches(t *testing.T) {
+	logged := []models.FetchLogEntry{{RepoID: "org/kept"}, {RepoID: "org/dropped"}, {RepoID: "org/other"}, {RepoID: "org/dropped"}}
+	list := []models.LlmModel{{Name: "org/kept"}, {Name: "org/listed"}}
+	if got, want := droppedFetches(logged, list), []string{"org/dropped", "org/other"}; !slices.Equal(got, want) {
+		t.Errorf("droppedFetches = %v, want %v", got, want)
+	}
+	if got := droppedFetches(nil, list); len(got) != 0 {
+		t.Errorf("droppedFetches without a log = %v, want none", got)
+	}
+}
+
+func TestBuildOptions_FromFlags(t *testing.T) {
+	origMargin, origKV := globalMargin, globalKVCache
+	defer func() { globalMargin, globalKVCache = origMargin, origKV }()
+
+	globalMargin, globalKVCache = 20, "q8_0"
+	opts, err := buildOptions()
+	if err != nil {
+		t.Fatalf("buildOptions: %v", err)
+	}
+	if opts.SafetyMargin != 0.2 || opts.KVCache != models.KVCacheQ8 {
+		t.Errorf("options = margin %g, KV %v; want 0.2 and q8_0 from the flags", opts.SafetyMargin, opts.KVCache)
+	}
+
+	globalMargin = 95
+	if _, err := buildOptions(); err == nil {
+		t.Error("buildOptions with --margin 95: want an error")
+	}
+	globalMargin, globalKVCache = 20, "q3"
+	if _, err := buildOptions(); err == nil {
+		t.Error("buildOptions with --kv-cache-type q3: want an error")
+	}
+}
@@ -1,12 +1,14 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/shayne-snap/llmpole/internal/display"
 	"github.com/shayne-snap/llmpole/internal/fetch"
-	"github.com/shayne-snap/llmpole/internal/hardware"
 	"github.com/shayne-snap/llmpole/internal/models"
 	"github.com/shayne-snap/llmpole/internal/pole"
 
@@ -14,50 +16,128 @@ import (
 )
 
 var infoCmd = &cobra.Command{
-	Use:   "info [model]",
-	Short: "Show detailed information about a model",
-	Args:  cobra.ExactArgs(1),
+	Use:   "info [model | Modelfile]",
+	Short: "Show detailed information about a model, or the model an Ollama Modelfile configures",
+	Args:  usageArgs(cobra.ExactArgs(1)),
 	RunE:  runInfo,
 }
 
+func init() {
+	contextFlag(infoCmd)
+	assumeFlags(infoCmd)
+	infoCmd.Flags().Bool("compare-hardware", false, "Show the model's fit, run mode, and tok/s on each built-in hardware profile instead of this machine")
+	infoCmd.Flags().Bool("memory-only", false, "Print only the memory in GB the model needs at its best quant (with --json: the weights/KV cache/overhead breakdown)")
+	infoCmd.MarkFlagsMutuallyExclusive("compare-hardware", "memory-only")
+	infoCmd.Flags().Bool("suggest-alternative", false, "When the model is Too Tight, also show the highest-quality model with the same use case that runs on this hardware")
+}
+
 func runInfo(cmd *cobra.Command, args []string) error {
 	query := args[0]
-	db, err := models.NewDB()
+	db, err := loadDB()
 	if err != nil {
 		return err
 	}
-	specs, err := hardware.Detect()
+	specs, err := detectSpecs()
 	if err != nil {
 		return err
 	}
+	if specs, err = applyAssumeFlags(cmd, specs); err != nil {
+		return err
+	}
+	var m *models.LlmModel
+	if st, statErr := os.Stat(query); statErr == nil && st.Mode().IsRegular() {
+		// An Ollama Modelfile: analyze the configuration it describes.
+		if m, err = fetch.ReadModelfile(query, db.GetAllModels()); err != nil {
+			return withExit(ExitNoModels, err)
+		}
+	} else if m, err = findInfoModel(query, db); err != nil || m == nil {
+		return err
+	}
+	opts := analyzeOptions()
+	fit := pole.AnalyzeWithOptions(m, specs, opts)
+	if compare, _ := cmd.Flags().GetBool("compare-hardware"); compare {
+		display.ProfileMatrix(os.Stdout, m.Name, pole.AnalyzeAcrossProfiles(m, opts), globalJSON)
+		return nil
+	}
+	if memOnly, _ := cmd.Flags().GetBool("memory-only"); memOnly {
+		display.MemoryOnly(os.Stdout, pole.EstimateMemory(fit, opts), globalJSON)
+		return nil
+	}
+	if outputTemplate != nil {
+		return display.FitsTemplate(os.Stdout, outputTemplate, []*pole.ModelFit{fit})
+	}
+	if suggest, _ := cmd.Flags().GetBool("suggest-alternative"); suggest && fit.FitLevel == pole.FitTooTight {
+		alt := pole.ClosestAlternative(fit, pole.AnalyzeAllWithOptions(catalogModels(db), specs, opts))
+		display.InfoWithAlternative(os.Stdout, specs, fit, alt, globalJSON)
+		return nil
+	}
+	display.Info(os.Stdout, specs, fit, globalJSON)
+	return nil
+}
+
+// findInfoModel looks query up in db, offering to fetch a missing HuggingFace repo. It returns a
+// nil model and error when the fetched model could not be cached (already reported).
+func findInfoModel(query string, db *models.ModelDatabase) (*models.LlmModel, error) {
 	results := db.FindModel(query)
 	if len(results) == 0 && looksLikeRepoID(query) {
 		if confirmFetch(query) {
-			m, err := fetch.FetchModel(query)
+			m, err := fetch.FetchModelWithOptions(query, fetch.Options{Thorough: globalThorough})
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Could not fetch model: %v\n", err)
-				return nil
+				return nil, withExit(ExitFetch, fmt.Errorf("could not fetch model: %w", err))
 			}
-			if err := models.AppendModelToCache(m); err != nil {
+			if err := cacheFetchedModel(query, m); err != nil {
 				fmt.Fprintf(os.Stderr, "Could not save to cache: %v\n", err)
-				return nil
+				return nil, nil
 			}
-			db, _ = models.NewDB()
+			db, _ = loadDB()
 			results = db.FindModel(query)
 		}
 	}
+	return singleMatch(query, results)
+}
+
+// findModels resolves each query like findInfoModel, but fetches the missing HuggingFace repos
+// in one rate-limited batch rather than one at a time.
+func findModels(queries []string, db *models.ModelDatabase) ([]*models.LlmModel, error) {
+	var missing []string
+	for _, query := range queries {
+		if len(db.FindModel(query)) == 0 && looksLikeRepoID(query) && !slices.Contains(missing, query) && confirmFetch(query) {
+			missing = append(missing, query)
+		}
+	}
+	if len(missing) > 0 {
+		if err := fetchAndCache(missing); err != nil {
+			return nil, err
+		}
+		var err error
+		if db, err = loadDB(); err != nil {
+			return nil, err
+		}
+	}
+	found := make([]*models.LlmModel, 0, len(queries))
+	for _, query := range queries {
+		m, err := singleMatch(query, db.FindModel(query))
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, m)
+	}
+	return found, nil
+}
+
+// singleMatch returns the one model in results, or an error naming the query: ExitNoModels when
+// there is no match, ExitUsage listing the candidates when the match is ambiguous.
+func singleMatch(query string, results []*models.LlmModel) (*models.LlmModel, error) {
 	if len(results) == 0 {
-		fmt.Printf("\nNo model found matching '%s'\n", query)
-		return nil
+		return nil, withExit(ExitNoModels, fmt.Errorf("no model found matching '%s'", query))
 	}
 	if len(results) > 1 {
-		fmt.Println("\nMultiple models found. Please be more specific:")
+		var b strings.Builder
+		fmt.Fprintf(&b, "multiple models match '%s'; please be more specific:", query)
 		for _, m := range results {
-			fmt.Printf("  - %s\n", m.Name)
+			fmt.Fprintf(&b, "\n  - %s", m.Name)
 		}
-		return nil
+		return nil, withExit(ExitUsage, errors.New(b.String()))
 	}
-	fit := pole.Analyze(results[0], specs)
-	display.Info(os.Stdout, specs, fit, globalJSON)
-	return nil
+	return results[0], nil
 }
@@ -1,7 +1,9 @@
 package cli
 
 import (
+	"fmt"
 	"os"
+	"time"
 
 	"github.com/shayne-snap/llmpole/internal/display"
 	"github.com/shayne-snap/llmpole/internal/models"
@@ -15,11 +17,47 @@ var listCmd = &cobra.Command{
 	RunE:  runList,
 }
 
+func init() {
+	listCmd.Flags().Bool("age", false, "Show when the embedded model list was generated and when the cache was last updated")
+	licenseFlag(listCmd)
+	minContextFlag(listCmd)
+}
+
 func runList(cmd *cobra.Command, args []string) error {
-	db, err := models.NewDB()
+	if age, _ := cmd.Flags().GetBool("age"); age {
+		return printListAge()
+	}
+	db, err := loadDB()
+	if err != nil {
+		return err
+	}
+	all := catalogModels(db)
+	if hideUnrunnable() {
+		specs, err := detectSpecs()
+		if err != nil {
+			return err
+		}
+		all = runnableModels(all, specs)
+	}
+	if globalVariants {
+		display.List(os.Stdout, all)
+		return nil
+	}
+	display.ListGroups(os.Stdout, models.GroupDuplicates(all))
+	return nil
+}
+
+func printListAge() error {
+	generated, err := models.ListGeneratedAt()
 	if err != nil {
 		return err
 	}
-	display.List(os.Stdout, db.GetAllModels())
+	now := time.Now()
+	fmt.Printf("Embedded list generated: %s (%d days ago)\n", generated.Format("2006-01-02"), int(now.Sub(generated).Hours()/24))
+	if cached := models.CacheModTime(); !cached.IsZero() {
+		fmt.Printf("User cache updated:      %s (%d days ago)\n", cached.Format("2006-01-02"), int(now.Sub(cached).Hours()/24))
+	} else {
+		fmt.Println("User cache updated:      never (run `llmpole update-list`)")
+	}
 	return nil
 }
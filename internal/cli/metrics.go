@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/shayne-snap/llmpole/internal/display"
+	"github.com/shayne-snap/llmpole/internal/pole"
+
+	"github.com/spf13/cobra"
+)
+
+var metricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Print system capacity and model fit counts as Prometheus text-format gauges",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE:  runMetrics,
+}
+
+func runMetrics(cmd *cobra.Command, args []string) error {
+	specs, err := detectSpecs()
+	if err != nil {
+		return err
+	}
+	db, err := loadDB()
+	if err != nil {
+		return err
+	}
+	fits := pole.AnalyzeAllWithOptions(catalogModels(db), specs, analyzeOptions())
+	display.Metrics(os.Stdout, specs, fits)
+	return nil
+}
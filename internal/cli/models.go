@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+
+	"github.com/shayne-snap/llmpole/internal/display"
+	"github.com/shayne-snap/llmpole/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "Model catalog maintenance",
+}
+
+var modelsValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check a catalog JSON file (default: the embedded list) for inconsistent entries",
+	Args:  usageArgs(cobra.MaximumNArgs(1)),
+	RunE:  runModelsValidate,
+}
+
+var modelsDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show models added or changed by the cached list (from update-list) versus the embedded one",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE:  runModelsDiff,
+}
+
+func init() {
+	modelsDiffCmd.Flags().String("against", "embedded", "Baseline to compare the cached list with (embedded: the list built into this binary)")
+	modelsCmd.AddCommand(modelsValidateCmd, modelsDiffCmd)
+}
+
+func runModelsValidate(cmd *cobra.Command, args []string) error {
+	source := "embedded list"
+	var list []*models.LlmModel
+	var err error
+	if len(args) == 1 {
+		source = args[0]
+		list, err = models.ReadModelsFile(source)
+	} else {
+		list, err = models.EmbeddedModels()
+	}
+	if err != nil {
+		return err
+	}
+	issues := models.Validate(list)
+	display.ValidationReport(os.Stdout, source, len(list), issues, globalJSON)
+	if len(issues) > 0 {
+		return withExit(ExitInvalid, nil)
+	}
+	return nil
+}
+
+func runModelsDiff(cmd *cobra.Command, args []string) error {
+	against, _ := cmd.Flags().GetString("against")
+	if against != "embedded" {
+		return usageErrorf("unknown baseline %q for --against (supported: embedded)", against)
+	}
+	base, err := models.EmbeddedModels()
+	if err != nil {
+		return err
+	}
+	cached, err := models.CachedModels()
+	if errors.Is(err, fs.ErrNotExist) {
+		return errors.New("no cached model list; run `llmpole update-list` first")
+	}
+	if err != nil {
+		return err
+	}
+	display.CatalogDiff(os.Stdout, models.DiffOverlay(base, cached), "cached list", "embedded list", globalJSON)
+	return nil
+}
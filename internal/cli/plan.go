@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/shayne-snap/llmpole/internal/display"
+	"github.com/shayne-snap/llmpole/internal/pole"
+
+	"github.com/spf13/cobra"
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan <model> <model>...",
+	Short: "Check whether several models fit in memory at the same time, e.g. an agent stack",
+	Args:  usageArgs(cobra.MinimumNArgs(2)),
+	RunE:  runPlan,
+}
+
+func runPlan(cmd *cobra.Command, args []string) error {
+	db, err := loadDB()
+	if err != nil {
+		return err
+	}
+	specs, err := detectSpecs()
+	if err != nil {
+		return err
+	}
+	opts := analyzeOptions()
+	found, err := findModels(args, db)
+	if err != nil {
+		return err
+	}
+	fits := make([]*pole.ModelFit, 0, len(args))
+	for _, m := range found {
+		fits = append(fits, pole.AnalyzeWithOptions(m, specs, opts))
+	}
+	p := pole.PlanTogether(fits, specs, opts)
+	display.Plan(os.Stdout, p, globalJSON)
+	if !p.Fits {
+		return withExit(ExitNoModels, nil)
+	}
+	return nil
+}
@@ -1,11 +1,7 @@
 package cli
 
 import (
-	"os"
-
 	"github.com/shayne-snap/llmpole/internal/display"
-	"github.com/shayne-snap/llmpole/internal/hardware"
-	"github.com/shayne-snap/llmpole/internal/models"
 	"github.com/shayne-snap/llmpole/internal/pole"
 
 	"github.com/spf13/cobra"
@@ -19,15 +15,24 @@ var poleCmd = &cobra.Command{
 
 func init() {
 	poleCmd.Flags().BoolP("perfect", "p", false, "Show only perfect fit")
-	poleCmd.Flags().UintP("limit", "n", 0, "Limit number of results")
+	limitFlag(poleCmd, 0, "Limit number of results: a count or a share of runnable models, e.g. 20%")
+	summaryFlags(poleCmd)
+	licenseFlag(poleCmd)
+	minContextFlag(poleCmd)
+	fullFlag(poleCmd)
+	contextFlag(poleCmd)
+	assumeFlags(poleCmd)
 }
 
 func runPole(cmd *cobra.Command, args []string) error {
-	specs, err := hardware.Detect()
+	specs, err := detectSpecs()
 	if err != nil {
 		return err
 	}
-	db, err := models.NewDB()
+	if specs, err = applyAssumeFlags(cmd, specs); err != nil {
+		return err
+	}
+	db, err := loadDB()
 	if err != nil {
 		return err
 	}
@@ -38,18 +43,20 @@ func runPole(cmd *cobra.Command, args []string) error {
 	}
 	limit := globalLimit
 	if cmd.Flags().Changed("limit") {
-		n, _ := cmd.Flags().GetUint("limit")
-		limit = n
+		limit = flagLimit(cmd)
 	}
-	useJSON := globalJSON
-	fits := pole.AnalyzeAll(db.GetAllModels(), specs)
-	fits = pole.RankModelsByFit(fits)
+	useJSON := applySummaryFlags(cmd) || globalJSON
+	applyFullFlag(cmd)
+	fits := pole.AnalyzeAllWithOptions(catalogModels(db), specs, analyzeOptions())
+	fits = rankFits(fits)
 	if perfect {
 		fits = pole.FilterPerfectOnly(fits)
 	}
-	if limit > 0 && len(fits) > int(limit) {
-		fits = fits[:limit]
+	fits = runnableFits(fits)
+	if !globalVariants {
+		fits = pole.CollapseDuplicates(fits)
 	}
-	display.Pole(os.Stdout, specs, fits, useJSON)
-	return nil
+	display.SetSummaryOf(fits)
+	fits = limit.Apply(fits)
+	return showPole(specs, fits, useJSON)
 }
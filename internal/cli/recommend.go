@@ -1,11 +1,12 @@
 package cli
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/shayne-snap/llmpole/internal/display"
 	"github.com/shayne-snap/llmpole/internal/hardware"
-	"github.com/shayne-snap/llmpole/internal/models"
 	"github.com/shayne-snap/llmpole/internal/pole"
 
 	"github.com/spf13/cobra"
@@ -18,31 +19,99 @@ var recommendCmd = &cobra.Command{
 }
 
 func init() {
-	recommendCmd.Flags().UintP("limit", "n", 5, "Limit number of recommendations")
+	limitFlag(recommendCmd, 5, "Limit number of recommendations: a count or a share of runnable models, e.g. 20%")
 	recommendCmd.Flags().String("use-case", "", "Filter by use case: general, coding, reasoning, chat, multimodal, embedding")
 	recommendCmd.Flags().Bool("json", true, "Output as JSON")
+	recommendCmd.Flags().Bool("tiers", false, "Group runnable models into Best (Perfect fit), Great (Good fit), and Works but tight (Marginal) tiers, --limit each, with a one-line rationale")
+	summaryFlags(recommendCmd)
+	licenseFlag(recommendCmd)
+	minContextFlag(recommendCmd)
+	fullFlag(recommendCmd)
+	contextFlag(recommendCmd)
+	assumeFlags(recommendCmd)
+	recommendCmd.Flags().Float64("budget", 0, "Rank against a hypothetical machine with this much memory (GB) instead of this one")
+	recommendCmd.Flags().String("budget-kind", "vram", "What --budget measures: vram (GPU memory) or ram (CPU-only)")
+	recommendCmd.Flags().String("backend", "", "Backend for --budget: cuda, metal, rocm, vulkan, sycl, cpu, cpu-arm (default cuda for vram, cpu for ram)")
+	for _, f := range []string{"assume-vram", "assume-ram", "assume-backend"} {
+		recommendCmd.MarkFlagsMutuallyExclusive("budget", f)
+	}
+}
+
+// budgetSpecs builds the hypothetical specs for recommend --budget.
+func budgetSpecs(budgetGB float64, kind, backendName string) (*hardware.SystemSpecs, error) {
+	kind = strings.ToLower(kind)
+	backend := hardware.BackendCuda
+	if kind == "ram" {
+		backend = hardware.BackendCpuX86
+	}
+	if backendName != "" {
+		b, ok := hardware.ParseBackend(backendName)
+		if !ok {
+			return nil, fmt.Errorf("unknown backend %q", backendName)
+		}
+		backend = b
+	}
+	return hardware.BudgetSpecs(budgetGB, kind, backend)
 }
 
 func runRecommend(cmd *cobra.Command, args []string) error {
-	specs, err := hardware.Detect()
-	if err != nil {
+	var specs *hardware.SystemSpecs
+	var err error
+	if cmd.Flags().Changed("budget") {
+		budget, _ := cmd.Flags().GetFloat64("budget")
+		kind, _ := cmd.Flags().GetString("budget-kind")
+		backend, _ := cmd.Flags().GetString("backend")
+		if specs, err = budgetSpecs(budget, kind, backend); err != nil {
+			return withExit(ExitUsage, err)
+		}
+	} else if specs, err = detectSpecs(); err != nil {
+		return err
+	} else if specs, err = applyAssumeFlags(cmd, specs); err != nil {
 		return err
 	}
-	db, err := models.NewDB()
+	db, err := loadDB()
 	if err != nil {
 		return err
 	}
-	limit, _ := cmd.Flags().GetUint("limit")
+	limit := flagLimit(cmd)
 	useCase, _ := cmd.Flags().GetString("use-case")
 	useJSON, _ := cmd.Flags().GetBool("json")
-	fits := pole.AnalyzeAll(db.GetAllModels(), specs)
+	useJSON = applySummaryFlags(cmd) || useJSON
+	applyFullFlag(cmd)
+	fits := pole.AnalyzeAllWithOptions(catalogModels(db), specs, analyzeOptions())
 	if useCase != "" {
 		fits = pole.FilterByUseCase(fits, useCase)
 	}
-	fits = pole.RankModelsByFit(fits)
-	if uint(len(fits)) > limit {
-		fits = fits[:limit]
+	fits = runnableFits(fits)
+	if tiered, _ := cmd.Flags().GetBool("tiers"); tiered {
+		by, _ := pole.ParseRankBy(globalRankBy)
+		tiers := pole.Tiers(fits, by)
+		runnable := 0
+		for i := range tiers {
+			tiers[i].Fits = limit.Apply(tiers[i].Fits)
+			runnable += len(tiers[i].Fits)
+		}
+		display.RecommendTiers(os.Stdout, specs, tiers, useJSON)
+		if runnable == 0 {
+			return withExit(ExitNoModels, nil)
+		}
+		return nil
+	}
+	fits = rankFits(fits)
+	display.SetSummaryOf(fits)
+	fits = limit.Apply(fits)
+	if outputTemplate != nil {
+		return display.FitsTemplate(os.Stdout, outputTemplate, fits)
+	}
+	if htmlReport {
+		if err := display.PoleHTML(os.Stdout, specs, fits); err != nil {
+			return err
+		}
+	} else {
+		display.Recommend(os.Stdout, specs, fits, useJSON)
+	}
+	if len(fits) == 0 {
+		return withExit(ExitNoModels, nil)
 	}
-	display.Recommend(os.Stdout, specs, fits, useJSON)
 	return nil
 }
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/shayne-snap/llmpole/internal/hardware"
+	"github.com/shayne-snap/llmpole/internal/models"
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+func TestBudgetSpecs_VRAMvsRAM(t *testing.T) {
+	vram8, vram2 := 8.0, 2.0
+	catalog := []*models.LlmModel{
+		{Name: "big-8b", ParameterCount: "8B", MinRAMGB: 10, RecommendedRAMGB: 12, MinVRAMGB: &vram8, Quantization: "Q4_K_M", ContextLength: 8192, UseCase: "general"},
+		{Name: "small-1.5b", ParameterCount: "1.5B", MinRAMGB: 3, RecommendedRAMGB: 4, MinVRAMGB: &vram2, Quantization: "Q4_K_M", ContextLength: 8192, UseCase: "general"},
+	}
+	top := func(kind, backend string) string {
+		specs, err := budgetSpecs(16, kind, backend)
+		if err != nil {
+			t.Fatalf("budgetSpecs(16, %q, %q): %v", kind, backend, err)
+		}
+		return pole.RankModelsByFit(pole.AnalyzeAll(catalog, specs))[0].Model.Name
+	}
+	vramTop, ramTop := top("vram", ""), top("ram", "")
+	if vramTop != "big-8b" {
+		t.Errorf("16 GB VRAM budget: top = %s, want big-8b", vramTop)
+	}
+	if ramTop != "small-1.5b" {
+		t.Errorf("16 GB RAM budget: top = %s, want small-1.5b", ramTop)
+	}
+}
+
+func TestBudgetSpecs_Validation(t *testing.T) {
+	specs, err := budgetSpecs(32, "vram", "metal")
+	if err != nil {
+		t.Fatalf("budgetSpecs(metal): %v", err)
+	}
+	if !specs.UnifiedMemory || specs.Backend != hardware.BackendMetal || specs.TotalRAMGB != 32 {
+		t.Errorf("metal budget = %+v, want unified 32 GB Metal", specs)
+	}
+	for _, tt := range []struct{ kind, backend string }{
+		{"vram", "cpu"},
+		{"ram", "cuda"},
+		{"disk", ""},
+		{"vram", "tpu"},
+	} {
+		if _, err := budgetSpecs(16, tt.kind, tt.backend); err == nil {
+			t.Errorf("budgetSpecs(16, %q, %q) = nil error, want error", tt.kind, tt.backend)
+		}
+	}
+	if _, err := budgetSpecs(0, "vram", ""); err == nil {
+		t.Error("budgetSpecs(0) = nil error, want error")
+	}
+}
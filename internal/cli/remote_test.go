@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shayne-snap/llmpole/internal/hardware"
+	"github.com/shayne-snap/llmpole/internal/models"
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+const remoteSystemJSON = `{
+  "system": {
+    "total_ram_gb": 128,
+    "available_ram_gb": 100,
+    "cpu_cores": 32,
+    "cpu_name": "Remote CPU",
+    "has_gpu": true,
+    "gpu_vram_gb": 48,
+    "gpu_name": "Remote GPU",
+    "gpu_count": 1,
+    "unified_memory": false,
+    "backend": "CUDA",
+    "gpus": [{"name": "Remote GPU", "vram_gb": 48, "backend": "CUDA", "count": 1, "unified_memory": false}]
+  }
+}`
+
+func TestDetectSpecs_Remote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(remoteSystemJSON))
+	}))
+	defer server.Close()
+	globalRemote = server.URL
+	defer func() { globalRemote = "" }()
+
+	specs, err := detectSpecs()
+	if err != nil {
+		t.Fatalf("detectSpecs: %v", err)
+	}
+	if specs.CPUName != "Remote CPU" || specs.Backend != hardware.BackendCuda || len(specs.Gpus) != 1 {
+		t.Fatalf("unexpected remote specs: %+v", specs)
+	}
+	minVram := 40.0
+	m := &models.LlmModel{Name: "big-70b", ParameterCount: "70B", MinRAMGB: 44, RecommendedRAMGB: 80, MinVRAMGB: &minVram, Quantization: "Q4_K_M", ContextLength: 4096}
+	fit := pole.Analyze(m, specs)
+	if fit.RunMode != pole.RunModeGpu || fit.MemoryAvailableGB != 48 {
+		t.Errorf("fit = %v/%v GB, want GPU with 48 GB from remote specs", fit.RunMode, fit.MemoryAvailableGB)
+	}
+}
+
+func TestDetectSpecs_RemoteUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	globalRemote = server.URL
+	defer func() { globalRemote = "" }()
+
+	if _, err := detectSpecs(); err == nil {
+		t.Error("expected error for failing remote")
+	}
+}
@@ -3,9 +3,9 @@ package cli
 import (
 	"fmt"
 	"os"
+	"text/template"
 
 	"github.com/shayne-snap/llmpole/internal/display"
-	"github.com/shayne-snap/llmpole/internal/hardware"
 	"github.com/shayne-snap/llmpole/internal/models"
 	"github.com/shayne-snap/llmpole/internal/pole"
 	"github.com/shayne-snap/llmpole/internal/tui"
@@ -17,11 +17,49 @@ import (
 var Version string
 
 var (
-	globalPerfect bool
-	globalLimit   uint
-	globalJSON    bool
-	globalCLI     bool
-	showVersion   bool
+	globalPerfect       bool
+	globalLimit         pole.Limit
+	globalJSON          bool
+	globalFormat        string
+	htmlReport          bool
+	globalCLI           bool
+	globalASCII         bool
+	globalRemote        string
+	globalVariants      bool
+	globalMargin        float64
+	globalMoE           bool
+	globalDense         bool
+	globalWorkload      string
+	globalFetch         bool
+	globalNoFetch       bool
+	globalThorough      bool
+	globalModelsFile    string
+	globalTemplate      string
+	outputTemplate      *template.Template
+	globalPage          int
+	globalPageSize      int
+	globalCompact       bool
+	globalNotes         string
+	globalQuiet         bool
+	globalRankBy        string
+	globalGoodRoom      float64
+	globalMarginRoom    float64
+	globalMaxQuant      string
+	globalRuntime       string
+	globalKVCache       string
+	globalPreferGPU     bool
+	globalPreferCPU     bool
+	globalSuggestQuants bool
+	globalPrecision     int
+	globalUsability     float64
+	globalDenseOnly     bool
+	globalPromptTokens  int
+	globalUnrunnable    bool
+	globalRefreshHW     bool
+	topByProvider       bool
+	sortProviders       string
+	themeName           string
+	showVersion         bool
 )
 
 var rootCmd = &cobra.Command{
@@ -29,6 +67,8 @@ var rootCmd = &cobra.Command{
 	Short: "Right-size LLM models to your system's hardware",
 	Long:  "LLM pole — find your pole-position models. Right-sizes LLM models to your hardware: detects RAM/CPU/GPU, scores models (quality, speed, fit, context), and shows which will run well. TUI by default; use --cli for table output. Supports multi-GPU, MoE, and quantization.",
 	RunE:  runDefault,
+	// main prints errors once and maps them to exit codes (see exitcode.go).
+	SilenceErrors: true,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		if showVersion {
 			if Version == "" {
@@ -37,49 +77,155 @@ var rootCmd = &cobra.Command{
 			fmt.Println(Version)
 			os.Exit(0)
 		}
+		// Flags parsed: from here on, failures are not usage mistakes, so don't print usage.
+		cmd.SilenceUsage = true
+		opts, err := buildOptions()
+		if err != nil {
+			return withExit(ExitUsage, err)
+		}
+		if _, err := pole.ParseRankBy(globalRankBy); err != nil {
+			return withExit(ExitUsage, err)
+		}
+		if err := applyFormat(globalFormat); err != nil {
+			return withExit(ExitUsage, err)
+		}
+		if opts.Weights, err = loadScoreWeights(); err != nil {
+			return err
+		}
+		analysisOptions = opts
+		if savedSettings, err = loadSettings(); err != nil {
+			// A broken settings file must not lock out `config`, which rewrites it.
+			fmt.Fprintf(os.Stderr, "Ignoring saved settings: %v (run `llmpole config` to rewrite them)\n", err)
+		}
+		if globalTemplate != "" {
+			tmpl, err := display.ParseFitTemplate(globalTemplate)
+			if err != nil {
+				return withExit(ExitUsage, err)
+			}
+			outputTemplate = tmpl
+		}
+		display.SetASCII(globalASCII || !display.LocaleSupportsUTF8())
+		display.SetCompact(globalCompact)
+		notes, err := display.ParseNotesMode(globalNotes)
+		if err != nil {
+			return withExit(ExitUsage, err)
+		}
+		display.SetNotes(notes)
+		display.SetQuiet(globalQuiet)
+		precision := -1
+		if cmd.Flags().Changed("precision") {
+			if precision, err = display.ParsePrecision(globalPrecision); err != nil {
+				return withExit(ExitUsage, err)
+			}
+		}
+		display.SetPrecision(precision)
+		if cmd != updateListCmd && !globalQuiet {
+			warnIfStaleList()
+		}
 		return nil
 	},
 }
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&globalPerfect, "perfect", false, "Show only models that perfectly match recommended specs")
-	rootCmd.PersistentFlags().UintVarP(&globalLimit, "limit", "n", 0, "Limit number of results (0 = no limit)")
+	rootCmd.PersistentFlags().VarP(limitValue{&globalLimit}, "limit", "n", "Limit number of results: a count (0 = no limit) or a share of runnable models, e.g. 20%")
 	rootCmd.PersistentFlags().BoolVar(&globalJSON, "json", false, "Output results as JSON")
+	rootCmd.PersistentFlags().StringVar(&globalFormat, "format", "table", "Output format: table, json (same as --json), or html (a standalone report page; model lists from pole, recommend, and --cli)")
 	rootCmd.PersistentFlags().BoolVar(&globalCLI, "cli", false, "Use classic CLI table output instead of TUI (when no subcommand)")
+	rootCmd.PersistentFlags().BoolVar(&globalASCII, "ascii", false, "Use ASCII-only borders and separators (auto when the locale is not UTF-8)")
+	rootCmd.PersistentFlags().StringVar(&globalRemote, "remote", "", "Analyze against a remote machine's specs (SSH host running llmpole, or URL serving 'system --json')")
+	rootCmd.PersistentFlags().BoolVar(&globalVariants, "variants", false, "Show likely-duplicate models (other uploaders, GGUF/AWQ re-uploads) individually instead of collapsing them")
+	rootCmd.PersistentFlags().Float64Var(&globalMargin, "margin", pole.DefaultSafetyMargin*100, "Percent of available memory reserved as a safety margin before fit decisions")
+	rootCmd.PersistentFlags().Float64Var(&globalGoodRoom, "good-headroom", pole.DefaultGoodHeadroom, "Label a fit Good when usable memory is at least this multiple of what the model needs")
+	rootCmd.PersistentFlags().Float64Var(&globalMarginRoom, "marginal-headroom", pole.DefaultMarginalHeadroom, "Label a fit Marginal (rather than Too Tight) when usable memory is at least this multiple of what the model needs")
+	rootCmd.PersistentFlags().StringVar(&globalMaxQuant, "max-quant", "", "Never suggest a quantization heavier than this (e.g. Q4_K_M); by default picks stay within the quants a model is published in")
+	rootCmd.PersistentFlags().StringVar(&globalRuntime, "runtime", "", "Inference runtime to estimate for on Apple Silicon: llama.cpp (default, GGUF quants) or mlx (MLX 8/6/4/3-bit quants)")
+	rootCmd.PersistentFlags().StringVar(&globalKVCache, "kv-cache-type", "f16", "KV cache type to estimate memory for, as set by llama.cpp's --cache-type-k/-v: f16, q8_0 (half the size), or q4_0 (a quarter)")
+	rootCmd.PersistentFlags().BoolVar(&globalPreferGPU, "prefer-gpu", false, "In borderline cases, load models into VRAM even inside the safety margin instead of offloading to RAM")
+	rootCmd.PersistentFlags().BoolVar(&globalPreferCPU, "prefer-cpu", false, "Run CPU-only instead of splitting a model across VRAM and RAM")
+	rootCmd.MarkFlagsMutuallyExclusive("prefer-gpu", "prefer-cpu")
+	rootCmd.PersistentFlags().BoolVar(&globalSuggestQuants, "suggest-quants", false, "For models that are Too Tight, name a lighter quantization (down to IQ2_XXS) that would fit")
+	rootCmd.PersistentFlags().Float64Var(&globalUsability, "usability-penalty", 0, "Lower scores of offloaded and CPU-only fits by up to this percent (0-50), scaled by how slow they are; off by default")
+	rootCmd.PersistentFlags().BoolVar(&globalDenseOnly, "dense-only-score", false, "Score MoE model quality by active parameters, with a small bonus for the total, instead of by total size")
+	rootCmd.PersistentFlags().IntVar(&globalPromptTokens, "prompt-tokens", pole.DefaultPromptTokens, "Prompt length in tokens that the time-to-first-token estimate assumes")
+	rootCmd.PersistentFlags().BoolVar(&globalUnrunnable, "show-unrunnable", false, "Show Too Tight models for this run even when 'config --hide-unrunnable' hides them")
+	rootCmd.PersistentFlags().BoolVar(&globalRefreshHW, "refresh-hardware", false, "Detect the GPUs again instead of reusing the detection cached for 60 seconds (RAM is always read live; system never uses the cache)")
+	rootCmd.PersistentFlags().BoolVar(&globalMoE, "moe", false, "Show only Mixture-of-Experts models")
+	rootCmd.PersistentFlags().BoolVar(&globalDense, "dense", false, "Show only dense (non-MoE) models")
+	rootCmd.MarkFlagsMutuallyExclusive("moe", "dense")
+	rootCmd.PersistentFlags().StringVar(&globalWorkload, "workload", "", "Workload preset tuning context scoring and analysis context length (chat, rag, agentic)")
+	rootCmd.PersistentFlags().BoolVar(&globalFetch, "fetch", false, "Fetch models missing from the list from HuggingFace without prompting")
+	rootCmd.PersistentFlags().BoolVar(&globalNoFetch, "no-fetch", false, "Never prompt to fetch missing models; report them as not found (implied when stdin is not a terminal)")
+	rootCmd.MarkFlagsMutuallyExclusive("fetch", "no-fetch")
+	rootCmd.PersistentFlags().BoolVar(&globalThorough, "thorough", false, "When fetching a model, always download its config.json for the most accurate context and MoE details, and count its GGUF quantizations (slower)")
+	rootCmd.PersistentFlags().StringVar(&globalModelsFile, "models-file", "", "JSON file of extra model entries merged over the list by name (default $"+models.CustomModelsEnv+")")
+	rootCmd.PersistentFlags().StringVar(&globalTemplate, "output-template", "", "Go text/template executed per model with the JSON fields as data, e.g. '{{.name}}: {{.estimated_tps}} tok/s'")
+	rootCmd.PersistentFlags().IntVar(&globalPage, "page", 0, "Show one page of the CLI results (1-based), with a \"showing a–b of n\" footer")
+	rootCmd.PersistentFlags().IntVar(&globalPageSize, "page-size", 0, "Results per page for --page (default 20; implies --page 1)")
+	rootCmd.PersistentFlags().BoolVar(&globalCompact, "compact", false, "Print CLI results one line per model (status score, name, quant, mode, tok/s) without table borders")
+	rootCmd.PersistentFlags().StringVar(&globalNotes, "notes", "", "Analysis notes to include: none, short (warnings only; default for lists), or full (default for info)")
+	rootCmd.PersistentFlags().BoolVarP(&globalQuiet, "quiet", "q", false, "Print only the requested data: no banners, counts, hints, or stale-list warnings (implies --no-fetch unless --fetch)")
+	rootCmd.PersistentFlags().StringVar(&globalRankBy, "rank-by", "", "Order results by: score (default), quality-per-gb, speed, or tps-per-gb; Too Tight models stay last")
+	rootCmd.PersistentFlags().IntVar(&globalPrecision, "precision", 0, "Decimal places for the numbers in tables, JSON, and the TUI (0-6), not the prose of fit notes or metrics output; by default each field keeps its usual precision")
+	rootCmd.Flags().BoolVar(&topByProvider, "top-by-provider", false, "Start the TUI showing only the best runnable model per provider (press t to expand)")
+	rootCmd.Flags().StringVar(&themeName, "theme", "", "TUI colors: dark, light, mono (no color), or auto (default, from the terminal background; $"+tui.ThemeEnv+" sets a default)")
+	rootCmd.Flags().StringVar(&sortProviders, "sort-providers", "", "Order the TUI provider popup: alpha (default), count (most models first), or a comma-separated list of providers to pin first, e.g. Meta,Qwen")
 	rootCmd.PersistentFlags().BoolVarP(&showVersion, "version", "v", false, "Print version and exit")
 
-	rootCmd.AddCommand(systemCmd, listCmd, poleCmd, searchCmd, infoCmd, recommendCmd, updateListCmd)
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return withExit(ExitUsage, err)
+	})
+	rootCmd.AddCommand(systemCmd, listCmd, poleCmd, searchCmd, infoCmd, compareCmd, capacityCmd, planCmd, recommendCmd, metricsCmd, bestCmd, adviseCmd, modelsCmd, updateListCmd, fetchLogCmd, configCmd)
 }
 
-// Execute runs the root command. Returns error for exit code handling.
+// Execute runs the root command. Map the returned error to a process exit code with ExitCode;
+// its message may be empty when the command already reported the problem.
 func Execute() error {
 	return rootCmd.Execute()
 }
 
 func runDefault(cmd *cobra.Command, args []string) error {
-	specs, err := hardware.Detect()
+	providerSort, pinned, err := tui.ParseProviderSort(sortProviders)
+	if err != nil {
+		return withExit(ExitUsage, err)
+	}
+	if themeName == "" {
+		themeName = os.Getenv(tui.ThemeEnv)
+	}
+	theme, err := tui.ParseTheme(themeName)
+	if err != nil {
+		return withExit(ExitUsage, err)
+	}
+	specs, err := detectSpecs()
 	if err != nil {
 		return err
 	}
-	db, err := models.NewDB()
+	db, err := loadDB()
 	if err != nil {
 		return err
 	}
-	fits := pole.AnalyzeAll(db.GetAllModels(), specs)
-	fits = pole.RankModelsByFit(fits)
+	fits := pole.AnalyzeAllWithOptions(catalogModels(db), specs, analyzeOptions())
+	fits = rankFits(fits)
 
 	if globalCLI {
 		perfect := globalPerfect
-		limit := globalLimit
 		useJSON := globalJSON
 		if perfect {
 			fits = pole.FilterPerfectOnly(fits)
 		}
-		if limit > 0 && len(fits) > int(limit) {
-			fits = fits[:limit]
+		fits = runnableFits(fits)
+		if !globalVariants {
+			fits = pole.CollapseDuplicates(fits)
 		}
-		display.Pole(os.Stdout, specs, fits, useJSON)
-		return nil
+		fits = globalLimit.Apply(fits)
+		return showPole(specs, fits, useJSON)
 	}
-	return tui.Run(specs, fits)
+	return tui.Run(specs, fits, tui.Options{
+		Live:           globalRemote == "",
+		TopByProvider:  topByProvider,
+		HideUnrunnable: hideUnrunnable(),
+		ProviderSort:   providerSort,
+		Pinned:         pinned,
+		Theme:          theme,
+	})
 }
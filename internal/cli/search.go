@@ -6,7 +6,6 @@ import (
 
 	"github.com/shayne-snap/llmpole/internal/display"
 	"github.com/shayne-snap/llmpole/internal/fetch"
-	"github.com/shayne-snap/llmpole/internal/models"
 
 	"github.com/spf13/cobra"
 )
@@ -14,32 +13,34 @@ import (
 var searchCmd = &cobra.Command{
 	Use:   "search [query]",
 	Short: "Search for models by name, provider, or size",
-	Args:  cobra.ExactArgs(1),
+	Args:  usageArgs(cobra.ExactArgs(1)),
 	RunE:  runSearch,
 }
 
 func runSearch(cmd *cobra.Command, args []string) error {
 	query := args[0]
-	db, err := models.NewDB()
+	db, err := loadDB()
 	if err != nil {
 		return err
 	}
 	results := db.FindModel(query)
 	if len(results) == 0 && looksLikeRepoID(query) {
 		if confirmFetch(query) {
-			m, err := fetch.FetchModel(query)
+			m, err := fetch.FetchModelWithOptions(query, fetch.Options{Thorough: globalThorough})
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Could not fetch model: %v\n", err)
-				return nil
+				return withExit(ExitFetch, fmt.Errorf("could not fetch model: %w", err))
 			}
-			if err := models.AppendModelToCache(m); err != nil {
+			if err := cacheFetchedModel(query, m); err != nil {
 				fmt.Fprintf(os.Stderr, "Could not save to cache: %v\n", err)
 				return nil
 			}
-			db, _ = models.NewDB()
+			db, _ = loadDB()
 			results = db.FindModel(query)
 		}
 	}
 	display.Search(os.Stdout, results, query)
+	if len(results) == 0 {
+		return withExit(ExitNoModels, nil)
+	}
 	return nil
 }
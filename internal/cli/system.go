@@ -1,7 +1,11 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"os"
+	"os/signal"
+	"time"
 
 	"github.com/shayne-snap/llmpole/internal/display"
 	"github.com/shayne-snap/llmpole/internal/hardware"
@@ -15,11 +19,53 @@ var systemCmd = &cobra.Command{
 	RunE:  runSystem,
 }
 
+func init() {
+	systemCmd.Flags().Duration("watch", 0, "After the specs, sample GPU utilization and temperature every interval until interrupted (default 2s when given without a value)")
+	systemCmd.Flags().Lookup("watch").NoOptDefVal = "2s"
+	systemCmd.Flags().Bool("explain-system", false, "Annotate each spec with how it was detected (e.g. nvidia-smi, /proc/meminfo, or an estimate from the GPU name)")
+}
+
 func runSystem(cmd *cobra.Command, args []string) error {
-	specs, err := hardware.Detect()
+	interval, _ := cmd.Flags().GetDuration("watch")
+	if interval > 0 && globalRemote != "" {
+		return usageErrorf("--watch samples this machine's GPUs; it cannot be combined with --remote")
+	}
+	// system reports what the hardware shows now, so it always detects afresh (and refreshes
+	// the cache for the commands that follow).
+	specs, err := detectSpecsRefresh(true)
 	if err != nil {
 		return err
 	}
-	display.System(os.Stdout, specs, globalJSON)
-	return nil
+	if explain, _ := cmd.Flags().GetBool("explain-system"); explain {
+		display.SystemExplained(os.Stdout, specs, globalJSON)
+	} else {
+		display.System(os.Stdout, specs, globalJSON)
+	}
+	if interval <= 0 {
+		return nil
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return watchGPUs(ctx, interval)
+}
+
+// watchGPUs prints a live GPU sample every interval until ctx is done.
+func watchGPUs(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		stats := hardware.SampleGPUStats(ctx)
+		if len(stats) == 0 {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return withExit(ExitDetection, errors.New("no live GPU stats available (needs nvidia-smi or rocm-smi)"))
+		}
+		display.LiveStats(os.Stdout, time.Now(), stats, globalJSON)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
 }
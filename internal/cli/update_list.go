@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/shayne-snap/llmpole/internal/fetch"
@@ -27,7 +28,7 @@ func runUpdateList(cmd *cobra.Command, args []string) error {
 	defer cancel()
 	body, err := fetch.FetchModelList(ctx, DefaultListURL)
 	if err != nil {
-		return fmt.Errorf("update-list: %w", err)
+		return withExit(ExitFetch, fmt.Errorf("update-list: %w", err))
 	}
 	var entries []models.LlmModel
 	if err := json.Unmarshal(body, &entries); err != nil {
@@ -36,6 +37,31 @@ func runUpdateList(cmd *cobra.Command, args []string) error {
 	if err := models.WriteCacheFile(body); err != nil {
 		return fmt.Errorf("could not write cache: %w", err)
 	}
-	fmt.Printf("Updated model list (%d models) in user cache.\n", len(entries))
+	if !globalQuiet {
+		fmt.Printf("Updated model list (%d models) in user cache.\n", len(entries))
+	}
+	return refetchDropped(entries)
+}
+
+// refetchDropped fetches again, in one rate-limited batch, the models fetched from HuggingFace
+// earlier that the new list leaves out: they were saved in the cache the list just replaced.
+func refetchDropped(entries []models.LlmModel) error {
+	logged, err := models.ReadFetchLog()
+	if err != nil {
+		if !globalQuiet {
+			fmt.Fprintf(os.Stderr, "Could not read the fetch log to restore fetched models: %v\n", err)
+		}
+		return nil
+	}
+	repos := droppedFetches(logged, entries)
+	if len(repos) == 0 || !confirmFetch(fmt.Sprintf("%d previously fetched model(s)", len(repos))) {
+		return nil
+	}
+	if err := fetchAndCache(repos); err != nil {
+		return err
+	}
+	if !globalQuiet {
+		fmt.Printf("Fetched %d model(s) again from HuggingFace.\n", len(repos))
+	}
 	return nil
 }
@@ -0,0 +1,49 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+// Advise prints the upgrade-path summary: fit counts before and after adding memory, and the
+// best models that newly become runnable (up to limit; 0 = all).
+func Advise(out io.Writer, extraRAMGB, extraVRAMGB float64, before, after pole.FitCounts, newly []*pole.ModelFit, limit int, useJSON bool) {
+	if limit > 0 && len(newly) > limit {
+		newly = newly[:limit]
+	}
+	if useJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(map[string]interface{}{
+			"extra_ram_gb":   round2(extraRAMGB),
+			"extra_vram_gb":  round2(extraVRAMGB),
+			"before":         before,
+			"after":          after,
+			"runnable_delta": after.Runnable - before.Runnable,
+			"perfect_delta":  after.Perfect - before.Perfect,
+			"newly_runnable": fitsToJSON(newly),
+		})
+		return
+	}
+	chatter(out, "\n=== Upgrade Path: +%s GB RAM, +%s GB VRAM ===\n", Num(extraRAMGB, 0), Num(extraVRAMGB, 0))
+	tbl := newTable(out)
+	tbl.Header("", "Now", "After", "Change")
+	row := func(label string, a, b int) {
+		tbl.Append([]string{label, fmt.Sprint(a), fmt.Sprint(b), fmt.Sprintf("%+d", b-a)})
+	}
+	row("Runnable", before.Runnable, after.Runnable)
+	row("Perfect", before.Perfect, after.Perfect)
+	row("Good", before.Good, after.Good)
+	row("Marginal", before.Marginal, after.Marginal)
+	row("Too Tight", before.TooTight, after.TooTight)
+	_ = tbl.Render()
+	if len(newly) == 0 {
+		fmt.Fprintln(out, "\nNo additional models become runnable.")
+		return
+	}
+	fmt.Fprintln(out, "\nNewly runnable:")
+	poleTable(out, newly)
+}
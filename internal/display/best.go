@@ -0,0 +1,67 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shayne-snap/llmpole/internal/hardware"
+	"github.com/shayne-snap/llmpole/internal/models"
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+// Best prints the single recommended download for a beginner: model, quant, expected speed,
+// and how to run it. A nil fit means nothing is runnable; useCase (may be empty) is echoed in
+// that message.
+func Best(out io.Writer, specs *hardware.SystemSpecs, fit *pole.ModelFit, useCase string, useJSON bool) {
+	if useJSON {
+		doc := map[string]interface{}{
+			"system": systemJSON(specs),
+			"best":   nil,
+		}
+		if fit != nil {
+			doc["best"] = fitToJSON(fit)
+			doc["how_to_run"] = RunHint(fit)
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(doc)
+		return
+	}
+	if fit == nil {
+		scope := "model"
+		if useCase != "" {
+			scope = useCase + " model"
+		}
+		chatter(out, "\nNo %s in the list runs on this system; every candidate is Too Tight.\n", scope)
+		chatter(out, "Try `llmpole advise --extra-ram 16` to see what an upgrade would unlock.\n")
+		return
+	}
+	m := fit.Model
+	chatter(out, "\n=== Best Model For Your System ===\n")
+	fmt.Fprintf(out, "%s (%s, %s params)\n\n", m.Name, m.Provider, m.ParameterCount)
+	fmt.Fprintf(out, "  Download:   %s quantization\n", fit.BestQuant)
+	fmt.Fprintf(out, "  Speed:      %s on %s\n", formatTPSBand(fit), fit.RunModeText())
+	fmt.Fprintf(out, "  Fit:        %s, score %s / 100\n", fitStatus(fit), Num(fit.Score, 0))
+	fmt.Fprintf(out, "  How to run: %s\n", RunHint(fit))
+}
+
+// RunHint is a one-line suggestion for running fit's model in its run mode, with the matching
+// llama.cpp GPU-layer flags, or with mlx-lm for MLX quants.
+func RunHint(fit *pole.ModelFit) string {
+	if models.IsMLXQuant(fit.BestQuant) {
+		return fmt.Sprintf("download the %s MLX conversion of %s (mlx-community) and run it with mlx_lm.generate", strings.TrimPrefix(fit.BestQuant, "mlx-"), fit.Model.Name)
+	}
+	what := fmt.Sprintf("download a %s GGUF of %s", fit.BestQuant, fit.Model.Name)
+	switch fit.RunMode {
+	case pole.RunModeGpu:
+		return what + " and load every layer on the GPU (llama.cpp: -ngl 99)"
+	case pole.RunModeMoeOffload:
+		return what + " and keep the expert weights in RAM (llama.cpp: -ngl 99 --cpu-moe)"
+	case pole.RunModeCpuOffload:
+		return what + " and put as many layers on the GPU as fit; the rest run from RAM (llama.cpp: -ngl N)"
+	default:
+		return what + " and run it on the CPU (llama.cpp: -ngl 0)"
+	}
+}
@@ -0,0 +1,66 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+// Capacity prints how many concurrent requests of c.Context tokens fit beside fit's weights.
+func Capacity(out io.Writer, fit *pole.ModelFit, c pole.Capacity, useJSON bool) {
+	if useJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(map[string]interface{}{
+			"model":    fit.Model.Name,
+			"run_mode": fit.RunModeText(),
+			"fit":      fitToJSON(fit),
+			"capacity": capacityJSON(c),
+		})
+		return
+	}
+	chatter(out, "\n=== Capacity: %s ===\n", fit.Model.Name)
+	tbl := newTable(out)
+	tbl.Header([]string{"", "Value"})
+	tbl.Append([]string{"Quant", c.Quant})
+	tbl.Append([]string{"Mode", fit.RunModeText()})
+	tbl.Append([]string{"Usable memory", Num(c.MemoryGB, 1) + " GB"})
+	tbl.Append([]string{"Weights", Num(c.WeightsGB, 1) + " GB"})
+	tbl.Append([]string{"KV per request", fmt.Sprintf("%s GB @ %d tokens", Num(c.KVPerRequestGB, 2), c.Context)})
+	tbl.Append([]string{"Concurrent requests", fmt.Sprintf("%d", c.Concurrent)})
+	_ = tbl.Render()
+	if c.Concurrent == 0 {
+		chatter(out, "\nNo room for a %d-token request beside the weights (%s).\n", c.Context, fitStatus(fit))
+	}
+}
+
+func capacityJSON(c pole.Capacity) map[string]interface{} {
+	return map[string]interface{}{
+		"quant":             c.Quant,
+		"context":           c.Context,
+		"memory_gb":         round2(c.MemoryGB),
+		"weights_gb":        round2(c.WeightsGB),
+		"kv_per_request_gb": round2(c.KVPerRequestGB),
+		"concurrent":        c.Concurrent,
+	}
+}
+
+// MemoryOnly prints just the total GB of e for scripts, or e with its breakdown as JSON.
+func MemoryOnly(out io.Writer, e pole.MemoryEstimate, useJSON bool) {
+	if useJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(map[string]interface{}{
+			"quant":       e.Quant,
+			"context":     e.Context,
+			"weights_gb":  round2(e.WeightsGB),
+			"kv_cache_gb": round2(e.KVCacheGB),
+			"overhead_gb": round2(e.OverheadGB),
+			"total_gb":    round2(e.TotalGB),
+		})
+		return
+	}
+	fmt.Fprintln(out, Num(e.TotalGB, 2))
+}
@@ -0,0 +1,82 @@
+package display
+
+import (
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// asciiMode, when set, replaces box-drawing borders, separators, and emoji with plain ASCII.
+var asciiMode bool
+
+// SetASCII enables or disables ASCII-only decorative output for the CLI and TUI.
+func SetASCII(v bool) {
+	asciiMode = v
+}
+
+// ASCII reports whether decorative output should avoid non-ASCII glyphs.
+func ASCII() bool {
+	return asciiMode
+}
+
+// LocaleSupportsUTF8 reports whether the terminal locale can render UTF-8 glyphs.
+// The first non-empty of LC_ALL, LC_CTYPE, LANG decides; with none set, Windows is
+// assumed UTF-8 only inside Windows Terminal and other platforms are assumed UTF-8.
+func LocaleSupportsUTF8() bool {
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			l := strings.ToLower(v)
+			return strings.Contains(l, "utf-8") || strings.Contains(l, "utf8")
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return os.Getenv("WT_SESSION") != ""
+	}
+	return true
+}
+
+// glyph returns unicode, or ascii when ASCII mode is on.
+func glyph(unicode, ascii string) string {
+	if asciiMode {
+		return ascii
+	}
+	return unicode
+}
+
+// Truncate shortens s to at most width terminal columns, ending in an ellipsis ("~" in ASCII
+// mode) when it had to cut. It cuts between code points, never inside one, and replaces any
+// invalid bytes in s, so the result is always valid UTF-8. Wide (e.g. CJK) runes count as two
+// columns. The TUI and CLI share it for every truncated cell.
+func Truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	if runewidth.StringWidth(s) <= width {
+		return s
+	}
+	ellipsis := glyph("…", "~")
+	limit := width - runewidth.StringWidth(ellipsis)
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		w := runewidth.RuneWidth(r)
+		if used+w > limit {
+			break
+		}
+		b.WriteRune(r)
+		used += w
+	}
+	return b.String() + ellipsis
+}
+
+// TruncatePad is Truncate padded with spaces to exactly width columns.
+func TruncatePad(s string, width int) string {
+	s = Truncate(s, width)
+	if pad := width - runewidth.StringWidth(s); pad > 0 {
+		s += strings.Repeat(" ", pad)
+	}
+	return s
+}
@@ -0,0 +1,33 @@
+package display
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+// compactMode, when set, prints pole results one line per model instead of a table.
+var compactMode bool
+
+// SetCompact enables or disables the one-line-per-model pole output.
+func SetCompact(v bool) {
+	compactMode = v
+}
+
+// poleCompact prints one line per model with no borders or header, for piping and narrow terminals.
+// Columns, separated by two spaces: status, score, name, quant, run mode, tok/s, e.g.
+//
+//	🟢 87  Qwen2.5-14B  Q5_K_M  GPU  42 tok/s
+//
+// The status is the fit emoji, or the fit text in ASCII mode.
+func poleCompact(out io.Writer, fits []*pole.ModelFit) {
+	for _, f := range fits {
+		status := f.FitEmoji()
+		if asciiMode {
+			status = f.FitText()
+		}
+		fmt.Fprintf(out, "%s %s  %s  %s  %s  %s tok/s\n",
+			status, Num(f.Score, 0), withVariants(f.Model.Name, f.VariantCount), f.BestQuant, f.RunModeText(), Num(f.EstimatedTPS, 0))
+	}
+}
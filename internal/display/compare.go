@@ -0,0 +1,123 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+// Compare prints two fits side by side with the winner of each score dimension. The JSON form is
+// compareJSON's, as for CompareMany, plus the two fits under "a" and "b".
+func Compare(out io.Writer, a, b *pole.ModelFit, useJSON bool) {
+	if useJSON {
+		compareJSON(out, []*pole.ModelFit{a, b})
+		return
+	}
+	c := pole.CompareFits(a, b)
+	winnerName := func(side string) string {
+		switch side {
+		case pole.WinnerA:
+			return a.Model.Name
+		case pole.WinnerB:
+			return b.Model.Name
+		}
+		return "tie"
+	}
+	sc := func(f *pole.ModelFit, dim string) float64 {
+		switch dim {
+		case "quality":
+			return f.ScoreComponents.Quality
+		case "speed":
+			return f.ScoreComponents.Speed
+		case "fit":
+			return f.ScoreComponents.Fit
+		}
+		return f.ScoreComponents.Context
+	}
+	chatter(out, "\n=== Compare ===\n")
+	tbl := newTable(out)
+	tbl.Header([]string{"", "A", "B", "Winner"})
+	tbl.Append([]string{"Model", a.Model.Name, b.Model.Name, ""})
+	tbl.Append([]string{"Score", Num(a.Score, 1), Num(b.Score, 1), winnerName(c.Overall)})
+	for _, dim := range pole.CompareDimensions {
+		tbl.Append([]string{strings.ToUpper(dim[:1]) + dim[1:], Num(sc(a, dim), 1), Num(sc(b, dim), 1), winnerName(c.Winners[dim])})
+	}
+	tbl.Append([]string{"tok/s", formatTPSBand(a), formatTPSBand(b), ""})
+	tbl.Append([]string{"Quant", a.BestQuant, b.BestQuant, ""})
+	tbl.Append([]string{"Mode", a.RunModeText(), b.RunModeText(), ""})
+	tbl.Append([]string{"Memory", compareMemory(a), compareMemory(b), ""})
+	tbl.Append([]string{"Fit level", fitStatus(a), fitStatus(b), ""})
+	_ = tbl.Render()
+}
+
+// CompareMany prints three or more fits with a column per model (A, B, C, …): score, tok/s, best
+// quant, run mode, memory utilization, and fit level. The JSON form is compareJSON's.
+func CompareMany(out io.Writer, fits []*pole.ModelFit, useJSON bool) {
+	if useJSON {
+		compareJSON(out, fits)
+		return
+	}
+	row := func(label string, cell func(f *pole.ModelFit) string) []string {
+		r := []string{label}
+		for _, f := range fits {
+			r = append(r, cell(f))
+		}
+		return r
+	}
+	chatter(out, "\n=== Compare ===\n")
+	tbl := newTable(out)
+	header := []string{""}
+	for i := range fits {
+		header = append(header, compareColumn(i))
+	}
+	tbl.Header(header)
+	tbl.Append(row("Model", func(f *pole.ModelFit) string { return f.Model.Name }))
+	tbl.Append(row("Score", func(f *pole.ModelFit) string { return Num(f.Score, 1) }))
+	tbl.Append(row("tok/s", formatTPSBand))
+	tbl.Append(row("Quant", func(f *pole.ModelFit) string { return f.BestQuant }))
+	tbl.Append(row("Mode", (*pole.ModelFit).RunModeText))
+	tbl.Append(row("Memory", compareMemory))
+	tbl.Append(row("Fit level", fitStatus))
+	_ = tbl.Render()
+}
+
+// compareJSON writes a comparison of any number of fits as one object keyed by model name,
+// {"models": {name: fit, ...}, "winners": {dimension: name}, "overall": name}, where a winner is
+// "tie" when no model leads. With two fits they are also under "a" and "b".
+func compareJSON(out io.Writer, fits []*pole.ModelFit) {
+	c := pole.CompareAll(fits)
+	names := map[string]string{pole.WinnerTie: pole.WinnerTie}
+	byName := make(map[string]interface{}, len(fits))
+	for i, f := range fits {
+		names[pole.Side(i)] = f.Model.Name
+		byName[f.Model.Name] = fitToJSON(f)
+	}
+	winners := make(map[string]string, len(c.Winners))
+	for dim, side := range c.Winners {
+		winners[dim] = names[side]
+	}
+	doc := map[string]interface{}{
+		"models":  byName,
+		"winners": winners,
+		"overall": names[c.Overall],
+	}
+	if len(fits) == 2 {
+		doc[pole.WinnerA], doc[pole.WinnerB] = byName[fits[0].Model.Name], byName[fits[1].Model.Name]
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(doc)
+}
+
+// compareColumn labels the i-th model's column A, B, C, …, continuing the two-model A/B table.
+func compareColumn(i int) string {
+	return strings.ToUpper(pole.Side(i))
+}
+
+// compareMemory is a fit's memory utilization with the GB behind it, e.g. "75.0% (6.0 / 8.0 GB)".
+func compareMemory(f *pole.ModelFit) string {
+	return fmt.Sprintf("%s%% (%s / %s GB)", Num(f.UtilizationPct, 1), Num(f.MemoryRequiredGB, 1), Num(f.MemoryAvailableGB, 1))
+}
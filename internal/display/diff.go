@@ -0,0 +1,62 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shayne-snap/llmpole/internal/models"
+)
+
+// CatalogDiff prints what the overlay list adds (+), removes (-), and changes (~) relative to
+// base, one model per line with a count summary, or the diff as JSON.
+func CatalogDiff(out io.Writer, d models.CatalogDiff, overlay, base string, useJSON bool) {
+	if useJSON {
+		if d.Added == nil {
+			d.Added = []string{}
+		}
+		if d.Removed == nil {
+			d.Removed = []string{}
+		}
+		if d.Changed == nil {
+			d.Changed = []models.ModelChange{}
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(map[string]interface{}{
+			"source":  overlay,
+			"against": base,
+			"added":   d.Added,
+			"removed": d.Removed,
+			"changed": d.Changed,
+		})
+		return
+	}
+	for _, name := range d.Added {
+		fmt.Fprintf(out, "+ %s\n", name)
+	}
+	for _, name := range d.Removed {
+		fmt.Fprintf(out, "- %s\n", name)
+	}
+	for _, c := range d.Changed {
+		parts := make([]string, 0, len(c.Fields))
+		for _, f := range c.Fields {
+			parts = append(parts, fmt.Sprintf("%s %s %s %s", f.Field, orNone(f.Old), glyph("→", "->"), orNone(f.New)))
+		}
+		fmt.Fprintf(out, "~ %s: %s\n", c.Name, strings.Join(parts, ", "))
+	}
+	if d.Empty() {
+		chatter(out, "%s matches the %s\n", overlay, base)
+		return
+	}
+	chatter(out, "%s vs %s: %d added, %d removed, %d changed\n", overlay, base, len(d.Added), len(d.Removed), len(d.Changed))
+}
+
+// orNone shows an unset field as "(none)".
+func orNone(v string) string {
+	if v == "" {
+		return "(none)"
+	}
+	return v
+}
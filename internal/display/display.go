@@ -9,6 +9,7 @@ import (
 	"text/template"
 
 	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/tw"
 	"github.com/shayne-snap/llmpole/internal/hardware"
 	"github.com/shayne-snap/llmpole/internal/models"
 	"github.com/shayne-snap/llmpole/internal/pole"
@@ -23,11 +24,13 @@ func init() {
 	systemTpl = template.Must(template.New("system").Parse(
 		`
 === System Specifications ===
-CPU: {{.CPUName}} ({{.TotalCPUCores}} cores)
-Total RAM: {{.TotalRAMGB}}
-Available RAM: {{.AvailableRAMGB}}
-Backend: {{.Backend}}
+CPU: {{.CPUName}} ({{.TotalCPUCores}} cores{{if .NumaNodes}}, {{.NumaNodes}} NUMA nodes{{end}}){{.CPUSource}}
+Total RAM: {{.TotalRAMGB}}{{.TotalRAMSource}}
+Available RAM: {{.AvailableRAMGB}}{{.AvailableRAMSource}}
+Backend: {{.Backend}}{{.BackendSource}}
 {{.GpuBlock}}
+Hardware score: {{.HardwareScore}} / 100{{range .Warnings}}
+Warning: {{.}}{{end}}
 
 `))
 	infoTpl = template.Must(template.New("info").Parse(
@@ -38,14 +41,17 @@ Provider: {{.Provider}}
 Parameters: {{.ParameterCount}}
 Quantization: {{.Quantization}}
 Best Quant: {{.BestQuant}}
-Context Length: {{.ContextLength}} tokens
+Quant Tradeoff: {{.QuantTradeoff}}
+Context Length: {{.ContextLength}} tokens{{if .AnalysisContext}} (analyzed at {{.AnalysisContext}}){{end}}
 Use Case: {{.UseCase}}
 Category: {{.Category}}
-
+{{if .License}}License: {{.License}}
+{{end}}
 Score Breakdown:
   Overall Score: {{.Score}} / 100
   Quality: {{.Quality}}  Speed: {{.Speed}}  Fit: {{.Fit}}  Context: {{.ContextScore}}
-  Estimated Speed: {{.EstimatedTPS}} tok/s
+  Estimated Speed: {{.EstimatedTPS}}
+  First Token: {{.TTFT}}
 
 Resource Requirements:
 {{.ResourceBlock}}
@@ -62,38 +68,65 @@ Fit Analysis:
 {{if .NotesBlock}}
 
 Notes:
-{{.NotesBlock}}{{end}}
+{{.NotesBlock}}{{end}}{{if .Alternative}}
+
+Closest runnable alternative:
+  {{.Alternative}}{{end}}
 
 `))
 }
 
 // System prints system specs to out (table or JSON).
 func System(out io.Writer, specs *hardware.SystemSpecs, useJSON bool) {
+	writeSystem(out, specs, useJSON, false)
+}
+
+func writeSystem(out io.Writer, specs *hardware.SystemSpecs, useJSON, explain bool) {
 	if useJSON {
+		sys := systemJSON(specs)
+		if explain {
+			sys["sources"] = sourcesJSON(specs)
+		}
 		enc := json.NewEncoder(out)
 		enc.SetIndent("", "  ")
 		_ = enc.Encode(map[string]interface{}{
-			"system": systemJSON(specs),
+			"system": sys,
 		})
 		return
 	}
-	gpuBlock := buildSystemGpuBlock(specs)
+	gpuBlock := buildSystemGpuBlock(specs, explain)
 	data := struct {
-		CPUName, Backend, GpuBlock   string
-		TotalCPUCores                int
-		TotalRAMGB, AvailableRAMGB   string
+		CPUName, Backend, GpuBlock string
+		TotalCPUCores, NumaNodes   int
+		TotalRAMGB, AvailableRAMGB string
+		CPUSource, TotalRAMSource  string
+		AvailableRAMSource         string
+		BackendSource              string
+		HardwareScore              string
+		Warnings                   []hardware.Warning
 	}{
 		CPUName:        specs.CPUName,
 		TotalCPUCores:  specs.TotalCPUCores,
-		TotalRAMGB:     fmt.Sprintf("%.2f GB", specs.TotalRAMGB),
-		AvailableRAMGB: fmt.Sprintf("%.2f GB", specs.AvailableRAMGB),
+		TotalRAMGB:     Num(specs.TotalRAMGB, 2) + " GB",
+		AvailableRAMGB: Num(specs.AvailableRAMGB, 2) + " GB",
 		Backend:        specs.Backend.String(),
 		GpuBlock:       gpuBlock,
+		HardwareScore:  Num(pole.HardwareScore(specs), 1),
+		Warnings:       specs.Warnings,
+	}
+	if specs.NumaNodes > 1 {
+		data.NumaNodes = specs.NumaNodes
+	}
+	if explain {
+		data.CPUSource = cpuSourceLine(specs)
+		data.TotalRAMSource = fromSource(specs.Source(hardware.FieldTotalRAM))
+		data.AvailableRAMSource = fromSource(specs.Source(hardware.FieldAvailableRAM))
+		data.BackendSource = fromSource(specs.Source(hardware.FieldBackend))
 	}
 	_ = systemTpl.Execute(out, data)
 }
 
-func buildSystemGpuBlock(specs *hardware.SystemSpecs) string {
+func buildSystemGpuBlock(specs *hardware.SystemSpecs, explain bool) string {
 	if len(specs.Gpus) == 0 {
 		return "GPU: Not detected"
 	}
@@ -109,18 +142,30 @@ func buildSystemGpuBlock(specs *hardware.SystemSpecs) string {
 			if g.VRAMGB != nil {
 				v = *g.VRAMGB
 			}
-			line = fmt.Sprintf("%s%s (unified memory, %.2f GB shared, %s)", prefix, g.Name, v, g.Backend.String())
+			line = fmt.Sprintf("%s%s (unified memory, %s GB shared, %s)", prefix, g.Name, Num(v, 2), g.Backend.String())
 		} else if g.VRAMGB != nil && *g.VRAMGB > 0 {
 			if g.Count > 1 {
-				line = fmt.Sprintf("%s%s x%d (%.2f GB VRAM total, %s)", prefix, g.Name, g.Count, *g.VRAMGB, g.Backend.String())
+				line = fmt.Sprintf("%s%s x%d (%s GB VRAM total, %s)", prefix, g.Name, g.Count, Num(*g.VRAMGB, 2), g.Backend.String())
 			} else {
-				line = fmt.Sprintf("%s%s (%.2f GB VRAM, %s)", prefix, g.Name, *g.VRAMGB, g.Backend.String())
+				line = fmt.Sprintf("%s%s (%s GB VRAM, %s)", prefix, g.Name, Num(*g.VRAMGB, 2), g.Backend.String())
 			}
 		} else if g.VRAMGB != nil {
 			line = fmt.Sprintf("%s%s (shared system memory, %s)", prefix, g.Name, g.Backend.String())
 		} else {
 			line = fmt.Sprintf("%s%s (VRAM unknown, %s)", prefix, g.Name, g.Backend.String())
 		}
+		if g.FreeVRAMGB != nil && !g.UnifiedMemory {
+			line += fmt.Sprintf(" [%s GB free]", Num(*g.FreeVRAMGB, 2))
+		}
+		if g.ComputeCapability != "" {
+			line += fmt.Sprintf(" [compute %s, driver %s]", g.ComputeCapability, g.DriverVersion)
+		}
+		if g.Note != "" {
+			line += fmt.Sprintf(" [%s]", g.Note)
+		}
+		if explain && g.VRAMSource != "" {
+			line += fmt.Sprintf(" (VRAM from %s)", g.VRAMSource)
+		}
 		lines = append(lines, line)
 	}
 	return strings.Join(lines, "\n")
@@ -130,14 +175,29 @@ func systemJSON(specs *hardware.SystemSpecs) map[string]interface{} {
 	gpus := make([]map[string]interface{}, 0, len(specs.Gpus))
 	for _, g := range specs.Gpus {
 		m := map[string]interface{}{
-			"name":            g.Name,
-			"backend":         g.Backend.String(),
-			"count":           g.Count,
-			"unified_memory":  g.UnifiedMemory,
+			"name":           g.Name,
+			"backend":        g.Backend.String(),
+			"count":          g.Count,
+			"unified_memory": g.UnifiedMemory,
 		}
 		if g.VRAMGB != nil {
 			m["vram_gb"] = round2(*g.VRAMGB)
 		}
+		if g.FreeVRAMGB != nil {
+			m["free_vram_gb"] = round2(*g.FreeVRAMGB)
+		}
+		if g.ECCEnabled {
+			m["ecc_enabled"] = true
+		}
+		if g.Note != "" {
+			m["note"] = g.Note
+		}
+		if g.ComputeCapability != "" {
+			m["compute_capability"] = g.ComputeCapability
+		}
+		if g.DriverVersion != "" {
+			m["driver_version"] = g.DriverVersion
+		}
 		gpus = append(gpus, m)
 	}
 	m := map[string]interface{}{
@@ -150,73 +210,234 @@ func systemJSON(specs *hardware.SystemSpecs) map[string]interface{} {
 		"unified_memory":   specs.UnifiedMemory,
 		"backend":          specs.Backend.String(),
 		"gpus":             gpus,
+		"hardware_score":   pole.HardwareScore(specs),
 	}
 	if specs.GpuVRAMGB != nil {
 		m["gpu_vram_gb"] = round2(*specs.GpuVRAMGB)
 	}
+	if specs.GpuFreeVRAMGB != nil {
+		m["gpu_free_vram_gb"] = round2(*specs.GpuFreeVRAMGB)
+	}
 	if specs.GpuName != nil {
 		m["gpu_name"] = *specs.GpuName
 	}
+	if specs.NumaNodes > 0 {
+		m["numa_nodes"] = specs.NumaNodes
+	}
+	if len(specs.Warnings) > 0 {
+		m["warnings"] = specs.Warnings
+	}
 	return m
 }
 
 // List prints all models as table to out.
 func List(out io.Writer, modelList []*models.LlmModel) {
-	fmt.Fprintln(out, "\n=== Available LLM Models ===")
-	fmt.Fprintf(out, "Total models: %d\n\n", len(modelList))
-	tbl := tablewriter.NewWriter(out)
-	tbl.Header("Status", "Model", "Provider", "Size", "Score", "tok/s", "Quant", "Mode", "Mem %", "Context")
+	groups := make([]models.ModelGroup, 0, len(modelList))
 	for _, m := range modelList {
-		tbl.Append([]string{"--", m.Name, m.Provider, m.ParameterCount, "-", "-", m.Quantization, "-", "-", fmt.Sprintf("%dk", m.ContextLength/1000)})
+		groups = append(groups, models.ModelGroup{Representative: m})
+	}
+	ListGroups(out, groups)
+}
+
+// ListGroups prints models as table to out, marking representatives that hide duplicate variants.
+func ListGroups(out io.Writer, groups []models.ModelGroup) {
+	chatter(out, "\n=== Available LLM Models ===\n")
+	chatter(out, "Total models: %d\n\n", len(groups))
+	showMoE := false
+	for _, g := range groups {
+		showMoE = showMoE || g.Representative.IsMoE
+	}
+	tbl := newTable(out)
+	tbl.Header(withMoEHeaders([]string{"Status", "Model", "Provider", "Size", "Score", "tok/s", "Quant", "Mode", "Mem %", "Context"}, showMoE))
+	for _, g := range groups {
+		m := g.Representative
+		row := []string{"--", withVariants(m.Name, len(g.Variants)), m.Provider, m.ParameterCount, "-", "-", m.Quantization, "-", "-", fmt.Sprintf("%dk", m.ContextLength/1000)}
+		tbl.Append(withMoECells(row, m, showMoE))
 	}
 	_ = tbl.Render()
 }
 
+// withVariants appends a "(+N variants)" marker to name when duplicates were collapsed behind it.
+func withVariants(name string, n int) string {
+	switch {
+	case n == 1:
+		return name + " (+1 variant)"
+	case n > 1:
+		return fmt.Sprintf("%s (+%d variants)", name, n)
+	}
+	return name
+}
+
+// newTable returns a table writer, using ASCII borders when ASCII mode is on.
+func newTable(out io.Writer) *tablewriter.Table {
+	if asciiMode {
+		return tablewriter.NewTable(out, tablewriter.WithSymbols(tw.NewSymbols(tw.StyleASCII)))
+	}
+	return tablewriter.NewWriter(out)
+}
+
+// fitStatus returns the fit emoji and text, or just the text in ASCII mode.
+func fitStatus(f *pole.ModelFit) string {
+	if asciiMode {
+		return f.FitText()
+	}
+	return f.FitEmoji() + " " + f.FitText()
+}
+
 // Pole prints pole/fit analysis to out (table or JSON).
 func Pole(out io.Writer, specs *hardware.SystemSpecs, fits []*pole.ModelFit, useJSON bool) {
 	if useJSON {
 		enc := json.NewEncoder(out)
 		enc.SetIndent("", "  ")
-		_ = enc.Encode(map[string]interface{}{
-			"system": systemJSON(specs),
-			"models": fitsToJSON(fits),
-		})
+		_ = enc.Encode(fitsJSON(specs, fits, summaryScope(fits)))
 		return
 	}
+	if showSystem {
+		System(out, specs, false)
+	}
 	if len(fits) == 0 {
-		fmt.Fprintln(out, "\nNo compatible models found for your system.")
+		chatter(out, "\nNo compatible models found for your system.\n")
 		return
 	}
-	fmt.Fprintln(out, "\n=== Pole Analysis ===")
-	fmt.Fprintf(out, "Found %d compatible model(s)\n\n", len(fits))
-	tbl := tablewriter.NewWriter(out)
-	tbl.Header("Status", "Model", "Provider", "Size", "Score", "tok/s", "Quant", "Mode", "Mem %", "Context")
+	if compactMode {
+		poleCompact(out, fits)
+		return
+	}
+	chatter(out, "\n=== Pole Analysis ===\n")
+	chatter(out, "Found %d compatible model(s)\n\n", len(fits))
+	poleTable(out, fits)
+}
+
+// showSystem, when set, prints the system specs block above pole and recommend tables, as their
+// JSON always carries "system".
+var showSystem bool
+
+// SetShowSystem sets whether table output starts with the system specs block (--full).
+func SetShowSystem(v bool) {
+	showSystem = v
+}
+
+// PolePage prints one page of the pole analysis, with a "showing a–b of n" footer under the table.
+// page is 1-based; a page past the end is an error.
+func PolePage(out io.Writer, specs *hardware.SystemSpecs, fits []*pole.ModelFit, page, size int, useJSON bool) error {
+	total := len(fits)
+	start, end := PageBounds(total, page, size)
+	if total > 0 && start >= total {
+		return fmt.Errorf("page %d is past the end (%d results, %d pages of %d)", page, total, (total+size-1)/size, size)
+	}
+	if useJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		doc := fitsJSON(specs, fits[start:end], summaryScope(fits))
+		doc["total"] = total
+		doc["page"] = page
+		doc["page_size"] = size
+		_ = enc.Encode(doc)
+		return nil
+	}
+	if showSystem {
+		System(out, specs, false)
+	}
+	if total == 0 {
+		chatter(out, "\nNo compatible models found for your system.\n")
+		return nil
+	}
+	if compactMode {
+		poleCompact(out, fits[start:end])
+		fmt.Fprintln(out, PageFooter(start, end, total))
+		return nil
+	}
+	chatter(out, "\n=== Pole Analysis ===\n")
+	chatter(out, "Found %d compatible model(s)\n\n", total)
+	poleTable(out, fits[start:end])
+	fmt.Fprintln(out, PageFooter(start, end, total))
+	return nil
+}
+
+// PageBounds returns the [start, end) slice bounds of 1-based page of size items out of total.
+// Pages below 1 are treated as 1; start == total means the page is past the end.
+func PageBounds(total, page, size int) (start, end int) {
+	if size < 1 {
+		size = 1
+	}
+	if page < 1 {
+		page = 1
+	}
+	start = (page - 1) * size
+	if start > total {
+		start = total
+	}
+	end = start + size
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+// PageFooter describes a page, e.g. "showing 21–40 of 137".
+func PageFooter(start, end, total int) string {
+	if end-start == 1 {
+		return fmt.Sprintf("showing %d of %d", start+1, total)
+	}
+	return fmt.Sprintf("showing %d%s%d of %d", start+1, glyph("–", "-"), end, total)
+}
+
+func poleTable(out io.Writer, fits []*pole.ModelFit) {
+	showMoE := false
+	for _, f := range fits {
+		showMoE = showMoE || f.Model.IsMoE
+	}
+	tbl := newTable(out)
+	tbl.Header(withMoEHeaders([]string{"Status", "Model", "Provider", "Size", "Score", "tok/s", "Quant", "Mode", "Mem %", "Context"}, showMoE))
 	for _, f := range fits {
-		tbl.Append([]string{
-			f.FitEmoji() + " " + f.FitText(),
-			f.Model.Name,
+		tbl.Append(withMoECells([]string{
+			fitStatus(f),
+			withVariants(f.Model.Name, f.VariantCount),
 			f.Model.Provider,
 			f.Model.ParameterCount,
-			fmt.Sprintf("%.0f", f.Score),
-			fmt.Sprintf("%.1f", f.EstimatedTPS),
+			Num(f.Score, 0),
+			Num(f.EstimatedTPS, 1),
 			f.BestQuant,
 			f.RunModeText(),
-			fmt.Sprintf("%.1f%%", f.UtilizationPct),
+			Num(f.UtilizationPct, 1) + "%",
 			fmt.Sprintf("%dk", f.Model.ContextLength/1000),
-		})
+		}, f.Model, showMoE))
 	}
 	_ = tbl.Render()
 }
 
+// withMoEHeaders appends the MoE columns (experts, active params) when the table has MoE rows.
+func withMoEHeaders(headers []string, showMoE bool) []string {
+	if !showMoE {
+		return headers
+	}
+	return append(headers, "Experts", "Active")
+}
+
+// withMoECells appends expert counts and active params for MoE rows ("-" for dense rows).
+func withMoECells(row []string, m *models.LlmModel, showMoE bool) []string {
+	if !showMoE {
+		return row
+	}
+	experts, active := "-", "-"
+	if m.IsMoE && m.NumExperts != nil && m.ActiveExperts != nil {
+		experts = fmt.Sprintf("%d/%d", *m.ActiveExperts, *m.NumExperts)
+	}
+	if m.IsMoE && m.ActiveParameters != nil {
+		active = Num(float64(*m.ActiveParameters)/1e9, 1) + "B"
+	}
+	return append(row, experts, active)
+}
+
 // Search prints search results table to out.
 func Search(out io.Writer, results []*models.LlmModel, query string) {
 	if len(results) == 0 {
-		fmt.Fprintf(out, "\nNo models found matching '%s'\n", query)
+		chatter(out, "\nNo models found matching '%s'\n", query)
 		return
 	}
-	fmt.Fprintf(out, "\n=== Search Results for '%s' ===\n", query)
-	fmt.Fprintf(out, "Found %d model(s)\n\n", len(results))
-	tbl := tablewriter.NewWriter(out)
+	chatter(out, "\n=== Search Results for '%s' ===\n", query)
+	chatter(out, "Found %d model(s)\n\n", len(results))
+	tbl := newTable(out)
 	tbl.Header("Status", "Model", "Provider", "Size", "Score", "tok/s", "Quant", "Mode", "Mem %", "Context")
 	for _, m := range results {
 		tbl.Append([]string{"--", m.Name, m.Provider, m.ParameterCount, "-", "-", m.Quantization, "-", "-", fmt.Sprintf("%dk", m.ContextLength/1000)})
@@ -227,62 +448,105 @@ func Search(out io.Writer, results []*models.LlmModel, query string) {
 // infoData holds template data for Info view.
 type infoData struct {
 	Name, Provider, ParameterCount, Quantization, BestQuant, UseCase, Category string
-	ContextLength                                                              string
+	ContextLength, AnalysisContext, License                                    string
 	Score, Quality, Speed, Fit, ContextScore, EstimatedTPS                     string
-	ResourceBlock, MoEBlock, FitStatus, RunMode, UtilizationPct                 string
+	ResourceBlock, MoEBlock, FitStatus, RunMode, UtilizationPct                string
 	MemoryRequired, MemoryAvailable, NotesBlock                                string
+	Alternative, QuantTradeoff, TTFT                                           string
 }
 
 // Info prints single model detail to out (table or JSON).
 func Info(out io.Writer, specs *hardware.SystemSpecs, fit *pole.ModelFit, useJSON bool) {
+	writeInfo(out, specs, fit, nil, false, useJSON)
+}
+
+// InfoWithAlternative is Info for a Too Tight model, followed by alt, the closest runnable
+// alternative (see pole.ClosestAlternative), or a note that there is none when alt is nil.
+// JSON output gains an "alternative" object (null when there is none).
+func InfoWithAlternative(out io.Writer, specs *hardware.SystemSpecs, fit, alt *pole.ModelFit, useJSON bool) {
+	writeInfo(out, specs, fit, alt, true, useJSON)
+}
+
+func writeInfo(out io.Writer, specs *hardware.SystemSpecs, fit, alt *pole.ModelFit, withAlt, useJSON bool) {
 	if useJSON {
+		obj := infoJSON(fit)
+		if withAlt {
+			obj["alternative"] = nil
+			if alt != nil {
+				obj["alternative"] = fitToJSON(alt)
+			}
+		}
 		enc := json.NewEncoder(out)
 		enc.SetIndent("", "  ")
 		_ = enc.Encode(map[string]interface{}{
 			"system": systemJSON(specs),
-			"models": fitsToJSON([]*pole.ModelFit{fit}),
+			"models": []map[string]interface{}{obj},
 		})
 		return
 	}
 	m := fit.Model
 	data := infoData{
-		Name:           m.Name,
-		Provider:       m.Provider,
-		ParameterCount: m.ParameterCount,
-		Quantization:   m.Quantization,
-		BestQuant:      fit.BestQuant,
-		ContextLength:  fmt.Sprintf("%d", m.ContextLength),
-		UseCase:        m.UseCase,
-		Category:       fit.UseCase.String(),
-		Score:          fmt.Sprintf("%.1f", fit.Score),
-		Quality:        fmt.Sprintf("%.0f", fit.ScoreComponents.Quality),
-		Speed:          fmt.Sprintf("%.0f", fit.ScoreComponents.Speed),
-		Fit:            fmt.Sprintf("%.0f", fit.ScoreComponents.Fit),
-		ContextScore:   fmt.Sprintf("%.0f", fit.ScoreComponents.Context),
-		EstimatedTPS:   fmt.Sprintf("%.1f", fit.EstimatedTPS),
-		ResourceBlock:  buildInfoResourceBlock(m),
-		FitStatus:      fit.FitEmoji() + " " + fit.FitText(),
-		RunMode:        fit.RunModeText(),
-		UtilizationPct: fmt.Sprintf("%.1f%%", fit.UtilizationPct),
-		MemoryRequired: fmt.Sprintf("%.1f", fit.MemoryRequiredGB),
-		MemoryAvailable: fmt.Sprintf("%.1f", fit.MemoryAvailableGB),
+		Name:            m.Name,
+		Provider:        m.Provider,
+		ParameterCount:  m.ParameterCount,
+		Quantization:    m.Quantization,
+		BestQuant:       fit.BestQuant,
+		ContextLength:   fmt.Sprintf("%d", m.ContextLength),
+		UseCase:         m.UseCase,
+		Category:        fit.UseCase.String(),
+		License:         m.License,
+		Score:           Num(fit.Score, 1),
+		Quality:         Num(fit.ScoreComponents.Quality, 0),
+		Speed:           Num(fit.ScoreComponents.Speed, 0),
+		Fit:             Num(fit.ScoreComponents.Fit, 0),
+		ContextScore:    Num(fit.ScoreComponents.Context, 0),
+		EstimatedTPS:    formatTPSBand(fit),
+		TTFT:            formatTTFT(fit),
+		ResourceBlock:   buildInfoResourceBlock(m),
+		FitStatus:       fitStatus(fit),
+		RunMode:         fit.RunModeText(),
+		UtilizationPct:  Num(fit.UtilizationPct, 1) + "%",
+		MemoryRequired:  Num(fit.MemoryRequiredGB, 1),
+		MemoryAvailable: Num(fit.MemoryAvailableGB, 1),
+		QuantTradeoff:   quantTradeoff(fit),
+	}
+	if fit.AnalysisContext != 0 && fit.AnalysisContext != m.ContextLength {
+		data.AnalysisContext = fmt.Sprintf("%d", fit.AnalysisContext)
 	}
 	if m.IsMoE {
 		data.MoEBlock = buildInfoMoEBlock(m, fit)
 	}
-	if len(fit.Notes) > 0 {
-		data.NotesBlock = "  " + strings.Join(fit.Notes, "\n  ")
+	if notes := visibleNotes(fit, NotesFull); len(notes) > 0 {
+		data.NotesBlock = "  " + strings.Join(notes, "\n  ")
+	}
+	switch {
+	case alt != nil:
+		data.Alternative = fmt.Sprintf("%s (%s) %s %s, %s, score %s, %s",
+			alt.Model.Name, alt.Model.ParameterCount, glyph("—", "-"), fitStatus(alt), alt.BestQuant, Num(alt.Score, 1), formatTPSBand(alt))
+	case withAlt:
+		data.Alternative = "none: no " + fit.UseCase.String() + " model runs on this hardware"
 	}
 	_ = infoTpl.Execute(out, data)
 }
 
+// formatTPSBand renders the tok/s estimate with its uncertainty band, e.g. "≈42 tok/s (30–55)".
+func formatTPSBand(fit *pole.ModelFit) string {
+	return glyph("≈", "~") + Num(fit.EstimatedTPS, 0) + " tok/s (" + Num(fit.EstimatedTPSLow, 0) + glyph("–", "-") + Num(fit.EstimatedTPSHigh, 0) + ")"
+}
+
+// formatTTFT renders the first-token latency with the prefill speed behind it, e.g.
+// "≈180 ms (prefill ≈2900 tok/s)".
+func formatTTFT(fit *pole.ModelFit) string {
+	return glyph("≈", "~") + Num(fit.EstimatedTTFTms, 0) + " ms (prefill " + glyph("≈", "~") + Num(fit.EstimatedPrefillTPS, 0) + " tok/s)"
+}
+
 func buildInfoResourceBlock(m *models.LlmModel) string {
 	var lines []string
 	if m.MinVRAMGB != nil {
-		lines = append(lines, fmt.Sprintf("  Min VRAM: %.1f GB", *m.MinVRAMGB))
+		lines = append(lines, "  Min VRAM: "+Num(*m.MinVRAMGB, 1)+" GB")
 	}
-	lines = append(lines, fmt.Sprintf("  Min RAM: %.1f GB (CPU inference)", m.MinRAMGB))
-	lines = append(lines, fmt.Sprintf("  Recommended RAM: %.1f GB", m.RecommendedRAMGB))
+	lines = append(lines, "  Min RAM: "+Num(m.MinRAMGB, 1)+" GB (CPU inference)")
+	lines = append(lines, "  Recommended RAM: "+Num(m.RecommendedRAMGB, 1)+" GB")
 	return strings.Join(lines, "\n")
 }
 
@@ -292,26 +556,80 @@ func buildInfoMoEBlock(m *models.LlmModel, fit *pole.ModelFit) string {
 		lines = append(lines, fmt.Sprintf("  Experts: %d active / %d total per token", *m.ActiveExperts, *m.NumExperts))
 	}
 	if m.MoeActiveVRAMGB() != nil && m.MinVRAMGB != nil {
-		lines = append(lines, fmt.Sprintf("  Active VRAM: %.1f GB (vs %.1f GB full model)", *m.MoeActiveVRAMGB(), *m.MinVRAMGB))
+		lines = append(lines, fmt.Sprintf("  Active VRAM: %s GB (vs %s GB full model)", Num(*m.MoeActiveVRAMGB(), 1), Num(*m.MinVRAMGB, 1)))
+	}
+	if fit.MoeResidentExperts != nil && m.NumExperts != nil {
+		lines = append(lines, fmt.Sprintf("  Resident: %d / %d experts in VRAM", *fit.MoeResidentExperts, *m.NumExperts))
 	}
 	if fit.MoeOffloadedGB != nil {
-		lines = append(lines, fmt.Sprintf("  Offloaded: %.1f GB inactive experts in RAM", *fit.MoeOffloadedGB))
+		lines = append(lines, "  Offloaded: "+Num(*fit.MoeOffloadedGB, 1)+" GB inactive experts in RAM")
 	}
 	return strings.Join(lines, "\n")
 }
 
+// quantTradeoff pairs the catalog quant with the recommended one, e.g.
+// "default Q4_K_M (6.1 GB, Good) → recommended Q5_K_M (7.4 GB, Good)", or names the one quant
+// when they are the same.
+func quantTradeoff(fit *pole.ModelFit) string {
+	option := func(q pole.QuantOption) string {
+		return fmt.Sprintf("%s (%s GB, %s)", q.Quant, Num(q.MemoryGB, 1), q.FitLevel)
+	}
+	if fit.DefaultQuant.Quant == fit.RecommendedQuant.Quant {
+		return option(fit.RecommendedQuant) + ", default and recommended"
+	}
+	return fmt.Sprintf("default %s %s recommended %s", option(fit.DefaultQuant), glyph("→", "->"), option(fit.RecommendedQuant))
+}
+
+// quantOptionJSON is a QuantOption with its fit level spelled out.
+func quantOptionJSON(q pole.QuantOption) map[string]interface{} {
+	return map[string]interface{}{"quant": q.Quant, "memory_gb": Round(q.MemoryGB, 2), "fit_level": q.FitLevel.String()}
+}
+
+// infoJSON is fitToJSON plus the resource and MoE breakdown shown by the Info text view.
+func infoJSON(fit *pole.ModelFit) map[string]interface{} {
+	m := fit.Model
+	obj := fitToJSON(fit)
+	obj["notes"] = visibleNotes(fit, NotesFull)
+	resources := map[string]interface{}{
+		"min_ram_gb":         round2(m.MinRAMGB),
+		"recommended_ram_gb": round2(m.RecommendedRAMGB),
+	}
+	if m.MinVRAMGB != nil {
+		resources["min_vram_gb"] = round2(*m.MinVRAMGB)
+	}
+	obj["resources"] = resources
+	obj["default_quant"] = quantOptionJSON(fit.DefaultQuant)
+	obj["recommended_quant"] = quantOptionJSON(fit.RecommendedQuant)
+	if !m.IsMoE {
+		return obj
+	}
+	moe := map[string]interface{}{}
+	if m.NumExperts != nil && m.ActiveExperts != nil {
+		moe["experts_active"] = *m.ActiveExperts
+		moe["experts_total"] = *m.NumExperts
+	}
+	if v := m.MoeActiveVRAMGB(); v != nil {
+		moe["active_vram_gb"] = round2(*v)
+	}
+	if fit.MoeResidentExperts != nil {
+		moe["resident_experts"] = *fit.MoeResidentExperts
+	}
+	if fit.MoeOffloadedGB != nil {
+		moe["offloaded_gb"] = round2(*fit.MoeOffloadedGB)
+	}
+	obj["moe"] = moe
+	return obj
+}
+
 // Recommend prints recommendation list to out (table or JSON).
 func Recommend(out io.Writer, specs *hardware.SystemSpecs, fits []*pole.ModelFit, useJSON bool) {
 	if useJSON {
 		enc := json.NewEncoder(out)
 		enc.SetIndent("", "  ")
-		_ = enc.Encode(map[string]interface{}{
-			"system": systemJSON(specs),
-			"models": fitsToJSON(fits),
-		})
+		_ = enc.Encode(fitsJSON(specs, fits, summaryScope(fits)))
 		return
 	}
-	if len(fits) > 0 {
+	if len(fits) > 0 && !quietMode && !showSystem {
 		System(out, specs, false)
 	}
 	Pole(out, specs, fits, false)
@@ -328,36 +646,53 @@ func fitsToJSON(fits []*pole.ModelFit) []map[string]interface{} {
 func fitToJSON(f *pole.ModelFit) map[string]interface{} {
 	m := f.Model
 	obj := map[string]interface{}{
-		"name":              m.Name,
-		"provider":          m.Provider,
-		"parameter_count":   m.ParameterCount,
-		"params_b":          round2(m.ParamsB()),
-		"context_length":    m.ContextLength,
-		"use_case":          m.UseCase,
-		"category":          f.UseCase.String(),
-		"is_moe":            m.IsMoE,
-		"fit_level":         f.FitText(),
-		"run_mode":          f.RunModeText(),
-		"score":             round1(f.Score),
+		"name":             m.Name,
+		"provider":         m.Provider,
+		"parameter_count":  m.ParameterCount,
+		"params_b":         round2(m.ParamsB()),
+		"context_length":   m.ContextLength,
+		"usable_context":   f.UsableContext,
+		"analysis_context": f.AnalysisContext,
+		"kv_cache_type":    f.KVCacheType,
+		"use_case":         m.UseCase,
+		"category":         f.UseCase.String(),
+		"is_moe":           m.IsMoE,
+		"variant_count":    f.VariantCount,
+		"fit_level":        f.FitText(),
+		"run_mode":         f.RunModeText(),
+		"score":            round1(f.Score),
 		"score_components": map[string]interface{}{
 			"quality": round1(f.ScoreComponents.Quality),
 			"speed":   round1(f.ScoreComponents.Speed),
 			"fit":     round1(f.ScoreComponents.Fit),
 			"context": round1(f.ScoreComponents.Context),
 		},
-		"estimated_tps":      round1(f.EstimatedTPS),
-		"best_quant":         f.BestQuant,
-		"memory_required_gb": round2(f.MemoryRequiredGB),
-		"memory_available_gb": round2(f.MemoryAvailableGB),
-		"utilization_pct":    round1(f.UtilizationPct),
-		"notes":              f.Notes,
+		"estimated_tps":         round1(f.EstimatedTPS),
+		"estimated_tps_low":     round1(f.EstimatedTPSLow),
+		"estimated_tps_high":    round1(f.EstimatedTPSHigh),
+		"estimated_prefill_tps": round1(f.EstimatedPrefillTPS),
+		"estimated_ttft_ms":     round1(f.EstimatedTTFTms),
+		"best_quant":            f.BestQuant,
+		"memory_required_gb":    round2(f.MemoryRequiredGB),
+		"memory_available_gb":   round2(f.MemoryAvailableGB),
+		"utilization_pct":       round1(f.UtilizationPct),
+		"notes":                 visibleNotes(f, NotesShort),
+	}
+	if m.UnknownSize {
+		obj["unknown_size"] = true
+	}
+	if m.License != "" {
+		obj["license"] = m.License
+	}
+	if f.SuggestedQuant != "" {
+		obj["suggested_quant"] = f.SuggestedQuant
 	}
 	return obj
 }
 
 func round1(v float64) float64 {
-	return float64(int(v*10+0.5)) / 10
+	return Round(v, 1)
 }
 func round2(v float64) float64 {
-	return float64(int(v*100+0.5)) / 100
+	return Round(v, 2)
 }
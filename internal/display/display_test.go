@@ -3,12 +3,21 @@ package display
 import (
 	"bytes"
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/shayne-snap/llmpole/internal/hardware"
 	"github.com/shayne-snap/llmpole/internal/models"
 	"github.com/shayne-snap/llmpole/internal/pole"
+
+	"github.com/mattn/go-runewidth"
 )
 
 func specNoGPU(ramGB float64, cores int) *hardware.SystemSpecs {
@@ -98,6 +107,51 @@ func TestSystem_Table(t *testing.T) {
 	}
 }
 
+func TestSystemExplained(t *testing.T) {
+	spec := specWithGPU(16, 32)
+	spec.Gpus[0].VRAMSource = "nvidia-smi memory.total"
+	spec.Sources = map[string]string{
+		hardware.FieldTotalRAM:     "/proc/meminfo MemTotal",
+		hardware.FieldAvailableRAM: "vm_stat fallback (free + inactive + purgeable pages)",
+		hardware.FieldCPUName:      "/proc/cpuinfo model name",
+	}
+	var buf bytes.Buffer
+	SystemExplained(&buf, spec, false)
+	s := buf.String()
+	for _, want := range []string{
+		"(16.00 GB VRAM, CUDA) (VRAM from nvidia-smi memory.total)",
+		"Total RAM: 32.00 GB (from /proc/meminfo MemTotal)",
+		"(from vm_stat fallback (free + inactive + purgeable pages))",
+		"  name from /proc/cpuinfo model name",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("explained output missing %q:\n%s", want, s)
+		}
+	}
+	buf.Reset()
+	System(&buf, spec, false)
+	if strings.Contains(buf.String(), "from") {
+		t.Errorf("plain System output should not carry sources:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	SystemExplained(&buf, spec, true)
+	var out struct {
+		System struct {
+			Sources map[string]interface{} `json:"sources"`
+		} `json:"system"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if out.System.Sources["total_ram"] != "/proc/meminfo MemTotal" {
+		t.Errorf("sources.total_ram = %v", out.System.Sources["total_ram"])
+	}
+	if gpus, _ := out.System.Sources["gpu_vram"].([]interface{}); len(gpus) != 1 || gpus[0] != "nvidia-smi memory.total" {
+		t.Errorf("sources.gpu_vram = %v", out.System.Sources["gpu_vram"])
+	}
+}
+
 func TestSystem_TableWithGPU(t *testing.T) {
 	spec := specWithGPU(8, 32)
 	var buf bytes.Buffer
@@ -186,6 +240,55 @@ func TestPole_NonEmpty_Table(t *testing.T) {
 	}
 }
 
+func TestPoleHTML(t *testing.T) {
+	spec := specWithGPU(8, 32)
+	tricky := model7B()
+	tricky.Name = `evil<b>&"model`
+	tricky.Provider = "A & B"
+	fits := []*pole.ModelFit{pole.Analyze(model7B(), spec), pole.Analyze(tricky, spec)}
+	var buf bytes.Buffer
+	if err := PoleHTML(&buf, spec, fits); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "<b>") {
+		t.Error("model name should be escaped, found a raw <b> tag")
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+	var cells []string
+	rows := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if err != io.EOF {
+				t.Fatalf("output is not well-formed HTML: %v", err)
+			}
+			break
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			for _, a := range tok.Attr {
+				if tok.Name.Local == "tr" && a.Name.Local == "class" && strings.HasPrefix(a.Value, "fit-") {
+					rows++
+				}
+			}
+		case xml.CharData:
+			cells = append(cells, strings.TrimSpace(string(tok)))
+		}
+	}
+	if rows != 2 {
+		t.Errorf("got %d model rows with a fit class, want 2", rows)
+	}
+	for _, want := range []string{"Test CPU (8 cores)", "Test GPU (8.00 GB VRAM, CUDA)", "test-7b", `evil<b>&"model`, "A & B", fits[0].FitText()} {
+		if !slices.Contains(cells, want) {
+			t.Errorf("HTML text should contain %q", want)
+		}
+	}
+}
+
 func TestSearch_Empty(t *testing.T) {
 	var buf bytes.Buffer
 	Search(&buf, nil, "nonexistent")
@@ -243,6 +346,40 @@ func TestInfo_Table(t *testing.T) {
 	}
 }
 
+func TestInfo_QuantTradeoff(t *testing.T) {
+	spec, fits := oneFit()
+	fit := fits[0]
+	fit.DefaultQuant = pole.QuantOption{Quant: "Q4_K_M", MemoryGB: 6.1, FitLevel: pole.FitGood}
+	fit.RecommendedQuant = pole.QuantOption{Quant: "Q5_K_M", MemoryGB: 7.4, FitLevel: pole.FitMarginal}
+	var buf bytes.Buffer
+	Info(&buf, spec, fit, false)
+	if want := "Quant Tradeoff: default Q4_K_M (6.1 GB, Good) → recommended Q5_K_M (7.4 GB, Marginal)"; !strings.Contains(buf.String(), want) {
+		t.Errorf("output missing %q:\n%s", want, buf.String())
+	}
+
+	buf.Reset()
+	Info(&buf, spec, fit, true)
+	var out struct {
+		Models []struct {
+			Default     map[string]interface{} `json:"default_quant"`
+			Recommended map[string]interface{} `json:"recommended_quant"`
+		} `json:"models"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if d, r := out.Models[0].Default, out.Models[0].Recommended; d["quant"] != "Q4_K_M" || d["memory_gb"] != 6.1 || r["quant"] != "Q5_K_M" || r["fit_level"] != "Marginal" {
+		t.Errorf("JSON default_quant = %v, recommended_quant = %v", d, r)
+	}
+
+	fit.RecommendedQuant = fit.DefaultQuant
+	buf.Reset()
+	Info(&buf, spec, fit, false)
+	if s := buf.String(); !strings.Contains(s, "Quant Tradeoff: Q4_K_M (6.1 GB, Good), default and recommended") || strings.Contains(s, "→") {
+		t.Errorf("identical quants should collapse to one:\n%s", s)
+	}
+}
+
 func TestInfo_Table_MoE(t *testing.T) {
 	spec := specNoGPU(32, 8)
 	activeParams := uint64(3_000_000_000)
@@ -303,3 +440,752 @@ func TestRecommend_Table(t *testing.T) {
 		t.Error("output should contain model name")
 	}
 }
+
+func TestInfo_Table_TPSBand(t *testing.T) {
+	spec, fits := oneFit()
+	var buf bytes.Buffer
+	Info(&buf, spec, fits[0], false)
+	s := buf.String()
+	if !strings.Contains(s, "≈") || !strings.Contains(s, "–") {
+		t.Errorf("Estimated Speed should show a band, got: %s", s)
+	}
+	buf.Reset()
+	Info(&buf, spec, fits[0], true)
+	if !strings.Contains(buf.String(), "estimated_tps_low") || !strings.Contains(buf.String(), "estimated_tps_high") {
+		t.Error("JSON should include estimated_tps_low and estimated_tps_high")
+	}
+}
+
+func TestPole_Table_ASCII(t *testing.T) {
+	SetASCII(true)
+	defer SetASCII(false)
+	spec, fits := oneFit()
+	var buf bytes.Buffer
+	Recommend(&buf, spec, fits, false)
+	Info(&buf, spec, fits[0], false)
+	for _, r := range buf.String() {
+		if r > 0x7f {
+			t.Fatalf("ASCII output contains non-ASCII rune %q:\n%s", r, buf.String())
+		}
+	}
+}
+
+func TestLocaleSupportsUTF8(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_CTYPE", "")
+	t.Setenv("LANG", "en_US.UTF-8")
+	if !LocaleSupportsUTF8() {
+		t.Error("LANG=en_US.UTF-8 should support UTF-8")
+	}
+	t.Setenv("LC_ALL", "C")
+	if LocaleSupportsUTF8() {
+		t.Error("LC_ALL=C should take precedence and disable UTF-8")
+	}
+}
+
+func TestListGroups_VariantMarker(t *testing.T) {
+	variant := model7B()
+	variant.Name = "test-7b-GGUF"
+	var buf bytes.Buffer
+	ListGroups(&buf, []models.ModelGroup{{Representative: model7B(), Variants: []*models.LlmModel{variant, variant}}})
+	if !strings.Contains(buf.String(), "(+2 variants)") {
+		t.Errorf("expected variant marker, got: %s", buf.String())
+	}
+}
+
+func TestPole_Table_MoEColumns(t *testing.T) {
+	spec := specNoGPU(32, 8)
+	numExp, activeExp := uint32(8), uint32(2)
+	activeParams := uint64(12_900_000_000)
+	moe := model7B()
+	moe.Name = "moe-model"
+	moe.IsMoE = true
+	moe.NumExperts, moe.ActiveExperts, moe.ActiveParameters = &numExp, &activeExp, &activeParams
+	var buf bytes.Buffer
+	Pole(&buf, spec, []*pole.ModelFit{pole.Analyze(moe, spec), pole.Analyze(model7B(), spec)}, false)
+	var moeRow, denseRow string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.Contains(line, "moe-model") {
+			moeRow = line
+		} else if strings.Contains(line, "test-7b") {
+			denseRow = line
+		}
+	}
+	if !strings.Contains(buf.String(), "EXPERTS") {
+		t.Fatalf("MoE columns missing: %s", buf.String())
+	}
+	if !strings.Contains(moeRow, "2/8") || !strings.Contains(moeRow, "12.9B") {
+		t.Errorf("MoE row missing expert data: %q", moeRow)
+	}
+	if strings.Contains(denseRow, "2/8") || strings.Contains(denseRow, "12.9B") {
+		t.Errorf("dense row should not have expert data: %q", denseRow)
+	}
+
+	buf.Reset()
+	Pole(&buf, spec, []*pole.ModelFit{pole.Analyze(model7B(), spec)}, false)
+	if strings.Contains(buf.String(), "EXPERTS") {
+		t.Error("MoE columns should be omitted when no MoE rows are shown")
+	}
+}
+
+func TestFitsTemplate(t *testing.T) {
+	fit := pole.Analyze(model7B(), specWithGPU(8, 64))
+	fits := []*pole.ModelFit{fit, fit}
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{"{{.name}}", "test-7b\ntest-7b\n"},
+		{"{{.name}} {{.best_quant}} {{.fit_level}}", fmt.Sprintf("test-7b %s %s\n", fit.BestQuant, fit.FitText())},
+		{"{{.name}} q={{.score_components.quality}} ctx={{.score_components.context}}", fmt.Sprintf("test-7b q=%v ctx=%v\n", round1(fit.ScoreComponents.Quality), round1(fit.ScoreComponents.Context))},
+		{"{{.name}}: {{.estimated_tps}} tok/s", fmt.Sprintf("test-7b: %v tok/s\n", round1(fit.EstimatedTPS))},
+	}
+	for _, tt := range tests {
+		tmpl, err := ParseFitTemplate(tt.tmpl)
+		if err != nil {
+			t.Fatalf("ParseFitTemplate(%q): %v", tt.tmpl, err)
+		}
+		var buf bytes.Buffer
+		if err := FitsTemplate(&buf, tmpl, fits); err != nil {
+			t.Fatalf("FitsTemplate(%q): %v", tt.tmpl, err)
+		}
+		if !strings.HasPrefix(buf.String(), tt.want) {
+			t.Errorf("FitsTemplate(%q) = %q, want prefix %q", tt.tmpl, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestFitsTemplate_Errors(t *testing.T) {
+	if _, err := ParseFitTemplate("{{.name"); err == nil {
+		t.Error("ParseFitTemplate(unclosed) = nil error, want parse error")
+	}
+	tmpl, err := ParseFitTemplate("{{.no_such_field}}")
+	if err != nil {
+		t.Fatalf("ParseFitTemplate: %v", err)
+	}
+	fit := pole.Analyze(model7B(), specWithGPU(8, 64))
+	if err := FitsTemplate(&bytes.Buffer{}, tmpl, []*pole.ModelFit{fit}); err == nil {
+		t.Error("FitsTemplate(unknown key) = nil error, want missing key error")
+	}
+}
+
+func TestPageBounds(t *testing.T) {
+	tests := []struct {
+		total, page, size int
+		start, end        int
+	}{
+		{137, 1, 20, 0, 20},
+		{137, 2, 20, 20, 40},
+		{137, 7, 20, 120, 137},
+		{137, 8, 20, 137, 137},
+		{137, 0, 20, 0, 20},
+		{5, 1, 20, 0, 5},
+		{0, 1, 20, 0, 0},
+	}
+	for _, tt := range tests {
+		start, end := PageBounds(tt.total, tt.page, tt.size)
+		if start != tt.start || end != tt.end {
+			t.Errorf("PageBounds(%d, %d, %d) = %d, %d; want %d, %d", tt.total, tt.page, tt.size, start, end, tt.start, tt.end)
+		}
+	}
+}
+
+func TestPageFooter(t *testing.T) {
+	if got := PageFooter(0, 20, 137); got != "showing 1–20 of 137" {
+		t.Errorf("PageFooter(0, 20, 137) = %q, want %q", got, "showing 1–20 of 137")
+	}
+	if got := PageFooter(136, 137, 137); got != "showing 137 of 137" {
+		t.Errorf("PageFooter(136, 137, 137) = %q, want %q", got, "showing 137 of 137")
+	}
+	SetASCII(true)
+	defer SetASCII(false)
+	if got := PageFooter(20, 40, 137); got != "showing 21-40 of 137" {
+		t.Errorf("ASCII PageFooter(20, 40, 137) = %q, want %q", got, "showing 21-40 of 137")
+	}
+}
+
+func TestPolePage(t *testing.T) {
+	spec := specWithGPU(8, 64)
+	var fits []*pole.ModelFit
+	for i := 0; i < 5; i++ {
+		fits = append(fits, pole.Analyze(model7B(), spec))
+	}
+	var buf bytes.Buffer
+	if err := PolePage(&buf, spec, fits, 2, 2, false); err != nil {
+		t.Fatalf("PolePage: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Found 5 compatible model(s)") || !strings.Contains(out, "showing 3–4 of 5") {
+		t.Errorf("PolePage page 2 output missing total or footer:\n%s", out)
+	}
+	if n := strings.Count(out, "test-7b"); n != 2 {
+		t.Errorf("PolePage page 2 rendered %d rows, want 2", n)
+	}
+	if err := PolePage(&bytes.Buffer{}, spec, fits, 4, 2, false); err == nil {
+		t.Error("PolePage(page past end) = nil error, want error")
+	}
+}
+
+func TestPole_JSONSummary(t *testing.T) {
+	spec := specWithGPU(8, 64)
+	big := model7B()
+	big.Name, big.Provider, big.ParameterCount, big.MinRAMGB, big.RecommendedRAMGB, big.MinVRAMGB = "test-70b", "Other", "70B", 48, 64, nil
+	fits := []*pole.ModelFit{pole.Analyze(model7B(), spec), pole.Analyze(model7B(), spec), pole.Analyze(big, spec)}
+
+	SetSummary(SummaryInclude)
+	defer SetSummary(SummaryOff)
+	var buf bytes.Buffer
+	Pole(&buf, spec, fits, true)
+	var doc struct {
+		Models []struct {
+			Provider string `json:"provider"`
+			FitLevel string `json:"fit_level"`
+			Category string `json:"category"`
+		} `json:"models"`
+		Summary pole.Summary `json:"summary"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	providers, levels, useCases := map[string]int{}, map[string]int{}, map[string]int{}
+	runnable := 0
+	for _, m := range doc.Models {
+		providers[m.Provider]++
+		levels[m.FitLevel]++
+		useCases[m.Category]++
+		if m.FitLevel != "Too Tight" {
+			runnable++
+		}
+	}
+	if doc.Summary.Total != len(doc.Models) || doc.Summary.Runnable != runnable {
+		t.Errorf("summary total/runnable = %d/%d, want %d/%d", doc.Summary.Total, doc.Summary.Runnable, len(doc.Models), runnable)
+	}
+	for name, pair := range map[string][2]map[string]int{
+		"providers":  {doc.Summary.Providers, providers},
+		"fit_levels": {doc.Summary.FitLevels, levels},
+		"use_cases":  {doc.Summary.UseCases, useCases},
+	} {
+		got, want := pair[0], pair[1]
+		if len(got) != len(want) {
+			t.Errorf("summary %s = %v, want %v", name, got, want)
+			continue
+		}
+		for k, v := range want {
+			if got[k] != v {
+				t.Errorf("summary %s[%q] = %d, want %d", name, k, got[k], v)
+			}
+		}
+	}
+
+	SetSummary(SummaryOnly)
+	buf.Reset()
+	Pole(&buf, spec, fits, true)
+	if strings.Contains(buf.String(), `"models"`) || !strings.Contains(buf.String(), `"summary"`) {
+		t.Errorf("SummaryOnly output should have summary and no models:\n%s", buf.String())
+	}
+}
+
+func TestPole_JSONSummaryCoversFullList(t *testing.T) {
+	spec := specWithGPU(8, 64)
+	fits := []*pole.ModelFit{pole.Analyze(model7B(), spec), pole.Analyze(model7B(), spec), pole.Analyze(model7B(), spec)}
+	SetSummary(SummaryInclude)
+	defer SetSummary(SummaryOff)
+	var doc struct {
+		Models  []interface{} `json:"models"`
+		Total   int           `json:"total"`
+		Summary pole.Summary  `json:"summary"`
+	}
+
+	var buf bytes.Buffer
+	if err := PolePage(&buf, spec, fits, 2, 2, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(doc.Models) != 1 || doc.Total != 3 || doc.Summary.Total != 3 {
+		t.Errorf("page 2 of 2: %d models, total %d, summary total %d; want 1, 3, 3", len(doc.Models), doc.Total, doc.Summary.Total)
+	}
+
+	SetSummaryOf(fits)
+	buf.Reset()
+	Pole(&buf, spec, fits[:1], true)
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(doc.Models) != 1 || doc.Summary.Total != 3 {
+		t.Errorf("limited to 1: %d models, summary total %d; want 1 and the full 3", len(doc.Models), doc.Summary.Total)
+	}
+}
+
+func TestPole_Compact(t *testing.T) {
+	SetCompact(true)
+	defer SetCompact(false)
+	spec := specWithGPU(8, 64)
+	m2 := model7B()
+	m2.Name = "test-7b-chat"
+	fits := []*pole.ModelFit{pole.Analyze(model7B(), spec), pole.Analyze(m2, spec)}
+	var buf bytes.Buffer
+	Pole(&buf, spec, fits, false)
+	out := buf.String()
+	if strings.ContainsAny(out, "│┌─+|") {
+		t.Errorf("compact output has table borders:\n%s", out)
+	}
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) != len(fits) {
+		t.Fatalf("compact output has %d lines, want %d:\n%s", len(lines), len(fits), out)
+	}
+	for i, f := range fits {
+		for _, tok := range []string{
+			f.FitEmoji(),
+			fmt.Sprintf("%.0f", f.Score),
+			f.Model.Name,
+			f.BestQuant,
+			f.RunModeText(),
+			fmt.Sprintf("%.0f tok/s", f.EstimatedTPS),
+		} {
+			if !strings.Contains(lines[i], tok) {
+				t.Errorf("line %d %q missing %q", i, lines[i], tok)
+			}
+		}
+	}
+}
+
+func TestInfo_JSONBreakdown(t *testing.T) {
+	for _, f := range goldenFits()[:2] {
+		var buf bytes.Buffer
+		Info(&buf, specWithGPU(8, 64), f, true)
+		var doc struct {
+			Models []struct {
+				Resources map[string]float64 `json:"resources"`
+				MoE       map[string]float64 `json:"moe"`
+			} `json:"models"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: invalid JSON: %v", f.Model.Name, err)
+		}
+		got := doc.Models[0]
+		for _, k := range []string{"min_vram_gb", "min_ram_gb", "recommended_ram_gb"} {
+			if _, ok := got.Resources[k]; !ok {
+				t.Errorf("%s: resources missing %q: %v", f.Model.Name, k, got.Resources)
+			}
+		}
+		if !f.Model.IsMoE {
+			if got.MoE != nil {
+				t.Errorf("%s: dense model has moe object %v", f.Model.Name, got.MoE)
+			}
+			continue
+		}
+		if got.MoE["experts_active"] != 2 || got.MoE["experts_total"] != 8 {
+			t.Errorf("%s: moe experts = %v, want 2/8", f.Model.Name, got.MoE)
+		}
+		if _, ok := got.MoE["active_vram_gb"]; !ok {
+			t.Errorf("%s: moe missing active_vram_gb: %v", f.Model.Name, got.MoE)
+		}
+	}
+}
+
+func TestBest(t *testing.T) {
+	spec := specWithGPU(8, 64)
+	fit := pole.Analyze(model7B(), spec)
+	var buf bytes.Buffer
+	Best(&buf, spec, fit, "", false)
+	out := buf.String()
+	for _, want := range []string{"test-7b", fit.BestQuant, "tok/s", "How to run:", "-ngl"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Best output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	Best(&buf, spec, nil, "coding", false)
+	if out := buf.String(); !strings.Contains(out, "No coding model") || !strings.Contains(out, "advise") {
+		t.Errorf("Best(nil) output = %q, want a nothing-runnable message", out)
+	}
+
+	buf.Reset()
+	Best(&buf, spec, nil, "", true)
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Best(nil) JSON: %v", err)
+	}
+	if v, ok := doc["best"]; !ok || v != nil {
+		t.Errorf("Best(nil) JSON best = %v, want null", v)
+	}
+}
+
+func TestCompare_JSONWinners(t *testing.T) {
+	fits := goldenFits()
+	var buf bytes.Buffer
+	Compare(&buf, fits[0], fits[1], true)
+	var doc struct {
+		A       map[string]interface{}            `json:"a"`
+		B       map[string]interface{}            `json:"b"`
+		Models  map[string]map[string]interface{} `json:"models"`
+		Winners map[string]string                 `json:"winners"`
+		Overall string                            `json:"overall"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if doc.A["name"] != fits[0].Model.Name || doc.B["name"] != fits[1].Model.Name {
+		t.Errorf("a = %v, b = %v; want %s and %s", doc.A["name"], doc.B["name"], fits[0].Model.Name, fits[1].Model.Name)
+	}
+	if len(doc.Models) != 2 || doc.Models[fits[0].Model.Name] == nil || doc.Models[fits[1].Model.Name] == nil {
+		t.Errorf("models = %v, want both keyed by name", doc.Models)
+	}
+	name := map[string]string{pole.WinnerA: fits[0].Model.Name, pole.WinnerB: fits[1].Model.Name, pole.WinnerTie: pole.WinnerTie}
+	c := pole.CompareFits(fits[0], fits[1])
+	if doc.Overall != name[c.Overall] || len(doc.Winners) != len(pole.CompareDimensions) {
+		t.Errorf("overall = %q, winners = %v; want %q and one winner per dimension", doc.Overall, doc.Winners, name[c.Overall])
+	}
+	for dim, side := range c.Winners {
+		if doc.Winners[dim] != name[side] {
+			t.Errorf("winners[%s] = %q, want %q", dim, doc.Winners[dim], name[side])
+		}
+	}
+
+	buf.Reset()
+	Compare(&buf, fits[0], fits[0], false)
+	if out := buf.String(); !strings.Contains(out, "tie") || !strings.Contains(out, "WINNER") {
+		t.Errorf("self-compare table should show ties:\n%s", out)
+	}
+}
+
+func TestCatalogDiff(t *testing.T) {
+	d := models.CatalogDiff{
+		Added:   []string{"org/new"},
+		Removed: []string{"org/old"},
+		Changed: []models.ModelChange{{Name: "org/tuned", Fields: []models.FieldChange{{Field: "min_vram_gb", Old: "6", New: ""}}}},
+	}
+	var buf bytes.Buffer
+	CatalogDiff(&buf, d, "cached list", "embedded list", false)
+	for _, want := range []string{"+ org/new\n", "- org/old\n", "~ org/tuned: min_vram_gb 6 ", " (none)\n", "1 added, 1 removed, 1 changed"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("CatalogDiff output missing %q:\n%s", want, buf.String())
+		}
+	}
+	buf.Reset()
+	CatalogDiff(&buf, models.CatalogDiff{}, "cached list", "embedded list", true)
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if added, ok := doc["added"].([]interface{}); !ok || len(added) != 0 {
+		t.Errorf("empty diff JSON added = %v, want []", doc["added"])
+	}
+}
+
+func TestVisibleNotes_Modes(t *testing.T) {
+	defer SetNotes(NotesAuto)
+	// test-70b spills to RAM: 2 warnings among 4 notes.
+	fit := goldenFits()[1]
+	if len(fit.Notes) != 4 || len(fit.Warnings()) != 2 {
+		t.Fatalf("fixture notes = %q, warnings = %q; want 4 notes, 2 warnings", fit.Notes, fit.Warnings())
+	}
+	tests := []struct {
+		mode       string
+		list, info int
+	}{
+		{"", 2, 4},
+		{"none", 0, 0},
+		{"short", 2, 2},
+		{"full", 4, 4},
+	}
+	for _, tt := range tests {
+		mode, err := ParseNotesMode(tt.mode)
+		if err != nil {
+			t.Fatalf("ParseNotesMode(%q): %v", tt.mode, err)
+		}
+		SetNotes(mode)
+		if n := len(fitToJSON(fit)["notes"].([]string)); n != tt.list {
+			t.Errorf("--notes %q: pole JSON has %d notes, want %d", tt.mode, n, tt.list)
+		}
+		if n := len(infoJSON(fit)["notes"].([]string)); n != tt.info {
+			t.Errorf("--notes %q: info JSON has %d notes, want %d", tt.mode, n, tt.info)
+		}
+	}
+	if _, err := ParseNotesMode("loud"); err == nil {
+		t.Error("ParseNotesMode(loud) = nil error, want error")
+	}
+}
+
+func TestGPULoad(t *testing.T) {
+	one := []hardware.GpuLiveStats{{Name: "GPU", UtilizationPct: 37, TemperatureC: 61}}
+	if got := GPULoad(one); got != "37% 61°C" {
+		t.Errorf("GPULoad(one) = %q", got)
+	}
+	two := append(one, hardware.GpuLiveStats{Name: "GPU", UtilizationPct: -1, TemperatureC: 40})
+	if got := GPULoad(two); got != "0: 37% 61°C, 1: 40°C" {
+		t.Errorf("GPULoad(two) = %q", got)
+	}
+}
+
+func TestMetrics_PrometheusText(t *testing.T) {
+	spec := specWithGPU(8, 32)
+	spec.Gpus[0].Name = `Quote "GPU"`
+	fits := []*pole.ModelFit{pole.Analyze(model7B(), spec)}
+	var buf bytes.Buffer
+	Metrics(&buf, spec, fits)
+	typed := map[string]bool{}
+	sample := regexp.MustCompile(`^([a-z_]+)(\{[a-z_]+="(?:[^"\\]|\\.)*"(?:,[a-z_]+="(?:[^"\\]|\\.)*")*\})? -?[0-9.e+]+$`)
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if name, ok := strings.CutPrefix(line, "# TYPE "); ok {
+			if !strings.HasSuffix(name, " gauge") {
+				t.Errorf("TYPE line %q is not a gauge", line)
+			}
+			typed[strings.TrimSuffix(name, " gauge")] = true
+			continue
+		}
+		if strings.HasPrefix(line, "# HELP ") {
+			continue
+		}
+		m := sample.FindStringSubmatch(line)
+		if m == nil {
+			t.Errorf("invalid sample line %q", line)
+			continue
+		}
+		if !typed[m[1]] {
+			t.Errorf("sample %q precedes its # TYPE header", line)
+		}
+	}
+	for _, want := range []string{
+		"llmpole_vram_gb 8\n",
+		"llmpole_available_ram_gb 25.6\n",
+		"llmpole_runnable_models 1\n",
+		`llmpole_gpu_vram_gb{gpu="0",name="Quote \"GPU\"",backend="CUDA",count="1"} 8`,
+		"# HELP llmpole_gpu_vram_gb Memory in GB of each detected GPU model, summed across its count devices.\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("metrics missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestPole_ShowSystemBlock(t *testing.T) {
+	spec := specWithGPU(8, 32)
+	fits := []*pole.ModelFit{pole.Analyze(model7B(), spec)}
+	render := func(full bool) string {
+		SetShowSystem(full)
+		defer SetShowSystem(false)
+		var buf bytes.Buffer
+		Pole(&buf, spec, fits, false)
+		return buf.String()
+	}
+	if out := render(false); strings.Contains(out, "System Specifications") {
+		t.Errorf("system block shown without --full:\n%s", out)
+	}
+	out := render(true)
+	if !strings.Contains(out, "=== System Specifications ===") || !strings.Contains(out, "Test CPU") {
+		t.Errorf("system block missing with --full:\n%s", out)
+	}
+	if strings.Index(out, "System Specifications") > strings.Index(out, "Pole Analysis") {
+		t.Error("system block should precede the table")
+	}
+	var rec bytes.Buffer
+	SetShowSystem(true)
+	Recommend(&rec, spec, fits, false)
+	SetShowSystem(false)
+	if n := strings.Count(rec.String(), "System Specifications"); n != 1 {
+		t.Errorf("recommend --full printed the system block %d times, want 1", n)
+	}
+}
+
+func TestTruncate_MultibyteStaysValidUTF8(t *testing.T) {
+	inputs := []string{
+		"Qwen2.5-72B-Instruct",
+		"通义千问-72B-指令微调版",
+		"café ☕ naïve résumé",
+		"🦙🦙🦙 llama emoji",
+		"bad \xff\xfe bytes",
+	}
+	for _, ascii := range []bool{false, true} {
+		SetASCII(ascii)
+		for _, s := range inputs {
+			for w := 0; w <= 24; w++ {
+				got := Truncate(s, w)
+				if !utf8.ValidString(got) {
+					t.Errorf("ascii=%v Truncate(%q, %d) = %q is not valid UTF-8", ascii, s, w, got)
+				}
+				if cw := runewidth.StringWidth(got); cw > w {
+					t.Errorf("ascii=%v Truncate(%q, %d) = %q is %d columns wide", ascii, s, w, got, cw)
+				}
+				cut := runewidth.StringWidth(strings.ToValidUTF8(s, "�")) > w
+				if ellipsis := glyph("…", "~"); cut && w > 1 && !strings.HasSuffix(got, ellipsis) {
+					t.Errorf("ascii=%v Truncate(%q, %d) = %q lacks the ellipsis", ascii, s, w, got)
+				}
+				if padded := TruncatePad(s, w); runewidth.StringWidth(padded) != w {
+					t.Errorf("ascii=%v TruncatePad(%q, %d) = %q, want exactly %d columns", ascii, s, w, padded, w)
+				}
+			}
+		}
+	}
+	SetASCII(false)
+	if got := Truncate("通义千问", 5); got != "通义…" {
+		t.Errorf("Truncate(通义千问, 5) = %q, want 通义…", got)
+	}
+}
+
+func TestMemoryOnly_MatchesEstimateMemoryGB(t *testing.T) {
+	m := model7B()
+	opts := pole.DefaultOptions()
+	fit := pole.AnalyzeWithOptions(m, specWithGPU(12, 32), opts)
+	want := m.EstimateMemoryGB(fit.BestQuant, m.ContextLength, models.KVCacheF16)
+
+	var buf bytes.Buffer
+	MemoryOnly(&buf, pole.EstimateMemory(fit, opts), false)
+	var got float64
+	if _, err := fmt.Sscanf(buf.String(), "%f\n", &got); err != nil || strings.Count(buf.String(), "\n") != 1 {
+		t.Fatalf("output %q, want a single number", buf.String())
+	}
+	if got != round2(want) {
+		t.Errorf("memory-only = %v, want EstimateMemoryGB(%s, %d) = %.2f", got, fit.BestQuant, m.ContextLength, want)
+	}
+
+	buf.Reset()
+	MemoryOnly(&buf, pole.EstimateMemory(fit, opts), true)
+	var obj struct {
+		Quant      string  `json:"quant"`
+		Context    uint32  `json:"context"`
+		WeightsGB  float64 `json:"weights_gb"`
+		KVCacheGB  float64 `json:"kv_cache_gb"`
+		OverheadGB float64 `json:"overhead_gb"`
+		TotalGB    float64 `json:"total_gb"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	if obj.Quant != fit.BestQuant || obj.TotalGB != round2(want) || obj.Context != m.ContextLength {
+		t.Errorf("JSON = %+v, want quant %s, total %.2f at %d tokens", obj, fit.BestQuant, want, m.ContextLength)
+	}
+	if sum := obj.WeightsGB + obj.KVCacheGB + obj.OverheadGB; math.Abs(sum-obj.TotalGB) > 0.02 {
+		t.Errorf("breakdown sums to %.2f, want %.2f", sum, obj.TotalGB)
+	}
+}
+
+func TestPrecision_TableAndJSONAgree(t *testing.T) {
+	defer SetPrecision(-1)
+	for _, tt := range []struct {
+		precision         int
+		tps, util         string
+		tpsJSON, utilJSON float64
+	}{
+		{-1, "12.3", "45.7%", 12.3, 45.7},
+		{2, "12.35", "45.68%", 12.35, 45.68},
+		{0, "12", "46%", 12, 46},
+	} {
+		SetPrecision(tt.precision)
+		spec, fits := oneFit()
+		fits[0].EstimatedTPS, fits[0].UtilizationPct = 12.34567, 45.6789
+		var table, js bytes.Buffer
+		Pole(&table, spec, fits, false)
+		Pole(&js, spec, fits, true)
+		for _, want := range []string{tt.tps, tt.util} {
+			if !strings.Contains(table.String(), " "+want+" ") {
+				t.Errorf("precision %d: table missing %q:\n%s", tt.precision, want, table.String())
+			}
+		}
+		var out struct {
+			Models []map[string]interface{} `json:"models"`
+		}
+		if err := json.Unmarshal(js.Bytes(), &out); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if got := out.Models[0]["estimated_tps"]; got != tt.tpsJSON {
+			t.Errorf("precision %d: estimated_tps = %v, want %v", tt.precision, got, tt.tpsJSON)
+		}
+		if got := out.Models[0]["utilization_pct"]; got != tt.utilJSON {
+			t.Errorf("precision %d: utilization_pct = %v, want %v", tt.precision, got, tt.utilJSON)
+		}
+	}
+}
+
+func TestPrecision_CapacityAndPlan(t *testing.T) {
+	defer SetPrecision(-1)
+	SetPrecision(3)
+	_, fits := oneFit()
+	c := pole.Capacity{Quant: "Q4_K_M", Context: 4096, MemoryGB: 21.23456, WeightsGB: 4.5, KVPerRequestGB: 0.51234, Concurrent: 3}
+	var table, js bytes.Buffer
+	Capacity(&table, fits[0], c, false)
+	Capacity(&js, fits[0], c, true)
+	for _, want := range []string{"21.235 GB", "4.500 GB", "0.512 GB @ 4096 tokens"} {
+		if !strings.Contains(table.String(), want) {
+			t.Errorf("capacity table missing %q:\n%s", want, table.String())
+		}
+	}
+	var capOut struct {
+		Capacity map[string]interface{} `json:"capacity"`
+	}
+	if err := json.Unmarshal(js.Bytes(), &capOut); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got := capOut.Capacity["memory_gb"]; got != 21.235 {
+		t.Errorf("capacity memory_gb = %v, want 21.235", got)
+	}
+
+	table.Reset()
+	js.Reset()
+	p := pole.Plan{Models: []pole.PlanEntry{{Model: "m", Quant: "Q4_K_M", MemoryGB: 5.12345, Pool: pole.PoolVRAM}}, VRAMGB: 10, TotalGB: 5.12345, HeadroomGB: 4.87655, Fits: true}
+	Plan(&table, p, false)
+	Plan(&js, p, true)
+	if !strings.Contains(table.String(), "5.123 GB of 10.000 GB usable, 4.877 GB headroom") {
+		t.Errorf("plan summary not at 3 decimals:\n%s", table.String())
+	}
+	var planOut struct {
+		Plan struct {
+			Models  []map[string]interface{} `json:"models"`
+			TotalGB float64                  `json:"total_gb"`
+		} `json:"plan"`
+	}
+	if err := json.Unmarshal(js.Bytes(), &planOut); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if planOut.Plan.TotalGB != 5.123 || planOut.Plan.Models[0]["memory_gb"] != 5.123 {
+		t.Errorf("plan JSON = %s, want GB rounded to 3 decimals", js.String())
+	}
+}
+
+func TestCompareMany_ColumnPerModel(t *testing.T) {
+	fits := goldenFits()
+	if len(fits) < 3 {
+		t.Fatalf("goldenFits has %d fits, want at least 3", len(fits))
+	}
+	fits = fits[:3]
+	var buf bytes.Buffer
+	CompareMany(&buf, fits, false)
+	out := buf.String()
+	for _, want := range []string{"Memory", "Fit level", "tok/s", fits[0].Model.Name, fits[1].Model.Name, fits[2].Model.Name} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table missing %q:\n%s", want, out)
+		}
+	}
+	if !strings.Contains(out, Num(fits[2].UtilizationPct, 1)+"%") {
+		t.Errorf("table missing the third model's utilization:\n%s", out)
+	}
+
+	buf.Reset()
+	CompareMany(&buf, fits, true)
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if _, ok := doc["a"]; ok {
+		t.Error(`three-model JSON has "a", which only a two-model compare carries`)
+	}
+	var models map[string]map[string]interface{}
+	var winners map[string]string
+	var overall string
+	if json.Unmarshal(doc["models"], &models) != nil || json.Unmarshal(doc["winners"], &winners) != nil || json.Unmarshal(doc["overall"], &overall) != nil {
+		t.Fatalf("models/winners/overall missing or malformed:\n%s", buf.String())
+	}
+	if len(models) != 3 || models[fits[2].Model.Name]["name"] != fits[2].Model.Name {
+		t.Errorf("models = %v, want one entry per model keyed by name", models)
+	}
+	name := map[string]string{pole.WinnerTie: pole.WinnerTie}
+	for i, f := range fits {
+		name[pole.Side(i)] = f.Model.Name
+	}
+	if c := pole.CompareAll(fits); overall != name[c.Overall] || len(winners) != len(pole.CompareDimensions) {
+		t.Errorf("overall = %q, winners = %v; want %q and one winner per dimension", overall, winners, name[c.Overall])
+	}
+}
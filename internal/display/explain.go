@@ -0,0 +1,58 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shayne-snap/llmpole/internal/hardware"
+)
+
+// SystemExplained prints specs like System, annotating each value with how it was detected
+// (system --explain-system). In JSON the provenance is under "sources".
+func SystemExplained(out io.Writer, specs *hardware.SystemSpecs, useJSON bool) {
+	writeSystem(out, specs, useJSON, true)
+}
+
+// fromSource formats a provenance suffix, or "" when the source is unrecorded.
+func fromSource(source string) string {
+	if source == "" {
+		return ""
+	}
+	return " (from " + source + ")"
+}
+
+// cpuSourceLine is the indented provenance line under the CPU entry.
+func cpuSourceLine(specs *hardware.SystemSpecs) string {
+	var parts []string
+	if s := specs.Source(hardware.FieldCPUName); s != "" {
+		parts = append(parts, "name from "+s)
+	}
+	if s := specs.Source(hardware.FieldCPUCores); s != "" {
+		parts = append(parts, "cores from "+s)
+	}
+	if s := specs.Source(hardware.FieldNUMANodes); s != "" {
+		parts = append(parts, "NUMA nodes from "+s)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "\n  " + strings.Join(parts, "; ")
+}
+
+func sourcesJSON(specs *hardware.SystemSpecs) map[string]interface{} {
+	m := make(map[string]interface{}, len(specs.Sources)+1)
+	for field, source := range specs.Sources {
+		m[field] = source
+	}
+	gpus := make([]string, 0, len(specs.Gpus))
+	for i, g := range specs.Gpus {
+		source := g.VRAMSource
+		if source == "" {
+			source = fmt.Sprintf("unknown (GPU %d reported no VRAM)", i+1)
+		}
+		gpus = append(gpus, source)
+	}
+	m["gpu_vram"] = gpus
+	return m
+}
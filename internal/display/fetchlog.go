@@ -0,0 +1,32 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shayne-snap/llmpole/internal/models"
+)
+
+// FetchLog prints the fetch log oldest first, one fetch per line (time, repo, size, quant,
+// context, source), or the entries as a JSON array.
+func FetchLog(out io.Writer, entries []models.FetchLogEntry, useJSON bool) {
+	if useJSON {
+		if entries == nil {
+			entries = []models.FetchLogEntry{}
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(entries)
+		return
+	}
+	if len(entries) == 0 {
+		chatter(out, "No models fetched yet.\n")
+		return
+	}
+	for _, e := range entries {
+		fmt.Fprintf(out, "%s  %s  %s %s, %d ctx  %s\n",
+			e.Time.Local().Format(time.DateTime), e.RepoID, e.ParameterCount, e.Quantization, e.ContextLength, e.Source)
+	}
+}
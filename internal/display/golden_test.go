@@ -0,0 +1,82 @@
+package display
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shayne-snap/llmpole/internal/models"
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/*.golden with current output")
+
+// goldenFits analyzes a fixed model set (fits in VRAM, spills to RAM, MoE, too big) on a fixed
+// CUDA system. The GPU system keeps results independent of GOARCH (CPU-only speed depends on it).
+func goldenFits() []*pole.ModelFit {
+	spec := specWithGPU(8, 64)
+	minVram70 := 40.0
+	big := &models.LlmModel{
+		Name: "test-70b", Provider: "Test", ParameterCount: "70B", MinRAMGB: 44, RecommendedRAMGB: 80,
+		MinVRAMGB: &minVram70, Quantization: "Q4_K_M", ContextLength: 8192, UseCase: "reasoning",
+	}
+	numExp, activeExp := uint32(8), uint32(2)
+	total, active := uint64(46_700_000_000), uint64(12_900_000_000)
+	minVramMoE := 24.0
+	moe := &models.LlmModel{
+		Name: "test-moe", Provider: "Test", ParameterCount: "46.7B", ParametersRaw: &total, MinRAMGB: 26, RecommendedRAMGB: 48,
+		MinVRAMGB: &minVramMoE, Quantization: "Q4_K_M", ContextLength: 32768, UseCase: "chat",
+		IsMoE: true, NumExperts: &numExp, ActiveExperts: &activeExp, ActiveParameters: &active,
+	}
+	fits := pole.AnalyzeAll([]*models.LlmModel{model7B(), moe, big}, spec)
+	return pole.RankModelsByFit(fits)
+}
+
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden (run go test ./internal/display -update to create): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s output differs from %s (run with -update if intended)\n--- got ---\n%s\n--- want ---\n%s", name, path, got, want)
+	}
+}
+
+func TestGolden_System(t *testing.T) {
+	var buf bytes.Buffer
+	System(&buf, specWithGPU(8, 64), false)
+	checkGolden(t, "system", buf.Bytes())
+}
+
+func TestGolden_Pole(t *testing.T) {
+	var buf bytes.Buffer
+	Pole(&buf, specWithGPU(8, 64), goldenFits(), false)
+	checkGolden(t, "pole", buf.Bytes())
+}
+
+func TestGolden_PoleJSON(t *testing.T) {
+	var buf bytes.Buffer
+	Pole(&buf, specWithGPU(8, 64), goldenFits(), true)
+	checkGolden(t, "pole_json", buf.Bytes())
+}
+
+func TestGolden_Info(t *testing.T) {
+	for _, f := range goldenFits() {
+		var buf bytes.Buffer
+		Info(&buf, specWithGPU(8, 64), f, false)
+		checkGolden(t, "info_"+f.Model.Name, buf.Bytes())
+	}
+}
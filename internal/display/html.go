@@ -0,0 +1,150 @@
+package display
+
+import (
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/shayne-snap/llmpole/internal/hardware"
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+// htmlTpl renders PoleHTML: one self-contained page, styles and the click-to-sort script inline.
+// The script avoids < and & so the page also reads as well-formed XML.
+var htmlTpl = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8"/>
+<meta name="viewport" content="width=device-width, initial-scale=1"/>
+<title>llmpole report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, Helvetica, Arial, sans-serif; margin: 2em; color: #222; background: #fff; }
+h1 { font-size: 1.5em; margin-bottom: 0.2em; }
+h2 { font-size: 1.15em; margin-top: 1.5em; }
+dl.system { display: grid; grid-template-columns: max-content auto; gap: 0.3em 1.2em; margin: 0; }
+dl.system dt { color: #666; }
+dl.system dd { margin: 0; }
+table { border-collapse: collapse; width: 100%; font-size: 0.92em; }
+th, td { padding: 0.4em 0.7em; border-bottom: 1px solid #ddd; text-align: left; white-space: nowrap; }
+th { background: #f4f4f4; cursor: pointer; user-select: none; }
+th.sorted-asc::after { content: " \25B2"; }
+th.sorted-desc::after { content: " \25BC"; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+tr.fit-perfect td.status { background: #d9f2d9; }
+tr.fit-good td.status { background: #fff3c4; }
+tr.fit-marginal td.status { background: #f6dcf2; }
+tr.fit-too-tight td.status { background: #f8d0d0; }
+tr.fit-too-tight { color: #888; }
+p.empty, footer { color: #666; }
+</style>
+</head>
+<body>
+<h1>llmpole report</h1>
+<h2>System</h2>
+<dl class="system">
+{{- range .System}}
+<dt>{{.Label}}</dt><dd>{{.Value}}</dd>
+{{- end}}
+</dl>
+<h2>Models ({{len .Rows}})</h2>
+{{- if .Rows}}
+<table id="models">
+<thead>
+<tr><th>Status</th><th>Model</th><th>Provider</th><th data-type="num">Size</th><th data-type="num">Score</th><th data-type="num">tok/s</th><th>Quant</th><th>Mode</th><th data-type="num">Mem %</th><th data-type="num">Context</th></tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr class="{{.Class}}"><td class="status" data-sort="{{.Rank}}">{{.Status}}</td><td>{{.Name}}</td><td>{{.Provider}}</td><td class="num" data-sort="{{.ParamsB}}">{{.Size}}</td><td class="num">{{.Score}}</td><td class="num">{{.TPS}}</td><td>{{.Quant}}</td><td>{{.Mode}}</td><td class="num" data-sort="{{.MemPct}}">{{.MemPctText}}</td><td class="num" data-sort="{{.Context}}">{{.ContextText}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- else}}
+<p class="empty">No compatible models found for this system.</p>
+{{- end}}
+<footer><p>Click a column header to sort. Generated by llmpole.</p></footer>
+<script>
+(function () {
+  var table = document.getElementById("models");
+  if (!table) { return; }
+  var heads = table.tHead.rows[0].cells;
+  function key(row, i, numeric) {
+    var cell = row.cells[i];
+    var v = cell.getAttribute("data-sort");
+    if (v === null) { v = cell.textContent; }
+    return numeric ? parseFloat(v) || 0 : v.toLowerCase();
+  }
+  Array.prototype.forEach.call(heads, function (th, i) {
+    th.addEventListener("click", function () {
+      var numeric = th.getAttribute("data-type") === "num";
+      var desc = !th.classList.contains("sorted-desc");
+      var body = table.tBodies[0];
+      var rows = Array.prototype.slice.call(body.rows);
+      rows.sort(function (a, b) {
+        var x = key(a, i, numeric), y = key(b, i, numeric);
+        var c = x === y ? 0 : (x > y ? 1 : -1);
+        return desc ? -c : c;
+      });
+      rows.forEach(function (r) { body.appendChild(r); });
+      Array.prototype.forEach.call(heads, function (h) { h.classList.remove("sorted-asc", "sorted-desc"); });
+      th.classList.add(desc ? "sorted-desc" : "sorted-asc");
+    });
+  });
+})();
+</script>
+</body>
+</html>
+`))
+
+type htmlField struct{ Label, Value string }
+
+type htmlRow struct {
+	Class, Status, Name, Provider, Size, Quant, Mode string
+	Score, TPS, MemPctText, ContextText              string
+	Rank                                             int
+	ParamsB, MemPct                                  float64
+	Context                                          uint32
+}
+
+// PoleHTML writes fits as a standalone HTML report: the system summary, then a table colored by
+// fit level that sorts by any column when its header is clicked. Strings are HTML-escaped.
+func PoleHTML(out io.Writer, specs *hardware.SystemSpecs, fits []*pole.ModelFit) error {
+	data := struct {
+		System []htmlField
+		Rows   []htmlRow
+	}{System: htmlSystem(specs)}
+	for _, f := range fits {
+		data.Rows = append(data.Rows, htmlRow{
+			Class:       "fit-" + strings.ReplaceAll(strings.ToLower(f.FitText()), " ", "-"),
+			Status:      f.FitText(),
+			Rank:        int(pole.FitTooTight - f.FitLevel),
+			Name:        withVariants(f.Model.Name, f.VariantCount),
+			Provider:    f.Model.Provider,
+			Size:        f.Model.ParameterCount,
+			ParamsB:     round2(f.Model.ParamsB()),
+			Score:       Num(f.Score, 0),
+			TPS:         Num(f.EstimatedTPS, 1),
+			Quant:       f.BestQuant,
+			Mode:        f.RunModeText(),
+			MemPct:      round1(f.UtilizationPct),
+			MemPctText:  Num(f.UtilizationPct, 1) + "%",
+			Context:     f.Model.ContextLength,
+			ContextText: Num(float64(f.Model.ContextLength)/1000, 0) + "k",
+		})
+	}
+	return htmlTpl.Execute(out, data)
+}
+
+// htmlSystem is the system summary of the HTML report, one labelled line per fact.
+func htmlSystem(specs *hardware.SystemSpecs) []htmlField {
+	fields := []htmlField{
+		{"CPU", specs.CPUName + " (" + Num(float64(specs.TotalCPUCores), 0) + " cores)"},
+		{"Total RAM", Num(specs.TotalRAMGB, 2) + " GB"},
+		{"Available RAM", Num(specs.AvailableRAMGB, 2) + " GB"},
+		{"Backend", specs.Backend.String()},
+	}
+	for _, line := range strings.Split(buildSystemGpuBlock(specs, false), "\n") {
+		label, value, _ := strings.Cut(line, ": ")
+		fields = append(fields, htmlField{label, value})
+	}
+	return append(fields, htmlField{"Hardware score", Num(pole.HardwareScore(specs), 1) + " / 100"})
+}
@@ -0,0 +1,61 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shayne-snap/llmpole/internal/hardware"
+)
+
+// GPULoad formats live GPU samples compactly, e.g. "37% 61°C" for one GPU or
+// "0: 37% 61°C, 1: 5% 40°C" for several. Fields the driver did not report are omitted.
+func GPULoad(stats []hardware.GpuLiveStats) string {
+	parts := make([]string, 0, len(stats))
+	for i, s := range stats {
+		var fields []string
+		if s.UtilizationPct >= 0 {
+			fields = append(fields, Num(s.UtilizationPct, 0)+"%")
+		}
+		if s.TemperatureC >= 0 {
+			fields = append(fields, Num(s.TemperatureC, 0)+glyph("°C", "C"))
+		}
+		if len(fields) == 0 {
+			fields = append(fields, "n/a")
+		}
+		p := strings.Join(fields, " ")
+		if len(stats) > 1 {
+			p = fmt.Sprintf("%d: %s", i, p)
+		}
+		parts = append(parts, p)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// LiveStats prints one watch sample: a timestamped line per GPU, or one JSON object per sample.
+func LiveStats(out io.Writer, at time.Time, stats []hardware.GpuLiveStats, useJSON bool) {
+	if useJSON {
+		_ = json.NewEncoder(out).Encode(map[string]interface{}{
+			"time": at.Format(time.RFC3339),
+			"gpus": liveStatsJSON(stats),
+		})
+		return
+	}
+	for _, s := range stats {
+		fmt.Fprintf(out, "%s  %s: %s\n", at.Format("15:04:05"), s.Name, GPULoad([]hardware.GpuLiveStats{s}))
+	}
+}
+
+func liveStatsJSON(stats []hardware.GpuLiveStats) []map[string]interface{} {
+	out := make([]map[string]interface{}, len(stats))
+	for i, s := range stats {
+		out[i] = map[string]interface{}{
+			"name":            s.Name,
+			"utilization_pct": round1(s.UtilizationPct),
+			"temperature_c":   round1(s.TemperatureC),
+		}
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/shayne-snap/llmpole/internal/hardware"
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+// metricSample is one labelled value of a metric family.
+type metricSample struct {
+	labels [][2]string // name, value pairs in output order
+	value  float64
+}
+
+// metricFamily is a Prometheus gauge with its help text and samples.
+type metricFamily struct {
+	name    string
+	help    string
+	samples []metricSample
+}
+
+func gauge(name, help string, value float64, labels ...[2]string) metricFamily {
+	return metricFamily{name: name, help: help, samples: []metricSample{{labels: labels, value: value}}}
+}
+
+// Metrics prints system capacity and fit counts as Prometheus text-format gauges, for
+// node_exporter's textfile collector or any scraper that reads the exposition format.
+func Metrics(out io.Writer, specs *hardware.SystemSpecs, fits []*pole.ModelFit) {
+	counts := pole.CountFits(fits)
+	vram := 0.0
+	if specs.GpuVRAMGB != nil {
+		vram = *specs.GpuVRAMGB
+	}
+	families := []metricFamily{
+		gauge("llmpole_info", "Detected hardware; the value is always 1.", 1,
+			[2]string{"cpu", specs.CPUName}, [2]string{"backend", specs.Backend.String()}),
+		gauge("llmpole_total_ram_gb", "Total system RAM in GB.", specs.TotalRAMGB),
+		gauge("llmpole_available_ram_gb", "Available system RAM in GB.", specs.AvailableRAMGB),
+		gauge("llmpole_cpu_cores", "Logical CPU cores.", float64(specs.TotalCPUCores)),
+		gauge("llmpole_vram_gb", "GPU memory in GB used for fit decisions (0 without a GPU).", vram),
+		gauge("llmpole_unified_memory", "1 when the GPU shares system RAM (e.g. Apple Silicon).", boolGauge(specs.UnifiedMemory)),
+		gauge("llmpole_catalog_models", "Models analyzed.", float64(len(fits))),
+		gauge("llmpole_runnable_models", "Models that fit this system (any level but Too Tight).", float64(counts.Runnable)),
+		{name: "llmpole_models", help: "Models analyzed by fit level.", samples: []metricSample{
+			{labels: [][2]string{{"fit", "perfect"}}, value: float64(counts.Perfect)},
+			{labels: [][2]string{{"fit", "good"}}, value: float64(counts.Good)},
+			{labels: [][2]string{{"fit", "marginal"}}, value: float64(counts.Marginal)},
+			{labels: [][2]string{{"fit", "too_tight"}}, value: float64(counts.TooTight)},
+		}},
+	}
+	gpus := metricFamily{name: "llmpole_gpu_vram_gb", help: "Memory in GB of each detected GPU model, summed across its count devices."}
+	for i, g := range specs.Gpus {
+		v := 0.0
+		if g.VRAMGB != nil {
+			v = *g.VRAMGB
+		}
+		gpus.samples = append(gpus.samples, metricSample{value: v, labels: [][2]string{
+			{"gpu", strconv.Itoa(i)}, {"name", g.Name}, {"backend", g.Backend.String()}, {"count", strconv.FormatUint(uint64(g.Count), 10)},
+		}})
+	}
+	if len(gpus.samples) > 0 {
+		families = append(families, gpus)
+	}
+	for _, f := range families {
+		fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s gauge\n", f.name, f.help, f.name)
+		for _, s := range f.samples {
+			fmt.Fprintf(out, "%s%s %s\n", f.name, promLabels(s.labels), strconv.FormatFloat(s.value, 'g', -1, 64))
+		}
+	}
+}
+
+func boolGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// promLabels formats labels as {a="x",b="y"}, escaping values per the exposition format.
+func promLabels(labels [][2]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	esc := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	parts := make([]string, len(labels))
+	for i, l := range labels {
+		parts[i] = l[0] + `="` + esc.Replace(l[1]) + `"`
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
@@ -0,0 +1,62 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+// NotesMode controls how many analysis notes are printed.
+type NotesMode int
+
+const (
+	NotesAuto  NotesMode = iota // short for pole/recommend output, full for info
+	NotesNone                   // drop notes
+	NotesShort                  // warnings only
+	NotesFull                   // every note
+)
+
+var notesMode = NotesAuto
+
+// SetNotes sets how many analysis notes the CLI and JSON output include.
+func SetNotes(m NotesMode) {
+	notesMode = m
+}
+
+// ParseNotesMode parses a --notes value: none, short, or full ("" is auto).
+func ParseNotesMode(s string) (NotesMode, error) {
+	switch strings.ToLower(s) {
+	case "":
+		return NotesAuto, nil
+	case "none":
+		return NotesNone, nil
+	case "short":
+		return NotesShort, nil
+	case "full":
+		return NotesFull, nil
+	}
+	return NotesAuto, fmt.Errorf("unknown --notes %q (use none, short, or full)", s)
+}
+
+// visibleNotes returns f's notes under the current mode, using auto when the mode is NotesAuto.
+// The result is never nil, so JSON always has a notes array.
+func visibleNotes(f *pole.ModelFit, auto NotesMode) []string {
+	mode := notesMode
+	if mode == NotesAuto {
+		mode = auto
+	}
+	switch mode {
+	case NotesNone:
+		return []string{}
+	case NotesShort:
+		if w := f.Warnings(); w != nil {
+			return w
+		}
+		return []string{}
+	}
+	if f.Notes == nil {
+		return []string{}
+	}
+	return f.Notes
+}
@@ -0,0 +1,69 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+// Plan prints where each model of p goes and whether they all fit at once.
+func Plan(out io.Writer, p pole.Plan, useJSON bool) {
+	if useJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(map[string]interface{}{"plan": planJSON(p)})
+		return
+	}
+	chatter(out, "\n=== Plan: %d models together ===\n", len(p.Models))
+	tbl := newTable(out)
+	tbl.Header([]string{"Model", "Quant", "Memory", "Placed in"})
+	for _, e := range p.Models {
+		pool := "does not fit"
+		switch e.Pool {
+		case pole.PoolVRAM:
+			pool = "VRAM"
+			if p.RAMGB == 0 && p.VRAMGB > 0 {
+				pool = "unified memory"
+			}
+		case pole.PoolRAM:
+			pool = "RAM"
+		}
+		tbl.Append([]string{e.Model, e.Quant, Num(e.MemoryGB, 1) + " GB", pool})
+	}
+	_ = tbl.Render()
+	usable := p.VRAMGB + p.RAMGB
+	if p.Fits {
+		fmt.Fprintf(out, "\nFits together: %s GB of %s GB usable, %s GB headroom\n", Num(p.TotalGB, 1), Num(usable, 1), Num(p.HeadroomGB, 1))
+		return
+	}
+	if p.HeadroomGB < 0 {
+		fmt.Fprintf(out, "\nDoes not fit: %s GB needed, %s GB usable (%s GB over)\n", Num(p.TotalGB, 1), Num(usable, 1), Num(-p.HeadroomGB, 1))
+	} else {
+		fmt.Fprintf(out, "\nDoes not fit: %s GB needed of %s GB usable, but not every model fits in what is left of one memory pool\n", Num(p.TotalGB, 1), Num(usable, 1))
+	}
+	fmt.Fprintf(out, "Offload first: %s\n", p.OffloadFirst)
+}
+
+func planJSON(p pole.Plan) map[string]interface{} {
+	entries := make([]map[string]interface{}, len(p.Models))
+	for i, e := range p.Models {
+		entries[i] = map[string]interface{}{"model": e.Model, "quant": e.Quant, "memory_gb": round2(e.MemoryGB)}
+		if e.Pool != "" {
+			entries[i]["pool"] = e.Pool
+		}
+	}
+	obj := map[string]interface{}{
+		"models":      entries,
+		"vram_gb":     round2(p.VRAMGB),
+		"ram_gb":      round2(p.RAMGB),
+		"total_gb":    round2(p.TotalGB),
+		"headroom_gb": round2(p.HeadroomGB),
+		"fits":        p.Fits,
+	}
+	if p.OffloadFirst != "" {
+		obj["offload_first"] = p.OffloadFirst
+	}
+	return obj
+}
@@ -0,0 +1,49 @@
+package display
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// MaxPrecision is the most decimal places --precision accepts.
+const MaxPrecision = 6
+
+// precision, when >= 0, overrides the decimal places of the numbers in tables, JSON, and the
+// TUI; numbers inside note prose and Prometheus metrics keep their own formats.
+// At -1 each field keeps its own default: whole-number scores in tables, one decimal for tok/s
+// and percentages, two for GB in JSON and the system block.
+var precision = -1
+
+// SetPrecision sets the --precision override; -1 restores per-field defaults.
+func SetPrecision(p int) {
+	precision = p
+}
+
+// ParsePrecision validates a --precision value.
+func ParsePrecision(p int) (int, error) {
+	if p < 0 || p > MaxPrecision {
+		return -1, fmt.Errorf("--precision must be between 0 and %d, got %d", MaxPrecision, p)
+	}
+	return p, nil
+}
+
+// places returns the override when set, else the field default.
+func places(def int) int {
+	if precision >= 0 {
+		return precision
+	}
+	return def
+}
+
+// Num formats v with the field's default decimal places, or the --precision override, so a value
+// reads the same in tables, the TUI, and JSON (see Round).
+func Num(v float64, def int) string {
+	return strconv.FormatFloat(v, 'f', places(def), 64)
+}
+
+// Round rounds v the way Num prints it, for JSON fields.
+func Round(v float64, def int) float64 {
+	scale := math.Pow(10, float64(places(def)))
+	return math.Round(v*scale) / scale
+}
@@ -0,0 +1,35 @@
+package display
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+// ProfileMatrix prints one model's fit, run mode, quant, and speed on each built-in hardware profile.
+func ProfileMatrix(out io.Writer, model string, rows []pole.ProfileFit, useJSON bool) {
+	if useJSON {
+		profiles := make([]map[string]interface{}, 0, len(rows))
+		for _, r := range rows {
+			profiles = append(profiles, map[string]interface{}{
+				"profile":       r.Profile,
+				"fit_level":     r.Fit.FitText(),
+				"run_mode":      r.Fit.RunModeText(),
+				"best_quant":    r.Fit.BestQuant,
+				"estimated_tps": round1(r.Fit.EstimatedTPS),
+			})
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(map[string]interface{}{"model": model, "profiles": profiles})
+		return
+	}
+	chatter(out, "\n=== %s across hardware profiles ===\n", model)
+	tbl := newTable(out)
+	tbl.Header([]string{"Profile", "Fit", "Mode", "Quant", "tok/s"})
+	for _, r := range rows {
+		tbl.Append([]string{r.Profile, fitStatus(r.Fit), r.Fit.RunModeText(), r.Fit.BestQuant, Num(r.Fit.EstimatedTPS, 1)})
+	}
+	_ = tbl.Render()
+}
@@ -0,0 +1,27 @@
+package display
+
+import (
+	"fmt"
+	"io"
+)
+
+// quietMode, when set, drops banners, counts, and empty-result messages so only the
+// requested data reaches stdout.
+var quietMode bool
+
+// SetQuiet enables or disables quiet output.
+func SetQuiet(v bool) {
+	quietMode = v
+}
+
+// Quiet reports whether non-data output is suppressed.
+func Quiet() bool {
+	return quietMode
+}
+
+// chatter prints a non-data line (banner, count, hint) unless quiet mode is on.
+func chatter(out io.Writer, format string, args ...interface{}) {
+	if !quietMode {
+		fmt.Fprintf(out, format, args...)
+	}
+}
@@ -0,0 +1,55 @@
+package display
+
+import (
+	"github.com/shayne-snap/llmpole/internal/hardware"
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+// SummaryMode controls the "summary" object in pole/recommend JSON output.
+type SummaryMode int
+
+const (
+	SummaryOff     SummaryMode = iota // models only
+	SummaryInclude                    // models plus summary
+	SummaryOnly                       // summary without the models array
+)
+
+var summaryMode = SummaryOff
+
+// summaryAll, when set, is the result set the summary covers instead of the fits printed.
+var summaryAll []*pole.ModelFit
+
+// SetSummary sets whether pole/recommend JSON includes the aggregate summary. It also resets the
+// summary to cover the fits printed (see SetSummaryOf).
+func SetSummary(m SummaryMode) {
+	summaryMode = m
+	summaryAll = nil
+}
+
+// SetSummaryOf makes the summary cover all, the full result set before --limit trims it, rather
+// than only the fits printed.
+func SetSummaryOf(all []*pole.ModelFit) {
+	summaryAll = all
+}
+
+// summaryScope is the result set to summarize when fits (a full list, not a page) are printed.
+func summaryScope(fits []*pole.ModelFit) []*pole.ModelFit {
+	if summaryAll != nil {
+		return summaryAll
+	}
+	return fits
+}
+
+// fitsJSON builds the pole/recommend JSON document for fits; the summary, if any, covers all.
+func fitsJSON(specs *hardware.SystemSpecs, fits, all []*pole.ModelFit) map[string]interface{} {
+	doc := map[string]interface{}{
+		"system": systemJSON(specs),
+	}
+	if summaryMode != SummaryOnly {
+		doc["models"] = fitsToJSON(fits)
+	}
+	if summaryMode != SummaryOff {
+		doc["summary"] = pole.Summarize(all)
+	}
+	return doc
+}
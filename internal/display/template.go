@@ -0,0 +1,31 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+// ParseFitTemplate parses a user --output-template. The template sees the same map as one
+// entry of the JSON "models" array, e.g. {{.name}}, {{.estimated_tps}}, {{.score_components.quality}}.
+// Unknown keys are an error at execution instead of printing "<no value>".
+func ParseFitTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// FitsTemplate executes tmpl once per fit, writing one line per model.
+func FitsTemplate(out io.Writer, tmpl *template.Template, fits []*pole.ModelFit) error {
+	for _, f := range fits {
+		if err := tmpl.Execute(out, fitToJSON(f)); err != nil {
+			return fmt.Errorf("output template: %w", err)
+		}
+		fmt.Fprintln(out)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/shayne-snap/llmpole/internal/hardware"
+	"github.com/shayne-snap/llmpole/internal/pole"
+)
+
+// RecommendTiers prints recommend --tiers: each tier's models with a one-line rationale, or
+// in JSON the system and {"tiers": {"best": [...], "great": [...], "tight": [...]}}.
+func RecommendTiers(out io.Writer, specs *hardware.SystemSpecs, tiers []pole.Tier, useJSON bool) {
+	if useJSON {
+		byKey := make(map[string]interface{}, len(tiers))
+		for _, t := range tiers {
+			byKey[t.Key] = fitsToJSON(t.Fits)
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(map[string]interface{}{
+			"system": systemJSON(specs),
+			"tiers":  byKey,
+		})
+		return
+	}
+	if !quietMode && !showSystem {
+		System(out, specs, false)
+	}
+	for i, t := range tiers {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintln(out, t.Label)
+		if len(t.Fits) == 0 {
+			fmt.Fprintln(out, "  (none)")
+		}
+		for j, f := range t.Fits {
+			fmt.Fprintf(out, "  %d. %s (%s, %s) %s %s\n",
+				j+1, withVariants(f.Model.Name, f.VariantCount), f.Model.ParameterCount, f.BestQuant, glyph("—", "-"), tierRationale(f))
+		}
+	}
+}
+
+// tierRationale says in one line where a fit runs, how much memory it takes, and how fast it is.
+func tierRationale(f *pole.ModelFit) string {
+	where := map[pole.RunMode]string{
+		pole.RunModeGpu:        "fully on the GPU",
+		pole.RunModeMoeOffload: "inactive MoE experts offloaded to RAM",
+		pole.RunModeCpuOffload: "split across VRAM and RAM",
+		pole.RunModeCpuOnly:    "CPU-only in system RAM",
+	}[f.RunMode]
+	return fmt.Sprintf("%s: %s of %s GB (%s%%), %s",
+		where, Num(f.MemoryRequiredGB, 1), Num(f.MemoryAvailableGB, 1), Num(f.UtilizationPct, 0), formatTPSBand(f))
+}
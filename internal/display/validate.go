@@ -0,0 +1,35 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/shayne-snap/llmpole/internal/models"
+)
+
+// ValidationReport prints the issues found in a catalog of total entries (one line each), or
+// a JSON object with the entry count and issues.
+func ValidationReport(out io.Writer, source string, total int, issues []models.Issue, useJSON bool) {
+	if useJSON {
+		if issues == nil {
+			issues = []models.Issue{}
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(map[string]interface{}{
+			"source":  source,
+			"entries": total,
+			"issues":  issues,
+		})
+		return
+	}
+	for _, i := range issues {
+		fmt.Fprintln(out, i)
+	}
+	if len(issues) == 0 {
+		chatter(out, "%s: %d entries, no issues\n", source, total)
+		return
+	}
+	chatter(out, "%s: %d issue(s) in %d entries\n", source, len(issues), total)
+}
@@ -0,0 +1,212 @@
+package fetch
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shayne-snap/llmpole/internal/models"
+)
+
+// Conservative defaults for FetchModels, well under HuggingFace's anonymous API limits.
+const (
+	DefaultMaxInFlight = 2
+	DefaultRatePerSec  = 2.0
+)
+
+// Limiter paces HuggingFace requests shared across a batch: at most maxInFlight requests are open
+// at once, and new ones start no faster than perSecond. A nil *Limiter does not limit.
+type Limiter struct {
+	slots    chan struct{}
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time // earliest start of the next request
+
+	now   func() time.Time                     // time.Now; tests substitute a fixed clock
+	after func(time.Duration) <-chan time.Time // time.After; tests substitute an instant wait
+}
+
+// NewLimiter returns a Limiter; maxInFlight < 1 means 1, and perSecond <= 0 means no rate limit.
+func NewLimiter(maxInFlight int, perSecond float64) *Limiter {
+	if maxInFlight < 1 {
+		maxInFlight = 1
+	}
+	l := &Limiter{slots: make(chan struct{}, maxInFlight), now: time.Now, after: time.After}
+	if perSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	return l
+}
+
+// acquire waits for an in-flight slot and for the request's turn under the rate limit.
+func (l *Limiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	now := l.now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+	if wait := start.Sub(now); wait > 0 {
+		select {
+		case <-l.after(wait):
+		case <-ctx.Done():
+			<-l.slots
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Retries of a request HuggingFace answers with 429 Too Many Requests.
+const (
+	maxRetries   = 3
+	retryBackoff = time.Second      // first wait without a Retry-After; doubled on each retry
+	maxRetryWait = 30 * time.Second // longer Retry-After waits are not worth it: the 429 is returned
+)
+
+// do sends req once the limiter allows it, retrying up to maxRetries times on 429 after the
+// Retry-After the server asks for, else after an exponential backoff. The in-flight slot is held
+// until the response body is closed, so callers must close it before issuing a follow-up request.
+func (l *Limiter) do(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := l.send(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempt == maxRetries {
+			return resp, err
+		}
+		wait, ok := retryAfter(resp.Header.Get("Retry-After"), l.clock())
+		if !ok {
+			wait = retryBackoff << attempt
+		}
+		if wait > maxRetryWait {
+			return resp, nil
+		}
+		resp.Body.Close()
+		select {
+		case <-l.wait(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// send makes one attempt at req, taking an in-flight slot that the response body's Close releases.
+func (l *Limiter) send(req *http.Request) (*http.Response, error) {
+	if l == nil {
+		return http.DefaultClient.Do(req)
+	}
+	if err := l.acquire(req.Context()); err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		<-l.slots
+		return nil, err
+	}
+	resp.Body = &slotBody{ReadCloser: resp.Body, release: func() { <-l.slots }}
+	return resp, nil
+}
+
+// slotBody is a response body that frees its Limiter slot on the first Close.
+type slotBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *slotBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// clock is l.now, or time.Now for a nil Limiter.
+func (l *Limiter) clock() time.Time {
+	if l == nil {
+		return time.Now()
+	}
+	return l.now()
+}
+
+// wait is l.after, or time.After for a nil Limiter.
+func (l *Limiter) wait(d time.Duration) <-chan time.Time {
+	if l == nil {
+		return time.After(d)
+	}
+	return l.after(d)
+}
+
+// retryAfter parses a Retry-After header, in seconds or as an HTTP date relative to now, and
+// reports false when it is missing or malformed.
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(header); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// BatchOptions tunes FetchModels.
+type BatchOptions struct {
+	Options
+	// MaxInFlight caps concurrent requests to HuggingFace (default DefaultMaxInFlight).
+	MaxInFlight int
+	// RatePerSec caps how many requests start per second (default DefaultRatePerSec; < 0 means unlimited).
+	RatePerSec float64
+}
+
+// BatchResult is the outcome of fetching one repo in FetchModels.
+type BatchResult struct {
+	RepoID string
+	Model  *models.LlmModel
+	Err    error
+}
+
+// FetchModels fetches several repos concurrently, pacing all their requests through one Limiter
+// so a large batch does not trip HuggingFace's rate limits. Results are in repoIDs order; a
+// failed repo has Err set and does not stop the others.
+func FetchModels(repoIDs []string, opts BatchOptions) []BatchResult {
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = DefaultMaxInFlight
+	}
+	if opts.RatePerSec == 0 {
+		opts.RatePerSec = DefaultRatePerSec
+	}
+	if opts.Limiter == nil {
+		opts.Limiter = NewLimiter(opts.MaxInFlight, opts.RatePerSec)
+	}
+	results := make([]BatchResult, len(repoIDs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(opts.MaxInFlight, len(repoIDs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				m, err := FetchModelWithOptions(repoIDs[i], opts.Options)
+				results[i] = BatchResult{RepoID: repoIDs[i], Model: m, Err: err}
+			}
+		}()
+	}
+	for i := range repoIDs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
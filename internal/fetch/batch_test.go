@@ -0,0 +1,195 @@
+package fetch
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFetchModels_BoundedAndRateLimited(t *testing.T) {
+	const (
+		maxInFlight = 2
+		perSec      = 20.0
+	)
+	var (
+		mu       sync.Mutex
+		inFlight int
+		peak     int
+		requests int
+		waits    []time.Duration
+	)
+	// A fixed clock and instant waits make the pacing deterministic: every request is scheduled
+	// at once, so the waits the limiter asks for are its schedule, one interval apart.
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewLimiter(maxInFlight, perSec)
+	limiter.now = func() time.Time { return t0 }
+	limiter.after = func(d time.Duration) <-chan time.Time {
+		mu.Lock()
+		waits = append(waits, d)
+		mu.Unlock()
+		ch := make(chan time.Time, 1)
+		ch <- t0.Add(d)
+		return ch
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		requests++
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		time.Sleep(30 * time.Millisecond)
+		if r.URL.Path == "/api/models" {
+			w.WriteHeader(http.StatusNotFound) // GGUF variant listing
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"safetensors":{"total":7000000000},"config":{"model_type":"llama","max_position_embeddings":4096}}`))
+	}))
+	defer server.Close()
+	apiBaseForTest = server.URL
+	defer func() { apiBaseForTest = "" }()
+
+	var repos []string
+	for i := range 6 {
+		repos = append(repos, fmt.Sprintf("org/repo-%d", i))
+	}
+	results := FetchModels(repos, BatchOptions{Options: Options{Limiter: limiter}, MaxInFlight: maxInFlight})
+
+	for i, r := range results {
+		if r.RepoID != repos[i] || r.Err != nil || r.Model == nil {
+			t.Errorf("result %d = %+v, want a model for %s", i, r, repos[i])
+		}
+	}
+	if peak > maxInFlight {
+		t.Errorf("peak concurrent requests = %d, want <= %d", peak, maxInFlight)
+	}
+	slices.Sort(waits)
+	interval := time.Duration(float64(time.Second) / perSec)
+	if len(waits) != requests-1 {
+		t.Fatalf("limiter waited %d times for %d requests, want %d", len(waits), requests, requests-1)
+	}
+	for i, w := range waits {
+		if want := time.Duration(i+1) * interval; w != want {
+			t.Errorf("request %d scheduled %v after the first, want %v", i+1, w, want)
+		}
+	}
+}
+
+func TestLimiter_RetriesTooManyRequests(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		retryAfter string
+		failures   int
+		wantStatus int
+		wantWaits  []time.Duration
+	}{
+		{"honors Retry-After seconds", "2", 2, http.StatusOK, []time.Duration{2 * time.Second, 2 * time.Second}},
+		{"backs off without Retry-After", "", 2, http.StatusOK, []time.Duration{time.Second, 2 * time.Second}},
+		{"gives up after maxRetries", "1", maxRetries + 1, http.StatusTooManyRequests, []time.Duration{time.Second, time.Second, time.Second}},
+		{"returns a wait too long to honor", "120", 1, http.StatusTooManyRequests, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls <= tt.failures {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.Write([]byte("ok"))
+			}))
+			defer server.Close()
+			var waits []time.Duration
+			limiter := NewLimiter(1, 0)
+			limiter.after = func(d time.Duration) <-chan time.Time {
+				waits = append(waits, d)
+				ch := make(chan time.Time, 1)
+				ch <- time.Now()
+				return ch
+			}
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			resp, err := limiter.do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus || !slices.Equal(waits, tt.wantWaits) {
+				t.Errorf("status %d after waits %v, want %d after %v", resp.StatusCode, waits, tt.wantStatus, tt.wantWaits)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	for header, want := range map[string]time.Duration{
+		"5":                             5 * time.Second,
+		"Fri, 16 Oct 2026 12:00:07 GMT": 7 * time.Second,
+		"Fri, 16 Oct 2026 11:00:00 GMT": 0,
+	} {
+		if got, ok := retryAfter(header, now); !ok || got != want {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v", header, got, ok, want)
+		}
+	}
+	for _, bad := range []string{"", "soon", "-3"} {
+		if _, ok := retryAfter(bad, now); ok {
+			t.Errorf("retryAfter(%q) parsed, want it ignored", bad)
+		}
+	}
+}
+
+func TestLimiter_HoldsSlotUntilBodyClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	limiter := NewLimiter(1, 0)
+	first, err := limiter.do(mustGet(t, server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	second := mustGet(t, server.URL)
+	go func() {
+		defer close(done)
+		if resp, err := limiter.do(second); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	select {
+	case <-done:
+		t.Fatal("second request ran while the first response body was still open")
+	case <-time.After(50 * time.Millisecond):
+	}
+	first.Body.Close()
+	first.Body.Close() // a second Close must not free another slot
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("second request still blocked after the first body was closed")
+	}
+	if n := len(limiter.slots); n != 0 {
+		t.Errorf("%d slots still held after every body was closed", n)
+	}
+}
+
+func mustGet(t *testing.T, url string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
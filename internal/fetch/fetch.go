@@ -8,6 +8,9 @@ import (
 	"io"
 	"math"
 	"net/http"
+	neturl "net/url"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -16,49 +19,66 @@ import (
 )
 
 const (
-	hfAPI        = "https://huggingface.co/api/models"
-	timeoutSec   = 30
-	runtimeOver  = 1.2
-	quantBPPQ4   = 0.5
-	defaultCtx   = 4096
+	hfAPI       = "https://huggingface.co/api/models"
+	timeoutSec  = 30
+	runtimeOver = 1.2
+	quantBPPQ4  = 0.5
+	defaultCtx  = 4096
 )
 
 // hfAPIResponse is the minimal shape of GET /api/models/{repo_id} we need.
 type hfAPIResponse struct {
-	Config       map[string]interface{} `json:"config"`
-	PipelineTag  string                 `json:"pipeline_tag"`
-	Safetensors  *struct {
-		Total      *uint64            `json:"total"`
-		Parameters map[string]uint64  `json:"parameters"`
+	Config      map[string]interface{} `json:"config"`
+	PipelineTag string                 `json:"pipeline_tag"`
+	Tags        []string               `json:"tags"`
+	CardData    *struct {
+		License interface{} `json:"license"` // a string, or a list for multi-licensed repos
+	} `json:"cardData"`
+	Safetensors *struct {
+		Total      *uint64           `json:"total"`
+		Parameters map[string]uint64 `json:"parameters"`
 	} `json:"safetensors"`
+	// GGUF is HF's summary of a GGUF-only repo's header metadata, when it has one.
+	GGUF *struct {
+		Total         uint64 `json:"total"`
+		Architecture  string `json:"architecture"`
+		ContextLength int    `json:"context_length"`
+	} `json:"gguf"`
+	Siblings []hfSibling `json:"siblings"` // file sizes need ?blobs=true
+}
+
+// hfSibling is one file of a repo listing.
+type hfSibling struct {
+	RFilename string `json:"rfilename"`
+	Size      uint64 `json:"size"`
 }
 
 // configJSON is the shape of config.json for context length.
 type configJSON map[string]interface{}
 
 var moeConfigs = map[string]struct{ NumExperts, ActiveExperts int }{
-	"mixtral":       {8, 2},
-	"deepseek_v2":   {64, 6},
-	"deepseek_v3":   {256, 8},
-	"qwen3_moe":     {128, 8},
-	"llama4":        {16, 1},
-	"grok":          {8, 2},
+	"mixtral":     {8, 2},
+	"deepseek_v2": {64, 6},
+	"deepseek_v3": {256, 8},
+	"qwen3_moe":   {128, 8},
+	"llama4":      {16, 1},
+	"grok":        {8, 2},
 }
 
 var moeActiveParams = map[string]uint64{
-	"mistralai/Mixtral-8x7B-Instruct-v0.1":                    12_900_000_000,
-	"mistralai/Mixtral-8x22B-Instruct-v0.1":                   39_100_000_000,
-	"NousResearch/Nous-Hermes-2-Mixtral-8x7B-DPO":            12_900_000_000,
-	"deepseek-ai/DeepSeek-Coder-V2-Lite-Instruct":            2_400_000_000,
-	"deepseek-ai/DeepSeek-V3":                                37_000_000_000,
-	"deepseek-ai/DeepSeek-R1":                                37_000_000_000,
-	"Qwen/Qwen3-30B-A3B":                                      3_300_000_000,
-	"Qwen/Qwen3-235B-A22B":                                    22_000_000_000,
-	"Qwen/Qwen3-Coder-480B-A35B-Instruct":                     35_000_000_000,
-	"meta-llama/Llama-4-Scout-17B-16E-Instruct":              17_000_000_000,
-	"meta-llama/Llama-4-Maverick-17B-128E-Instruct":          17_000_000_000,
-	"xai-org/grok-1":                                          86_000_000_000,
-	"moonshotai/Kimi-K2-Instruct":                             32_000_000_000,
+	"mistralai/Mixtral-8x7B-Instruct-v0.1":          12_900_000_000,
+	"mistralai/Mixtral-8x22B-Instruct-v0.1":         39_100_000_000,
+	"NousResearch/Nous-Hermes-2-Mixtral-8x7B-DPO":   12_900_000_000,
+	"deepseek-ai/DeepSeek-Coder-V2-Lite-Instruct":   2_400_000_000,
+	"deepseek-ai/DeepSeek-V3":                       37_000_000_000,
+	"deepseek-ai/DeepSeek-R1":                       37_000_000_000,
+	"Qwen/Qwen3-30B-A3B":                            3_300_000_000,
+	"Qwen/Qwen3-235B-A22B":                          22_000_000_000,
+	"Qwen/Qwen3-Coder-480B-A35B-Instruct":           35_000_000_000,
+	"meta-llama/Llama-4-Scout-17B-16E-Instruct":     17_000_000_000,
+	"meta-llama/Llama-4-Maverick-17B-128E-Instruct": 17_000_000_000,
+	"xai-org/grok-1":                                86_000_000_000,
+	"moonshotai/Kimi-K2-Instruct":                   32_000_000_000,
 }
 
 var providerMap = map[string]string{
@@ -86,6 +106,11 @@ func apiBase() string {
 	return "https://huggingface.co"
 }
 
+// ModelURL returns the HuggingFace API URL FetchModel reads repoID's metadata from.
+func ModelURL(repoID string) string {
+	return apiBase() + "/api/models/" + repoID
+}
+
 // FetchModelList fetches the raw model list JSON from url (e.g. default list URL). Caller should validate and write to cache.
 func FetchModelList(ctx context.Context, url string) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -108,18 +133,32 @@ func FetchModelList(ctx context.Context, url string) ([]byte, error) {
 	return body, nil
 }
 
+// Options tunes FetchModel.
+type Options struct {
+	// Thorough always fetches config.json, even when the API response already has context length
+	// and architecture, and lists the model's GGUF quantizations on HF (QuantAvailability).
+	Thorough bool
+	// Limiter, when set, paces every HuggingFace request the fetch makes (see FetchModels).
+	Limiter *Limiter
+}
+
 // FetchModel fetches one model by repo_id from HuggingFace and returns an LlmModel (or error).
 func FetchModel(repoID string) (*models.LlmModel, error) {
+	return FetchModelWithOptions(repoID, Options{})
+}
+
+// FetchModelWithOptions is FetchModel with tunable options.
+func FetchModelWithOptions(repoID string, opts Options) (*models.LlmModel, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSec)*time.Second)
 	defer cancel()
 
-	url := apiBase() + "/api/models/" + repoID
+	url := ModelURL(repoID) + "?blobs=true"
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("User-Agent", userAgent)
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := opts.Limiter.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("network: %w", err)
 	}
@@ -131,6 +170,7 @@ func FetchModel(repoID string) (*models.LlmModel, error) {
 	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
 		return nil, fmt.Errorf("invalid JSON: %w", err)
 	}
+	resp.Body.Close() // frees the Limiter slot before the follow-up requests below
 
 	totalParams := uint64(0)
 	if info.Safetensors != nil && info.Safetensors.Total != nil {
@@ -142,6 +182,12 @@ func FetchModel(repoID string) (*models.LlmModel, error) {
 			}
 		}
 	}
+	quant := "Q4_K_M"
+	ggufFiles := ggufFileQuants(info.Siblings)
+	if totalParams == 0 && len(ggufFiles) > 0 {
+		// GGUF-only repo (bartowski, TheBloke, ...): no safetensors, so size it from the GGUF files.
+		totalParams, quant = ggufParams(repoID, &info, ggufFiles)
+	}
 	if totalParams == 0 {
 		return nil, fmt.Errorf("no parameter count in API response (gated or private repo?)")
 	}
@@ -152,40 +198,130 @@ func FetchModel(repoID string) (*models.LlmModel, error) {
 			arch = v
 		}
 	}
-	fullConfig := fetchConfigJSON(repoID)
+	if arch == "unknown" && info.GGUF != nil && info.GGUF.Architecture != "" {
+		arch = info.GGUF.Architecture
+	}
+	var fullConfig configJSON
+	if opts.Thorough || !configSufficient(info.Config) {
+		fullConfig = fetchConfigJSON(repoID, opts)
+	} else {
+		fullConfig = info.Config
+	}
 	ctxLen := inferContextLength(fullConfig)
 	if ctxLen == 0 && info.Config != nil {
 		ctxLen = inferContextLength(info.Config)
 	}
+	if ctxLen == 0 && info.GGUF != nil && info.GGUF.ContextLength > 0 {
+		ctxLen = info.GGUF.ContextLength
+	}
 	if ctxLen == 0 {
 		ctxLen = defaultCtx
 	}
 
 	minRAM, recRAM := estimateRAM(totalParams)
 	minVRAM := estimateVRAM(totalParams)
-	quant := "Q4_K_M"
 	isMoE, numExp, activeExp, activeParams := detectMoE(repoID, fullConfig, arch, totalParams)
+	var quantAvail *uint32
+	var availQuants []string
+	if opts.Thorough {
+		quantAvail, availQuants = fetchGGUFVariants(repoID, opts)
+	}
+	if len(ggufFiles) > 0 {
+		availQuants = mergeQuants(availQuants, ggufQuantList(ggufFiles))
+	}
+	modelArch := ""
+	if arch != "unknown" {
+		modelArch = arch
+	}
 
 	m := &models.LlmModel{
-		Name:             repoID,
-		Provider:         extractProvider(repoID),
-		ParameterCount:   formatParamCount(totalParams),
-		ParametersRaw:    &totalParams,
-		MinRAMGB:         minRAM,
-		RecommendedRAMGB: recRAM,
-		MinVRAMGB:        &minVRAM,
-		Quantization:     quant,
-		ContextLength:    uint32(ctxLen),
-		UseCase:          inferUseCase(repoID, info.PipelineTag, info.Config),
-		IsMoE:            isMoE,
-		NumExperts:       numExp,
-		ActiveExperts:    activeExp,
-		ActiveParameters: activeParams,
+		Name:              repoID,
+		Provider:          extractProvider(repoID),
+		ParameterCount:    formatParamCount(totalParams),
+		ParametersRaw:     &totalParams,
+		MinRAMGB:          minRAM,
+		RecommendedRAMGB:  recRAM,
+		MinVRAMGB:         &minVRAM,
+		Quantization:      quant,
+		ContextLength:     uint32(ctxLen),
+		UseCase:           inferUseCase(repoID, info.PipelineTag, info.Config),
+		IsMoE:             isMoE,
+		NumExperts:        numExp,
+		ActiveExperts:     activeExp,
+		ActiveParameters:  activeParams,
+		QuantAvailability: quantAvail,
+		Architecture:      modelArch,
+		AvailableQuants:   availQuants,
+		License:           modelLicense(&info),
 	}
 	return m, nil
 }
 
-func fetchConfigJSON(repoID string) configJSON {
+// modelLicense returns the license from the model card, falling back to a "license:" tag.
+// Multiple card licenses are joined with "/".
+func modelLicense(info *hfAPIResponse) string {
+	if info.CardData != nil {
+		switch l := info.CardData.License.(type) {
+		case string:
+			if l != "" {
+				return l
+			}
+		case []interface{}:
+			var names []string
+			for _, v := range l {
+				if s, ok := v.(string); ok && s != "" {
+					names = append(names, s)
+				}
+			}
+			if len(names) > 0 {
+				return strings.Join(names, "/")
+			}
+		}
+	}
+	for _, tag := range info.Tags {
+		if l, ok := strings.CutPrefix(tag, "license:"); ok && l != "" {
+			return l
+		}
+	}
+	return ""
+}
+
+// configSufficient reports whether the API response's config already carries what config.json
+// would add: a context length and the model_type used for MoE detection. For a MoE model_type
+// it must also carry the expert counts, which the API config often leaves out; the model_type
+// table is only a fallback for when config.json lacks them too.
+func configSufficient(c configJSON) bool {
+	if inferContextLength(c) == 0 {
+		return false
+	}
+	arch, _ := c["model_type"].(string)
+	if arch == "" {
+		return false
+	}
+	if _, ok := moeConfigs[arch]; ok || strings.Contains(arch, "moe") {
+		return expertCount(c) > 0 && configInt(c, "num_experts_per_tok") > 0
+	}
+	return true
+}
+
+// expertCount is the config's expert count: num_local_experts (Mixtral and most others), else
+// num_experts (Qwen MoE). It is 0 when neither is set.
+func expertCount(c configJSON) int {
+	if n := configInt(c, "num_local_experts"); n > 0 {
+		return n
+	}
+	return configInt(c, "num_experts")
+}
+
+// configInt returns the positive integer at key, or 0.
+func configInt(c configJSON, key string) int {
+	if n, ok := toInt(c[key]); ok && n > 0 {
+		return n
+	}
+	return 0
+}
+
+func fetchConfigJSON(repoID string, opts Options) configJSON {
 	url := apiBase() + "/" + repoID + "/resolve/main/config.json"
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
@@ -194,7 +330,7 @@ func fetchConfigJSON(repoID string) configJSON {
 		return nil
 	}
 	req.Header.Set("User-Agent", userAgent)
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := opts.Limiter.do(req)
 	if err != nil {
 		return nil
 	}
@@ -209,6 +345,159 @@ func fetchConfigJSON(repoID string) configJSON {
 	return c
 }
 
+// fetchGGUFVariants returns how many GGUF repos on HF are quantizations of the model, or nil if
+// the listing failed, and the quantizations found in those repos' .gguf file names (nil when none
+// are listed). A repo counts only when its card names repoID as the base model it quantizes (the
+// "base_model:quantized:<repoID>" tag); repos that merely share a name are not the model's.
+func fetchGGUFVariants(repoID string, opts Options) (*uint32, []string) {
+	tag := "base_model:quantized:" + repoID
+	query := neturl.Values{"filter": {"gguf", tag}, "full": {"true"}, "limit": {"100"}}
+	url := apiBase() + "/api/models?" + query.Encode()
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := opts.Limiter.do(req)
+	if err != nil {
+		return nil, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil
+	}
+	var variants []struct {
+		ID       string   `json:"id"`
+		Tags     []string `json:"tags"`
+		Siblings []struct {
+			RFilename string `json:"rfilename"`
+		} `json:"siblings"`
+	}
+	if json.NewDecoder(resp.Body).Decode(&variants) != nil {
+		return nil, nil
+	}
+	var files []string
+	var n uint32
+	for _, v := range variants {
+		if strings.EqualFold(v.ID, repoID) || !slices.ContainsFunc(v.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			continue
+		}
+		n++
+		for _, f := range v.Siblings {
+			files = append(files, f.RFilename)
+		}
+	}
+	return &n, ggufQuants(files)
+}
+
+// ggufQuants returns the QuantHierarchy entries named by any .gguf file, best first.
+func ggufQuants(files []string) []string {
+	named := map[string]bool{}
+	for _, f := range files {
+		named[ggufFileQuant(f)] = true
+	}
+	var out []string
+	for _, q := range models.QuantHierarchy {
+		if named[q] {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// ggufFileQuant returns the QuantHierarchy entry a .gguf file name carries, or "". The quant must
+// stand as its own token, delimited by "-", ".", "_", or the ends of the name, so BF16 is not
+// F16; of several that do, the longest wins, so a Q4_K_M file is not also Q4_K.
+func ggufFileQuant(name string) string {
+	upper := strings.ToUpper(name)
+	if !strings.HasSuffix(upper, ".GGUF") {
+		return ""
+	}
+	delim := func(i int) bool { return i < 0 || i >= len(upper) || strings.IndexByte("-._", upper[i]) >= 0 }
+	best := ""
+	for _, q := range models.QuantHierarchy {
+		if len(q) <= len(best) {
+			continue
+		}
+		for i := 0; i+len(q) <= len(upper); i++ {
+			if upper[i:i+len(q)] == q && delim(i-1) && delim(i+len(q)) {
+				best = q
+				break
+			}
+		}
+	}
+	return best
+}
+
+// ggufFileQuants maps each quantization named by a repo's .gguf files to their total size in
+// bytes (split files "-00001-of-00003.gguf" add up). Files without a known quant are skipped.
+func ggufFileQuants(siblings []hfSibling) map[string]uint64 {
+	out := map[string]uint64{}
+	for _, f := range siblings {
+		if q := ggufFileQuant(f.RFilename); q != "" {
+			out[q] += f.Size
+		}
+	}
+	return out
+}
+
+// ggufQuantList returns the quants of files (see ggufFileQuants), best first.
+func ggufQuantList(files map[string]uint64) []string {
+	var out []string
+	for _, q := range models.QuantHierarchy {
+		if _, ok := files[q]; ok {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// mergeQuants returns the QuantHierarchy entries in a or b, best first.
+func mergeQuants(a, b []string) []string {
+	var out []string
+	for _, q := range models.QuantHierarchy {
+		if slices.Contains(a, q) || slices.Contains(b, q) {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// paramSizeRe matches a size label such as "8B", "0.5B", "8x7B", or "135M" between separators.
+var paramSizeRe = regexp.MustCompile(`(?i)(?:^|[-_./])(\d+x)?(\d+(?:\.\d+)?[bm])(?:[-_.]|$)`)
+
+// ggufParams sizes a GGUF-only repo and picks the quant it is listed at: Q4_K_M when published,
+// otherwise the best quant it has. The parameter count comes from HF's GGUF metadata, else the
+// size label in the repo or file names, else the chosen quant's file size divided by its bits
+// per weight. It returns 0 when none of those is available.
+func ggufParams(repoID string, info *hfAPIResponse, files map[string]uint64) (uint64, string) {
+	quant := "Q4_K_M"
+	if _, ok := files[quant]; !ok {
+		quant = ggufQuantList(files)[0]
+	}
+	if info.GGUF != nil && info.GGUF.Total > 0 {
+		return info.GGUF.Total, quant
+	}
+	names := []string{repoID[strings.LastIndex(repoID, "/")+1:]}
+	for _, f := range info.Siblings {
+		names = append(names, f.RFilename)
+	}
+	for _, name := range names {
+		if m := paramSizeRe.FindStringSubmatch(name); m != nil {
+			if b, ok := models.ParseParamCount(m[1] + m[2]); ok && b > 0 {
+				return uint64(math.Round(b * 1e9)), quant
+			}
+		}
+	}
+	if size := files[quant]; size > 0 {
+		params := float64(size) / (models.QuantBPP(quant) * 1024 * 1024 * 1024) * 1e9
+		return uint64(math.Round(params/1e8) * 1e8), quant // file sizes only pin it to ~0.1B
+	}
+	return 0, quant
+}
+
 func formatParamCount(n uint64) string {
 	if n >= 1_000_000_000 {
 		val := float64(n) / 1e9
@@ -306,19 +595,7 @@ func extractProvider(repoID string) string {
 }
 
 func detectMoE(repoID string, fullConfig configJSON, arch string, totalParams uint64) (isMoE bool, numExperts, activeExperts *uint32, activeParams *uint64) {
-	var numExp, activeExp int
-	if fullConfig != nil {
-		if v, ok := fullConfig["num_local_experts"]; ok {
-			if n, ok := toInt(v); ok && n > 0 {
-				numExp = n
-			}
-		}
-		if v, ok := fullConfig["num_experts_per_tok"]; ok {
-			if n, ok := toInt(v); ok && n > 0 {
-				activeExp = n
-			}
-		}
-	}
+	numExp, activeExp := expertCount(fullConfig), configInt(fullConfig, "num_experts_per_tok")
 	if numExp == 0 || activeExp == 0 {
 		if c, ok := moeConfigs[arch]; ok {
 			numExp = c.NumExperts
@@ -6,8 +6,11 @@ import (
 	"math"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/shayne-snap/llmpole/internal/models"
 )
 
 func TestFormatParamCount(t *testing.T) {
@@ -272,6 +275,77 @@ func TestFetchModel_NoParams(t *testing.T) {
 	}
 }
 
+func TestFetchModel_GGUFOnly(t *testing.T) {
+	const gib = 1 << 30
+	tests := []struct {
+		repo       string
+		siblings   []map[string]interface{}
+		wantParams string
+		wantQuant  string
+	}{
+		{
+			repo: "bartowski/Meta-Llama-3.1-8B-Instruct-GGUF",
+			siblings: []map[string]interface{}{
+				{"rfilename": "README.md", "size": 9000},
+				{"rfilename": "Meta-Llama-3.1-8B-Instruct-Q8_0.gguf", "size": 8 * gib},
+				{"rfilename": "Meta-Llama-3.1-8B-Instruct-Q4_K_M.gguf", "size": 5 * gib},
+			},
+			wantParams: "8B",
+			wantQuant:  "Q4_K_M",
+		},
+		{
+			// No size label anywhere: sized from the split Q8_0 file and Q8_0's bytes per weight.
+			repo: "someone/mystery-GGUF",
+			siblings: []map[string]interface{}{
+				{"rfilename": "mystery-Q8_0-00001-of-00002.gguf", "size": int64(7 * models.QuantBPP("Q8_0") * gib)},
+				{"rfilename": "mystery-Q8_0-00002-of-00002.gguf", "size": int64(7 * models.QuantBPP("Q8_0") * gib)},
+			},
+			wantParams: "14B",
+			wantQuant:  "Q8_0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.repo, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]interface{}{
+				"pipeline_tag": "text-generation",
+				"gguf":         map[string]interface{}{"architecture": "llama", "context_length": 131072},
+				"siblings":     tt.siblings,
+			})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/models/"+tt.repo {
+					w.Header().Set("Content-Type", "application/json")
+					w.Write(body)
+					return
+				}
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			defer server.Close()
+			apiBaseForTest = server.URL
+			defer func() { apiBaseForTest = "" }()
+
+			m, err := FetchModel(tt.repo)
+			if err != nil {
+				t.Fatalf("FetchModel: %v", err)
+			}
+			if m.ParameterCount != tt.wantParams {
+				t.Errorf("ParameterCount = %q, want %q", m.ParameterCount, tt.wantParams)
+			}
+			if m.Quantization != tt.wantQuant {
+				t.Errorf("Quantization = %q, want %q", m.Quantization, tt.wantQuant)
+			}
+			if !slices.Contains(m.AvailableQuants, tt.wantQuant) {
+				t.Errorf("AvailableQuants = %v, want it to include %s", m.AvailableQuants, tt.wantQuant)
+			}
+			if m.ContextLength != 131072 || m.Architecture != "llama" {
+				t.Errorf("ContextLength = %d, Architecture = %q; want GGUF metadata", m.ContextLength, m.Architecture)
+			}
+			if m.MinRAMGB <= 0 {
+				t.Errorf("MinRAMGB = %v, want a usable estimate", m.MinRAMGB)
+			}
+		})
+	}
+}
+
 func TestFetchModelList(t *testing.T) {
 	validBody := []byte(`[{"name":"org/model","provider":"Org","parameter_count":"7B"}]`)
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -313,3 +387,198 @@ func TestFetchModelList_Non200(t *testing.T) {
 		t.Fatal("expected error for 404")
 	}
 }
+
+func TestFetchGGUFVariants(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filters := r.URL.Query()["filter"]
+		if r.URL.Path != "/api/models" || !slices.Contains(filters, "gguf") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !slices.Contains(filters, "base_model:quantized:org/repo") {
+			t.Errorf("filters = %q, want the exact base_model:quantized:org/repo", filters)
+		}
+		if r.URL.Query().Get("search") != "" {
+			t.Errorf("search = %q, want no fuzzy name search", r.URL.Query().Get("search"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"id":"a/repo-GGUF","tags":["gguf","base_model:quantized:org/repo"],"siblings":[{"rfilename":"repo-Q4_K_M.gguf"},{"rfilename":"README.md"}]},
+			{"id":"b/repo-GGUF","tags":["gguf","base_model:quantized:org/repo"],"siblings":[{"rfilename":"repo.Q8_0.gguf"}]},
+			{"id":"c/repo-extended-GGUF","tags":["gguf","base_model:quantized:c/repo-extended"],"siblings":[{"rfilename":"repo-extended-Q2_K.gguf"}]},
+			{"id":"org/repo","tags":["gguf","base_model:quantized:org/repo"],"siblings":[{"rfilename":"repo-Q3_K_M.gguf"}]}
+		]`))
+	}))
+	defer server.Close()
+	apiBaseForTest = server.URL
+	defer func() { apiBaseForTest = "" }()
+
+	got, quants := fetchGGUFVariants("org/repo", Options{})
+	if got == nil || *got != 2 {
+		t.Errorf("fetchGGUFVariants count = %v, want 2 (the unrelated repo and the model itself excluded)", got)
+	}
+	if strings.Join(quants, ",") != "Q8_0,Q4_K_M" {
+		t.Errorf("fetchGGUFVariants quants = %v, want [Q8_0 Q4_K_M]", quants)
+	}
+}
+
+func TestGGUFFileQuant_TokenBoundaries(t *testing.T) {
+	for _, tt := range []struct{ file, want string }{
+		{"model-Q4_K_M.gguf", "Q4_K_M"},
+		{"model.q8_0.gguf", "Q8_0"},
+		{"Model-IQ4_XS-imat.gguf", "IQ4_XS"},
+		{"model-IQ2_XXS.gguf", "IQ2_XXS"},
+		{"model-Q5_K_S-00001-of-00002.gguf", "Q5_K_S"},
+		{"model-BF16.gguf", ""},
+		{"model-XQ4_K_M.gguf", ""},
+		{"model-Q8_0.safetensors", ""},
+	} {
+		if got := ggufFileQuant(tt.file); got != tt.want {
+			t.Errorf("ggufFileQuant(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+	if got := ggufQuants([]string{"model-BF16.gguf", "model-Q4_K_M.gguf"}); !slices.Equal(got, []string{"Q4_K_M"}) {
+		t.Errorf("ggufQuants = %v, want only Q4_K_M", got)
+	}
+}
+
+func TestFetchModel_AvailableQuantsIgnoreUnrelatedRepos(t *testing.T) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"safetensors": map[string]interface{}{"total": float64(7_000_000_000)},
+		"config":      map[string]interface{}{"model_type": "llama", "max_position_embeddings": float64(8192)},
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/models/org/repo":
+			w.Write(body)
+		case "/api/models":
+			w.Write([]byte(`[
+				{"id":"a/repo-GGUF","tags":["gguf","base_model:quantized:org/repo"],"siblings":[{"rfilename":"repo-Q4_K_M.gguf"},{"rfilename":"repo-BF16.gguf"}]},
+				{"id":"c/repo-extended-GGUF","tags":["gguf","base_model:quantized:c/repo-extended"],"siblings":[{"rfilename":"repo-extended-Q8_0.gguf"}]}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	apiBaseForTest = server.URL
+	defer func() { apiBaseForTest = "" }()
+	m, err := FetchModelWithOptions("org/repo", Options{Thorough: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(m.AvailableQuants, []string{"Q4_K_M"}) {
+		t.Errorf("AvailableQuants = %v, want [Q4_K_M] from the repo's own quantization only", m.AvailableQuants)
+	}
+}
+
+func TestFetchModel_GGUFVariantsOnlyWhenThorough(t *testing.T) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"safetensors": map[string]interface{}{"total": float64(7_000_000_000)},
+		"config":      map[string]interface{}{"model_type": "llama", "max_position_embeddings": float64(8192)},
+	})
+	for _, thorough := range []bool{false, true} {
+		listed := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/models/org/repo":
+				w.Write(body)
+			case "/api/models":
+				listed = true
+				w.Write([]byte(`[]`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		apiBaseForTest = server.URL
+		m, err := FetchModelWithOptions("org/repo", Options{Thorough: thorough})
+		if err != nil {
+			t.Fatalf("thorough=%v: %v", thorough, err)
+		}
+		if listed != thorough || (m.QuantAvailability != nil) != thorough {
+			t.Errorf("thorough=%v: GGUF listing requested = %v, QuantAvailability = %v", thorough, listed, m.QuantAvailability)
+		}
+		server.Close()
+	}
+	apiBaseForTest = ""
+}
+
+func TestFetchModel_ConfigFastPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     map[string]interface{}
+		thorough   bool
+		wantConfig bool
+	}{
+		{"sufficient", map[string]interface{}{"model_type": "llama", "max_position_embeddings": float64(8192)}, false, false},
+		{"sufficient thorough", map[string]interface{}{"model_type": "llama", "max_position_embeddings": float64(8192)}, true, true},
+		{"no context length", map[string]interface{}{"model_type": "llama"}, false, true},
+		{"no model_type", map[string]interface{}{"max_position_embeddings": float64(8192)}, false, true},
+		{"moe without expert counts", map[string]interface{}{"model_type": "mixtral", "max_position_embeddings": float64(32768)}, false, true},
+		{"moe with expert counts", map[string]interface{}{"model_type": "mixtral", "max_position_embeddings": float64(32768), "num_local_experts": float64(8), "num_experts_per_tok": float64(2)}, false, false},
+		{"unlisted moe with num_experts", map[string]interface{}{"model_type": "olmoe", "max_position_embeddings": float64(4096), "num_experts": float64(64), "num_experts_per_tok": float64(8)}, false, false},
+		{"unlisted moe without expert counts", map[string]interface{}{"model_type": "olmoe", "max_position_embeddings": float64(4096)}, false, true},
+	}
+	for _, tt := range tests {
+		body, _ := json.Marshal(map[string]interface{}{
+			"safetensors": map[string]interface{}{"total": float64(7_000_000_000)},
+			"config":      tt.config,
+		})
+		configHits := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/models/org/repo":
+				w.Write(body)
+			case "/org/repo/resolve/main/config.json":
+				configHits++
+				w.Write([]byte(`{"model_type": "llama", "max_position_embeddings": 32768}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		apiBaseForTest = server.URL
+
+		if _, err := FetchModelWithOptions("org/repo", Options{Thorough: tt.thorough}); err != nil {
+			t.Errorf("%s: FetchModelWithOptions: %v", tt.name, err)
+		}
+		if got := configHits > 0; got != tt.wantConfig {
+			t.Errorf("%s: config.json requested = %v, want %v", tt.name, got, tt.wantConfig)
+		}
+		server.Close()
+	}
+	apiBaseForTest = ""
+}
+
+func TestFetchModel_License(t *testing.T) {
+	tests := []struct {
+		name string
+		body map[string]interface{}
+		want string
+	}{
+		{"card data", map[string]interface{}{"cardData": map[string]interface{}{"license": "apache-2.0"}, "tags": []string{"license:mit"}}, "apache-2.0"},
+		{"card data list", map[string]interface{}{"cardData": map[string]interface{}{"license": []string{"apache-2.0", "mit"}}}, "apache-2.0/mit"},
+		{"tag fallback", map[string]interface{}{"tags": []string{"text-generation", "license:llama3.1"}}, "llama3.1"},
+		{"unknown", map[string]interface{}{}, ""},
+	}
+	for _, tt := range tests {
+		tt.body["safetensors"] = map[string]interface{}{"total": float64(7_000_000_000)}
+		tt.body["config"] = map[string]interface{}{"model_type": "llama", "max_position_embeddings": float64(8192)}
+		body, _ := json.Marshal(tt.body)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/models/org/repo" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(body)
+		}))
+		apiBaseForTest = server.URL
+		m, err := FetchModelWithOptions("org/repo", Options{})
+		if err != nil {
+			t.Errorf("%s: FetchModelWithOptions: %v", tt.name, err)
+		} else if m.License != tt.want {
+			t.Errorf("%s: License = %q, want %q", tt.name, m.License, tt.want)
+		}
+		server.Close()
+	}
+	apiBaseForTest = ""
+}
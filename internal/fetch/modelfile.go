@@ -0,0 +1,232 @@
+package fetch
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/shayne-snap/llmpole/internal/models"
+)
+
+// modelfile is the part of an Ollama Modelfile that changes what a model needs to run.
+type modelfile struct {
+	From   string // FROM: an Ollama or hf.co model reference, or a path to a GGUF file
+	NumCtx uint32 // PARAMETER num_ctx; 0 when not set
+}
+
+// ReadModelfile reads an Ollama Modelfile and returns the model it configures: FROM is resolved
+// against catalog (Ollama names such as "llama3.1:8b-instruct-q5_K_M" or hf.co references) or,
+// when it names a .gguf file, sized from that file. A quant in the tag or file name pins the
+// quantization, and PARAMETER num_ctx overrides the context length. The catalog entry is copied,
+// not modified.
+func ReadModelfile(path string, catalog []*models.LlmModel) (*models.LlmModel, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	mf, err := parseModelfile(bufio.NewScanner(f))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var m *models.LlmModel
+	if strings.HasSuffix(strings.ToLower(mf.From), ".gguf") {
+		ggufPath := mf.From
+		if !filepath.IsAbs(ggufPath) {
+			ggufPath = filepath.Join(filepath.Dir(path), ggufPath)
+		}
+		if m, err = localGGUFModel(ggufPath); err != nil {
+			return nil, err
+		}
+	} else {
+		name, tag := splitModelRef(mf.From)
+		found := matchCatalog(name, tag, catalog)
+		if found == nil {
+			return nil, fmt.Errorf("FROM %s: no matching model in the list", mf.From)
+		}
+		copied := *found
+		m = &copied
+		if q := tagQuant(tag); q != "" {
+			m.Quantization = q
+			m.AvailableQuants = []string{q}
+		}
+	}
+	if mf.NumCtx > 0 {
+		m.ContextLength = mf.NumCtx
+	}
+	return m, nil
+}
+
+// parseModelfile reads the FROM and PARAMETER num_ctx instructions, skipping comments and the
+// bodies of other instructions (including """-quoted multi-line TEMPLATE and SYSTEM blocks).
+func parseModelfile(sc *bufio.Scanner) (modelfile, error) {
+	var mf modelfile
+	inBlock := false
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if inBlock {
+			inBlock = !strings.Contains(line, `"""`)
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		instr, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+		if strings.HasPrefix(rest, `"""`) && !strings.Contains(rest[3:], `"""`) {
+			inBlock = true
+			continue
+		}
+		switch strings.ToUpper(instr) {
+		case "FROM":
+			mf.From = strings.Trim(rest, `"`)
+		case "PARAMETER":
+			key, value, _ := strings.Cut(rest, " ")
+			if strings.ToLower(key) != "num_ctx" {
+				continue
+			}
+			n, err := strconv.ParseUint(strings.TrimSpace(value), 10, 32)
+			if err != nil || n == 0 {
+				return mf, fmt.Errorf("PARAMETER num_ctx %q is not a positive integer", strings.TrimSpace(value))
+			}
+			mf.NumCtx = uint32(n)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return mf, err
+	}
+	if mf.From == "" {
+		return mf, fmt.Errorf("no FROM instruction")
+	}
+	return mf, nil
+}
+
+// splitModelRef splits "hf.co/org/repo:tag" or "llama3.1:8b" into name and tag, dropping the
+// registry host.
+func splitModelRef(ref string) (name, tag string) {
+	for _, host := range []string{"hf.co/", "huggingface.co/", "registry.ollama.ai/library/"} {
+		ref = strings.TrimPrefix(ref, host)
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// tagQuant returns the quantization named by a "-"-separated tag token ("q5_K_M", "fp16"), or "".
+func tagQuant(tag string) string {
+	for _, tok := range strings.Split(tag, "-") {
+		if len(tok) > 2 && strings.EqualFold(tok[:2], "fp") {
+			tok = "F" + tok[2:] // Ollama's fp16 is F16
+		}
+		if q := models.CanonicalQuant(tok); q != "" {
+			return q
+		}
+	}
+	return ""
+}
+
+// matchCatalog finds the catalog model a reference names: every token of the name's last path
+// segment and of the tag (other than the quant) must be a whole token of the model's name (see
+// nameTokens), so "llama3.1:8b-instruct" matches meta-llama/Llama-3.1-8B-Instruct while
+// "qwen2.5:7b" does not match Qwen2.5-72B and "llama3:8b" does not match Llama-3.1-8B. The
+// shortest matching name wins, preferring the original repo over re-uploads.
+func matchCatalog(name, tag string, catalog []*models.LlmModel) *models.LlmModel {
+	tokens := nameTokens(strings.TrimSuffix(strings.ToLower(name[strings.LastIndex(name, "/")+1:]), "-gguf"))
+	for _, tok := range strings.Split(tag, "-") {
+		if tok != "" && tok != "latest" && tagQuant(tok) == "" {
+			tokens = append(tokens, nameTokens(tok)...)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+	var best *models.LlmModel
+	for _, m := range catalog {
+		have := nameTokens(m.Name)
+		ok := true
+		for _, tok := range tokens {
+			if !slices.Contains(have, tok) {
+				ok = false
+				break
+			}
+		}
+		if ok && (best == nil || len(m.Name) < len(best.Name)) {
+			best = m
+		}
+	}
+	return best
+}
+
+// nameTokens lowercases s and splits it into tokens at punctuation and where a letter is followed
+// by a digit, keeping a dotted version whole: "Llama-3.1-8B" and "llama3.1:8b" both give llama,
+// 3.1, 8b. Tokens compare as units, so 7b is not 72b and 3 is not 3.1.
+func nameTokens(s string) []string {
+	rs := []rune(strings.ToLower(s))
+	isDigit := func(r rune) bool { return r >= '0' && r <= '9' }
+	isLetter := func(r rune) bool { return r >= 'a' && r <= 'z' }
+	var tokens []string
+	start := -1
+	flush := func(i int) {
+		if start >= 0 {
+			tokens = append(tokens, string(rs[start:i]))
+		}
+		start = -1
+	}
+	for i, r := range rs {
+		switch {
+		case isLetter(r) || isDigit(r):
+			if start >= 0 && isDigit(r) && isLetter(rs[i-1]) {
+				flush(i)
+			}
+			if start < 0 {
+				start = i
+			}
+		case r == '.' && start >= 0 && isDigit(rs[i-1]) && i+1 < len(rs) && isDigit(rs[i+1]):
+			// part of a version number such as 3.1
+		default:
+			flush(i)
+		}
+	}
+	flush(len(rs))
+	return tokens
+}
+
+// localGGUFModel sizes a local GGUF file the way ggufParams sizes a GGUF-only repo: from a size
+// label in its name, else its size divided by the quant's bits per weight.
+func localGGUFModel(path string) (*models.LlmModel, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	base := filepath.Base(path)
+	files := ggufFileQuants([]hfSibling{{RFilename: base, Size: uint64(st.Size())}})
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s: no quantization in the file name (e.g. Q4_K_M)", base)
+	}
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	totalParams, quant := ggufParams(name, &hfAPIResponse{}, files)
+	if totalParams == 0 {
+		return nil, fmt.Errorf("%s: cannot determine the parameter count", base)
+	}
+	minRAM, recRAM := estimateRAM(totalParams)
+	minVRAM := estimateVRAM(totalParams)
+	return &models.LlmModel{
+		Name:             name,
+		Provider:         "Local",
+		ParameterCount:   formatParamCount(totalParams),
+		ParametersRaw:    &totalParams,
+		MinRAMGB:         minRAM,
+		RecommendedRAMGB: recRAM,
+		MinVRAMGB:        &minVRAM,
+		Quantization:     quant,
+		ContextLength:    defaultCtx,
+		UseCase:          inferUseCase(name, "", nil),
+		AvailableQuants:  []string{quant},
+	}, nil
+}
@@ -0,0 +1,132 @@
+package fetch
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/shayne-snap/llmpole/internal/models"
+)
+
+func writeModelfile(t *testing.T, dir, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, "Modelfile")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadModelfile_CatalogWithOverrides(t *testing.T) {
+	catalog := []*models.LlmModel{
+		{Name: "meta-llama/Llama-3.1-70B-Instruct", ParameterCount: "70B", Quantization: "Q4_K_M", ContextLength: 131072},
+		{Name: "meta-llama/Llama-3.1-8B-Instruct", ParameterCount: "8B", Quantization: "Q4_K_M", ContextLength: 131072},
+		{Name: "bartowski/Meta-Llama-3.1-8B-Instruct-GGUF", ParameterCount: "8B", Quantization: "Q4_K_M", ContextLength: 131072},
+	}
+	path := writeModelfile(t, t.TempDir(), `# tuned for long documents
+FROM llama3.1:8b-instruct-q5_K_M
+PARAMETER temperature 0.2
+PARAMETER num_ctx 16384
+SYSTEM """
+You are terse.
+FROM this line is part of the system prompt
+"""
+`)
+	m, err := ReadModelfile(path, catalog)
+	if err != nil {
+		t.Fatalf("ReadModelfile: %v", err)
+	}
+	if m.Name != "meta-llama/Llama-3.1-8B-Instruct" {
+		t.Errorf("Name = %q, want the 8B instruct catalog entry", m.Name)
+	}
+	if m.ContextLength != 16384 {
+		t.Errorf("ContextLength = %d, want num_ctx 16384", m.ContextLength)
+	}
+	if m.Quantization != "Q5_K_M" || !slices.Equal(m.AvailableQuants, []string{"Q5_K_M"}) {
+		t.Errorf("quant = %s (available %v), want pinned to Q5_K_M", m.Quantization, m.AvailableQuants)
+	}
+	if catalog[1].ContextLength != 131072 || catalog[1].Quantization != "Q4_K_M" {
+		t.Error("ReadModelfile modified the catalog entry")
+	}
+
+	fp16, err := ReadModelfile(writeModelfile(t, t.TempDir(), "FROM llama3.1:8b-instruct-fp16\n"), catalog)
+	if err != nil || fp16.Name != "meta-llama/Llama-3.1-8B-Instruct" || fp16.Quantization != "F16" {
+		t.Errorf("FROM llama3.1:8b-instruct-fp16 = %+v, %v; want the 8B instruct entry pinned to F16", fp16, err)
+	}
+
+	if _, err := ReadModelfile(writeModelfile(t, t.TempDir(), "FROM llama3.1:8b\nPARAMETER num_ctx lots\n"), catalog); err == nil {
+		t.Error("expected an error for a non-numeric num_ctx")
+	}
+	if _, err := ReadModelfile(writeModelfile(t, t.TempDir(), "PARAMETER num_ctx 4096\n"), catalog); err == nil {
+		t.Error("expected an error for a Modelfile without FROM")
+	}
+	if _, err := ReadModelfile(writeModelfile(t, t.TempDir(), "FROM mystery-model:7b\n"), catalog); err == nil {
+		t.Error("expected an error for a FROM that matches nothing")
+	}
+}
+
+func TestMatchCatalog_WholeTokens(t *testing.T) {
+	catalog := []*models.LlmModel{
+		{Name: "Qwen/Qwen2.5-72B-Instruct"},
+		{Name: "meta-llama/Llama-3.1-8B-Instruct"},
+	}
+	if m := matchCatalog("qwen2.5", "7b", catalog); m != nil {
+		t.Errorf("qwen2.5:7b matched %s; 7b is not 72b", m.Name)
+	}
+	if m := matchCatalog("llama3", "8b", catalog); m != nil {
+		t.Errorf("llama3:8b matched %s; llama3 is not llama 3.1", m.Name)
+	}
+
+	catalog = append(catalog, &models.LlmModel{Name: "Qwen/Qwen2.5-7B-Instruct"}, &models.LlmModel{Name: "meta-llama/Meta-Llama-3-8B-Instruct"})
+	if m := matchCatalog("qwen2.5", "7b", catalog); m == nil || m.Name != "Qwen/Qwen2.5-7B-Instruct" {
+		t.Errorf("qwen2.5:7b = %v, want Qwen/Qwen2.5-7B-Instruct", m)
+	}
+	if m := matchCatalog("llama3", "8b", catalog); m == nil || m.Name != "meta-llama/Meta-Llama-3-8B-Instruct" {
+		t.Errorf("llama3:8b = %v, want meta-llama/Meta-Llama-3-8B-Instruct", m)
+	}
+}
+
+func TestNameTokens(t *testing.T) {
+	for in, want := range map[string][]string{
+		"llama3.1":                 {"llama", "3.1"},
+		"meta-llama/Llama-3.1-8B":  {"meta", "llama", "llama", "3.1", "8b"},
+		"Qwen2.5-72B-Instruct":     {"qwen", "2.5", "72b", "instruct"},
+		"Mistral-7B-Instruct-v0.3": {"mistral", "7b", "instruct", "v", "0.3"},
+		"deepseek-r1:7b":           {"deepseek", "r", "1", "7b"},
+	} {
+		if got := nameTokens(in); !slices.Equal(got, want) {
+			t.Errorf("nameTokens(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTagQuant(t *testing.T) {
+	for tag, want := range map[string]string{
+		"q5_K_M":           "Q5_K_M",
+		"fp16":             "F16",
+		"FP32":             "F32",
+		"8b-instruct-fp16": "F16",
+		"8b-instruct":      "",
+		"fp":               "",
+	} {
+		if got := tagQuant(tag); got != want {
+			t.Errorf("tagQuant(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestReadModelfile_LocalGGUF(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Qwen2.5-7B-Instruct-Q6_K.gguf"), []byte("GGUF"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := writeModelfile(t, dir, "FROM ./Qwen2.5-7B-Instruct-Q6_K.gguf\nPARAMETER num_ctx 32768\n")
+	m, err := ReadModelfile(path, nil)
+	if err != nil {
+		t.Fatalf("ReadModelfile: %v", err)
+	}
+	if m.ParameterCount != "7B" || m.Quantization != "Q6_K" || m.ContextLength != 32768 {
+		t.Errorf("model = %s %s ctx %d, want 7B Q6_K ctx 32768", m.ParameterCount, m.Quantization, m.ContextLength)
+	}
+}
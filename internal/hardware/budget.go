@@ -0,0 +1,223 @@
+package hardware
+
+import "fmt"
+
+// BudgetSpecs synthesizes specs for a hypothetical machine with budgetGB of memory, for
+// "what fits in N GB" questions independent of this machine. kind is "vram" (a GPU with
+// budgetGB of VRAM and twice that in system RAM; unified when the backend is Metal) or
+// "ram" (CPU-only with budgetGB of RAM). All memory is treated as available.
+func BudgetSpecs(budgetGB float64, kind string, backend GpuBackend) (*SystemSpecs, error) {
+	if budgetGB <= 0 {
+		return nil, fmt.Errorf("budget must be positive, got %g GB", budgetGB)
+	}
+	specs := &SystemSpecs{
+		TotalCPUCores: 8,
+		CPUName:       "Hypothetical CPU",
+		Backend:       backend,
+	}
+	switch kind {
+	case "vram":
+		if backend == BackendCpuX86 || backend == BackendCpuArm {
+			return nil, fmt.Errorf("a VRAM budget needs a GPU backend, got %s", backend)
+		}
+		vram := budgetGB
+		unified := backend == BackendMetal
+		specs.TotalRAMGB = budgetGB * 2
+		if unified {
+			specs.TotalRAMGB = budgetGB
+		}
+		name := fmt.Sprintf("Hypothetical %s GPU", backend)
+		specs.HasGPU = true
+		specs.GpuVRAMGB = &vram
+		specs.GpuName = &name
+		specs.GpuCount = 1
+		specs.UnifiedMemory = unified
+		specs.Gpus = []GpuInfo{{Name: name, VRAMGB: &vram, Backend: backend, Count: 1, UnifiedMemory: unified}}
+	case "ram":
+		if backend != BackendCpuX86 && backend != BackendCpuArm {
+			return nil, fmt.Errorf("a RAM budget is CPU-only; backend must be CPU (x86) or CPU (ARM), got %s", backend)
+		}
+		specs.TotalRAMGB = budgetGB
+	default:
+		return nil, fmt.Errorf("unknown budget kind %q (want vram or ram)", kind)
+	}
+	specs.AvailableRAMGB = specs.TotalRAMGB
+	return specs, nil
+}
+
+// WithExtraMemory returns a copy of s with extra system RAM and VRAM added, for "what if I
+// upgraded" analysis. On unified-memory systems both go to the shared pool. Extra VRAM on a
+// machine without a GPU adds a hypothetical CUDA GPU of that size.
+func (s *SystemSpecs) WithExtraMemory(extraRAMGB, extraVRAMGB float64) *SystemSpecs {
+	out := *s
+	out.Gpus = append([]GpuInfo(nil), s.Gpus...)
+	out.TotalRAMGB += extraRAMGB
+	out.AvailableRAMGB += extraRAMGB
+	if s.UnifiedMemory {
+		out.TotalRAMGB += extraVRAMGB
+		out.AvailableRAMGB += extraVRAMGB
+	}
+	addVRAM := extraVRAMGB
+	if s.UnifiedMemory {
+		addVRAM += extraRAMGB
+	}
+	if addVRAM <= 0 {
+		return &out
+	}
+	if !s.HasGPU || len(out.Gpus) == 0 {
+		vram := extraVRAMGB
+		name := "Hypothetical CUDA GPU"
+		out.HasGPU = true
+		out.GpuVRAMGB = &vram
+		out.GpuName = &name
+		out.GpuCount = 1
+		out.Backend = BackendCuda
+		out.Gpus = []GpuInfo{{Name: name, VRAMGB: &vram, Backend: BackendCuda, Count: 1}}
+		return &out
+	}
+	base := 0.0
+	if s.GpuVRAMGB != nil {
+		base = *s.GpuVRAMGB
+	}
+	vram := base + addVRAM
+	out.GpuVRAMGB = &vram
+	primary := out.Gpus[0]
+	primary.VRAMGB = &vram
+	if primary.FreeVRAMGB != nil {
+		free := *primary.FreeVRAMGB + addVRAM
+		primary.FreeVRAMGB = &free
+	}
+	out.GpuFreeVRAMGB = primary.FreeVRAMGB
+	out.Gpus[0] = primary
+	return &out
+}
+
+// Assumptions override parts of detected specs for a single run, e.g. to ask what fits on a
+// machine like this one with a bigger GPU. A nil field keeps the detected value.
+type Assumptions struct {
+	VRAMGB     *float64 // 0 means no GPU (CPU-only)
+	FreeVRAMGB *float64 // VRAM not in use by other processes; at most the GPU's VRAM
+	RAMGB      *float64 // total RAM, all treated as available
+	Backend    *GpuBackend
+}
+
+// AssumedSource is the provenance recorded for values replaced by Assumptions.
+const AssumedSource = "assumed on the command line"
+
+// WithAssumptions returns a copy of s with the assumed values patched in. Assumed VRAM replaces
+// the detected GPUs with a single GPU of that size (named after the detected primary GPU when
+// there is one); a GPU backend without any GPU needs VRAM to be assumed too. A Metal backend
+// implies unified memory, where the GPU pool is the assumed RAM unless VRAM is given. Assumed
+// free VRAM applies to the resulting primary GPU; without it, assumed VRAM is all free.
+func (s *SystemSpecs) WithAssumptions(a Assumptions) (*SystemSpecs, error) {
+	out, err := s.withAssumedMemory(a)
+	if err != nil || a.FreeVRAMGB == nil {
+		return out, err
+	}
+	free := *a.FreeVRAMGB
+	switch {
+	case !out.HasGPU || len(out.Gpus) == 0:
+		return nil, fmt.Errorf("assumed free VRAM needs a GPU")
+	case free < 0:
+		return nil, fmt.Errorf("assumed free VRAM must not be negative, got %g GB", free)
+	case out.GpuVRAMGB != nil && free > *out.GpuVRAMGB:
+		return nil, fmt.Errorf("assumed free VRAM of %g GB exceeds the GPU's %g GB", free, *out.GpuVRAMGB)
+	}
+	out.GpuFreeVRAMGB = &free
+	out.Gpus[0].FreeVRAMGB = &free
+	return out, nil
+}
+
+// withAssumedMemory is WithAssumptions without the free-VRAM assumption.
+func (s *SystemSpecs) withAssumedMemory(a Assumptions) (*SystemSpecs, error) {
+	out := *s
+	out.Gpus = append([]GpuInfo(nil), s.Gpus...)
+	out.Sources = make(map[string]string, len(s.Sources))
+	for k, v := range s.Sources {
+		out.Sources[k] = v
+	}
+	if a.RAMGB != nil {
+		if *a.RAMGB <= 0 {
+			return nil, fmt.Errorf("assumed RAM must be positive, got %g GB", *a.RAMGB)
+		}
+		out.TotalRAMGB = *a.RAMGB
+		out.AvailableRAMGB = *a.RAMGB
+		out.Sources[FieldTotalRAM] = AssumedSource
+		out.Sources[FieldAvailableRAM] = AssumedSource
+	}
+	backend := s.Backend
+	if a.Backend != nil {
+		backend = *a.Backend
+		out.Sources[FieldBackend] = AssumedSource
+	}
+	cpuOnly := isCPUBackend(backend)
+	if a.VRAMGB != nil {
+		switch {
+		case *a.VRAMGB < 0:
+			return nil, fmt.Errorf("assumed VRAM must not be negative, got %g GB", *a.VRAMGB)
+		case *a.VRAMGB == 0:
+			if a.Backend != nil && !cpuOnly {
+				return nil, fmt.Errorf("assumed VRAM of 0 GB means no GPU, but backend is %s", backend)
+			}
+			if !cpuOnly {
+				backend = backendCPU(s.CPUName)
+			}
+			cpuOnly = true
+		case cpuOnly:
+			if a.Backend != nil {
+				return nil, fmt.Errorf("assumed VRAM needs a GPU backend, got %s", backend)
+			}
+			backend = BackendCuda
+			cpuOnly = false
+		}
+	} else if !cpuOnly && !s.HasGPU {
+		return nil, fmt.Errorf("backend %s needs a GPU; assume its VRAM too", backend)
+	}
+	out.Backend = backend
+	if cpuOnly {
+		out.HasGPU = false
+		out.GpuVRAMGB = nil
+		out.GpuFreeVRAMGB = nil
+		out.GpuName = nil
+		out.GpuCount = 0
+		out.UnifiedMemory = false
+		out.Gpus = nil
+		return &out, nil
+	}
+	unified := backend == BackendMetal
+	var vram float64
+	switch {
+	case a.VRAMGB != nil:
+		vram = *a.VRAMGB
+	case unified && a.RAMGB != nil:
+		vram = *a.RAMGB
+	case s.GpuVRAMGB != nil:
+		vram = *s.GpuVRAMGB
+	}
+	name := fmt.Sprintf("Assumed %s GPU", backend)
+	if a.Backend == nil && len(s.Gpus) > 0 {
+		name = s.Gpus[0].Name
+	} else if a.Backend == nil && s.GpuName != nil {
+		name = *s.GpuName
+	}
+	if a.VRAMGB == nil && a.Backend == nil && !(unified && a.RAMGB != nil) {
+		// Only RAM changed on a discrete-GPU machine: keep the detected GPUs as they are.
+		return &out, nil
+	}
+	source := AssumedSource
+	if a.VRAMGB == nil && !(unified && a.RAMGB != nil) && len(s.Gpus) > 0 {
+		source = s.Gpus[0].VRAMSource
+	}
+	out.HasGPU = true
+	out.GpuVRAMGB = &vram
+	out.GpuFreeVRAMGB = nil
+	out.GpuName = &name
+	out.GpuCount = 1
+	out.UnifiedMemory = unified
+	out.Gpus = []GpuInfo{{Name: name, VRAMGB: &vram, Backend: backend, Count: 1, UnifiedMemory: unified, VRAMSource: source}}
+	return &out, nil
+}
+
+func isCPUBackend(b GpuBackend) bool {
+	return b == BackendCpuX86 || b == BackendCpuArm
+}
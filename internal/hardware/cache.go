@@ -0,0 +1,121 @@
+package hardware
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// SpecsCacheTTL is how long a cached detection is reused before the GPU tools are run again.
+const SpecsCacheTTL = 60 * time.Second
+
+// deviceMaskEnv lists the variables that change which GPUs detection reports.
+var deviceMaskEnv = []string{"NVIDIA_VISIBLE_DEVICES", "CUDA_VISIBLE_DEVICES", "HIP_VISIBLE_DEVICES"}
+
+// specsCache is the on-disk form of a cached detection. Env holds the deviceMaskEnv variables
+// that were set at detection time; a cache detected under different ones is not reused.
+type specsCache struct {
+	DetectedAt time.Time         `json:"detected_at"`
+	Env        map[string]string `json:"env,omitempty"`
+	Specs      *SystemSpecs      `json:"specs"`
+}
+
+// deviceMask returns the deviceMaskEnv variables that are set, empty values included: an empty
+// CUDA_VISIBLE_DEVICES hides every GPU.
+func deviceMask() map[string]string {
+	env := map[string]string{}
+	for _, name := range deviceMaskEnv {
+		if v, ok := os.LookupEnv(name); ok {
+			env[name] = v
+		}
+	}
+	return env
+}
+
+// DetectCached is Detect, reusing the detection cached at path while it is younger than
+// SpecsCacheTTL so repeated commands skip nvidia-smi, rocm-smi, and the like. RAM is always
+// read live, as it changes between runs; GPU identity, VRAM, and free VRAM come from the cache,
+// and each GPU's VRAMSource says so (free VRAM may have moved since). A change to
+// NVIDIA_VISIBLE_DEVICES, CUDA_VISIBLE_DEVICES, or HIP_VISIBLE_DEVICES since the cached
+// detection counts as a miss. refresh forces a new detection, and path "" disables the cache.
+// Writing the cache is best-effort. Unified-memory machines are never cached: their VRAM
+// follows available RAM.
+func DetectCached(path string, refresh bool) (*SystemSpecs, error) {
+	now, env := time.Now(), deviceMask()
+	if path != "" && !refresh {
+		if data, err := os.ReadFile(path); err == nil {
+			if specs, ok := decodeSpecsCache(data, now, SpecsCacheTTL, env); ok && specs.refreshRAM() == nil {
+				return specs, nil
+			}
+		}
+	}
+	specs, err := Detect()
+	if err != nil {
+		return nil, err
+	}
+	if path != "" && !specs.UnifiedMemory {
+		if data, err := encodeSpecsCache(specs, now, env); err == nil && os.MkdirAll(filepath.Dir(path), 0755) == nil {
+			_ = os.WriteFile(path, data, 0644)
+		}
+	}
+	return specs, nil
+}
+
+// encodeSpecsCache serializes specs as detected at now under the device mask env.
+func encodeSpecsCache(specs *SystemSpecs, now time.Time, env map[string]string) ([]byte, error) {
+	data, err := json.MarshalIndent(specsCache{DetectedAt: now, Env: env, Specs: specs}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// decodeSpecsCache returns the cached specs when data parses, was detected less than ttl before
+// now, and was detected under the device mask env, with each GPU's VRAMSource marked as cached.
+// A detection time in the future (a clock change) counts as expired.
+func decodeSpecsCache(data []byte, now time.Time, ttl time.Duration, env map[string]string) (*SystemSpecs, bool) {
+	var c specsCache
+	if err := json.Unmarshal(data, &c); err != nil || c.Specs == nil || c.DetectedAt.IsZero() {
+		return nil, false
+	}
+	if age := now.Sub(c.DetectedAt); age < 0 || age >= ttl {
+		return nil, false
+	}
+	if !maps.Equal(c.Env, env) {
+		return nil, false
+	}
+	for i := range c.Specs.Gpus {
+		if g := &c.Specs.Gpus[i]; g.VRAMSource != "" {
+			g.VRAMSource += fmt.Sprintf(", cached %s ago", now.Sub(c.DetectedAt).Round(time.Second))
+		}
+	}
+	return c.Specs, true
+}
+
+// refreshRAM replaces cached RAM readings, their sources, and their warnings with live ones.
+func (s *SystemSpecs) refreshRAM() error {
+	v, err := mem.VirtualMemory()
+	if err != nil {
+		return err
+	}
+	if s.Sources == nil {
+		s.Sources = map[string]string{}
+	}
+	s.Sources[FieldTotalRAM] = memSource(runtime.GOOS, "total")
+	s.Sources[FieldAvailableRAM] = memSource(runtime.GOOS, "available")
+	var warnings []Warning
+	s.TotalRAMGB, s.AvailableRAMGB, warnings = readRAM(v, s.Sources)
+	s.Warnings = slices.DeleteFunc(s.Warnings, func(w Warning) bool {
+		return w.Code == WarnRAMFallback || w.Code == WarnRAMCorrected
+	})
+	s.Warnings = append(warnings, s.Warnings...)
+	s.sanitize()
+	return nil
+}
@@ -4,11 +4,16 @@ package hardware
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -54,53 +59,189 @@ func (b GpuBackend) String() string {
 
 // GpuInfo holds one detected GPU (name, VRAM, backend, unified memory).
 type GpuInfo struct {
-	Name           string     `json:"name"`
-	VRAMGB         *float64   `json:"vram_gb,omitempty"`
-	Backend        GpuBackend `json:"backend"`
-	Count          uint32     `json:"count"`
-	UnifiedMemory  bool       `json:"unified_memory"`
+	Name          string     `json:"name"`
+	VRAMGB        *float64   `json:"vram_gb,omitempty"`
+	Backend       GpuBackend `json:"backend"`
+	Count         uint32     `json:"count"`
+	UnifiedMemory bool       `json:"unified_memory"`
+	Note          string     `json:"note,omitempty"`
+	VRAMSource    string     `json:"vram_source,omitempty"` // where VRAMGB came from, e.g. "nvidia-smi memory.total"
+	// NVIDIA only, from nvidia-smi when it supports the query: the lowest CUDA compute capability
+	// among the devices (e.g. "8.6") and the driver version (e.g. "550.54.14").
+	ComputeCapability string `json:"compute_capability,omitempty"`
+	DriverVersion     string `json:"driver_version,omitempty"`
+	// FreeVRAMGB is the VRAM not in use by other processes at detection time, across Count
+	// devices like VRAMGB; nil when the driver tool cannot report it.
+	FreeVRAMGB *float64 `json:"free_vram_gb,omitempty"`
+	// ECCEnabled is set for NVIDIA cards running with ECC on. nvidia-smi's memory.total already
+	// excludes the VRAM ECC takes, so VRAMGB needs no adjustment; it is reported for reference.
+	ECCEnabled bool `json:"ecc_enabled,omitempty"`
+}
+
+// ComputeCapabilityValue returns ComputeCapability as a number (8.6), and false when unknown.
+func (g GpuInfo) ComputeCapabilityValue() (float64, bool) {
+	v, err := strconv.ParseFloat(g.ComputeCapability, 64)
+	return v, err == nil && v > 0
+}
+
+// DriverMajor returns the major driver version (550 for "550.54.14"), or 0 when unknown.
+func (g GpuInfo) DriverMajor() int {
+	major, _, _ := strings.Cut(g.DriverVersion, ".")
+	n, _ := strconv.Atoi(major)
+	return n
+}
+
+// integratedGPUNames are lowercase name fragments of integrated GPUs that borrow system RAM.
+var integratedGPUNames = []string{"uhd graphics", "hd graphics", "iris", "intel(r) graphics", "intel graphics", "radeon graphics", "radeon(tm) graphics", "vega 8", "vega 11"}
+
+// Integrated reports whether g is an integrated GPU whose memory is carved out of system RAM.
+func (g GpuInfo) Integrated() bool {
+	if g.UnifiedMemory {
+		return true
+	}
+	name := strings.ToLower(g.Name)
+	for _, frag := range integratedGPUNames {
+		if strings.Contains(name, frag) {
+			return true
+		}
+	}
+	return false
+}
+
+// IntegratedGPU returns an integrated GPU detected alongside a discrete primary GPU (the
+// typical laptop iGPU + dGPU pair), or nil when there is none or the primary is integrated.
+// The primary is Gpus[0], as sorted by Detect.
+func (s *SystemSpecs) IntegratedGPU() *GpuInfo {
+	if s.UnifiedMemory || len(s.Gpus) < 2 || s.Gpus[0].Integrated() {
+		return nil
+	}
+	for i := 1; i < len(s.Gpus); i++ {
+		if s.Gpus[i].Integrated() {
+			return &s.Gpus[i]
+		}
+	}
+	return nil
+}
+
+// SharedGPUMemoryGB is how much system RAM an integrated GPU may borrow: half of total RAM,
+// the default cap on Windows and Linux drivers.
+func (s *SystemSpecs) SharedGPUMemoryGB() float64 {
+	return s.TotalRAMGB / 2
 }
 
 // SystemSpecs holds detected system specs (RAM, CPU, GPUs).
 type SystemSpecs struct {
-	TotalRAMGB      float64   `json:"total_ram_gb"`
-	AvailableRAMGB  float64   `json:"available_ram_gb"`
-	TotalCPUCores   int       `json:"cpu_cores"`
-	CPUName         string    `json:"cpu_name"`
-	HasGPU          bool      `json:"has_gpu"`
-	GpuVRAMGB       *float64  `json:"gpu_vram_gb,omitempty"`
-	GpuName         *string   `json:"gpu_name,omitempty"`
-	GpuCount        uint32    `json:"gpu_count"`
-	UnifiedMemory   bool      `json:"unified_memory"`
-	Backend         GpuBackend `json:"backend"`
-	Gpus            []GpuInfo `json:"gpus"`
+	TotalRAMGB     float64           `json:"total_ram_gb"`
+	AvailableRAMGB float64           `json:"available_ram_gb"`
+	TotalCPUCores  int               `json:"cpu_cores"`
+	NumaNodes      int               `json:"numa_nodes,omitempty"`
+	CPUName        string            `json:"cpu_name"`
+	HasGPU         bool              `json:"has_gpu"`
+	GpuVRAMGB      *float64          `json:"gpu_vram_gb,omitempty"`
+	GpuFreeVRAMGB  *float64          `json:"gpu_free_vram_gb,omitempty"` // primary GPU's free VRAM; nil when unknown
+	GpuName        *string           `json:"gpu_name,omitempty"`
+	GpuCount       uint32            `json:"gpu_count"`
+	UnifiedMemory  bool              `json:"unified_memory"`
+	Backend        GpuBackend        `json:"backend"`
+	Gpus           []GpuInfo         `json:"gpus"`
+	Sources        map[string]string `json:"sources,omitempty"`  // Field* name -> where the value came from
+	Warnings       []Warning         `json:"warnings,omitempty"` // detection anomalies: failed probes, estimates, corrected readings
+}
+
+// FitVRAMGB returns the primary GPU's VRAM a model can be loaded into: its free VRAM when
+// detected, else its total. It is nil when VRAM is unknown.
+func (s *SystemSpecs) FitVRAMGB() *float64 {
+	if s.GpuFreeVRAMGB != nil {
+		return s.GpuFreeVRAMGB
+	}
+	return s.GpuVRAMGB
+}
+
+// Field names keying SystemSpecs.Sources. GPU VRAM provenance is on each GpuInfo.
+const (
+	FieldTotalRAM     = "total_ram"
+	FieldAvailableRAM = "available_ram"
+	FieldCPUName      = "cpu_name"
+	FieldCPUCores     = "cpu_cores"
+	FieldNUMANodes    = "numa_nodes"
+	FieldBackend      = "backend"
+)
+
+// Source returns how field (a Field* constant) was detected, or "" when unrecorded.
+func (s *SystemSpecs) Source(field string) string {
+	return s.Sources[field]
+}
+
+// setAllSources records the same source for every field and GPU, e.g. for specs read from a remote host.
+func (s *SystemSpecs) setAllSources(source string) {
+	s.Sources = map[string]string{}
+	for _, f := range []string{FieldTotalRAM, FieldAvailableRAM, FieldCPUName, FieldCPUCores, FieldNUMANodes, FieldBackend} {
+		s.Sources[f] = source
+	}
+	for i := range s.Gpus {
+		s.Gpus[i].VRAMSource = source
+	}
 }
 
 const gb = 1024 * 1024 * 1024
 
-// Detect returns system specs for the current machine (RAM, CPU, GPUs per OS).
+// Detect returns system specs for the current machine (RAM, CPU, GPUs per OS), recording in
+// Sources and GpuInfo.VRAMSource where each value came from.
 func Detect() (*SystemSpecs, error) {
 	v, err := mem.VirtualMemory()
 	if err != nil {
 		return nil, fmt.Errorf("mem: %w", err)
 	}
-	totalRAMGB := float64(v.Total) / float64(gb)
-	availableRAMGB := float64(v.Available) / float64(gb)
-	if v.Available == 0 && v.Total > 0 {
-		availableRAMGB = availableRAMFallback(totalRAMGB)
+	sources := map[string]string{
+		FieldTotalRAM:     memSource(runtime.GOOS, "total"),
+		FieldAvailableRAM: memSource(runtime.GOOS, "available"),
+		FieldCPUCores:     "Go runtime.NumCPU (logical CPUs usable by this process)",
 	}
+	totalRAMGB, availableRAMGB, ramWarnings := readRAM(v, sources)
 
 	infos, _ := cpu.Info()
 	totalCPUCores := runtime.NumCPU()
 	cpuName := "Unknown CPU"
+	sources[FieldCPUName] = "default (CPU info unavailable)"
 	if len(infos) > 0 {
 		cpuName = infos[0].ModelName
+		sources[FieldCPUName] = cpuInfoSource(runtime.GOOS) + " model name"
 		if cpuName == "" {
 			cpuName = infos[0].VendorID
+			sources[FieldCPUName] = cpuInfoSource(runtime.GOOS) + " vendor ID"
 		}
 	}
 
-	gpus := detectAllGPUs(totalRAMGB, availableRAMGB, cpuName)
+	numaNodes := detectNUMANodes()
+	sources[FieldNUMANodes] = "not detected (non-Linux or /sys/devices/system/node unreadable)"
+	if numaNodes > 0 {
+		sources[FieldNUMANodes] = "/sys/devices/system/node"
+	}
+
+	gpus, failures := detectAllGPUs(totalRAMGB, availableRAMGB, cpuName)
+	specs := assembleSpecs(totalRAMGB, availableRAMGB, totalCPUCores, numaNodes, cpuName, gpus, sources)
+	specs.Warnings = append(append(failures, ramWarnings...), specs.Warnings...)
+	return specs, nil
+}
+
+// readRAM converts gopsutil's memory reading to GB, estimating available memory when the OS
+// reports none and recording the source of each value in sources.
+func readRAM(v *mem.VirtualMemoryStat, sources map[string]string) (totalGB, availableGB float64, warnings []Warning) {
+	totalGB = float64(v.Total) / float64(gb)
+	availableGB = float64(v.Available) / float64(gb)
+	if v.Available == 0 && v.Total > 0 {
+		availableGB, sources[FieldAvailableRAM] = availableRAMFallback(totalGB)
+		warnings = append(warnings, Warning{
+			Code:    WarnRAMFallback,
+			Message: fmt.Sprintf("OS reported no available RAM: using %.2f GB from %s", availableGB, sources[FieldAvailableRAM]),
+		})
+	}
+	return totalGB, availableGB, warnings
+}
+
+// assembleSpecs builds SystemSpecs from detected readings: GPUs are sorted by VRAM (descending)
+// and the first becomes the primary that sets the backend.
+func assembleSpecs(totalRAMGB, availableRAMGB float64, cores, numaNodes int, cpuName string, gpus []GpuInfo, sources map[string]string) *SystemSpecs {
 	sort.Slice(gpus, func(i, j int) bool {
 		vi, vj := 0.0, 0.0
 		if gpus[i].VRAMGB != nil {
@@ -122,27 +263,145 @@ func Detect() (*SystemSpecs, error) {
 	gpuCount := uint32(0)
 	unified := false
 	backend := backendCPU(cpuName)
+	sources[FieldBackend] = "CPU architecture (no GPU detected)"
+	var gpuFreeVRAMGB *float64
 	if primary != nil {
 		gpuVRAMGB = primary.VRAMGB
+		gpuFreeVRAMGB = primary.FreeVRAMGB
 		gpuName = &primary.Name
 		gpuCount = primary.Count
 		unified = primary.UnifiedMemory
 		backend = primary.Backend
+		sources[FieldBackend] = "primary GPU " + primary.Name
 	}
 
-	return &SystemSpecs{
+	specs := &SystemSpecs{
 		TotalRAMGB:     totalRAMGB,
 		AvailableRAMGB: availableRAMGB,
-		TotalCPUCores:  totalCPUCores,
+		TotalCPUCores:  cores,
+		NumaNodes:      numaNodes,
 		CPUName:        cpuName,
 		HasGPU:         hasGPU,
 		GpuVRAMGB:      gpuVRAMGB,
+		GpuFreeVRAMGB:  gpuFreeVRAMGB,
 		GpuName:        gpuName,
 		GpuCount:       gpuCount,
 		UnifiedMemory:  unified,
 		Backend:        backend,
 		Gpus:           gpus,
-	}, nil
+		Sources:        sources,
+	}
+	for _, g := range gpus {
+		if g.VRAMGB != nil && strings.HasPrefix(g.VRAMSource, nameEstimatePrefix) {
+			specs.warn(WarnVRAMFromName, "%s VRAM of %.1f GB is an %s", g.Name, *g.VRAMGB, g.VRAMSource)
+		}
+	}
+	specs.sanitize()
+	return specs
+}
+
+// maxPlausibleVRAMGB bounds the VRAM of a single device; larger readings are unit or parse errors.
+const maxPlausibleVRAMGB = 512
+
+// sanitize corrects readings that contradict each other, such as more available than total RAM
+// from a bad vm_stat parse or an implausible VRAM figure, and records a warning for each fix.
+func (s *SystemSpecs) sanitize() {
+	if s.AvailableRAMGB < 0 || math.IsNaN(s.AvailableRAMGB) {
+		s.warn(WarnRAMCorrected, "Available RAM reported as %g GB: treated as 0", s.AvailableRAMGB)
+		s.AvailableRAMGB = 0
+	}
+	if s.TotalRAMGB > 0 && s.AvailableRAMGB > s.TotalRAMGB {
+		s.warn(WarnRAMCorrected, "Available RAM reported as %.2f GB, more than the %.2f GB total: clamped to total", s.AvailableRAMGB, s.TotalRAMGB)
+		s.AvailableRAMGB = s.TotalRAMGB
+	}
+	for i := range s.Gpus {
+		g := &s.Gpus[i]
+		if g.VRAMGB == nil {
+			continue
+		}
+		v := *g.VRAMGB
+		count := math.Max(1, float64(g.Count))
+		switch {
+		case v < 0 || math.IsNaN(v) || math.IsInf(v, 0) || v/count > maxPlausibleVRAMGB:
+			s.warn(WarnVRAMCorrected, "%s VRAM reported as %g GB, which is not plausible: treated as unknown", g.Name, v)
+			g.VRAMGB = nil
+		case g.UnifiedMemory && s.TotalRAMGB > 0 && v > s.TotalRAMGB:
+			s.warn(WarnVRAMCorrected, "%s unified memory reported as %.2f GB, more than the %.2f GB of RAM: clamped to total RAM", g.Name, v, s.TotalRAMGB)
+			clamped := s.TotalRAMGB
+			g.VRAMGB = &clamped
+		}
+		if g.FreeVRAMGB != nil && (g.VRAMGB == nil || *g.FreeVRAMGB < 0 || *g.FreeVRAMGB > *g.VRAMGB) {
+			s.warn(WarnVRAMCorrected, "%s free VRAM reported as %g GB, which does not fit its total: treated as unknown", g.Name, *g.FreeVRAMGB)
+			g.FreeVRAMGB = nil
+		}
+	}
+	if len(s.Gpus) > 0 {
+		s.GpuVRAMGB = s.Gpus[0].VRAMGB
+		s.GpuFreeVRAMGB = s.Gpus[0].FreeVRAMGB
+	}
+}
+
+// memSource names the OS facility gopsutil reads total or available memory from.
+func memSource(goos, which string) string {
+	switch goos {
+	case "linux":
+		if which == "total" {
+			return "/proc/meminfo MemTotal"
+		}
+		return "/proc/meminfo MemAvailable"
+	case "darwin":
+		if which == "total" {
+			return "sysctl hw.memsize"
+		}
+		return "host_statistics free + inactive pages"
+	case "windows":
+		return "GlobalMemoryStatusEx"
+	}
+	return "gopsutil mem.VirtualMemory"
+}
+
+// cpuInfoSource names the OS facility gopsutil reads the CPU model from.
+func cpuInfoSource(goos string) string {
+	switch goos {
+	case "linux":
+		return "/proc/cpuinfo"
+	case "darwin":
+		return "sysctl machdep.cpu"
+	case "windows":
+		return "WMI Win32_Processor"
+	}
+	return "gopsutil cpu.Info"
+}
+
+// detectNUMANodes returns the number of NUMA nodes on Linux (from /sys/devices/system/node), or 0 when unknown.
+func detectNUMANodes() int {
+	if runtime.GOOS != "linux" {
+		return 0
+	}
+	entries, err := os.ReadDir("/sys/devices/system/node")
+	if err != nil {
+		return 0
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return countNUMANodes(names)
+}
+
+// countNUMANodes counts "nodeN" entries in a /sys/devices/system/node listing.
+func countNUMANodes(names []string) int {
+	n := 0
+	for _, name := range names {
+		id := strings.TrimPrefix(name, "node")
+		if id == name || id == "" {
+			continue
+		}
+		if _, err := strconv.Atoi(id); err == nil {
+			n++
+		}
+	}
+	return n
 }
 
 func backendCPU(cpuName string) GpuBackend {
@@ -153,13 +412,49 @@ func backendCPU(cpuName string) GpuBackend {
 	return BackendCpuX86
 }
 
-func availableRAMFallback(totalGB float64) float64 {
-	if runtime.GOOS == "darwin" {
+// availableRAMFallback estimates available memory when the OS reports none, and says how.
+func availableRAMFallback(totalGB float64) (float64, string) {
+	switch runtime.GOOS {
+	case "darwin":
 		if avail := availableFromVMStat(); avail > 0 {
-			return avail
+			return avail, "vm_stat fallback (free + inactive + purgeable pages)"
+		}
+	case "linux":
+		if avail := availableFromMeminfo(); avail > 0 {
+			return avail, "/proc/meminfo MemAvailable fallback"
 		}
 	}
-	return totalGB * 0.8
+	return totalGB * 0.8, "estimate: 80% of total RAM (OS reported none available)"
+}
+
+func availableFromMeminfo() float64 {
+	out, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	return parseMeminfo(out)
+}
+
+// parseMeminfo returns the MemAvailable figure of a /proc/meminfo body in GB, or 0 when the line
+// is missing (kernels before 3.14) or not in the kB (KiB) the kernel reports it in.
+func parseMeminfo(out []byte) float64 {
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		rest, ok := strings.CutPrefix(sc.Text(), "MemAvailable:")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) != 2 || !strings.EqualFold(fields[1], "kB") {
+			return 0
+		}
+		kb, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return 0
+		}
+		return float64(kb*1024) / float64(gb)
+	}
+	return 0
 }
 
 func availableFromVMStat() float64 {
@@ -167,6 +462,11 @@ func availableFromVMStat() float64 {
 	if err != nil {
 		return 0
 	}
+	return parseVMStat(out)
+}
+
+// parseVMStat returns available memory in GB (free + inactive + purgeable pages) from vm_stat output.
+func parseVMStat(out []byte) float64 {
 	var pageSize uint64 = 16384
 	var free, inactive, purgeable uint64
 	sc := bufio.NewScanner(bytes.NewReader(out))
@@ -199,15 +499,32 @@ func availableFromVMStat() float64 {
 	return float64(avail) / float64(gb)
 }
 
-func detectAllGPUs(totalRAMGB, availableRAMGB float64, cpuName string) []GpuInfo {
-	var gpus []GpuInfo
-	gpus = append(gpus, detectNvidiaGPUs()...)
-	if amd := detectAMDROCM(); amd != nil {
-		gpus = append(gpus, *amd)
-	} else if amd := detectAMDSysfs(); amd != nil {
+func detectAllGPUs(totalRAMGB, availableRAMGB float64, cpuName string) (gpus []GpuInfo, failures []Warning) {
+	probeSafely("nvidia-smi", &failures, func() error {
+		nvidia, err := probeNvidia()
+		gpus = append(gpus, nvidia...)
+		return err
+	})
+	var amd *GpuInfo
+	probeSafely("AMD ROCm", &failures, func() (err error) {
+		amd, err = probeAMDROCm()
+		return err
+	})
+	if amd == nil {
+		probeSafely("AMD sysfs", &failures, func() (err error) {
+			amd, err = probeAMDSysfs()
+			return err
+		})
+	}
+	if amd != nil {
 		gpus = append(gpus, *amd)
 	}
-	for _, wmi := range detectWindowsGPU() {
+	var windows []GpuInfo
+	probeSafely("Windows", &failures, func() (err error) {
+		windows, err = probeWindows()
+		return err
+	})
+	for _, wmi := range windows {
 		dup := false
 		for _, e := range gpus {
 			el, wl := strings.ToLower(e.Name), strings.ToLower(wmi.Name)
@@ -220,7 +537,13 @@ func detectAllGPUs(totalRAMGB, availableRAMGB float64, cpuName string) []GpuInfo
 			gpus = append(gpus, wmi)
 		}
 	}
-	if found, vramGB := detectIntelGPU(); found {
+	var intelFound bool
+	var intelVRAM *float64
+	probeSafely("Intel", &failures, func() (err error) {
+		intelFound, intelVRAM, err = probeIntel()
+		return err
+	})
+	if intelFound {
 		hasIntel := false
 		for _, g := range gpus {
 			if strings.Contains(strings.ToLower(g.Name), "intel") {
@@ -230,96 +553,475 @@ func detectAllGPUs(totalRAMGB, availableRAMGB float64, cpuName string) []GpuInfo
 		}
 		if !hasIntel {
 			gpus = append(gpus, GpuInfo{
-				Name: "Intel Arc", VRAMGB: vramGB, Backend: BackendSycl, Count: 1,
+				Name: "Intel Arc", VRAMGB: intelVRAM, Backend: BackendSycl, Count: 1, VRAMSource: intelVRAMSource(intelVRAM),
 			})
 		}
 	}
-	if vram := detectAppleGPU(totalRAMGB, cpuName); vram > 0 {
+	probeSafely("Apple", &failures, func() error {
+		if found, err := probeApple(); !found {
+			return err
+		}
 		name := "Apple Silicon"
 		if strings.Contains(strings.ToLower(cpuName), "apple") {
 			name = cpuName
 		}
+		overrideMB := sysctlWiredLimitMB()
+		limit := appleWiredLimitGB(totalRAMGB, overrideMB)
+		vram := appleSharedVRAM(availableRAMGB, limit)
+		var note string
+		if vram < limit {
+			note = fmt.Sprintf("shared memory reduced by memory pressure (%.1f of %.1f GB GPU limit free)", vram, limit)
+		}
 		gpus = append(gpus, GpuInfo{
-			Name: name, VRAMGB: &vram, Backend: BackendMetal, Count: 1, UnifiedMemory: true,
+			Name: name, VRAMGB: &vram, Backend: BackendMetal, Count: 1, UnifiedMemory: true, Note: note,
+			VRAMSource: appleVRAMSource(overrideMB),
 		})
+		return nil
+	})
+	return gpus, failures
+}
+
+// GPU detectors run by detectAllGPUs; variables so tests can inject a misbehaving probe.
+var (
+	probeNvidia   = detectNvidiaGPUs
+	probeAMDROCm  = detectAMDROCM
+	probeAMDSysfs = detectAMDSysfs
+	probeWindows  = detectWindowsGPU
+	probeIntel    = detectIntelGPU
+	probeApple    = detectAppleGPU
+)
+
+// probeSafely runs one GPU probe, turning its error or a panic (e.g. a parser tripping over
+// unexpected tool output) into a failure note so the other probes still run. A panicking probe
+// contributes no GPUs.
+func probeSafely(name string, failures *[]Warning, probe func() error) {
+	defer func() {
+		if r := recover(); r != nil {
+			*failures = append(*failures, Warning{Code: WarnGPUProbeFailed, Message: fmt.Sprintf("%s GPU probe failed (%v): skipped", name, r)})
+		}
+	}()
+	if err := probe(); err != nil {
+		*failures = append(*failures, Warning{Code: WarnGPUProbeFailed, Message: fmt.Sprintf("%s GPU probe failed (%v): skipped", name, err)})
 	}
-	return gpus
 }
 
-func detectNvidiaGPUs() []GpuInfo {
-	cmd := exec.Command("nvidia-smi", "--query-gpu=memory.total,name", "--format=csv,noheader,nounits")
+// toolError is the error a probe reports when running tool failed, with the first line of its
+// stderr when there is one. A tool that is not installed is no error: the probe finds nothing.
+func toolError(tool string, err error) error {
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if msg, _, _ := strings.Cut(strings.TrimSpace(string(exitErr.Stderr)), "\n"); msg != "" {
+			return fmt.Errorf("%s: %w: %s", tool, err, msg)
+		}
+	}
+	return fmt.Errorf("%s: %w", tool, err)
+}
+
+// sysfsDRM is where Linux exposes DRM devices; a variable so tests can point it at a fixture.
+var sysfsDRM = "/sys/class/drm"
+
+// inWSL reports whether detection should take the WSL path; a variable so tests can force it.
+var inWSL = IsRunningInWSL
+
+// wslLibDir holds the CUDA-on-WSL driver libraries and nvidia-smi, which are often not on PATH.
+const wslLibDir = "/usr/lib/wsl/lib"
+
+// wslPassthroughNote marks GPUs reached through WSL2 GPU passthrough.
+const wslPassthroughNote = "WSL2 GPU passthrough (driver from " + wslLibDir + ")"
+
+// nvidiaSMICommand builds an nvidia-smi invocation. Under WSL it falls back to the copy in
+// wslLibDir when nvidia-smi is not on PATH, and adds wslLibDir to LD_LIBRARY_PATH so it finds NVML.
+func nvidiaSMICommand(ctx context.Context, args ...string) *exec.Cmd {
+	if !inWSL() {
+		return exec.CommandContext(ctx, "nvidia-smi", args...)
+	}
+	bin := "nvidia-smi"
+	if _, err := exec.LookPath(bin); err != nil {
+		bin = filepath.Join(wslLibDir, "nvidia-smi")
+	}
+	cmd := exec.CommandContext(ctx, bin, args...)
+	libPath := wslLibDir
+	if cur := os.Getenv("LD_LIBRARY_PATH"); cur != "" {
+		libPath += ":" + cur
+	}
+	cmd.Env = append(os.Environ(), "LD_LIBRARY_PATH="+libPath)
+	return cmd
+}
+
+func detectNvidiaGPUs() ([]GpuInfo, error) {
+	cmd := nvidiaSMICommand(context.Background(), "--query-gpu=index,uuid,memory.total,name", "--format=csv,noheader,nounits")
 	out, err := cmd.Output()
 	if err != nil {
-		return nil
+		return nil, toolError("nvidia-smi", err)
 	}
-	var totalVRAMMB float64
-	var count uint32
-	var firstName string
+	var notes []string
+	if inWSL() {
+		notes = append(notes, wslPassthroughNote)
+	}
+	devs := parseNvidiaDevices(out)
+	// A separate query: nvidia-smi before driver 510 rejects compute_cap, failing the whole query.
+	capCmd := nvidiaSMICommand(context.Background(), "--query-gpu=index,compute_cap,driver_version", "--format=csv,noheader,nounits")
+	if capOut, err := capCmd.Output(); err == nil {
+		applyNvidiaCapabilities(devs, capOut)
+	}
+	freeCmd := nvidiaSMICommand(context.Background(), "--query-gpu=index,memory.free", "--format=csv,noheader,nounits")
+	if freeOut, err := freeCmd.Output(); err == nil {
+		applyNvidiaFreeMemory(devs, freeOut)
+	}
+	// Also separate: most consumer cards have no ECC mode to report.
+	eccCmd := nvidiaSMICommand(context.Background(), "--query-gpu=index,ecc.mode.current", "--format=csv,noheader,nounits")
+	if eccOut, err := eccCmd.Output(); err == nil {
+		applyNvidiaECC(devs, eccOut)
+	}
+	devs, maskNotes := visibleNvidiaDevices(devs)
+	notes = append(notes, maskNotes...)
+	if len(devs) == 0 {
+		return nil, nil
+	}
+	return nvidiaGPUInfos(devs, strings.Join(notes, "; ")), nil
+}
+
+// nvidiaGPUInfos returns one GpuInfo per distinct card model, in nvidia-smi order: identical
+// cards (same name and memory) are combined into one entry with a Count, while different cards
+// keep their own name and VRAM, so an RTX 4090 plus an RTX 3060 are reported separately.
+func nvidiaGPUInfos(devs []nvidiaDevice, note string) []GpuInfo {
+	type model struct {
+		name   string
+		vramMB float64
+	}
+	var order []model
+	groups := make(map[model][]nvidiaDevice)
+	for _, d := range devs {
+		k := model{d.name, d.vramMB}
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], d)
+	}
+	out := make([]GpuInfo, 0, len(order))
+	for _, k := range order {
+		out = append(out, nvidiaGPUInfo(groups[k], note))
+	}
+	return out
+}
+
+// applyNvidiaCapabilities fills in each device's compute capability and driver version from
+// `nvidia-smi --query-gpu=index,compute_cap,driver_version` output, matching lines by index.
+func applyNvidiaCapabilities(devs []nvidiaDevice, out []byte) {
 	sc := bufio.NewScanner(bytes.NewReader(out))
 	for sc.Scan() {
-		line := strings.TrimSpace(sc.Text())
-		if line == "" {
+		parts := splitSMIFields(sc.Text(), -1)
+		if len(parts) != 3 {
 			continue
 		}
-		parts := strings.SplitN(line, ",", 2)
-		if len(parts) < 1 {
+		idx, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil {
 			continue
 		}
-		var vramMB float64
-		if _, err := fmt.Sscanf(strings.TrimSpace(parts[0]), "%f", &vramMB); err != nil {
+		for i := range devs {
+			if devs[i].index == idx {
+				devs[i].computeCap = strings.Replace(strings.TrimSpace(parts[1]), ",", ".", 1)
+				devs[i].driver = strings.TrimSpace(parts[2])
+			}
+		}
+	}
+}
+
+// applyNvidiaFreeMemory fills in each device's free memory from `nvidia-smi
+// --query-gpu=index,memory.free` output, matching lines by index. "[N/A]" leaves it unknown.
+func applyNvidiaFreeMemory(devs []nvidiaDevice, out []byte) {
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		parts := splitSMIFields(sc.Text(), 2)
+		if len(parts) != 2 {
 			continue
 		}
-		totalVRAMMB += vramMB
-		count++
-		if firstName == "" && len(parts) > 1 {
-			firstName = strings.TrimSpace(parts[1])
+		idx, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil {
+			continue
+		}
+		freeMB, ok := parseLocaleNumber(parts[1])
+		if !ok {
+			continue
+		}
+		for i := range devs {
+			if devs[i].index == idx {
+				devs[i].freeMB = &freeMB
+			}
 		}
 	}
-	if count == 0 {
+}
+
+// applyNvidiaECC marks the devices running with ECC from `nvidia-smi
+// --query-gpu=index,ecc.mode.current` output, matching lines by index. "[N/A]" or a disabled
+// mode leaves the device as it is.
+func applyNvidiaECC(devs []nvidiaDevice, out []byte) {
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		parts := splitSMIFields(sc.Text(), 2)
+		if len(parts) != 2 || !strings.EqualFold(strings.TrimSpace(parts[1]), "Enabled") {
+			continue
+		}
+		idx, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil {
+			continue
+		}
+		for i := range devs {
+			if devs[i].index == idx {
+				devs[i].eccEnabled = true
+			}
+		}
+	}
+}
+
+// visibleNvidiaDevices applies the container runtime's NVIDIA_VISIBLE_DEVICES, then
+// CUDA_VISIBLE_DEVICES, to devs. The notes say how many were masked.
+func visibleNvidiaDevices(devs []nvidiaDevice) ([]nvidiaDevice, []string) {
+	var notes []string
+	if v, ok := os.LookupEnv("NVIDIA_VISIBLE_DEVICES"); ok {
+		visible := filterContainerDevices(devs, v)
+		if masked := len(devs) - len(visible); masked > 0 {
+			notes = append(notes, fmt.Sprintf("%d of %d GPUs not exposed to the container (NVIDIA_VISIBLE_DEVICES=%s)", masked, len(devs), v))
+		}
+		devs = visible
+	}
+	if v, ok := os.LookupEnv("CUDA_VISIBLE_DEVICES"); ok && len(devs) > 0 {
+		visible := filterVisibleDevices(devs, v)
+		if masked := len(devs) - len(visible); masked > 0 {
+			notes = append(notes, fmt.Sprintf("%d of %d GPUs masked by CUDA_VISIBLE_DEVICES=%s", masked, len(devs), v))
+		}
+		devs = visible
+	}
+	return devs, notes
+}
+
+// filterContainerDevices keeps the devices an NVIDIA_VISIBLE_DEVICES value exposes: "all", none
+// for "none", "void", or empty (as the nvidia container runtime treats them), or a list of
+// indices or UUIDs (UUID prefixes allowed), kept in nvidia-smi order. Inside the container
+// nvidia-smi already lists only the exposed GPUs, renumbered from 0, so a list naming as many
+// devices as nvidia-smi reports is taken as already applied.
+func filterContainerDevices(devs []nvidiaDevice, spec string) []nvidiaDevice {
+	spec = strings.TrimSpace(spec)
+	switch strings.ToLower(spec) {
+	case "all":
+		return devs
+	case "", "none", "void":
 		return nil
 	}
+	tokens := strings.Split(spec, ",")
+	if len(tokens) == len(devs) {
+		return devs
+	}
+	var out []nvidiaDevice
+	for _, d := range devs {
+		for _, tok := range tokens {
+			if d.matches(strings.TrimSpace(tok)) {
+				out = append(out, d)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// nvidiaGPUInfo combines identical NVIDIA devices into one GpuInfo with their total VRAM,
+// falling back to a name-based estimate when nvidia-smi reports no memory.
+func nvidiaGPUInfo(devs []nvidiaDevice, note string) GpuInfo {
+	var totalVRAMMB float64
+	firstName := devs[0].name
+	var computeCap, driver string
+	minCap := math.Inf(1)
+	freeMB, freeKnown := 0.0, true
+	eccEnabled := false
+	for _, d := range devs {
+		totalVRAMMB += d.vramMB
+		eccEnabled = eccEnabled || d.eccEnabled
+		if d.freeMB != nil {
+			freeMB += *d.freeMB
+		} else {
+			freeKnown = false
+		}
+		if c, err := strconv.ParseFloat(d.computeCap, 64); err == nil && c < minCap {
+			minCap, computeCap = c, d.computeCap
+		}
+		if driver == "" {
+			driver = d.driver
+		}
+	}
 	if firstName == "" {
 		firstName = "NVIDIA GPU"
 	}
 	vramGB := totalVRAMMB / 1024
+	source := "nvidia-smi memory.total"
 	if vramGB < 0.1 {
-		est := estimateVRAMFromName(firstName)
-		vramGB = est
+		vramGB = estimateVRAMFromName(firstName) * float64(len(devs))
+		source = nameEstimateSource("nvidia-smi reported no memory")
+		freeKnown = false
+	}
+	if eccEnabled {
+		eccNote := "ECC enabled (memory.total already excludes its overhead)"
+		if note != "" {
+			eccNote = note + "; " + eccNote
+		}
+		note = eccNote
+	}
+	var free *float64
+	if freeKnown {
+		freeGB := freeMB / 1024
+		free = &freeGB
 	}
 	var v *float64
 	if vramGB > 0 {
 		v = &vramGB
+	} else {
+		source = ""
+	}
+	return GpuInfo{
+		Name: firstName, VRAMGB: v, Backend: BackendCuda, Count: uint32(len(devs)), Note: note, VRAMSource: source,
+		ComputeCapability: computeCap, DriverVersion: driver, FreeVRAMGB: free, ECCEnabled: eccEnabled,
 	}
-	return []GpuInfo{{
-		Name: firstName, VRAMGB: v, Backend: BackendCuda, Count: count,
-	}}
 }
 
-func detectAMDROCM() *GpuInfo {
-	cmd := exec.Command("rocm-smi", "--showmeminfo", "vram")
-	out, err := cmd.Output()
-	if err != nil {
-		return nil
+// nameEstimatePrefix starts every VRAMSource set by nameEstimateSource.
+const nameEstimatePrefix = "estimate from GPU model name"
+
+// nameEstimateSource describes a VRAM value taken from the GPU-name lookup table, and why.
+func nameEstimateSource(why string) string {
+	return nameEstimatePrefix + " (" + why + ")"
+}
+
+// nvidiaDevice is one line of `nvidia-smi --query-gpu=index,uuid,memory.total,name`.
+type nvidiaDevice struct {
+	index  int
+	uuid   string
+	vramMB float64
+	name   string
+
+	computeCap, driver string   // from the optional capability query; "" when unknown
+	freeMB             *float64 // from the optional memory.free query; nil when unknown
+	eccEnabled         bool     // from the optional ECC query; false when ECC is off or unknown
+}
+
+// matches reports whether a visible-devices entry names d: its index, or a prefix of its UUID.
+func (d nvidiaDevice) matches(tok string) bool {
+	if n, err := strconv.Atoi(tok); err == nil {
+		return d.index == n
 	}
-	var totalBytes uint64
-	var gpuCount uint32
+	return tok != "" && d.uuid != "" && strings.HasPrefix(strings.ToLower(d.uuid), strings.ToLower(tok))
+}
+
+func parseNvidiaDevices(out []byte) []nvidiaDevice {
+	var devs []nvidiaDevice
 	sc := bufio.NewScanner(bytes.NewReader(out))
 	for sc.Scan() {
-		line := strings.ToLower(sc.Text())
-		if strings.Contains(line, "total") && !strings.Contains(line, "used") {
-			fields := strings.Fields(sc.Text())
-			for i := len(fields) - 1; i >= 0; i-- {
-				if n, err := strconv.ParseUint(fields[i], 10, 64); err == nil && n > 0 {
-					totalBytes += n
-					gpuCount++
-					break
-				}
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		parts := splitSMIFields(line, 4)
+		if len(parts) < 3 {
+			continue
+		}
+		idx, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil {
+			continue
+		}
+		vramMB, ok := parseLocaleNumber(parts[2])
+		if !ok {
+			continue
+		}
+		d := nvidiaDevice{index: idx, uuid: strings.TrimSpace(parts[1]), vramMB: vramMB}
+		if len(parts) > 3 {
+			d.name = strings.TrimSpace(parts[3])
+		}
+		devs = append(devs, d)
+	}
+	return devs
+}
+
+// splitSMIFields splits a line of nvidia-smi CSV output into at most n fields (all when n < 0).
+// nvidia-smi separates fields with ", ", so under a locale that prints "24,576" the comma
+// inside a number is not a field break; lines without the space split on bare commas.
+func splitSMIFields(line string, n int) []string {
+	if strings.Contains(line, ", ") {
+		return strings.SplitN(line, ", ", n)
+	}
+	return strings.SplitN(line, ",", n)
+}
+
+// parseLocaleNumber parses a number as a driver tool may print it under any locale: "24576",
+// "24.576", "24,576" or "24 576" (thousands grouped), "8,6" (decimal comma), or "1.234,5". When
+// both marks appear the later one is the decimal mark. A single mark followed by exactly three
+// digits is read as grouping, as the memory sizes parsed here are whole MiB or bytes.
+func parseLocaleNumber(s string) (float64, bool) {
+	s = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "", "'", "").Replace(strings.TrimSpace(s))
+	dot, comma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	switch {
+	case dot >= 0 && comma >= 0:
+		if dot > comma {
+			s = strings.ReplaceAll(s, ",", "")
+		} else {
+			s = strings.Replace(strings.ReplaceAll(s, ".", ""), ",", ".", 1)
+		}
+	case dot >= 0 || comma >= 0:
+		mark, at := ".", dot
+		if comma >= 0 {
+			mark, at = ",", comma
+		}
+		if strings.Count(s, mark) > 1 || len(s)-at-1 == 3 {
+			s = strings.ReplaceAll(s, mark, "")
+		} else {
+			s = strings.Replace(s, ",", ".", 1)
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil
+}
+
+// filterVisibleDevices keeps the devices named by a CUDA_VISIBLE_DEVICES-style list of indices or
+// UUIDs (UUID prefixes allowed). Like the CUDA runtime, enumeration stops at the first entry
+// that matches no device, so "0,7,1" with two GPUs exposes only device 0.
+func filterVisibleDevices(devs []nvidiaDevice, spec string) []nvidiaDevice {
+	var out []nvidiaDevice
+	seen := make(map[int]bool)
+	for _, tok := range strings.Split(spec, ",") {
+		tok = strings.TrimSpace(tok)
+		if tok == "" {
+			break
+		}
+		match := -1
+		for i, d := range devs {
+			if d.matches(tok) {
+				match = i
+				break
 			}
 		}
+		if match < 0 {
+			break
+		}
+		if !seen[match] {
+			seen[match] = true
+			out = append(out, devs[match])
+		}
 	}
-	if gpuCount == 0 {
-		gpuCount = 1
+	return out
+}
+
+func detectAMDROCM() (*GpuInfo, error) {
+	cmd := exec.Command("rocm-smi", "--showmeminfo", "vram")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, toolError("rocm-smi", err)
+	}
+	parsed := parseROCmDevices(out)
+	devs, note := visibleROCmDevices(parsed)
+	if len(parsed) > 0 && len(devs) == 0 {
+		return nil, nil // HIP_VISIBLE_DEVICES hides every GPU
 	}
+	totalBytes, usedBytes, gpuCount, usedCount := rocmMemTotals(devs)
 	name := "AMD GPU"
 	cmd2 := exec.Command("rocm-smi", "--showproductname")
 	if out2, err := cmd2.Output(); err == nil {
@@ -336,45 +1038,166 @@ func detectAMDROCM() *GpuInfo {
 			}
 		}
 	}
-	var vramGB *float64
+	var vramGB, freeGB *float64
+	var source string
 	if totalBytes > 0 {
 		v := float64(totalBytes) / float64(gb)
 		vramGB = &v
+		source = "rocm-smi --showmeminfo vram"
+		if usedCount == gpuCount && usedBytes <= totalBytes {
+			free := float64(totalBytes-usedBytes) / float64(gb)
+			freeGB = &free
+		}
 	} else {
 		est := estimateVRAMFromName(name)
 		if est > 0 {
 			vramGB = &est
+			source = nameEstimateSource("rocm-smi reported no memory")
 		}
 	}
 	return &GpuInfo{
-		Name: name, VRAMGB: vramGB, Backend: BackendRocm, Count: gpuCount,
+		Name: name, VRAMGB: vramGB, Backend: BackendRocm, Count: gpuCount, Note: note, VRAMSource: source, FreeVRAMGB: freeGB,
+	}, nil
+}
+
+// rocmDevice is one GPU's memory as `rocm-smi --showmeminfo vram` reports it.
+type rocmDevice struct {
+	index      int
+	totalBytes uint64
+	usedBytes  *uint64 // nil when not reported
+}
+
+// parseROCmDevices reads the "VRAM Total Memory (B)" and "VRAM Total Used Memory (B)" lines of
+// `rocm-smi --showmeminfo vram`, keyed by their GPU[n] prefix. Devices reporting no total are
+// dropped.
+func parseROCmDevices(out []byte) []rocmDevice {
+	var devs []rocmDevice
+	byIndex := make(map[int]int) // GPU index -> position in devs
+	used := make(map[int]uint64)
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		line := strings.ToLower(sc.Text())
+		if !strings.Contains(line, "total") {
+			continue
+		}
+		// Without a GPU[n] prefix, a total starts the next device and a used line belongs to the last.
+		idx := len(devs)
+		if strings.Contains(line, "used") {
+			idx--
+		}
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "gpu["); ok {
+			if n, _, ok := strings.Cut(rest, "]"); ok {
+				if v, err := strconv.Atoi(n); err == nil {
+					idx = v
+				}
+			}
+		}
+		fields := strings.Fields(sc.Text())
+		for i := len(fields) - 1; i >= 0; i-- {
+			v, ok := parseLocaleNumber(fields[i])
+			if !ok || v < 0 || v != math.Trunc(v) {
+				continue
+			}
+			n := uint64(v)
+			if strings.Contains(line, "used") {
+				used[idx] = n
+			} else if _, seen := byIndex[idx]; !seen && n > 0 {
+				byIndex[idx] = len(devs)
+				devs = append(devs, rocmDevice{index: idx, totalBytes: n})
+			}
+			break
+		}
+	}
+	for idx, n := range used {
+		if pos, ok := byIndex[idx]; ok {
+			devs[pos].usedBytes = &n
+		}
 	}
+	return devs
 }
 
-func detectAMDSysfs() *GpuInfo {
-	if runtime.GOOS != "linux" {
-		return nil
+// visibleROCmDevices applies HIP_VISIBLE_DEVICES to devs, like CUDA_VISIBLE_DEVICES: a list of
+// indices, cut at the first entry that matches no device. rocm-smi reports no UUIDs, so a list
+// naming devices by UUID is not applied. The note says how many were masked.
+func visibleROCmDevices(devs []rocmDevice) ([]rocmDevice, string) {
+	spec, ok := os.LookupEnv("HIP_VISIBLE_DEVICES")
+	if !ok || len(devs) == 0 {
+		return devs, ""
+	}
+	var visible []rocmDevice
+	seen := make(map[int]bool)
+	for _, tok := range strings.Split(spec, ",") {
+		tok = strings.TrimSpace(tok)
+		if tok == "" {
+			break
+		}
+		n, err := strconv.Atoi(tok)
+		if err != nil {
+			return devs, ""
+		}
+		i := slices.IndexFunc(devs, func(d rocmDevice) bool { return d.index == n })
+		if i < 0 {
+			break
+		}
+		if !seen[i] {
+			seen[i] = true
+			visible = append(visible, devs[i])
+		}
+	}
+	if masked := len(devs) - len(visible); masked > 0 {
+		return visible, fmt.Sprintf("%d of %d GPUs masked by HIP_VISIBLE_DEVICES=%s", masked, len(devs), spec)
+	}
+	return visible, ""
+}
+
+// rocmMemTotals sums devs' memory, counting the devices reporting each. gpuCount is at least 1.
+func rocmMemTotals(devs []rocmDevice) (totalBytes, usedBytes uint64, gpuCount, usedCount uint32) {
+	for _, d := range devs {
+		totalBytes += d.totalBytes
+		gpuCount++
+		if d.usedBytes != nil {
+			usedBytes += *d.usedBytes
+			usedCount++
+		}
+	}
+	if gpuCount == 0 {
+		gpuCount = 1
+	}
+	return totalBytes, usedBytes, gpuCount, usedCount
+}
+
+// detectAMDSysfs finds an AMD card in sysfs. It is skipped under WSL, where /sys/class/drm
+// holds only stub devices without VRAM information.
+func detectAMDSysfs() (*GpuInfo, error) {
+	if runtime.GOOS != "linux" || inWSL() {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(sysfsDRM)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
 	}
-	entries, err := os.ReadDir("/sys/class/drm")
 	if err != nil {
-		return nil
+		return nil, err
 	}
 	for _, e := range entries {
 		name := e.Name()
 		if !e.IsDir() || !strings.HasPrefix(name, "card") || strings.Contains(name, "-") {
 			continue
 		}
-		vendor, _ := os.ReadFile(filepath.Join("/sys/class/drm", name, "device/vendor"))
+		vendor, _ := os.ReadFile(filepath.Join(sysfsDRM, name, "device/vendor"))
 		if strings.TrimSpace(string(vendor)) != "0x1002" {
 			continue
 		}
 		var vramGB *float64
-		data, err := os.ReadFile(filepath.Join("/sys/class/drm", name, "device/mem_info_vram_total"))
+		var source string
+		vramPath := filepath.Join(sysfsDRM, name, "device/mem_info_vram_total")
+		data, err := os.ReadFile(vramPath)
 		if err == nil {
 			var bytes uint64
 			if _, err := fmt.Sscanf(strings.TrimSpace(string(data)), "%d", &bytes); err == nil && bytes > 0 {
 				v := float64(bytes) / float64(gb)
 				vramGB = &v
+				source = vramPath
 			}
 		}
 		gpuName := getAMDGpuNameLspci()
@@ -385,13 +1208,14 @@ func detectAMDSysfs() *GpuInfo {
 			est := estimateVRAMFromName(gpuName)
 			if est > 0 {
 				vramGB = &est
+				source = nameEstimateSource("sysfs reported no memory")
 			}
 		}
 		return &GpuInfo{
-			Name: gpuName, VRAMGB: vramGB, Backend: BackendVulkan, Count: 1,
-		}
+			Name: gpuName, VRAMGB: vramGB, Backend: BackendVulkan, Count: 1, VRAMSource: source,
+		}, nil
 	}
-	return nil
+	return nil, nil
 }
 
 func getAMDGpuNameLspci() string {
@@ -418,17 +1242,17 @@ func getAMDGpuNameLspci() string {
 	return ""
 }
 
-func detectWindowsGPU() []GpuInfo {
+func detectWindowsGPU() ([]GpuInfo, error) {
 	if runtime.GOOS != "windows" {
-		return nil
+		return nil, nil
 	}
 	ps := `Get-CimInstance Win32_VideoController | Select-Object Name,AdapterRAM | ForEach-Object { $_.Name + '|' + $_.AdapterRAM }`
 	cmd := exec.Command("powershell", "-NoProfile", "-Command", ps)
 	out, err := cmd.Output()
 	if err != nil {
-		return nil
+		return nil, toolError("powershell", err)
 	}
-	return parseWindowsGPUList(string(out))
+	return parseWindowsGPUList(string(out)), nil
 }
 
 func parseWindowsGPUList(text string) []GpuInfo {
@@ -446,29 +1270,35 @@ func parseWindowsGPUList(text string) []GpuInfo {
 		}
 		var rawVRAM uint64
 		if len(parts) > 1 {
-			fmt.Sscanf(strings.TrimSpace(parts[1]), "%d", &rawVRAM)
+			if v, ok := parseLocaleNumber(parts[1]); ok && v > 0 {
+				rawVRAM = uint64(v)
+			}
 		}
 		backend := inferGPUBackend(name)
-		vramGB := resolveWmiVRAM(rawVRAM, name)
+		vramGB, source := resolveWmiVRAM(rawVRAM, name)
 		gpus = append(gpus, GpuInfo{
-			Name: name, VRAMGB: vramGB, Backend: backend, Count: 1,
+			Name: name, VRAMGB: vramGB, Backend: backend, Count: 1, VRAMSource: source,
 		})
 	}
 	return gpus
 }
 
-func resolveWmiVRAM(rawBytes uint64, name string) *float64 {
+// resolveWmiVRAM returns the VRAM for a WMI adapter and its source. AdapterRAM is a 32-bit
+// field that caps at 4 GB, so larger cards known by name use the name estimate instead.
+func resolveWmiVRAM(rawBytes uint64, name string) (*float64, string) {
 	vramGB := float64(rawBytes) / float64(gb)
+	source := "WMI Win32_VideoController AdapterRAM"
 	est := estimateVRAMFromName(name)
 	if vramGB < 0.1 || (vramGB <= 4.1 && est > 4.1) {
 		if est > 0 {
 			vramGB = est
+			source = nameEstimateSource("WMI AdapterRAM missing or capped at 4 GB")
 		}
 	}
 	if vramGB > 0 {
-		return &vramGB
+		return &vramGB, source
 	}
-	return nil
+	return nil, ""
 }
 
 func inferGPUBackend(name string) GpuBackend {
@@ -485,15 +1315,17 @@ func inferGPUBackend(name string) GpuBackend {
 	return BackendVulkan
 }
 
-func detectIntelGPU() (found bool, vramGB *float64) {
-	if runtime.GOOS == "linux" {
-		entries, _ := os.ReadDir("/sys/class/drm")
+// detectIntelGPU finds an Intel Arc card via sysfs or lspci on Linux (not under WSL, whose
+// sysfs and PCI listings are stubs). Only a failing lspci is an error; sysfs is read best-effort.
+func detectIntelGPU() (found bool, vramGB *float64, err error) {
+	if runtime.GOOS == "linux" && !inWSL() {
+		entries, _ := os.ReadDir(sysfsDRM)
 		for _, e := range entries {
 			if !e.IsDir() {
 				continue
 			}
 			name := e.Name()
-			devicePath := filepath.Join("/sys/class/drm", name, "device")
+			devicePath := filepath.Join(sysfsDRM, name, "device")
 			vendor, _ := os.ReadFile(filepath.Join(devicePath, "vendor"))
 			if strings.TrimSpace(string(vendor)) != "0x8086" {
 				continue
@@ -503,39 +1335,86 @@ func detectIntelGPU() (found bool, vramGB *float64) {
 				var bytes uint64
 				if _, err := fmt.Sscanf(strings.TrimSpace(string(data)), "%d", &bytes); err == nil && bytes > 0 {
 					v := float64(bytes) / float64(gb)
-					return true, &v
+					return true, &v, nil
 				}
 			}
 		}
 		out, err := exec.Command("lspci").Output()
-		if err == nil {
-			for _, line := range strings.Split(string(out), "\n") {
-				l := strings.ToLower(line)
-				if strings.Contains(l, "intel") && strings.Contains(l, "arc") {
-					return true, nil
-				}
+		if err != nil {
+			return false, nil, toolError("lspci", err)
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			l := strings.ToLower(line)
+			if strings.Contains(l, "intel") && strings.Contains(l, "arc") {
+				return true, nil, nil
 			}
 		}
 	}
-	return false, nil
+	return false, nil, nil
 }
 
-func detectAppleGPU(totalRAMGB float64, cpuName string) float64 {
+func detectAppleGPU() (bool, error) {
 	if runtime.GOOS != "darwin" {
-		return 0
+		return false, nil
 	}
 	out, err := exec.Command("system_profiler", "SPDisplaysDataType").Output()
 	if err != nil {
-		return 0
+		return false, toolError("system_profiler", err)
 	}
 	text := string(out)
 	for _, line := range strings.Split(text, "\n") {
 		l := strings.ToLower(line)
 		if strings.Contains(l, "apple m") || strings.Contains(l, "apple gpu") {
-			return totalRAMGB
+			return true, nil
 		}
 	}
-	return 0
+	return false, nil
+}
+
+// intelVRAMSource describes where detectIntelGPU's VRAM came from (nil when only lspci found the card).
+func intelVRAMSource(vramGB *float64) string {
+	if vramGB == nil {
+		return ""
+	}
+	return "/sys/class/drm/*/device/mem_info_vram_total"
+}
+
+// appleVRAMSource describes how the Metal GPU's usable unified memory was derived.
+func appleVRAMSource(overrideMB uint64) string {
+	if overrideMB > 0 {
+		return "available RAM capped by sysctl iogpu.wired_limit_mb"
+	}
+	return "available RAM capped by the macOS default GPU wired limit (2/3 of RAM, 3/4 above 36 GB)"
+}
+
+// sysctlWiredLimitMB returns iogpu.wired_limit_mb (0 when unset or unreadable, meaning the macOS default).
+func sysctlWiredLimitMB() uint64 {
+	out, err := exec.Command("sysctl", "-n", "iogpu.wired_limit_mb").Output()
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.ParseUint(strings.TrimSpace(string(out)), 10, 64)
+	return n
+}
+
+// appleWiredLimitGB returns how much unified memory the GPU may wire: the iogpu.wired_limit_mb
+// override when set, else the macOS default of about 2/3 of RAM (3/4 above 36 GB).
+func appleWiredLimitGB(totalRAMGB float64, overrideMB uint64) float64 {
+	if overrideMB > 0 {
+		return math.Min(float64(overrideMB)/1024, totalRAMGB)
+	}
+	if totalRAMGB > 36 {
+		return totalRAMGB * 0.75
+	}
+	return totalRAMGB * 2 / 3
+}
+
+// appleSharedVRAM is the unified memory the GPU can use right now: what is available, capped by the wired limit.
+func appleSharedVRAM(availableRAMGB, wiredLimitGB float64) float64 {
+	if availableRAMGB <= 0 {
+		return wiredLimitGB
+	}
+	return math.Min(availableRAMGB, wiredLimitGB)
 }
 
 var (
@@ -568,54 +1447,144 @@ func IsRunningInWSL() bool {
 func estimateVRAMFromName(name string) float64 {
 	l := strings.ToLower(name)
 	// NVIDIA RTX 50
-	if strings.Contains(l, "5090") { return 32 }
-	if strings.Contains(l, "5080") { return 16 }
-	if strings.Contains(l, "5070 ti") { return 16 }
-	if strings.Contains(l, "5070") { return 12 }
-	if strings.Contains(l, "5060 ti") { return 16 }
-	if strings.Contains(l, "5060") { return 8 }
+	if strings.Contains(l, "5090") {
+		return 32
+	}
+	if strings.Contains(l, "5080") {
+		return 16
+	}
+	if strings.Contains(l, "5070 ti") {
+		return 16
+	}
+	if strings.Contains(l, "5070") {
+		return 12
+	}
+	if strings.Contains(l, "5060 ti") {
+		return 16
+	}
+	if strings.Contains(l, "5060") {
+		return 8
+	}
 	// RTX 40
-	if strings.Contains(l, "4090") { return 24 }
-	if strings.Contains(l, "4080") { return 16 }
-	if strings.Contains(l, "4070 ti") { return 12 }
-	if strings.Contains(l, "4070") { return 12 }
-	if strings.Contains(l, "4060 ti") { return 16 }
-	if strings.Contains(l, "4060") { return 8 }
+	if strings.Contains(l, "4090") {
+		return 24
+	}
+	if strings.Contains(l, "4080") {
+		return 16
+	}
+	if strings.Contains(l, "4070 ti") {
+		return 12
+	}
+	if strings.Contains(l, "4070") {
+		return 12
+	}
+	if strings.Contains(l, "4060 ti") {
+		return 16
+	}
+	if strings.Contains(l, "4060") {
+		return 8
+	}
 	// RTX 30
-	if strings.Contains(l, "3090") { return 24 }
-	if strings.Contains(l, "3080 ti") { return 12 }
-	if strings.Contains(l, "3080") { return 10 }
-	if strings.Contains(l, "3070") { return 8 }
-	if strings.Contains(l, "3060 ti") { return 8 }
-	if strings.Contains(l, "3060") { return 12 }
+	if strings.Contains(l, "3090") {
+		return 24
+	}
+	if strings.Contains(l, "3080 ti") {
+		return 12
+	}
+	if strings.Contains(l, "3080") {
+		return 10
+	}
+	if strings.Contains(l, "3070") {
+		return 8
+	}
+	if strings.Contains(l, "3060 ti") {
+		return 8
+	}
+	if strings.Contains(l, "3060") {
+		return 12
+	}
 	// Data center
-	if strings.Contains(l, "h100") { return 80 }
-	if strings.Contains(l, "a100") { return 80 }
-	if strings.Contains(l, "l40") { return 48 }
-	if strings.Contains(l, "a10") { return 24 }
-	if strings.Contains(l, "t4") { return 16 }
+	if strings.Contains(l, "h100") {
+		return 80
+	}
+	if strings.Contains(l, "a100") {
+		return 80
+	}
+	if strings.Contains(l, "l40") {
+		return 48
+	}
+	if strings.Contains(l, "a10") {
+		return 24
+	}
+	if strings.Contains(l, "t4") {
+		return 16
+	}
 	// AMD RX 9000/7000/6000/5000
-	if strings.Contains(l, "9070 xt") { return 16 }
-	if strings.Contains(l, "9070") { return 12 }
-	if strings.Contains(l, "7900 xtx") { return 24 }
-	if strings.Contains(l, "7900") { return 20 }
-	if strings.Contains(l, "7800") { return 16 }
-	if strings.Contains(l, "7700") { return 12 }
-	if strings.Contains(l, "7600") { return 8 }
-	if strings.Contains(l, "6950") { return 16 }
-	if strings.Contains(l, "6900") { return 16 }
-	if strings.Contains(l, "6800") { return 16 }
-	if strings.Contains(l, "6750") { return 12 }
-	if strings.Contains(l, "6700") { return 12 }
-	if strings.Contains(l, "6650") { return 8 }
-	if strings.Contains(l, "6600") { return 8 }
-	if strings.Contains(l, "6500") { return 4 }
-	if strings.Contains(l, "5700 xt") { return 8 }
-	if strings.Contains(l, "5700") { return 8 }
-	if strings.Contains(l, "5600") { return 6 }
-	if strings.Contains(l, "5500") { return 4 }
-	if strings.Contains(l, "rtx") { return 8 }
-	if strings.Contains(l, "gtx") { return 4 }
-	if strings.Contains(l, "rx ") || strings.Contains(l, "radeon") { return 8 }
+	if strings.Contains(l, "9070 xt") {
+		return 16
+	}
+	if strings.Contains(l, "9070") {
+		return 12
+	}
+	if strings.Contains(l, "7900 xtx") {
+		return 24
+	}
+	if strings.Contains(l, "7900") {
+		return 20
+	}
+	if strings.Contains(l, "7800") {
+		return 16
+	}
+	if strings.Contains(l, "7700") {
+		return 12
+	}
+	if strings.Contains(l, "7600") {
+		return 8
+	}
+	if strings.Contains(l, "6950") {
+		return 16
+	}
+	if strings.Contains(l, "6900") {
+		return 16
+	}
+	if strings.Contains(l, "6800") {
+		return 16
+	}
+	if strings.Contains(l, "6750") {
+		return 12
+	}
+	if strings.Contains(l, "6700") {
+		return 12
+	}
+	if strings.Contains(l, "6650") {
+		return 8
+	}
+	if strings.Contains(l, "6600") {
+		return 8
+	}
+	if strings.Contains(l, "6500") {
+		return 4
+	}
+	if strings.Contains(l, "5700 xt") {
+		return 8
+	}
+	if strings.Contains(l, "5700") {
+		return 8
+	}
+	if strings.Contains(l, "5600") {
+		return 6
+	}
+	if strings.Contains(l, "5500") {
+		return 4
+	}
+	if strings.Contains(l, "rtx") {
+		return 8
+	}
+	if strings.Contains(l, "gtx") {
+		return 4
+	}
+	if strings.Contains(l, "rx ") || strings.Contains(l, "radeon") {
+		return 8
+	}
 	return 0
 }
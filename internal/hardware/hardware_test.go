@@ -1,8 +1,22 @@
 package hardware
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
 	"runtime"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/mem"
 )
 
 func TestParseWindowsGPUList(t *testing.T) {
@@ -30,21 +44,123 @@ func TestParseWindowsGPUList(t *testing.T) {
 
 func TestResolveWmiVRAM(t *testing.T) {
 	// rawBytes small but name known -> use estimate
-	got := resolveWmiVRAM(0, "NVIDIA GeForce RTX 4090")
+	got, src := resolveWmiVRAM(0, "NVIDIA GeForce RTX 4090")
 	if got == nil {
 		t.Fatal("resolveWmiVRAM(0, RTX 4090) = nil")
 	}
 	if *got != 24 {
 		t.Errorf("resolveWmiVRAM(0, RTX 4090) = %v, want 24", *got)
 	}
+	if !strings.HasPrefix(src, "estimate from GPU model name") {
+		t.Errorf("resolveWmiVRAM(0, RTX 4090) source = %q, want a name estimate", src)
+	}
 	// rawBytes large -> use raw
-	got2 := resolveWmiVRAM(32*1024*1024*1024, "Unknown GPU")
+	got2, src2 := resolveWmiVRAM(32*1024*1024*1024, "Unknown GPU")
 	if got2 == nil {
 		t.Fatal("resolveWmiVRAM(32GB, Unknown) = nil")
 	}
 	if *got2 != 32 {
 		t.Errorf("resolveWmiVRAM(32GB, Unknown) = %v, want 32", *got2)
 	}
+	if src2 != "WMI Win32_VideoController AdapterRAM" {
+		t.Errorf("resolveWmiVRAM(32GB, Unknown) source = %q", src2)
+	}
+}
+
+func TestDetectionSources(t *testing.T) {
+	smi := nvidiaGPUInfo(parseNvidiaDevices([]byte("0, GPU-a, 16384, NVIDIA GeForce RTX 4080\n")), "")
+	noMem := nvidiaGPUInfo(parseNvidiaDevices([]byte("0, GPU-b, 0, NVIDIA GeForce RTX 3060\n")), "")
+	sources := map[string]string{FieldTotalRAM: memSource("linux", "total"), FieldAvailableRAM: memSource("linux", "available")}
+	specs := assembleSpecs(64, 48, 16, 1, "Test CPU", []GpuInfo{noMem, smi}, sources)
+
+	if got := specs.Gpus[0].VRAMSource; got != "nvidia-smi memory.total" {
+		t.Errorf("primary VRAM source = %q, want nvidia-smi memory.total", got)
+	}
+	if got := specs.Gpus[1].VRAMSource; got != "estimate from GPU model name (nvidia-smi reported no memory)" {
+		t.Errorf("secondary VRAM source = %q", got)
+	}
+	for field, want := range map[string]string{
+		FieldTotalRAM:     "/proc/meminfo MemTotal",
+		FieldAvailableRAM: "/proc/meminfo MemAvailable",
+		FieldBackend:      "primary GPU NVIDIA GeForce RTX 4080",
+	} {
+		if got := specs.Source(field); got != want {
+			t.Errorf("Source(%s) = %q, want %q", field, got, want)
+		}
+	}
+	if got := assembleSpecs(8, 4, 4, 0, "Test CPU", nil, map[string]string{}).Source(FieldBackend); got != "CPU architecture (no GPU detected)" {
+		t.Errorf("CPU-only Source(backend) = %q", got)
+	}
+}
+
+func TestSanitize_InconsistentReadings(t *testing.T) {
+	huge, negative, unified := 81920.0, -1.0, 96.0
+	gpus := []GpuInfo{
+		{Name: "Bad Parse", VRAMGB: &huge, Backend: BackendCuda, Count: 1},
+		{Name: "Negative", VRAMGB: &negative, Backend: BackendVulkan, Count: 1},
+	}
+	specs := assembleSpecs(16, 40, 8, 0, "Test CPU", gpus, map[string]string{})
+	if specs.AvailableRAMGB != 16 {
+		t.Errorf("AvailableRAMGB = %v, want clamped to the 16 GB total", specs.AvailableRAMGB)
+	}
+	for _, g := range specs.Gpus {
+		if g.VRAMGB != nil {
+			t.Errorf("%s VRAM = %v, want unknown", g.Name, *g.VRAMGB)
+		}
+	}
+	if specs.GpuVRAMGB != nil {
+		t.Errorf("GpuVRAMGB = %v, want unknown like the primary GPU", *specs.GpuVRAMGB)
+	}
+	if len(specs.Warnings) != 3 || !strings.Contains(specs.Warnings[0].Message, "clamped to total") {
+		t.Errorf("Warnings = %q, want one per correction", specs.Warnings)
+	}
+
+	apple := assembleSpecs(64, 50, 12, 0, "Apple M3 Max", []GpuInfo{{Name: "Apple M3 Max", VRAMGB: &unified, Backend: BackendMetal, Count: 1, UnifiedMemory: true}}, map[string]string{})
+	if *apple.GpuVRAMGB != 64 || len(apple.Warnings) != 1 {
+		t.Errorf("unified VRAM = %v, warnings %q; want clamped to 64 GB with a warning", *apple.GpuVRAMGB, apple.Warnings)
+	}
+
+	sane := assembleSpecs(64, 48, 16, 0, "Test CPU", nil, map[string]string{})
+	if sane.AvailableRAMGB != 48 || len(sane.Warnings) != 0 {
+		t.Errorf("consistent specs changed: available %v, warnings %q", sane.AvailableRAMGB, sane.Warnings)
+	}
+
+	remote, err := SpecsFromJSON([]byte(`{"total_ram_gb": 8, "available_ram_gb": 12, "backend": "CPU (x86)"}`))
+	if err != nil {
+		t.Fatalf("SpecsFromJSON: %v", err)
+	}
+	if remote.AvailableRAMGB != 8 || len(remote.Warnings) != 1 {
+		t.Errorf("remote specs: available %v, warnings %q; want clamped to 8 GB", remote.AvailableRAMGB, remote.Warnings)
+	}
+}
+
+func TestDetectionWarningCodes(t *testing.T) {
+	noMem := nvidiaGPUInfo(parseNvidiaDevices([]byte("0, GPU-b, 0, NVIDIA GeForce RTX 3060\n")), "")
+	specs := assembleSpecs(64, 48, 16, 0, "Test CPU", []GpuInfo{noMem}, map[string]string{})
+	if !specs.HasWarning(WarnVRAMFromName) {
+		t.Errorf("name-estimated VRAM: warnings = %v, want %s", specs.Warnings, WarnVRAMFromName)
+	}
+
+	sources := map[string]string{}
+	total, avail, warnings := readRAM(&mem.VirtualMemoryStat{Total: 16 * gb}, sources)
+	if total != 16 || avail <= 0 || sources[FieldAvailableRAM] == "" {
+		t.Errorf("readRAM fallback = %v / %v GB, source %q", total, avail, sources[FieldAvailableRAM])
+	}
+	if len(warnings) != 1 || warnings[0].Code != WarnRAMFallback {
+		t.Errorf("RAM fallback warnings = %v, want one %s", warnings, WarnRAMFallback)
+	}
+	if _, _, warnings := readRAM(&mem.VirtualMemoryStat{Total: 16 * gb, Available: 8 * gb}, map[string]string{}); len(warnings) != 0 {
+		t.Errorf("reported RAM: warnings = %v, want none", warnings)
+	}
+
+	// Older hosts serve plain-string warnings; they still parse, with the catch-all code.
+	remote, err := SpecsFromJSON([]byte(`{"total_ram_gb": 8, "available_ram_gb": 4, "backend": "CPU (x86)", "warnings": ["legacy note"]}`))
+	if err != nil {
+		t.Fatalf("SpecsFromJSON: %v", err)
+	}
+	if len(remote.Warnings) != 1 || remote.Warnings[0] != (Warning{Code: WarnOther, Message: "legacy note"}) {
+		t.Errorf("remote warnings = %v, want the legacy string as %s", remote.Warnings, WarnOther)
+	}
 }
 
 func TestInferGPUBackend(t *testing.T) {
@@ -109,3 +225,697 @@ func TestBackendCPU(t *testing.T) {
 		}
 	}
 }
+
+func TestSpecsFromJSON(t *testing.T) {
+	bare := `{"total_ram_gb": 16, "available_ram_gb": 12, "cpu_cores": 8, "cpu_name": "X", "backend": "CPU (ARM)", "gpus": []}`
+	specs, err := SpecsFromJSON([]byte(bare))
+	if err != nil {
+		t.Fatalf("SpecsFromJSON(bare): %v", err)
+	}
+	if specs.Backend != BackendCpuArm || specs.TotalRAMGB != 16 {
+		t.Errorf("specs = %+v", specs)
+	}
+	if _, err := SpecsFromJSON([]byte(`{"system": {}}`)); err == nil {
+		t.Error("expected error for missing total_ram_gb")
+	}
+	if _, err := SpecsFromJSON([]byte(`not json`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestDetectRemote_RejectsOptionLikeHost(t *testing.T) {
+	dir := t.TempDir()
+	_, err := DetectRemote("-oProxyCommand=touch " + filepath.Join(dir, "pwned"))
+	if err == nil || !strings.Contains(err.Error(), "must not start with '-'") {
+		t.Fatalf("err = %v, want the host rejected", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, "pwned")); statErr == nil {
+		t.Error("the host was passed to ssh as an option")
+	}
+}
+
+func TestParseBackend(t *testing.T) {
+	for b := BackendCuda; b <= BackendCpuX86; b++ {
+		got, ok := ParseBackend(b.String())
+		if !ok || got != b {
+			t.Errorf("ParseBackend(%q) = %v, %v", b.String(), got, ok)
+		}
+	}
+	if _, ok := ParseBackend("bogus"); ok {
+		t.Error("ParseBackend(bogus) should not be ok")
+	}
+}
+
+func TestFilterVisibleDevices(t *testing.T) {
+	out := []byte("0, GPU-aaaa1111, 24576, NVIDIA GeForce RTX 4090\n1, GPU-bbbb2222, 24576, NVIDIA GeForce RTX 4090\n2, GPU-cccc3333, 12288, NVIDIA GeForce RTX 3060\n")
+	devs := parseNvidiaDevices(out)
+	if len(devs) != 3 {
+		t.Fatalf("parseNvidiaDevices: got %d devices, want 3", len(devs))
+	}
+	tests := []struct {
+		spec string
+		want []int
+	}{
+		{"0,1,2", []int{0, 1, 2}},
+		{"2", []int{2}},
+		{"1,0", []int{1, 0}},
+		{"GPU-cccc", []int{2}},
+		{"0,7,1", []int{0}},
+		{"", nil},
+		{"-1", nil},
+	}
+	for _, tt := range tests {
+		got := filterVisibleDevices(devs, tt.spec)
+		var idx []int
+		for _, d := range got {
+			idx = append(idx, d.index)
+		}
+		if len(idx) != len(tt.want) {
+			t.Errorf("filterVisibleDevices(%q) = %v, want %v", tt.spec, idx, tt.want)
+			continue
+		}
+		for i := range idx {
+			if idx[i] != tt.want[i] {
+				t.Errorf("filterVisibleDevices(%q) = %v, want %v", tt.spec, idx, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestVisibleNvidiaDevices_IgnoresHIP(t *testing.T) {
+	devs := parseNvidiaDevices([]byte("0, GPU-a, 8192, NVIDIA GeForce RTX 4060\n1, GPU-b, 8192, NVIDIA GeForce RTX 4060\n"))
+	for _, name := range []string{"NVIDIA_VISIBLE_DEVICES", "CUDA_VISIBLE_DEVICES"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	t.Setenv("HIP_VISIBLE_DEVICES", "1")
+	if got, notes := visibleNvidiaDevices(devs); len(got) != 2 || len(notes) != 0 {
+		t.Errorf("HIP_VISIBLE_DEVICES=1 on NVIDIA: %d devices, notes %v; want both, unmasked", len(got), notes)
+	}
+}
+
+func TestVisibleROCmDevices(t *testing.T) {
+	rocm := []byte(`GPU[0]		: VRAM Total Memory (B): 17163091968
+GPU[0]		: VRAM Total Used Memory (B): 2147483648
+GPU[1]		: VRAM Total Memory (B): 25753026560
+GPU[1]		: VRAM Total Used Memory (B): 1073741824
+`)
+	devs := parseROCmDevices(rocm)
+	if len(devs) != 2 || devs[1].index != 1 || devs[1].usedBytes == nil || *devs[1].usedBytes != 1073741824 {
+		t.Fatalf("parseROCmDevices = %+v, want two devices with their used memory", devs)
+	}
+	t.Setenv("HIP_VISIBLE_DEVICES", "")
+	os.Unsetenv("HIP_VISIBLE_DEVICES")
+	t.Setenv("CUDA_VISIBLE_DEVICES", "0")
+	if got, note := visibleROCmDevices(devs); len(got) != 2 || note != "" {
+		t.Errorf("CUDA_VISIBLE_DEVICES=0 on ROCm: %d devices, note %q; want both, unmasked", len(got), note)
+	}
+	for _, tt := range []struct {
+		spec string
+		want []int
+	}{
+		{"1", []int{1}},
+		{"1,0", []int{1, 0}},
+		{"0,7,1", []int{0}}, // cut at the first unknown index, like CUDA
+		{"", nil},
+		{"GPU-1234", []int{0, 1}}, // UUIDs cannot be matched from rocm-smi: not applied
+	} {
+		t.Setenv("HIP_VISIBLE_DEVICES", tt.spec)
+		got, note := visibleROCmDevices(devs)
+		var idx []int
+		for _, d := range got {
+			idx = append(idx, d.index)
+		}
+		if !slices.Equal(idx, tt.want) {
+			t.Errorf("HIP_VISIBLE_DEVICES=%q: devices %v, want %v", tt.spec, idx, tt.want)
+		}
+		if (note != "") != (len(tt.want) < len(devs)) {
+			t.Errorf("HIP_VISIBLE_DEVICES=%q: note %q", tt.spec, note)
+		}
+	}
+	t.Setenv("HIP_VISIBLE_DEVICES", "1")
+	visible, _ := visibleROCmDevices(devs)
+	if total, used, count, usedCount := rocmMemTotals(visible); total != 25753026560 || used != 1073741824 || count != 1 || usedCount != 1 {
+		t.Errorf("rocmMemTotals(GPU 1) = %d, %d, %d, %d; want only GPU 1", total, used, count, usedCount)
+	}
+}
+
+const vmStatIdle = `Mach Virtual Memory Statistics: (page size of 16384 bytes)
+Pages free:                             1500000.
+Pages active:                            200000.
+Pages inactive:                          300000.
+Pages speculative:                        20000.
+Pages purgeable:                          50000.
+`
+
+const vmStatPressure = `Mach Virtual Memory Statistics: (page size of 16384 bytes)
+Pages free:                               20000.
+Pages active:                           1400000.
+Pages inactive:                          100000.
+Pages speculative:                         5000.
+Pages purgeable:                          10000.
+`
+
+func TestParseVMStat(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want float64
+	}{
+		{"idle", vmStatIdle, float64(1850000*16384) / gb},
+		{"pressure", vmStatPressure, float64(130000*16384) / gb},
+		{"empty", "", 0},
+	}
+	for _, tt := range tests {
+		if got := parseVMStat([]byte(tt.out)); math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("parseVMStat(%s) = %.2f, want %.2f", tt.name, got, tt.want)
+		}
+	}
+}
+
+const meminfoServer = `MemTotal:       65842860 kB
+MemFree:         1203644 kB
+MemAvailable:   41943040 kB
+Buffers:          512000 kB
+Cached:         38000000 kB
+HugePages_Total:       0
+`
+
+func TestParseMeminfo(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want float64
+	}{
+		{"server", meminfoServer, 40},
+		{"no MemAvailable", "MemTotal:       65842860 kB\nMemFree:         1203644 kB\n", 0},
+		{"no unit", "MemAvailable:   41943040\n", 0},
+		{"garbled", "MemAvailable:   lots kB\n", 0},
+		{"empty", "", 0},
+	}
+	for _, tt := range tests {
+		if got := parseMeminfo([]byte(tt.out)); math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("parseMeminfo(%s) = %.2f, want %.2f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAppleSharedVRAM(t *testing.T) {
+	// 32 GB Mac: default wired limit is 2/3 of RAM.
+	limit := appleWiredLimitGB(32, 0)
+	if math.Abs(limit-21.33) > 0.01 {
+		t.Errorf("appleWiredLimitGB(32, 0) = %.2f, want 21.33", limit)
+	}
+	if got := appleWiredLimitGB(64, 0); got != 48 {
+		t.Errorf("appleWiredLimitGB(64, 0) = %.2f, want 48", got)
+	}
+	if got := appleWiredLimitGB(32, 28*1024); got != 28 {
+		t.Errorf("appleWiredLimitGB(32, 28672) = %.2f, want 28", got)
+	}
+	idle := appleSharedVRAM(parseVMStat([]byte(vmStatIdle)), limit)
+	if idle != limit {
+		t.Errorf("idle shared VRAM = %.2f, want wired limit %.2f", idle, limit)
+	}
+	pressure := appleSharedVRAM(parseVMStat([]byte(vmStatPressure)), limit)
+	if pressure >= 2 || pressure >= idle {
+		t.Errorf("under pressure shared VRAM = %.2f, want under 2 GB (idle %.2f)", pressure, idle)
+	}
+}
+
+func TestWithExtraMemory(t *testing.T) {
+	vram := 8.0
+	s := &SystemSpecs{TotalRAMGB: 32, AvailableRAMGB: 24, HasGPU: true, GpuVRAMGB: &vram, Backend: BackendCuda,
+		Gpus: []GpuInfo{{Name: "GPU", VRAMGB: &vram, Backend: BackendCuda, Count: 1}}}
+	up := s.WithExtraMemory(16, 8)
+	if up.TotalRAMGB != 48 || up.AvailableRAMGB != 40 || *up.GpuVRAMGB != 16 || *up.Gpus[0].VRAMGB != 16 {
+		t.Errorf("WithExtraMemory(16, 8) = RAM %.0f/%.0f VRAM %.0f, want 48/40 and 16", up.TotalRAMGB, up.AvailableRAMGB, *up.GpuVRAMGB)
+	}
+	if *s.GpuVRAMGB != 8 || *s.Gpus[0].VRAMGB != 8 || s.TotalRAMGB != 32 {
+		t.Error("WithExtraMemory modified the original specs")
+	}
+	cpu := &SystemSpecs{TotalRAMGB: 16, AvailableRAMGB: 12, Backend: BackendCpuX86}
+	if g := cpu.WithExtraMemory(0, 12); !g.HasGPU || g.GpuVRAMGB == nil || *g.GpuVRAMGB != 12 {
+		t.Error("WithExtraMemory(0, 12) on a CPU-only machine: want a 12 GB GPU")
+	}
+}
+
+func TestCountNUMANodes(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  int
+	}{
+		{nil, 0},
+		{[]string{"node0", "has_cpu", "online", "possible", "power", "uevent"}, 1},
+		{[]string{"node0", "node1", "has_memory", "online"}, 2},
+		{[]string{"node0", "node1", "node2", "node3", "nodefoo", "node"}, 4},
+	}
+	for _, tt := range tests {
+		if got := countNUMANodes(tt.names); got != tt.want {
+			t.Errorf("countNUMANodes(%v) = %d, want %d", tt.names, got, tt.want)
+		}
+	}
+}
+
+func TestIntegratedGPU(t *testing.T) {
+	dvram := 6.0
+	laptop := &SystemSpecs{
+		TotalRAMGB: 32,
+		Gpus: []GpuInfo{
+			{Name: "NVIDIA GeForce RTX 3050 Laptop GPU", VRAMGB: &dvram, Backend: BackendCuda, Count: 1},
+			{Name: "Intel(R) Iris(R) Xe Graphics", Backend: BackendSycl, Count: 1},
+		},
+	}
+	if g := laptop.IntegratedGPU(); g == nil || g.Name != "Intel(R) Iris(R) Xe Graphics" {
+		t.Errorf("IntegratedGPU() = %v, want the Iris Xe", g)
+	}
+	if got := laptop.SharedGPUMemoryGB(); got != 16 {
+		t.Errorf("SharedGPUMemoryGB() = %v, want 16", got)
+	}
+	desktop := &SystemSpecs{Gpus: laptop.Gpus[:1]}
+	if g := desktop.IntegratedGPU(); g != nil {
+		t.Errorf("IntegratedGPU() on dGPU-only = %v, want nil", g)
+	}
+	igpuOnly := &SystemSpecs{Gpus: laptop.Gpus[1:]}
+	if g := igpuOnly.IntegratedGPU(); g != nil {
+		t.Errorf("IntegratedGPU() with an iGPU primary = %v, want nil", g)
+	}
+}
+
+func TestParseNvidiaLiveStats(t *testing.T) {
+	out := []byte("0, 37, 61, NVIDIA GeForce RTX 4090\n1, [N/A], 40, NVIDIA GeForce RTX 3060\n\ngarbage line\n")
+	stats := parseNvidiaLiveStats(out)
+	if len(stats) != 2 {
+		t.Fatalf("parseNvidiaLiveStats: got %d GPUs, want 2: %+v", len(stats), stats)
+	}
+	if s := stats[0]; s.Name != "NVIDIA GeForce RTX 4090" || s.UtilizationPct != 37 || s.TemperatureC != 61 {
+		t.Errorf("GPU 0 = %+v", s)
+	}
+	if s := stats[1]; s.UtilizationPct != -1 || s.TemperatureC != 40 {
+		t.Errorf("GPU 1 = %+v, want utilization -1 for [N/A]", s)
+	}
+}
+
+func TestParseROCmLiveStats(t *testing.T) {
+	out := []byte(`{"card1": {"GPU use (%)": "5", "Temperature (Sensor junction) (C)": "48.0", "Temperature (Sensor edge) (C)": "41.0"},
+		"card0": {"GPU use (%)": "99", "Temperature (Sensor junction) (C)": "80.0"}, "system": {"Driver version": "6.7"}}`)
+	stats := parseROCmLiveStats(out)
+	if len(stats) != 2 {
+		t.Fatalf("parseROCmLiveStats: got %d GPUs, want 2: %+v", len(stats), stats)
+	}
+	if s := stats[0]; s.Name != "AMD GPU 0" || s.UtilizationPct != 99 || s.TemperatureC != 80 {
+		t.Errorf("card0 = %+v", s)
+	}
+	if s := stats[1]; s.UtilizationPct != 5 || s.TemperatureC != 41 {
+		t.Errorf("card1 = %+v, want the edge sensor (41)", s)
+	}
+	if stats := parseROCmLiveStats([]byte("not json")); stats != nil {
+		t.Errorf("parseROCmLiveStats(invalid) = %+v, want nil", stats)
+	}
+}
+
+// fakeDRM writes a /sys/class/drm-style card with the given PCI vendor and VRAM bytes.
+func fakeDRM(t *testing.T, vendor string, vramBytes uint64) string {
+	t.Helper()
+	root := t.TempDir()
+	dev := filepath.Join(root, "card0", "device")
+	if err := os.MkdirAll(dev, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{"vendor": vendor + "\n", "mem_info_vram_total": strconv.FormatUint(vramBytes, 10) + "\n"}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dev, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// forceWSL points sysfs detection at root and forces inWSL to wsl for the test.
+func forceWSL(t *testing.T, root string, wsl bool) {
+	t.Helper()
+	oldDRM, oldWSL := sysfsDRM, inWSL
+	sysfsDRM, inWSL = root, func() bool { return wsl }
+	t.Cleanup(func() { sysfsDRM, inWSL = oldDRM, oldWSL })
+}
+
+func TestSysfsGPUDetection_SkippedUnderWSL(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("sysfs GPU detection is Linux-only")
+	}
+	amd := fakeDRM(t, "0x1002", 16*gb)
+	forceWSL(t, amd, false)
+	if g, _ := detectAMDSysfs(); g == nil || g.VRAMGB == nil || *g.VRAMGB != 16 {
+		t.Fatalf("detectAMDSysfs outside WSL = %+v, want a 16 GB card", g)
+	}
+	forceWSL(t, amd, true)
+	if g, _ := detectAMDSysfs(); g != nil {
+		t.Errorf("detectAMDSysfs under WSL = %+v, want nil", g)
+	}
+
+	intel := fakeDRM(t, "0x8086", 8*gb)
+	forceWSL(t, intel, false)
+	if found, v, _ := detectIntelGPU(); !found || v == nil || *v != 8 {
+		t.Fatalf("detectIntelGPU outside WSL = %v, %v; want an 8 GB card", found, v)
+	}
+	forceWSL(t, intel, true)
+	if found, _, _ := detectIntelGPU(); found {
+		t.Error("detectIntelGPU under WSL found a card, want sysfs skipped")
+	}
+}
+
+func TestNvidiaSMICommand_WSL(t *testing.T) {
+	forceWSL(t, sysfsDRM, true)
+	cmd := nvidiaSMICommand(context.Background(), "-L")
+	var libPath string
+	for _, kv := range cmd.Env {
+		if v, ok := strings.CutPrefix(kv, "LD_LIBRARY_PATH="); ok {
+			libPath = v
+		}
+	}
+	if !strings.HasPrefix(libPath, wslLibDir) {
+		t.Errorf("LD_LIBRARY_PATH = %q, want it to start with %s", libPath, wslLibDir)
+	}
+	forceWSL(t, sysfsDRM, false)
+	if cmd := nvidiaSMICommand(context.Background(), "-L"); cmd.Env != nil {
+		t.Errorf("outside WSL nvidia-smi should inherit the environment, got %d vars", len(cmd.Env))
+	}
+}
+
+func TestVisibleNvidiaDevices_ContainerEnv(t *testing.T) {
+	devs := parseNvidiaDevices([]byte("0, GPU-aaaa1111, 24576, RTX 4090\n1, GPU-bbbb2222, 24576, RTX 4090\n2, GPU-cccc3333, 12288, RTX 3060\n"))
+	t.Setenv("CUDA_VISIBLE_DEVICES", "")
+	os.Unsetenv("CUDA_VISIBLE_DEVICES")
+	t.Setenv("HIP_VISIBLE_DEVICES", "")
+	os.Unsetenv("HIP_VISIBLE_DEVICES")
+	tests := []struct {
+		spec  string
+		want  []int
+		noted bool
+	}{
+		{"all", []int{0, 1, 2}, false},
+		{"none", nil, true},
+		{"void", nil, true},
+		{"0,2", []int{0, 2}, true},
+		{"GPU-bbbb", []int{1}, true},
+		{"3,4,5", []int{0, 1, 2}, false}, // renumbered inside the container: already applied
+	}
+	for _, tt := range tests {
+		t.Setenv("NVIDIA_VISIBLE_DEVICES", tt.spec)
+		got, notes := visibleNvidiaDevices(devs)
+		var idx []int
+		for _, d := range got {
+			idx = append(idx, d.index)
+		}
+		if fmt.Sprint(idx) != fmt.Sprint(tt.want) {
+			t.Errorf("NVIDIA_VISIBLE_DEVICES=%s: devices %v, want %v", tt.spec, idx, tt.want)
+		}
+		if (len(notes) > 0) != tt.noted {
+			t.Errorf("NVIDIA_VISIBLE_DEVICES=%s: notes %v, want noted=%v", tt.spec, notes, tt.noted)
+		}
+	}
+	// CUDA_VISIBLE_DEVICES still applies on top of the container's set.
+	t.Setenv("NVIDIA_VISIBLE_DEVICES", "0,2")
+	t.Setenv("CUDA_VISIBLE_DEVICES", "2")
+	if got, _ := visibleNvidiaDevices(devs); len(got) != 1 || got[0].index != 2 {
+		t.Errorf("NVIDIA_VISIBLE_DEVICES=0,2 CUDA_VISIBLE_DEVICES=2: got %v, want device 2", got)
+	}
+}
+
+// stubProbes restores the GPU probes when the test ends, so it can replace them.
+func stubProbes(t *testing.T) {
+	t.Helper()
+	oldNvidia, oldROCm, oldSysfs := probeNvidia, probeAMDROCm, probeAMDSysfs
+	oldWindows, oldIntel, oldApple := probeWindows, probeIntel, probeApple
+	t.Cleanup(func() {
+		probeNvidia, probeAMDROCm, probeAMDSysfs = oldNvidia, oldROCm, oldSysfs
+		probeWindows, probeIntel, probeApple = oldWindows, oldIntel, oldApple
+	})
+}
+
+func TestDetectAllGPUs_PanickingProbeIsSkipped(t *testing.T) {
+	stubProbes(t)
+	vram := 16.0
+	probeNvidia = func() ([]GpuInfo, error) {
+		var fields []string
+		_ = fields[3] // a parser indexing past malformed output
+		return nil, nil
+	}
+	probeAMDROCm = func() (*GpuInfo, error) {
+		return &GpuInfo{Name: "Radeon RX 7800 XT", VRAMGB: &vram, Backend: BackendRocm, Count: 1}, nil
+	}
+	probeAMDSysfs = func() (*GpuInfo, error) { return nil, nil }
+	probeWindows = func() ([]GpuInfo, error) { panic("powershell: unexpected output") }
+	probeIntel = func() (bool, *float64, error) { return true, nil, nil }
+	probeApple = func() (bool, error) { return false, nil }
+
+	gpus, failures := detectAllGPUs(32, 24, "Test CPU")
+	var names []string
+	for _, g := range gpus {
+		names = append(names, g.Name)
+	}
+	if !slices.Equal(names, []string{"Radeon RX 7800 XT", "Intel Arc"}) {
+		t.Errorf("GPUs = %v, want the AMD and Intel GPUs despite the failing probes", names)
+	}
+	if len(failures) != 2 || !strings.Contains(failures[0].Message, "nvidia-smi") || !strings.Contains(failures[1].Message, "powershell: unexpected output") {
+		t.Errorf("failures = %q, want one note each for nvidia-smi and Windows", failures)
+	}
+}
+
+func TestDetectAllGPUs_ErroringProbeIsRecorded(t *testing.T) {
+	stubProbes(t)
+	vram := 16.0
+	probeNvidia = func() ([]GpuInfo, error) {
+		return nil, errors.New("nvidia-smi: exit status 9: NVIDIA-SMI has failed because it couldn't communicate with the NVIDIA driver")
+	}
+	probeAMDROCm = func() (*GpuInfo, error) { return nil, errors.New("rocm-smi: exit status 2") }
+	probeAMDSysfs = func() (*GpuInfo, error) {
+		return &GpuInfo{Name: "Radeon RX 7800 XT", VRAMGB: &vram, Backend: BackendVulkan, Count: 1}, nil
+	}
+	probeWindows = func() ([]GpuInfo, error) { return nil, nil }
+	probeIntel = func() (bool, *float64, error) { return false, nil, nil }
+	probeApple = func() (bool, error) { return false, nil }
+
+	gpus, failures := detectAllGPUs(32, 24, "Test CPU")
+	if len(gpus) != 1 || gpus[0].Name != "Radeon RX 7800 XT" {
+		t.Errorf("GPUs = %v, want the sysfs AMD GPU after rocm-smi failed", gpus)
+	}
+	if len(failures) != 2 || failures[0].Code != WarnGPUProbeFailed || !strings.Contains(failures[0].Message, "couldn't communicate") ||
+		!strings.Contains(failures[1].Message, "rocm-smi") {
+		t.Errorf("failures = %q, want gpu_probe_failed notes for nvidia-smi and rocm-smi", failures)
+	}
+}
+
+func TestToolError(t *testing.T) {
+	if err := toolError("nvidia-smi", exec.ErrNotFound); err != nil {
+		t.Errorf("missing tool: err = %v, want nil", err)
+	}
+	_, err := exec.Command("sh", "-c", "echo 'driver not loaded' >&2; echo more >&2; exit 9").Output()
+	if _, ok := err.(*exec.ExitError); !ok {
+		t.Skip("no sh to run")
+	}
+	if err := toolError("nvidia-smi", err); err == nil || err.Error() != "nvidia-smi: exit status 9: driver not loaded" {
+		t.Errorf("err = %v, want the exit status and first stderr line", err)
+	}
+}
+
+func TestApplyNvidiaCapabilities(t *testing.T) {
+	devs := parseNvidiaDevices([]byte("0, GPU-a, 24576, NVIDIA GeForce RTX 4090\n1, GPU-b, 11264, NVIDIA GeForce GTX 1080 Ti\n"))
+	applyNvidiaCapabilities(devs, []byte("0, 8.9, 550.54.14\n1, 6.1, 550.54.14\n"))
+	g := nvidiaGPUInfo(devs, "")
+	if g.ComputeCapability != "6.1" || g.DriverVersion != "550.54.14" || g.DriverMajor() != 550 {
+		t.Errorf("GpuInfo compute %q driver %q, want the lowest capability 6.1 and driver 550", g.ComputeCapability, g.DriverVersion)
+	}
+	if g := nvidiaGPUInfo(parseNvidiaDevices([]byte("0, GPU-a, 24576, RTX 4090\n")), ""); g.ComputeCapability != "" || g.DriverMajor() != 0 {
+		t.Errorf("without the capability query: compute %q driver %q, want unknown", g.ComputeCapability, g.DriverVersion)
+	}
+}
+
+func TestNvidiaGPUInfos_MixedCards(t *testing.T) {
+	devs := parseNvidiaDevices([]byte("0, GPU-a, 8192, NVIDIA GeForce RTX 3060\n" +
+		"1, GPU-b, 24564, NVIDIA GeForce RTX 4090\n" +
+		"2, GPU-c, 8192, NVIDIA GeForce RTX 3060\n" +
+		"3, GPU-d, 0, NVIDIA GeForce RTX 3080\n"))
+	gpus := nvidiaGPUInfos(devs, "")
+	specs := assembleSpecs(64, 48, 16, 1, "Test CPU", gpus, map[string]string{})
+
+	type card struct {
+		name  string
+		vram  float64
+		count uint32
+	}
+	var got []card
+	for _, g := range specs.Gpus {
+		c := card{name: g.Name, count: g.Count}
+		if g.VRAMGB != nil {
+			c.vram = math.Round(*g.VRAMGB)
+		}
+		got = append(got, c)
+	}
+	want := []card{
+		{"NVIDIA GeForce RTX 4090", 24, 1},
+		{"NVIDIA GeForce RTX 3060", 16, 2},
+		{"NVIDIA GeForce RTX 3080", 10, 1},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("GPUs = %+v, want %+v", got, want)
+	}
+	if *specs.GpuName != "NVIDIA GeForce RTX 4090" || specs.GpuCount != 1 || math.Round(*specs.GpuVRAMGB) != 24 {
+		t.Errorf("primary = %s x%d (%.1f GB), want the single RTX 4090 with its own 24 GB", *specs.GpuName, specs.GpuCount, *specs.GpuVRAMGB)
+	}
+	if !strings.HasPrefix(specs.Gpus[2].VRAMSource, nameEstimatePrefix) {
+		t.Errorf("RTX 3080 VRAM source = %q, want the per-card name estimate", specs.Gpus[2].VRAMSource)
+	}
+}
+
+func TestFreeVRAM_NvidiaAndROCm(t *testing.T) {
+	devs := parseNvidiaDevices([]byte("0, GPU-a, 8192, NVIDIA GeForce RTX 4060\n1, GPU-b, 8192, NVIDIA GeForce RTX 4060\n"))
+	applyNvidiaFreeMemory(devs, []byte("0, 5120\n1, 7168\n"))
+	g := nvidiaGPUInfo(devs, "")
+	if g.FreeVRAMGB == nil || *g.FreeVRAMGB != 12 {
+		t.Errorf("NVIDIA free VRAM = %v, want 12 GB across both cards", g.FreeVRAMGB)
+	}
+	devs = parseNvidiaDevices([]byte("0, GPU-a, 8192, NVIDIA GeForce RTX 4060\n1, GPU-b, 8192, NVIDIA GeForce RTX 4060\n"))
+	applyNvidiaFreeMemory(devs, []byte("0, 5120\n1, [N/A]\n"))
+	if g := nvidiaGPUInfo(devs, ""); g.FreeVRAMGB != nil {
+		t.Errorf("NVIDIA free VRAM with one card unknown = %v, want nil", *g.FreeVRAMGB)
+	}
+
+	rocm := []byte(`GPU[0]		: VRAM Total Memory (B): 17163091968
+GPU[0]		: VRAM Total Used Memory (B): 2147483648
+`)
+	total, used, count, usedCount := rocmMemTotals(parseROCmDevices(rocm))
+	if total != 17163091968 || used != 2147483648 || count != 1 || usedCount != 1 {
+		t.Errorf("rocmMemTotals = %d, %d, %d, %d", total, used, count, usedCount)
+	}
+
+	vram, free := 8.0, 9.0
+	specs := assembleSpecs(32, 24, 8, 1, "Test CPU", []GpuInfo{{Name: "Test GPU", VRAMGB: &vram, FreeVRAMGB: &free, Backend: BackendCuda, Count: 1}}, map[string]string{})
+	if specs.GpuFreeVRAMGB != nil || !specs.HasWarning(WarnVRAMCorrected) || *specs.FitVRAMGB() != 8 {
+		t.Errorf("free VRAM above total: free %v, warnings %v; want it dropped with a warning and total used", specs.GpuFreeVRAMGB, specs.Warnings)
+	}
+}
+
+func TestNvidiaECC_NotedNotSubtracted(t *testing.T) {
+	devs := parseNvidiaDevices([]byte("0, GPU-a, 46068, NVIDIA RTX A6000\n1, GPU-b, 46068, NVIDIA RTX A6000\n"))
+	applyNvidiaFreeMemory(devs, []byte("0, 45000\n1, 45000\n"))
+	applyNvidiaECC(devs, []byte("0, Enabled\n1, Enabled\n"))
+	g := nvidiaGPUInfo(devs, "")
+	if g.VRAMGB == nil || math.Abs(*g.VRAMGB-2*46068/1024.0) > 0.001 || g.VRAMSource != "nvidia-smi memory.total" {
+		t.Errorf("VRAM = %v from %q, want memory.total as reported: it already excludes ECC", g.VRAMGB, g.VRAMSource)
+	}
+	if !g.ECCEnabled || !strings.Contains(g.Note, "ECC enabled") {
+		t.Errorf("ECC enabled = %v, note %q; want ECC noted", g.ECCEnabled, g.Note)
+	}
+
+	devs = parseNvidiaDevices([]byte("0, GPU-a, 49140, NVIDIA RTX A6000\n1, GPU-b, 24576, NVIDIA GeForce RTX 4090\n"))
+	applyNvidiaECC(devs, []byte("0, Disabled\n1, [N/A]\n"))
+	for _, g := range nvidiaGPUInfos(devs, "") {
+		if g.ECCEnabled || strings.Contains(g.Note, "ECC") {
+			t.Errorf("%s: ECC enabled %v, note %q; want none without ECC enabled", g.Name, g.ECCEnabled, g.Note)
+		}
+	}
+}
+
+func cachedTestSpecs() *SystemSpecs {
+	vram, free := 45.0, 40.0
+	name := "NVIDIA RTX A6000"
+	return &SystemSpecs{
+		TotalRAMGB: 1, AvailableRAMGB: 0.5, TotalCPUCores: 16, NumaNodes: 2, CPUName: "Test CPU",
+		HasGPU: true, GpuVRAMGB: &vram, GpuFreeVRAMGB: &free, GpuName: &name, GpuCount: 1, Backend: BackendCuda,
+		Gpus: []GpuInfo{{
+			Name: name, VRAMGB: &vram, FreeVRAMGB: &free, ECCEnabled: true, Backend: BackendCuda, Count: 1,
+			VRAMSource: "nvidia-smi memory.total", ComputeCapability: "8.6", DriverVersion: "550.54.14", Note: "ECC enabled (memory.total already excludes its overhead)",
+		}},
+		Sources:  map[string]string{FieldCPUName: "/proc/cpuinfo model name"},
+		Warnings: []Warning{{Code: WarnRAMFallback, Message: "stale RAM warning"}, {Code: WarnVRAMFromName, Message: "kept"}},
+	}
+}
+
+func TestSpecsCache_RoundTrip(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	data, err := encodeSpecsCache(cachedTestSpecs(), now, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := decodeSpecsCache(data, now.Add(time.Second), SpecsCacheTTL, nil)
+	if !ok {
+		t.Fatal("fresh cache was not accepted")
+	}
+	want := cachedTestSpecs()
+	want.Gpus[0].VRAMSource = "nvidia-smi memory.total, cached 1s ago"
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip changed the specs:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestSpecsCache_TTL(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	data, _ := encodeSpecsCache(cachedTestSpecs(), now, nil)
+	for _, tt := range []struct {
+		name string
+		at   time.Time
+		ok   bool
+	}{
+		{"just written", now, true},
+		{"59s old", now.Add(59 * time.Second), true},
+		{"at the TTL", now.Add(SpecsCacheTTL), false},
+		{"an hour old", now.Add(time.Hour), false},
+		{"written in the future", now.Add(-time.Second), false},
+	} {
+		if _, ok := decodeSpecsCache(data, tt.at, SpecsCacheTTL, nil); ok != tt.ok {
+			t.Errorf("%s: cache used = %v, want %v", tt.name, ok, tt.ok)
+		}
+	}
+	for _, bad := range []string{"", "not json", `{"detected_at": "2026-10-16T12:00:00Z"}`, `{"specs": {"cpu_name": "x"}}`} {
+		if _, ok := decodeSpecsCache([]byte(bad), now, SpecsCacheTTL, nil); ok {
+			t.Errorf("cache %q was accepted, want it ignored", bad)
+		}
+	}
+}
+
+func TestSpecsCache_DeviceMaskChangeIsAMiss(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	masked := map[string]string{"CUDA_VISIBLE_DEVICES": "0"}
+	data, _ := encodeSpecsCache(cachedTestSpecs(), now, masked)
+	for _, tt := range []struct {
+		name string
+		env  map[string]string
+		ok   bool
+	}{
+		{"same mask", map[string]string{"CUDA_VISIBLE_DEVICES": "0"}, true},
+		{"other device", map[string]string{"CUDA_VISIBLE_DEVICES": "1"}, false},
+		{"mask cleared", nil, false},
+		{"set but empty", map[string]string{"CUDA_VISIBLE_DEVICES": ""}, false},
+		{"container mask added", map[string]string{"CUDA_VISIBLE_DEVICES": "0", "NVIDIA_VISIBLE_DEVICES": "all"}, false},
+	} {
+		if _, ok := decodeSpecsCache(data, now, SpecsCacheTTL, tt.env); ok != tt.ok {
+			t.Errorf("%s: cache used = %v, want %v", tt.name, ok, tt.ok)
+		}
+	}
+}
+
+func TestDetectCached_ReusesGPUsReadsRAMLive(t *testing.T) {
+	v, err := mem.VirtualMemory()
+	if err != nil || v.Available == 0 {
+		t.Skip("no live memory reading")
+	}
+	path := filepath.Join(t.TempDir(), "specs.json")
+	data, _ := encodeSpecsCache(cachedTestSpecs(), time.Now(), deviceMask())
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	specs, err := DetectCached(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs.Gpus) != 1 || specs.Gpus[0].Name != "NVIDIA RTX A6000" {
+		t.Fatalf("GPUs = %+v, want the cached RTX A6000", specs.Gpus)
+	}
+	if specs.TotalRAMGB != float64(v.Total)/gb {
+		t.Errorf("total RAM = %.2f GB, want the live %.2f GB", specs.TotalRAMGB, float64(v.Total)/gb)
+	}
+	if specs.HasWarning(WarnRAMFallback) || !specs.HasWarning(WarnVRAMFromName) {
+		t.Errorf("warnings = %v: want the cached RAM warning replaced and the GPU one kept", specs.Warnings)
+	}
+}
@@ -0,0 +1,105 @@
+package hardware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// liveStatsTimeout bounds each nvidia-smi / rocm-smi call so sampling never stalls the caller.
+const liveStatsTimeout = 2 * time.Second
+
+// GpuLiveStats is a point-in-time load sample for one GPU. It is transient: it is not part of
+// SystemSpecs, its JSON, or any cache, and is sampled on demand for watch views.
+type GpuLiveStats struct {
+	Name           string  `json:"name"`
+	UtilizationPct float64 `json:"utilization_pct"`
+	TemperatureC   float64 `json:"temperature_c"`
+}
+
+// SampleGPUStats queries current utilization and temperature from nvidia-smi, else rocm-smi.
+// It returns nil when neither tool is available or answers within the timeout.
+func SampleGPUStats(ctx context.Context) []GpuLiveStats {
+	ctx, cancel := context.WithTimeout(ctx, liveStatsTimeout)
+	defer cancel()
+	out, err := nvidiaSMICommand(ctx, "--query-gpu=index,utilization.gpu,temperature.gpu,name", "--format=csv,noheader,nounits").Output()
+	if err == nil {
+		if stats := parseNvidiaLiveStats(out); len(stats) > 0 {
+			return stats
+		}
+	}
+	out, err = exec.CommandContext(ctx, "rocm-smi", "--showuse", "--showtemp", "--json").Output()
+	if err == nil {
+		return parseROCmLiveStats(out)
+	}
+	return nil
+}
+
+// parseNvidiaLiveStats parses `nvidia-smi --query-gpu=index,utilization.gpu,temperature.gpu,name
+// --format=csv,noheader,nounits`. Devices reporting "[N/A]" for a field get -1 there.
+func parseNvidiaLiveStats(out []byte) []GpuLiveStats {
+	var stats []GpuLiveStats
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		parts := splitSMIFields(strings.TrimSpace(sc.Text()), 4)
+		if len(parts) < 4 {
+			continue
+		}
+		if _, err := strconv.Atoi(strings.TrimSpace(parts[0])); err != nil {
+			continue
+		}
+		stats = append(stats, GpuLiveStats{
+			Name:           strings.TrimSpace(parts[3]),
+			UtilizationPct: parseStat(parts[1]),
+			TemperatureC:   parseStat(parts[2]),
+		})
+	}
+	return stats
+}
+
+// parseROCmLiveStats parses `rocm-smi --showuse --showtemp --json`: one object per "cardN" with
+// "GPU use (%)" and "Temperature (Sensor edge) (C)" (or another temperature sensor).
+func parseROCmLiveStats(out []byte) []GpuLiveStats {
+	var cards map[string]map[string]string
+	if err := json.Unmarshal(out, &cards); err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(cards))
+	for name := range cards {
+		if strings.HasPrefix(name, "card") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var stats []GpuLiveStats
+	for _, name := range names {
+		s := GpuLiveStats{Name: fmt.Sprintf("AMD GPU %s", strings.TrimPrefix(name, "card")), UtilizationPct: -1, TemperatureC: -1}
+		for k, v := range cards[name] {
+			lk := strings.ToLower(k)
+			switch {
+			case strings.HasPrefix(lk, "gpu use"):
+				s.UtilizationPct = parseStat(v)
+			case strings.HasPrefix(lk, "temperature") && (s.TemperatureC < 0 || strings.Contains(lk, "edge")):
+				s.TemperatureC = parseStat(v)
+			}
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+// parseStat parses a numeric sample, returning -1 for "[N/A]" and other non-numbers.
+func parseStat(s string) float64 {
+	v, ok := parseLocaleNumber(s)
+	if !ok {
+		return -1
+	}
+	return v
+}
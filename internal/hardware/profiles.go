@@ -0,0 +1,32 @@
+package hardware
+
+// Profile is a built-in reference machine for "where would this model run" comparisons, sized
+// like a recommend --budget (see BudgetSpecs).
+type Profile struct {
+	Name     string
+	BudgetGB float64
+	Kind     string // "vram" or "ram", as for BudgetSpecs
+	Backend  GpuBackend
+}
+
+// Profiles are the built-in reference machines, in order of usable memory.
+var Profiles = []Profile{
+	{Name: "8 GB GPU", BudgetGB: 8, Kind: "vram", Backend: BackendCuda},
+	{Name: "16 GB Mac", BudgetGB: 16, Kind: "vram", Backend: BackendMetal},
+	{Name: "16 GB GPU", BudgetGB: 16, Kind: "vram", Backend: BackendCuda},
+	{Name: "24 GB GPU", BudgetGB: 24, Kind: "vram", Backend: BackendCuda},
+	{Name: "32 GB RAM, CPU only", BudgetGB: 32, Kind: "ram", Backend: BackendCpuX86},
+	{Name: "48 GB GPU", BudgetGB: 48, Kind: "vram", Backend: BackendCuda},
+	{Name: "64 GB Mac", BudgetGB: 64, Kind: "vram", Backend: BackendMetal},
+	{Name: "80 GB GPU", BudgetGB: 80, Kind: "vram", Backend: BackendCuda},
+	{Name: "128 GB Mac", BudgetGB: 128, Kind: "vram", Backend: BackendMetal},
+}
+
+// Specs returns the hypothetical specs for p.
+func (p Profile) Specs() *SystemSpecs {
+	specs, err := BudgetSpecs(p.BudgetGB, p.Kind, p.Backend)
+	if err != nil {
+		panic("hardware: invalid built-in profile " + p.Name + ": " + err.Error())
+	}
+	return specs
+}
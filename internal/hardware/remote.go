@@ -0,0 +1,151 @@
+package hardware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const remoteTimeout = 30 * time.Second
+
+// specsJSON mirrors the `llmpole system --json` output (backend as display string).
+type specsJSON struct {
+	TotalRAMGB     float64  `json:"total_ram_gb"`
+	AvailableRAMGB float64  `json:"available_ram_gb"`
+	CPUCores       int      `json:"cpu_cores"`
+	NumaNodes      int      `json:"numa_nodes"`
+	CPUName        string   `json:"cpu_name"`
+	HasGPU         bool     `json:"has_gpu"`
+	GpuVRAMGB      *float64 `json:"gpu_vram_gb"`
+	GpuFreeVRAMGB  *float64 `json:"gpu_free_vram_gb"`
+	GpuName        *string  `json:"gpu_name"`
+	GpuCount       uint32   `json:"gpu_count"`
+	UnifiedMemory  bool     `json:"unified_memory"`
+	Backend        string   `json:"backend"`
+	Gpus           []struct {
+		Name          string   `json:"name"`
+		VRAMGB        *float64 `json:"vram_gb"`
+		FreeVRAMGB    *float64 `json:"free_vram_gb"`
+		ECCEnabled    bool     `json:"ecc_enabled"`
+		Backend       string   `json:"backend"`
+		Count         uint32   `json:"count"`
+		UnifiedMemory bool     `json:"unified_memory"`
+		Note          string   `json:"note"`
+		ComputeCap    string   `json:"compute_capability"`
+		Driver        string   `json:"driver_version"`
+	} `json:"gpus"`
+	Warnings []Warning `json:"warnings"`
+}
+
+// ParseBackend maps a backend display string (e.g. "CUDA", "CPU (ARM)") back to a GpuBackend.
+func ParseBackend(s string) (GpuBackend, bool) {
+	for b := BackendCuda; b <= BackendCpuX86; b++ {
+		if strings.EqualFold(b.String(), s) {
+			return b, true
+		}
+	}
+	switch strings.ToLower(s) {
+	case "cpu", "cpu-x86", "x86":
+		return BackendCpuX86, true
+	case "cpu-arm", "arm":
+		return BackendCpuArm, true
+	}
+	return BackendCpuX86, false
+}
+
+// SpecsFromJSON parses `llmpole system --json` output (wrapped in {"system": ...} or bare) into SystemSpecs.
+func SpecsFromJSON(body []byte) (*SystemSpecs, error) {
+	var wrapped struct {
+		System *specsJSON `json:"system"`
+	}
+	if err := json.Unmarshal(body, &wrapped); err != nil {
+		return nil, fmt.Errorf("invalid system JSON: %w", err)
+	}
+	raw := wrapped.System
+	if raw == nil {
+		raw = &specsJSON{}
+		if err := json.Unmarshal(body, raw); err != nil {
+			return nil, fmt.Errorf("invalid system JSON: %w", err)
+		}
+	}
+	if raw.TotalRAMGB <= 0 {
+		return nil, fmt.Errorf("invalid system JSON: missing total_ram_gb")
+	}
+	backend, _ := ParseBackend(raw.Backend)
+	specs := &SystemSpecs{
+		TotalRAMGB:     raw.TotalRAMGB,
+		AvailableRAMGB: raw.AvailableRAMGB,
+		TotalCPUCores:  raw.CPUCores,
+		NumaNodes:      raw.NumaNodes,
+		CPUName:        raw.CPUName,
+		HasGPU:         raw.HasGPU,
+		GpuVRAMGB:      raw.GpuVRAMGB,
+		GpuFreeVRAMGB:  raw.GpuFreeVRAMGB,
+		GpuName:        raw.GpuName,
+		GpuCount:       raw.GpuCount,
+		UnifiedMemory:  raw.UnifiedMemory,
+		Backend:        backend,
+		Warnings:       raw.Warnings,
+	}
+	for _, g := range raw.Gpus {
+		b, _ := ParseBackend(g.Backend)
+		specs.Gpus = append(specs.Gpus, GpuInfo{
+			Name: g.Name, VRAMGB: g.VRAMGB, Backend: b, Count: g.Count, UnifiedMemory: g.UnifiedMemory, Note: g.Note,
+			ComputeCapability: g.ComputeCap, DriverVersion: g.Driver, FreeVRAMGB: g.FreeVRAMGB,
+			ECCEnabled: g.ECCEnabled,
+		})
+	}
+	specs.sanitize()
+	return specs, nil
+}
+
+// DetectRemote returns the specs of a remote machine. An http(s) URL is fetched directly and must
+// serve `llmpole system --json` output; anything else is treated as an SSH host running llmpole.
+// A host starting with "-" is rejected rather than handed to ssh.
+func DetectRemote(host string) (*SystemSpecs, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	var body []byte
+	var err error
+	if strings.HasPrefix(host, "http://") || strings.HasPrefix(host, "https://") {
+		body, err = fetchRemoteHTTP(ctx, host)
+	} else if strings.HasPrefix(host, "-") {
+		// ssh would read such a host as an option (say -oProxyCommand=...), not a destination.
+		err = fmt.Errorf("invalid SSH host %q: must not start with '-'", host)
+	} else {
+		body, err = exec.CommandContext(ctx, "ssh", "-o", "BatchMode=yes", "--", host, "llmpole", "system", "--json").Output()
+		if err != nil {
+			err = fmt.Errorf("ssh %s: %w", host, err)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("remote %s: %w", host, err)
+	}
+	specs, err := SpecsFromJSON(body)
+	if err != nil {
+		return nil, fmt.Errorf("remote %s: %w", host, err)
+	}
+	specs.setAllSources("reported by remote " + host)
+	return specs, nil
+}
+
+func fetchRemoteHTTP(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,54 @@
+package hardware
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WarningCode identifies a kind of detection anomaly, stable for tools reading `system --json`.
+type WarningCode string
+
+const (
+	WarnGPUProbeFailed WarningCode = "gpu_probe_failed"       // a GPU probe errored or panicked and was skipped
+	WarnVRAMFromName   WarningCode = "vram_from_name"         // VRAM taken from the GPU-name lookup table
+	WarnRAMFallback    WarningCode = "available_ram_fallback" // the OS reported no available RAM; estimated instead
+	WarnRAMCorrected   WarningCode = "ram_corrected"          // an inconsistent RAM reading was clamped
+	WarnVRAMCorrected  WarningCode = "vram_corrected"         // an implausible VRAM reading was dropped or clamped
+	WarnOther          WarningCode = "other"                  // a warning from a host that predates codes
+)
+
+// Warning is a detection anomaly: a questionable value llmpole used, or a reading it corrected.
+type Warning struct {
+	Code    WarningCode `json:"code"`
+	Message string      `json:"message"`
+}
+
+func (w Warning) String() string {
+	return w.Message
+}
+
+// UnmarshalJSON also accepts the plain-string warnings of older `system --json` output.
+func (w *Warning) UnmarshalJSON(b []byte) error {
+	var msg string
+	if err := json.Unmarshal(b, &msg); err == nil {
+		*w = Warning{Code: WarnOther, Message: msg}
+		return nil
+	}
+	type plain Warning
+	return json.Unmarshal(b, (*plain)(w))
+}
+
+// warn records a warning on s.
+func (s *SystemSpecs) warn(code WarningCode, format string, args ...any) {
+	s.Warnings = append(s.Warnings, Warning{Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// HasWarning reports whether detection recorded a warning with code.
+func (s *SystemSpecs) HasWarning(code WarningCode) bool {
+	for _, w := range s.Warnings {
+		if w.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package models
+
+import "strings"
+
+// archProfile adjusts the generic memory estimate for an architecture family.
+type archProfile struct {
+	// kvScale multiplies the transformer KV-cache heuristic (1 = every layer attends).
+	kvScale float64
+	// overheadGB is the fixed runtime overhead.
+	overheadGB float64
+}
+
+// defaultArchProfile is the standard transformer treatment, also used for unknown architectures.
+var defaultArchProfile = archProfile{kvScale: 1, overheadGB: 0.5}
+
+// archProfiles is keyed by HF config model_type. SSM/recurrent models keep a fixed-size state
+// instead of a KV cache; hybrids only cache the few attention layers they interleave.
+var archProfiles = map[string]archProfile{
+	"mamba":            {kvScale: 0.01, overheadGB: 0.5},
+	"mamba2":           {kvScale: 0.01, overheadGB: 0.5},
+	"falcon_mamba":     {kvScale: 0.01, overheadGB: 0.5},
+	"rwkv":             {kvScale: 0.01, overheadGB: 0.5},
+	"rwkv5":            {kvScale: 0.01, overheadGB: 0.5},
+	"rwkv6":            {kvScale: 0.01, overheadGB: 0.5},
+	"rwkv7":            {kvScale: 0.01, overheadGB: 0.5},
+	"xlstm":            {kvScale: 0.01, overheadGB: 0.5},
+	"jamba":            {kvScale: 0.15, overheadGB: 0.6},
+	"zamba":            {kvScale: 0.15, overheadGB: 0.6},
+	"zamba2":           {kvScale: 0.15, overheadGB: 0.6},
+	"bamba":            {kvScale: 0.15, overheadGB: 0.6},
+	"falcon_h1":        {kvScale: 0.15, overheadGB: 0.6},
+	"nemotron_h":       {kvScale: 0.15, overheadGB: 0.6},
+	"granitemoehybrid": {kvScale: 0.15, overheadGB: 0.6},
+}
+
+func architectureProfile(arch string) archProfile {
+	key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(arch)), "-", "_")
+	if p, ok := archProfiles[key]; ok {
+		return p
+	}
+	return defaultArchProfile
+}
+
+// IsStateSpace reports whether the architecture is an SSM/recurrent model with near-zero KV cache.
+func (m *LlmModel) IsStateSpace() bool {
+	return architectureProfile(m.Architecture).kvScale < 0.1
+}
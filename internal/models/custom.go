@@ -0,0 +1,58 @@
+package models
+
+import "fmt"
+
+// CustomModelsEnv names the environment variable read when --models-file is not given.
+const CustomModelsEnv = "LLMPOLE_MODELS_FILE"
+
+// LoadCustomModels reads user model entries (same shape as the catalog JSON) from path.
+// Entries that fail validation are skipped and reported in the returned slice of errors;
+// the error return is only for an unreadable or unparsable file.
+func LoadCustomModels(path string) ([]*LlmModel, []error, error) {
+	entries, err := ReadModelsFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var out []*LlmModel
+	var bad []error
+	seen := make(map[string]bool)
+	for i, m := range entries {
+		if m.Quantization == "" {
+			m.Quantization = "Q4_K_M"
+		}
+		if err := validateCustomModel(m); err != nil {
+			bad = append(bad, fmt.Errorf("entry %d (%q): %w", i+1, m.Name, err))
+			continue
+		}
+		if seen[m.Name] {
+			bad = append(bad, fmt.Errorf("entry %d (%q): duplicate name", i+1, m.Name))
+			continue
+		}
+		seen[m.Name] = true
+		out = append(out, m)
+	}
+	return out, bad, nil
+}
+
+func validateCustomModel(m *LlmModel) error {
+	switch {
+	case m.Name == "":
+		return fmt.Errorf("name is required")
+	case m.ParameterCount == "" && m.ParametersRaw == nil:
+		return fmt.Errorf("parameter_count or parameters_raw is required")
+	case m.MinRAMGB <= 0:
+		return fmt.Errorf("min_ram_gb must be positive")
+	case m.RecommendedRAMGB < m.MinRAMGB:
+		return fmt.Errorf("recommended_ram_gb (%.1f) is below min_ram_gb (%.1f)", m.RecommendedRAMGB, m.MinRAMGB)
+	case m.MinVRAMGB != nil && *m.MinVRAMGB <= 0:
+		return fmt.Errorf("min_vram_gb must be positive when set")
+	case m.ContextLength == 0:
+		return fmt.Errorf("context_length is required")
+	}
+	return nil
+}
+
+// MergeCustom overlays custom models onto the database by name, replacing same-named catalog entries.
+func (db *ModelDatabase) MergeCustom(custom []*LlmModel) {
+	db.models = mergeModels(db.models, custom)
+}
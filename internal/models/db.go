@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/shayne-snap/llmpole/data"
 )
@@ -20,21 +21,27 @@ func CachePath() (string, error) {
 }
 
 func entryToModel(e *hfModelEntry) *LlmModel {
+	_, parsed := ParseParamCount(e.ParameterCount)
 	return &LlmModel{
-		Name:             e.Name,
-		Provider:         e.Provider,
-		ParameterCount:   e.ParameterCount,
-		ParametersRaw:    e.ParametersRaw,
-		MinRAMGB:         e.MinRAMGB,
-		RecommendedRAMGB: e.RecommendedRAMGB,
-		MinVRAMGB:        e.MinVRAMGB,
-		Quantization:     e.Quantization,
-		ContextLength:    e.ContextLength,
-		UseCase:          e.UseCase,
-		IsMoE:            e.IsMoE,
-		NumExperts:       e.NumExperts,
-		ActiveExperts:    e.ActiveExperts,
-		ActiveParameters: e.ActiveParameters,
+		Name:              e.Name,
+		Provider:          e.Provider,
+		ParameterCount:    e.ParameterCount,
+		ParametersRaw:     e.ParametersRaw,
+		MinRAMGB:          e.MinRAMGB,
+		RecommendedRAMGB:  e.RecommendedRAMGB,
+		MinVRAMGB:         e.MinVRAMGB,
+		Quantization:      e.Quantization,
+		ContextLength:     e.ContextLength,
+		UseCase:           e.UseCase,
+		IsMoE:             e.IsMoE,
+		NumExperts:        e.NumExperts,
+		ActiveExperts:     e.ActiveExperts,
+		ActiveParameters:  e.ActiveParameters,
+		QuantAvailability: e.QuantAvailability,
+		Architecture:      e.Architecture,
+		AvailableQuants:   e.AvailableQuants,
+		License:           e.License,
+		UnknownSize:       !parsed && (e.ParametersRaw == nil || *e.ParametersRaw == 0),
 	}
 }
 
@@ -123,6 +130,51 @@ func (db *ModelDatabase) FindModel(query string) []*LlmModel {
 	return out
 }
 
+// FilterByMoE keeps only MoE models when moe is true, or only dense models when false.
+func FilterByMoE(ms []*LlmModel, moe bool) []*LlmModel {
+	var out []*LlmModel
+	for _, m := range ms {
+		if m.IsMoE == moe {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// FilterByLicense keeps models whose License is one of licenses (case-insensitive); a dual-licensed
+// model ("apache-2.0/mit") matches on either. Models with no known license are dropped, since
+// they cannot be shown to comply.
+func FilterByLicense(ms []*LlmModel, licenses []string) []*LlmModel {
+	allowed := make(map[string]bool, len(licenses))
+	for _, l := range licenses {
+		allowed[strings.ToLower(strings.TrimSpace(l))] = true
+	}
+	var out []*LlmModel
+	for _, m := range ms {
+		for _, l := range strings.Split(m.License, "/") {
+			if l != "" && allowed[strings.ToLower(l)] {
+				out = append(out, m)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// FilterByMinContext keeps models whose context length is at least minCtx tokens; 0 keeps all.
+func FilterByMinContext(ms []*LlmModel, minCtx uint32) []*LlmModel {
+	if minCtx == 0 {
+		return ms
+	}
+	var out []*LlmModel
+	for _, m := range ms {
+		if m.ContextLength >= minCtx {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
 // WriteCacheFile writes raw JSON bytes to the user cache path (e.g. for update-list). Creates parent dir if needed.
 func WriteCacheFile(body []byte) error {
 	cachePath, err := CachePath()
@@ -173,3 +225,38 @@ func AppendModelToCache(m *LlmModel) error {
 	}
 	return os.WriteFile(cachePath, data, 0644)
 }
+
+// StaleListAge is how old the model list may get before update-list is suggested.
+const StaleListAge = 90 * 24 * time.Hour
+
+// ListGeneratedAt returns when the embedded model list was generated.
+func ListGeneratedAt() (time.Time, error) {
+	var meta struct {
+		GeneratedAt string `json:"generated_at"`
+	}
+	if err := json.Unmarshal(data.HFModelsMetaJSON, &meta); err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse("2006-01-02", meta.GeneratedAt)
+}
+
+// CacheModTime returns the user cache file's modification time, or the zero time if there is no cache.
+func CacheModTime() time.Time {
+	cachePath, err := CachePath()
+	if err != nil {
+		return time.Time{}
+	}
+	info, err := os.Stat(cachePath)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// IsListStale reports whether the embedded list is older than maxAge and no cache newer than maxAge exists.
+func IsListStale(generatedAt, cacheModTime, now time.Time, maxAge time.Duration) bool {
+	if generatedAt.IsZero() || now.Sub(generatedAt) <= maxAge {
+		return false
+	}
+	return cacheModTime.IsZero() || now.Sub(cacheModTime) > maxAge
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// variantSuffixes are repo-name suffixes that mark a re-upload (quantized or converted) of a base model.
+var variantSuffixes = []string{"-gguf", "-awq", "-gptq", "-mlx", "-fp8", "-int4", "-int8", "-bnb-4bit", "-4bit", "-8bit", "-hf"}
+
+// ModelGroup is a representative model plus likely-duplicate entries collapsed behind it.
+type ModelGroup struct {
+	Representative *LlmModel
+	Variants       []*LlmModel
+}
+
+// DuplicateKey returns a normalized key (repo basename without uploader or format suffix, plus size);
+// models with equal keys are likely the same model from different uploaders or formats.
+func DuplicateKey(m *LlmModel) string {
+	name := strings.ToLower(m.Name)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.ReplaceAll(name, "_", "-")
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, suf := range variantSuffixes {
+			if strings.HasSuffix(name, suf) {
+				name = strings.TrimSuffix(name, suf)
+				trimmed = true
+			}
+		}
+	}
+	return fmt.Sprintf("%s|%.1f", name, m.ParamsB())
+}
+
+// GroupDuplicates groups likely-duplicate models, keeping input order. The first model of each
+// group is its representative.
+func GroupDuplicates(ms []*LlmModel) []ModelGroup {
+	index := make(map[string]int)
+	var groups []ModelGroup
+	for _, m := range ms {
+		key := DuplicateKey(m)
+		if i, ok := index[key]; ok {
+			groups[i].Variants = append(groups[i].Variants, m)
+			continue
+		}
+		index[key] = len(groups)
+		groups = append(groups, ModelGroup{Representative: m})
+	}
+	return groups
+}
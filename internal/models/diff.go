@@ -0,0 +1,121 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// FieldChange is one catalog field whose value differs between two lists.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// ModelChange lists the changed fields of a model present in both lists.
+type ModelChange struct {
+	Name   string        `json:"name"`
+	Fields []FieldChange `json:"fields"`
+}
+
+// CatalogDiff is what an overlay list adds, removes, and changes relative to a base list.
+type CatalogDiff struct {
+	Added   []string      `json:"added"`   // in the overlay only
+	Removed []string      `json:"removed"` // in the base only
+	Changed []ModelChange `json:"changed"`
+}
+
+// Empty reports whether the lists are identical by name and catalog fields.
+func (d CatalogDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// diffFields are the catalog fields compared by DiffModels, with their JSON names.
+var diffFields = []struct {
+	name  string
+	value func(m *LlmModel) string
+}{
+	{"provider", func(m *LlmModel) string { return m.Provider }},
+	{"parameter_count", func(m *LlmModel) string { return m.ParameterCount }},
+	{"parameters_raw", func(m *LlmModel) string { return optString(m.ParametersRaw) }},
+	{"min_ram_gb", func(m *LlmModel) string { return fmt.Sprint(m.MinRAMGB) }},
+	{"recommended_ram_gb", func(m *LlmModel) string { return fmt.Sprint(m.RecommendedRAMGB) }},
+	{"min_vram_gb", func(m *LlmModel) string { return optString(m.MinVRAMGB) }},
+	{"quantization", func(m *LlmModel) string { return m.Quantization }},
+	{"context_length", func(m *LlmModel) string { return fmt.Sprint(m.ContextLength) }},
+	{"use_case", func(m *LlmModel) string { return m.UseCase }},
+	{"is_moe", func(m *LlmModel) string { return fmt.Sprint(m.IsMoE) }},
+	{"num_experts", func(m *LlmModel) string { return optString(m.NumExperts) }},
+	{"active_experts", func(m *LlmModel) string { return optString(m.ActiveExperts) }},
+	{"active_parameters", func(m *LlmModel) string { return optString(m.ActiveParameters) }},
+	{"quant_availability", func(m *LlmModel) string { return optString(m.QuantAvailability) }},
+	{"architecture", func(m *LlmModel) string { return m.Architecture }},
+	{"available_quants", func(m *LlmModel) string { return strings.Join(m.AvailableQuants, ",") }},
+	{"license", func(m *LlmModel) string { return m.License }},
+}
+
+// optString formats an optional field, "" when unset.
+func optString[T any](p *T) string {
+	if p == nil {
+		return ""
+	}
+	return fmt.Sprint(*p)
+}
+
+// DiffModels compares overlay against base by model name, as mergeModels would combine them.
+// Added and Removed are sorted by name; Changed follows base order.
+func DiffModels(base, overlay []*LlmModel) CatalogDiff {
+	var d CatalogDiff
+	inBase := make(map[string]*LlmModel, len(base))
+	for _, m := range base {
+		inBase[m.Name] = m
+	}
+	inOverlay := make(map[string]*LlmModel, len(overlay))
+	for _, m := range overlay {
+		inOverlay[m.Name] = m
+		if inBase[m.Name] == nil {
+			d.Added = append(d.Added, m.Name)
+		}
+	}
+	for _, old := range base {
+		cur := inOverlay[old.Name]
+		if cur == nil {
+			d.Removed = append(d.Removed, old.Name)
+			continue
+		}
+		var fields []FieldChange
+		for _, f := range diffFields {
+			if a, b := f.value(old), f.value(cur); a != b {
+				fields = append(fields, FieldChange{Field: f.name, Old: a, New: b})
+			}
+		}
+		if len(fields) > 0 {
+			d.Changed = append(d.Changed, ModelChange{Name: old.Name, Fields: fields})
+		}
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	return d
+}
+
+// DiffOverlay compares the catalog that base and overlay merge into against base, as the cache
+// is merged over the embedded list. An overlay only adds and replaces models, so a model it
+// leaves out is kept, not Removed: fetching one model diffs as that model alone.
+func DiffOverlay(base, overlay []*LlmModel) CatalogDiff {
+	return DiffModels(base, mergeModels(base, overlay))
+}
+
+// CachedModels returns the models in the user cache written by update-list (or by fetching),
+// and an os.ErrNotExist error when there is no cache.
+func CachedModels() ([]*LlmModel, error) {
+	cachePath, err := CachePath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(cachePath); err != nil {
+		return nil, err
+	}
+	return ReadModelsFile(cachePath)
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FetchLogEntry records one model fetched from HuggingFace into the cache: when, from where, and
+// the parameters it resolved to, so a curated list can be reproduced or audited later.
+type FetchLogEntry struct {
+	Time             time.Time `json:"time"`
+	RepoID           string    `json:"repo_id"`
+	Source           string    `json:"source"`
+	ParameterCount   string    `json:"parameter_count"`
+	ParametersRaw    *uint64   `json:"parameters_raw,omitempty"`
+	Quantization     string    `json:"quantization"`
+	ContextLength    uint32    `json:"context_length"`
+	IsMoE            bool      `json:"is_moe,omitempty"`
+	ActiveParameters *uint64   `json:"active_parameters,omitempty"`
+	License          string    `json:"license,omitempty"`
+}
+
+// NewFetchLogEntry describes m, fetched from source, at the current time.
+func NewFetchLogEntry(m *LlmModel, source string) FetchLogEntry {
+	return FetchLogEntry{
+		Time:             time.Now().UTC(),
+		RepoID:           m.Name,
+		Source:           source,
+		ParameterCount:   m.ParameterCount,
+		ParametersRaw:    m.ParametersRaw,
+		Quantization:     m.Quantization,
+		ContextLength:    m.ContextLength,
+		IsMoE:            m.IsMoE,
+		ActiveParameters: m.ActiveParameters,
+		License:          m.License,
+	}
+}
+
+// FetchLogPath returns the fetch log path, next to the model cache (config dir/llmpole/fetch_log.jsonl).
+func FetchLogPath() (string, error) {
+	cachePath, err := CachePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(cachePath), "fetch_log.jsonl"), nil
+}
+
+// AppendFetchLog appends e to the fetch log as one JSON line, creating the file if needed.
+func AppendFetchLog(e FetchLogEntry) error {
+	path, err := FetchLogPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadFetchLog returns the fetch log oldest first. A missing log is empty; lines that do not
+// parse (e.g. cut short by a crash) are skipped.
+func ReadFetchLog() ([]FetchLogEntry, error) {
+	path, err := FetchLogPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []FetchLogEntry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e FetchLogEntry
+		if json.Unmarshal(sc.Bytes(), &e) == nil && e.RepoID != "" {
+			entries = append(entries, e)
+		}
+	}
+	return entries, sc.Err()
+}
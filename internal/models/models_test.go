@@ -2,7 +2,11 @@ package models
 
 import (
 	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestQuantBPP(t *testing.T) {
@@ -16,10 +20,19 @@ func TestQuantBPP(t *testing.T) {
 		{"Q8_0", 1.05},
 		{"Q6_K", 0.80},
 		{"Q5_K_M", 0.68},
+		{"Q5_K_S", 0.66},
 		{"Q4_K_M", 0.58},
 		{"Q4_0", 0.58},
+		{"Q4_K_S", 0.55},
+		{"IQ4_XS", 0.51},
 		{"Q3_K_M", 0.48},
+		{"IQ3_M", 0.44},
+		{"Q3_K_S", 0.41},
+		{"IQ3_XXS", 0.38},
 		{"Q2_K", 0.37},
+		{"IQ2_M", 0.33},
+		{"IQ2_XS", 0.28},
+		{"IQ2_XXS", 0.25},
 		{"unknown", 0.58},
 	}
 	for _, tt := range tests {
@@ -40,10 +53,19 @@ func TestQuantSpeedMultiplier(t *testing.T) {
 		{"Q8_0", 0.8},
 		{"Q6_K", 0.95},
 		{"Q5_K_M", 1.0},
+		{"Q5_K_S", 1.02},
 		{"Q4_K_M", 1.15},
 		{"Q4_0", 1.15},
+		{"Q4_K_S", 1.18},
+		{"IQ4_XS", 1.05},
 		{"Q3_K_M", 1.25},
+		{"IQ3_M", 1.1},
+		{"Q3_K_S", 1.28},
+		{"IQ3_XXS", 1.1},
 		{"Q2_K", 1.35},
+		{"IQ2_M", 1.1},
+		{"IQ2_XS", 1.1},
+		{"IQ2_XXS", 1.1},
 		{"unknown", 1.0},
 	}
 	for _, tt := range tests {
@@ -64,10 +86,19 @@ func TestQuantQualityPenalty(t *testing.T) {
 		{"Q8_0", 0.0},
 		{"Q6_K", -1.0},
 		{"Q5_K_M", -2.0},
+		{"Q5_K_S", -3.0},
 		{"Q4_K_M", -5.0},
 		{"Q4_0", -5.0},
+		{"Q4_K_S", -6.0},
+		{"IQ4_XS", -6.5},
 		{"Q3_K_M", -8.0},
+		{"IQ3_M", -8.5},
+		{"Q3_K_S", -9.5},
+		{"IQ3_XXS", -11.0},
 		{"Q2_K", -12.0},
+		{"IQ2_M", -14.0},
+		{"IQ2_XS", -17.0},
+		{"IQ2_XXS", -20.0},
 		{"unknown", -5.0},
 	}
 	for _, tt := range tests {
@@ -78,6 +109,26 @@ func TestQuantQualityPenalty(t *testing.T) {
 	}
 }
 
+func TestQuantHierarchy_Ordered(t *testing.T) {
+	for i := 1; i < len(QuantHierarchy); i++ {
+		prev, q := QuantHierarchy[i-1], QuantHierarchy[i]
+		if QuantBPP(q) >= QuantBPP(prev) {
+			t.Errorf("QuantBPP(%s) = %v, want below %v of %s before it", q, QuantBPP(q), QuantBPP(prev), prev)
+		}
+		if QuantQualityPenalty(q) >= QuantQualityPenalty(prev) {
+			t.Errorf("QuantQualityPenalty(%s) = %v, want below %v of %s before it", q, QuantQualityPenalty(q), QuantQualityPenalty(prev), prev)
+		}
+	}
+	m := &LlmModel{ParameterCount: "32B", Quantization: "Q4_K_M"}
+	budget := m.EstimateMemoryGB("IQ4_XS", 4096, KVCacheF16) + 0.1
+	if got, _ := m.BestQuantAmong(QuantHierarchy, budget, 4096, KVCacheF16); got != "IQ4_XS" {
+		t.Errorf("BestQuantAmong just above the IQ4_XS estimate = %s, want IQ4_XS", got)
+	}
+	if got := CanonicalQuant("iq2_xxs"); got != "IQ2_XXS" {
+		t.Errorf("CanonicalQuant(iq2_xxs) = %q, want IQ2_XXS", got)
+	}
+}
+
 func TestLlmModel_ParamsB(t *testing.T) {
 	raw7B := uint64(7_000_000_000)
 	raw1_5B := uint64(1_500_000_000)
@@ -93,7 +144,11 @@ func TestLlmModel_ParamsB(t *testing.T) {
 		{"137M string", &LlmModel{ParameterCount: "137M"}, 0.137},
 		{"ParametersRaw 7B", &LlmModel{ParametersRaw: &raw7B, ParameterCount: "?"}, 7.0},
 		{"ParametersRaw 1.5B", &LlmModel{ParametersRaw: &raw1_5B}, 1.5},
-		{"default", &LlmModel{ParameterCount: ""}, 7.0},
+		{"MoE 8x7B", &LlmModel{ParameterCount: "8x7B"}, 56.0},
+		{"MoE with spaces", &LlmModel{ParameterCount: " 8 x 22B "}, 176.0},
+		{"whitespace and case", &LlmModel{ParameterCount: " 1.5 b"}, 1.5},
+		{"unparseable falls back to min RAM", &LlmModel{ParameterCount: "MoE", MinRAMGB: 4.56, Quantization: "Q4_K_M"}, 7.0},
+		{"unparseable without RAM", &LlmModel{ParameterCount: "?"}, 0},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -105,10 +160,34 @@ func TestLlmModel_ParamsB(t *testing.T) {
 	}
 }
 
+func TestReadModelsFile_UnknownSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.json")
+	body := `[
+  {"name": "a", "parameter_count": "8x7B", "min_ram_gb": 30, "recommended_ram_gb": 40, "context_length": 4096},
+  {"name": "b", "parameter_count": "?", "min_ram_gb": 4.56, "recommended_ram_gb": 8, "context_length": 4096},
+  {"name": "c", "parameter_count": "", "parameters_raw": 3000000000, "min_ram_gb": 2, "recommended_ram_gb": 4, "context_length": 4096}
+]`
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ms, err := ReadModelsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []bool{false, true, false} {
+		if ms[i].UnknownSize != want {
+			t.Errorf("%s: UnknownSize = %v, want %v", ms[i].Name, ms[i].UnknownSize, want)
+		}
+	}
+	if issues := Validate(ms); len(issues) != 1 || issues[0].Name != "b" || issues[0].Field != "parameter_count" {
+		t.Errorf("Validate = %v, want one parameter_count issue on b", issues)
+	}
+}
+
 func TestLlmModel_EstimateMemoryGB(t *testing.T) {
 	m := &LlmModel{ParameterCount: "7B", Quantization: "Q4_K_M"}
 	// modelMem = 7 * 0.58, kvCache = 0.000008 * 7 * 4096, overhead = 0.5
-	got := m.EstimateMemoryGB("Q4_K_M", 4096)
+	got := m.EstimateMemoryGB("Q4_K_M", 4096, KVCacheF16)
 	wantModel := 7.0 * 0.58
 	wantKv := 0.000008 * 7.0 * 4096
 	wantOverhead := 0.5
@@ -118,6 +197,59 @@ func TestLlmModel_EstimateMemoryGB(t *testing.T) {
 	}
 }
 
+func TestLlmModel_EstimateMemoryGB_KVCacheType(t *testing.T) {
+	m := &LlmModel{ParameterCount: "7B", Quantization: "Q4_K_M"}
+	const ctx = 32768
+	f16 := m.EstimateMemoryGB("Q4_K_M", ctx, KVCacheF16)
+	q4 := m.EstimateMemoryGB("Q4_K_M", ctx, KVCacheQ4)
+	// Only the KV term shrinks: a Q4 cache is a quarter of the F16 one.
+	if want := m.KVCacheGB(ctx, KVCacheF16) * 0.75; math.Abs(f16-q4-want) > 1e-9 {
+		t.Errorf("F16 - Q4 memory = %v, want %v", f16-q4, want)
+	}
+	if got, want := m.KVCacheGB(ctx, KVCacheQ8), m.KVCacheGB(ctx, KVCacheF16)/2; math.Abs(got-want) > 1e-9 {
+		t.Errorf("KVCacheGB(q8_0) = %v, want %v", got, want)
+	}
+	if got := m.EstimateMemoryGB("Q4_K_M", ctx, ""); got != f16 {
+		t.Errorf("EstimateMemoryGB with no KV type = %v, want the F16 %v", got, f16)
+	}
+	for in, want := range map[string]KVCacheType{"": KVCacheF16, "F16": KVCacheF16, "q8": KVCacheQ8, "q4_0": KVCacheQ4} {
+		if got, err := ParseKVCacheType(in); err != nil || got != want {
+			t.Errorf("ParseKVCacheType(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseKVCacheType("q5_1"); err == nil {
+		t.Error("ParseKVCacheType(q5_1) = nil error, want unknown type error")
+	}
+}
+
+func TestEstimateMemoryForParams_MatchesModel(t *testing.T) {
+	for _, tc := range []struct {
+		params string
+		quant  string
+		ctx    uint32
+	}{
+		{"7B", "Q4_K_M", 4096},
+		{"70B", "Q8_0", 32768},
+		{"0.5B", "F16", 2048},
+		{"8x7B", "Q2_K", 8192},
+	} {
+		m := &LlmModel{ParameterCount: tc.params, Quantization: "Q4_K_M"}
+		got := EstimateMemoryForParams(m.ParamsB(), tc.quant, tc.ctx)
+		if want := m.EstimateMemoryGB(tc.quant, tc.ctx, KVCacheF16); got != want {
+			t.Errorf("%s %s ctx %d: EstimateMemoryForParams = %v, EstimateMemoryGB = %v", tc.params, tc.quant, tc.ctx, got, want)
+		}
+	}
+
+	active := uint64(3_000_000_000)
+	moe := &LlmModel{IsMoE: true, ActiveParameters: &active, Quantization: "Q5_K_M"}
+	if got, want := EstimateVRAMForParams(3, "Q5_K_M"), *moe.MoeActiveVRAMGB(); got != want {
+		t.Errorf("EstimateVRAMForParams(3, Q5_K_M) = %v, MoeActiveVRAMGB = %v", got, want)
+	}
+	if got := EstimateVRAMForParams(0.1, "Q4_K_M"); got != 0.5 {
+		t.Errorf("EstimateVRAMForParams(0.1B) = %v, want the 0.5 GB floor", got)
+	}
+}
+
 func TestLlmModel_BestQuantForBudget(t *testing.T) {
 	m := &LlmModel{ParameterCount: "7B", Quantization: "Q4_K_M", ContextLength: 4096}
 	// Large budget: should get best quant that fits
@@ -132,6 +264,85 @@ func TestLlmModel_BestQuantForBudget(t *testing.T) {
 	}
 }
 
+func TestLlmModel_BestQuantClampedToAvailable(t *testing.T) {
+	none := uint32(0)
+	tests := []struct {
+		name     string
+		m        *LlmModel
+		maxQuant string
+		want     string
+	}{
+		{"unknown availability: full hierarchy", &LlmModel{ParameterCount: "7B", Quantization: "Q4_K_M"}, "", "Q8_0"},
+		{"published quants only", &LlmModel{ParameterCount: "7B", Quantization: "Q4_K_M", AvailableQuants: []string{"q5_k_m", "Q4_K_M"}}, "", "Q5_K_M"},
+		{"no community quants: stated quant caps", &LlmModel{ParameterCount: "7B", Quantization: "Q4_K_M", QuantAvailability: &none}, "", "Q4_K_M"},
+		{"explicit cap", &LlmModel{ParameterCount: "7B", Quantization: "Q4_K_M"}, "Q6_K", "Q6_K"},
+		{"cap within published quants", &LlmModel{ParameterCount: "7B", Quantization: "Q4_K_M", AvailableQuants: []string{"Q8_0", "Q5_K_M"}}, "Q6_K", "Q5_K_M"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := tt.m.BestQuantAmong(tt.m.QuantCandidates(tt.maxQuant), 100, 4096, KVCacheF16)
+			if got != tt.want {
+				t.Errorf("best quant = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	// Nothing fits: fall back to the lightest published quant rather than an unpublished default.
+	m := &LlmModel{ParameterCount: "7B", Quantization: "Q4_K_M", AvailableQuants: []string{"Q8_0", "Q6_K"}}
+	if got, _ := m.BestQuantForBudget(0.1, 4096); got != "Q6_K" {
+		t.Errorf("BestQuantForBudget(0.1) = %q, want Q6_K", got)
+	}
+}
+
+func TestFilterByLicense(t *testing.T) {
+	ms := []*LlmModel{
+		{Name: "apache", License: "apache-2.0"},
+		{Name: "mit", License: "MIT"},
+		{Name: "dual", License: "apache-2.0/mit"},
+		{Name: "nc", License: "cc-by-nc-4.0"},
+		{Name: "unknown"},
+	}
+	names := func(ms []*LlmModel) string {
+		var out []string
+		for _, m := range ms {
+			out = append(out, m.Name)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := names(FilterByLicense(ms, []string{"apache-2.0", " mit"})); got != "apache,mit,dual" {
+		t.Errorf("apache-2.0,mit kept %q, want apache,mit,dual", got)
+	}
+	if got := names(FilterByLicense(ms, []string{"cc-by-nc-4.0"})); got != "nc" {
+		t.Errorf("cc-by-nc-4.0 kept %q, want nc", got)
+	}
+}
+
+func TestFilterByMinContext(t *testing.T) {
+	ms := []*LlmModel{
+		{Name: "short", ContextLength: 2048},
+		{Name: "mid", ContextLength: 8192},
+		{Name: "long", ContextLength: 32768},
+		{Name: "huge", ContextLength: 131072},
+	}
+	for _, tt := range []struct {
+		minCtx uint32
+		want   string
+	}{
+		{0, "short,mid,long,huge"},
+		{8192, "mid,long,huge"},
+		{8193, "long,huge"},
+		{32768, "long,huge"},
+		{200000, ""},
+	} {
+		var got []string
+		for _, m := range FilterByMinContext(ms, tt.minCtx) {
+			got = append(got, m.Name)
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("FilterByMinContext(%d) kept %v, want %s", tt.minCtx, got, tt.want)
+		}
+	}
+}
+
 func TestUseCaseFromModel(t *testing.T) {
 	tests := []struct {
 		name string
@@ -256,3 +467,262 @@ func TestUseCase_String(t *testing.T) {
 		}
 	}
 }
+
+func TestIsListStale(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	days := func(n int) time.Time { return now.Add(-time.Duration(n) * 24 * time.Hour) }
+	tests := []struct {
+		name      string
+		generated time.Time
+		cache     time.Time
+		want      bool
+	}{
+		{"fresh list, no cache", days(10), time.Time{}, false},
+		{"old list, no cache", days(120), time.Time{}, true},
+		{"old list, fresh cache", days(120), days(5), false},
+		{"old list, old cache", days(200), days(100), true},
+		{"unknown generation date", time.Time{}, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := IsListStale(tt.generated, tt.cache, now, StaleListAge)
+			if got != tt.want {
+				t.Errorf("IsListStale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListGeneratedAt(t *testing.T) {
+	got, err := ListGeneratedAt()
+	if err != nil {
+		t.Fatalf("ListGeneratedAt() err = %v", err)
+	}
+	if got.IsZero() {
+		t.Error("ListGeneratedAt() returned zero time")
+	}
+}
+
+func TestGroupDuplicates(t *testing.T) {
+	ms := []*LlmModel{
+		{Name: "meta-llama/Llama-3.1-8B-Instruct", ParameterCount: "8B"},
+		{Name: "bartowski/Llama-3.1-8B-Instruct-GGUF", ParameterCount: "8B"},
+		{Name: "someone/llama-3.1-8b-instruct-AWQ", ParameterCount: "8B"},
+		{Name: "Qwen/Qwen2.5-7B-Instruct", ParameterCount: "7B"},
+		{Name: "meta-llama/Llama-3.1-70B-Instruct", ParameterCount: "70B"},
+	}
+	groups := GroupDuplicates(ms)
+	if len(groups) != 3 {
+		t.Fatalf("len(groups) = %d, want 3", len(groups))
+	}
+	if groups[0].Representative != ms[0] || len(groups[0].Variants) != 2 {
+		t.Errorf("group 0 = %s +%d, want %s +2", groups[0].Representative.Name, len(groups[0].Variants), ms[0].Name)
+	}
+	for _, g := range groups[1:] {
+		if len(g.Variants) != 0 {
+			t.Errorf("%s should not have variants, got %d", g.Representative.Name, len(g.Variants))
+		}
+	}
+}
+
+func TestDuplicateKey_DistinctSizes(t *testing.T) {
+	a := &LlmModel{Name: "org/model-instruct", ParameterCount: "7B"}
+	b := &LlmModel{Name: "org/model-instruct", ParameterCount: "14B"}
+	if DuplicateKey(a) == DuplicateKey(b) {
+		t.Error("models with different sizes should not share a duplicate key")
+	}
+}
+
+func TestFilterByMoE(t *testing.T) {
+	ms := []*LlmModel{{Name: "dense-a"}, {Name: "moe-a", IsMoE: true}, {Name: "dense-b"}}
+	moe := FilterByMoE(ms, true)
+	if len(moe) != 1 || moe[0].Name != "moe-a" {
+		t.Errorf("FilterByMoE(true) = %v", moe)
+	}
+	dense := FilterByMoE(ms, false)
+	if len(dense) != 2 {
+		t.Errorf("FilterByMoE(false) len = %d, want 2", len(dense))
+	}
+}
+
+func TestLoadCustomModels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.json")
+	body := `[
+  {"name": "acme/internal-13b", "provider": "Acme", "parameter_count": "13B", "min_ram_gb": 10, "recommended_ram_gb": 16, "context_length": 8192},
+  {"name": "", "parameter_count": "7B", "min_ram_gb": 6, "recommended_ram_gb": 8, "context_length": 4096},
+  {"name": "acme/bad-ram", "parameter_count": "7B", "min_ram_gb": 8, "recommended_ram_gb": 4, "context_length": 4096},
+  {"name": "acme/internal-13b", "parameter_count": "13B", "min_ram_gb": 10, "recommended_ram_gb": 16, "context_length": 8192}
+]`
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, bad, err := LoadCustomModels(path)
+	if err != nil {
+		t.Fatalf("LoadCustomModels: %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "acme/internal-13b" {
+		t.Fatalf("LoadCustomModels: got %d models, want only acme/internal-13b", len(got))
+	}
+	if got[0].Quantization != "Q4_K_M" {
+		t.Errorf("default Quantization = %q, want Q4_K_M", got[0].Quantization)
+	}
+	if len(bad) != 3 {
+		t.Errorf("LoadCustomModels: %d bad entries (%v), want 3", len(bad), bad)
+	}
+	if _, _, err := LoadCustomModels(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadCustomModels(missing) = nil error, want error")
+	}
+}
+
+func TestModelDatabase_MergeCustom(t *testing.T) {
+	db := &ModelDatabase{models: []*LlmModel{
+		{Name: "org/a", Provider: "Catalog", ContextLength: 4096},
+		{Name: "org/b", Provider: "Catalog", ContextLength: 4096},
+	}}
+	db.MergeCustom([]*LlmModel{
+		{Name: "org/b", Provider: "Custom", ContextLength: 32768},
+		{Name: "acme/c", Provider: "Custom", ContextLength: 8192},
+	})
+	all := db.GetAllModels()
+	if len(all) != 3 {
+		t.Fatalf("MergeCustom: %d models, want 3", len(all))
+	}
+	if all[1].Name != "org/b" || all[1].Provider != "Custom" {
+		t.Errorf("MergeCustom: org/b provider = %q, want Custom override", all[1].Provider)
+	}
+	if all[2].Name != "acme/c" {
+		t.Errorf("MergeCustom: last model = %q, want acme/c appended", all[2].Name)
+	}
+}
+
+func TestEstimateMemoryGB_Architecture(t *testing.T) {
+	transformer := &LlmModel{ParameterCount: "7B", Architecture: "llama"}
+	ssm := &LlmModel{ParameterCount: "7B", Architecture: "mamba"}
+	hybrid := &LlmModel{ParameterCount: "7B", Architecture: "jamba"}
+	unknown := &LlmModel{ParameterCount: "7B"}
+	const longCtx = 131072
+
+	tGB := transformer.EstimateMemoryGB("Q4_K_M", longCtx, KVCacheF16)
+	sGB := ssm.EstimateMemoryGB("Q4_K_M", longCtx, KVCacheF16)
+	hGB := hybrid.EstimateMemoryGB("Q4_K_M", longCtx, KVCacheF16)
+	if !(sGB < hGB && hGB < tGB) {
+		t.Errorf("at %d ctx: mamba %.2f, jamba %.2f, llama %.2f GB; want mamba < jamba < llama", longCtx, sGB, hGB, tGB)
+	}
+	// SSM KV growth from 4k to 128k context is near zero.
+	if growth := sGB - ssm.EstimateMemoryGB("Q4_K_M", 4096, KVCacheF16); growth > 0.1 {
+		t.Errorf("mamba KV growth 4k→128k = %.2f GB, want < 0.1", growth)
+	}
+	if got := unknown.EstimateMemoryGB("Q4_K_M", longCtx, KVCacheF16); got != tGB {
+		t.Errorf("unknown architecture = %.2f GB, want transformer default %.2f", got, tGB)
+	}
+	if !ssm.IsStateSpace() || transformer.IsStateSpace() || hybrid.IsStateSpace() {
+		t.Error("IsStateSpace: want true only for mamba")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	u32 := func(v uint32) *uint32 { return &v }
+	u64 := func(v uint64) *uint64 { return &v }
+	f64 := func(v float64) *float64 { return &v }
+	good := func(name string) *LlmModel {
+		return &LlmModel{Name: name, ParameterCount: "7B", ParametersRaw: u64(7e9), MinRAMGB: 8, RecommendedRAMGB: 12, MinVRAMGB: f64(6), ContextLength: 4096}
+	}
+	goodMoE := func(name string) *LlmModel {
+		m := good(name)
+		m.IsMoE, m.NumExperts, m.ActiveExperts, m.ActiveParameters = true, u32(8), u32(2), u64(2e9)
+		return m
+	}
+	tests := []struct {
+		name   string
+		mutate func(m *LlmModel)
+		field  string
+	}{
+		{"moe without expert counts", func(m *LlmModel) { m.NumExperts, m.ActiveExperts = nil, nil }, "num_experts"},
+		{"more active than total experts", func(m *LlmModel) { m.ActiveExperts = u32(9) }, "active_experts"},
+		{"active params not below total", func(m *LlmModel) { m.ActiveParameters = u64(7e9) }, "active_parameters"},
+		{"vram above ram", func(m *LlmModel) { m.MinVRAMGB = f64(9) }, "min_vram_gb"},
+		{"zero context", func(m *LlmModel) { m.ContextLength = 0 }, "context_length"},
+		{"recommended below min", func(m *LlmModel) { m.RecommendedRAMGB = 4 }, "recommended_ram_gb"},
+		{"experts on a dense model", func(m *LlmModel) { m.IsMoE = false }, "is_moe"},
+		{"missing parameter count", func(m *LlmModel) { m.ParameterCount, m.ParametersRaw = "", nil }, "parameter_count"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := goodMoE("bad")
+			tt.mutate(m)
+			issues := Validate([]*LlmModel{good("ok-dense"), goodMoE("ok-moe"), m})
+			if len(issues) != 1 || issues[0].Field != tt.field || issues[0].Index != 3 {
+				t.Errorf("Validate = %v, want one %s issue on entry 3", issues, tt.field)
+			}
+		})
+	}
+	if issues := Validate([]*LlmModel{good("dup"), good("dup")}); len(issues) != 1 || issues[0].Field != "name" {
+		t.Errorf("Validate(duplicates) = %v, want one name issue", issues)
+	}
+	if issues := Validate([]*LlmModel{good("a"), goodMoE("b")}); len(issues) != 0 {
+		t.Errorf("Validate(valid) = %v, want none", issues)
+	}
+}
+
+func TestValidate_EmbeddedList(t *testing.T) {
+	ms, err := EmbeddedModels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range Validate(ms) {
+		t.Error(i)
+	}
+}
+
+func TestDiffModels(t *testing.T) {
+	f64 := func(v float64) *float64 { return &v }
+	embedded := []*LlmModel{
+		{Name: "org/kept", ParameterCount: "7B", MinRAMGB: 8, ContextLength: 4096},
+		{Name: "org/retuned", ParameterCount: "7B", MinRAMGB: 8, MinVRAMGB: f64(6), ContextLength: 4096},
+		{Name: "org/dropped", ParameterCount: "3B", MinRAMGB: 4, ContextLength: 4096},
+	}
+	cache := []*LlmModel{
+		{Name: "org/new", ParameterCount: "14B", MinRAMGB: 12, ContextLength: 32768},
+		{Name: "org/retuned", ParameterCount: "7B", MinRAMGB: 9, ContextLength: 8192},
+		{Name: "org/kept", ParameterCount: "7B", MinRAMGB: 8, ContextLength: 4096},
+	}
+	d := DiffModels(embedded, cache)
+	if len(d.Added) != 1 || d.Added[0] != "org/new" {
+		t.Errorf("Added = %v, want [org/new]", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed[0] != "org/dropped" {
+		t.Errorf("Removed = %v, want [org/dropped]", d.Removed)
+	}
+	if len(d.Changed) != 1 || d.Changed[0].Name != "org/retuned" {
+		t.Fatalf("Changed = %+v, want only org/retuned", d.Changed)
+	}
+	want := []FieldChange{
+		{Field: "min_ram_gb", Old: "8", New: "9"},
+		{Field: "min_vram_gb", Old: "6", New: ""},
+		{Field: "context_length", Old: "4096", New: "8192"},
+	}
+	got := d.Changed[0].Fields
+	if len(got) != len(want) {
+		t.Fatalf("Fields = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Fields[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if d := DiffModels(embedded, embedded); !d.Empty() {
+		t.Errorf("DiffModels(same, same) = %+v, want empty", d)
+	}
+}
+
+func TestDiffOverlay_PartialCacheRemovesNothing(t *testing.T) {
+	embedded := []*LlmModel{
+		{Name: "org/a", ParameterCount: "7B", MinRAMGB: 8, ContextLength: 4096},
+		{Name: "org/b", ParameterCount: "3B", MinRAMGB: 4, ContextLength: 4096},
+	}
+	fetched := []*LlmModel{{Name: "org/fetched", ParameterCount: "14B", MinRAMGB: 12, ContextLength: 32768}}
+	d := DiffOverlay(embedded, fetched)
+	if len(d.Added) != 1 || d.Added[0] != "org/fetched" || len(d.Removed) != 0 || len(d.Changed) != 0 {
+		t.Errorf("DiffOverlay(one fetched model) = %+v, want only org/fetched added", d)
+	}
+}
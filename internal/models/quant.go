@@ -1,8 +1,118 @@
 // Package models provides the model database and quantization helpers.
 package models
 
-// QuantHierarchy lists quantizations from best quality to most compressed (used for best-quant selection).
-var QuantHierarchy = []string{"Q8_0", "Q6_K", "Q5_K_M", "Q4_K_M", "Q3_K_M", "Q2_K"}
+import (
+	"fmt"
+	"strings"
+)
+
+// QuantHierarchy lists quantizations from best quality to most compressed (used for best-quant
+// selection). The K_S and importance-matrix IQ quants sit where their quality falls between
+// the classic K-quants; bytes per parameter shrink strictly along the list.
+var QuantHierarchy = []string{
+	"Q8_0", "Q6_K", "Q5_K_M", "Q5_K_S", "Q4_K_M", "Q4_K_S", "IQ4_XS", "Q3_K_M", "IQ3_M", "Q3_K_S",
+	"IQ3_XXS", "Q2_K", "IQ2_M", "IQ2_XS", "IQ2_XXS",
+}
+
+// MLXQuantHierarchy lists Apple MLX quantizations (affine, group size 64) from best quality to
+// most compressed, as published by mlx-community.
+var MLXQuantHierarchy = []string{"mlx-8bit", "mlx-6bit", "mlx-4bit", "mlx-3bit"}
+
+// Runtime is the inference engine memory and speed are estimated for.
+type Runtime string
+
+const (
+	RuntimeLlamaCpp Runtime = "llama.cpp" // GGUF quants; the default
+	RuntimeMLX      Runtime = "mlx"       // Apple MLX; only applies on the Metal backend
+)
+
+// ParseRuntime parses a --runtime value; "" is llama.cpp.
+func ParseRuntime(s string) (Runtime, error) {
+	switch strings.ToLower(s) {
+	case "", "llama.cpp", "llamacpp", "gguf":
+		return RuntimeLlamaCpp, nil
+	case "mlx":
+		return RuntimeMLX, nil
+	}
+	return "", fmt.Errorf("unknown runtime %q (want llama.cpp or mlx)", s)
+}
+
+// KVCacheType is the element type of the KV cache, as set by llama.cpp's --cache-type-k and
+// --cache-type-v. Quantized caches trade a little accuracy for a much smaller cache.
+type KVCacheType string
+
+const (
+	KVCacheF16 KVCacheType = "f16"  // full precision; the default
+	KVCacheQ8  KVCacheType = "q8_0" // half the F16 size
+	KVCacheQ4  KVCacheType = "q4_0" // a quarter of the F16 size
+)
+
+// ParseKVCacheType parses a --kv-cache-type value; "" is f16.
+func ParseKVCacheType(s string) (KVCacheType, error) {
+	switch strings.ToLower(s) {
+	case "", "f16", "fp16":
+		return KVCacheF16, nil
+	case "q8_0", "q8":
+		return KVCacheQ8, nil
+	case "q4_0", "q4":
+		return KVCacheQ4, nil
+	}
+	return "", fmt.Errorf("unknown KV cache type %q (want f16, q8_0, or q4_0)", s)
+}
+
+// Factor is the KV cache size relative to F16; unknown types (and "") count as F16.
+func (t KVCacheType) Factor() float64 {
+	switch t {
+	case KVCacheQ8:
+		return 0.5
+	case KVCacheQ4:
+		return 0.25
+	default:
+		return 1
+	}
+}
+
+// Quants returns the runtime's quantization hierarchy, best first.
+func (r Runtime) Quants() []string {
+	if r == RuntimeMLX {
+		return MLXQuantHierarchy
+	}
+	return QuantHierarchy
+}
+
+// IsMLXQuant reports whether q is an MLX quantization.
+func IsMLXQuant(q string) bool {
+	return containsQuant(MLXQuantHierarchy, q)
+}
+
+// IsQuantName reports whether q names a known quantization (case-insensitive): one in
+// QuantHierarchy or MLXQuantHierarchy, or an unquantized/legacy format QuantBPP knows.
+func IsQuantName(q string) bool {
+	return CanonicalQuant(q) != ""
+}
+
+// CanonicalQuant returns the known quantization q names, spelled as QuantBPP expects
+// ("q4_k_m" -> "Q4_K_M", "MLX-4BIT" -> "mlx-4bit"), or "" when q is unknown.
+func CanonicalQuant(q string) string {
+	for _, list := range [][]string{QuantHierarchy, MLXQuantHierarchy, {"F32", "F16", "BF16", "Q4_0"}} {
+		for _, c := range list {
+			if strings.EqualFold(c, q) {
+				return c
+			}
+		}
+	}
+	return ""
+}
+
+// containsQuant reports whether quants includes q, ignoring case.
+func containsQuant(quants []string, q string) bool {
+	for _, c := range quants {
+		if strings.EqualFold(c, q) {
+			return true
+		}
+	}
+	return false
+}
 
 // QuantBPP returns bytes per parameter for the given quantization.
 func QuantBPP(quant string) float64 {
@@ -17,32 +127,71 @@ func QuantBPP(quant string) float64 {
 		return 0.80
 	case "Q5_K_M":
 		return 0.68
+	case "Q5_K_S":
+		return 0.66
 	case "Q4_K_M", "Q4_0":
 		return 0.58
+	case "Q4_K_S":
+		return 0.55
+	case "IQ4_XS":
+		return 0.51
 	case "Q3_K_M":
 		return 0.48
+	case "IQ3_M":
+		return 0.44
+	case "Q3_K_S":
+		return 0.41
+	case "IQ3_XXS":
+		return 0.38
 	case "Q2_K":
 		return 0.37
+	case "IQ2_M":
+		return 0.33
+	case "IQ2_XS":
+		return 0.28
+	case "IQ2_XXS":
+		return 0.25
+	// MLX stores an fp16 scale and bias per 64-weight group: 0.5 extra bits per weight.
+	case "mlx-8bit":
+		return 1.0625
+	case "mlx-6bit":
+		return 0.8125
+	case "mlx-4bit":
+		return 0.5625
+	case "mlx-3bit":
+		return 0.4375
 	default:
 		return 0.58
 	}
 }
 
-// QuantSpeedMultiplier returns the relative inference speed factor for the quantization.
+// QuantSpeedMultiplier returns the relative inference speed factor for the quantization. IQ
+// quants decode their codebooks on every token, so they gain less speed than their size suggests,
+// and the smaller ones spend on decoding what they save in bandwidth.
 func QuantSpeedMultiplier(quant string) float64 {
 	switch quant {
 	case "F16", "BF16":
 		return 0.6
-	case "Q8_0":
+	case "Q8_0", "mlx-8bit":
 		return 0.8
-	case "Q6_K":
+	case "Q6_K", "mlx-6bit":
 		return 0.95
 	case "Q5_K_M":
 		return 1.0
-	case "Q4_K_M", "Q4_0":
+	case "Q5_K_S":
+		return 1.02
+	case "IQ4_XS":
+		return 1.05
+	case "IQ3_M", "IQ3_XXS", "IQ2_M", "IQ2_XS", "IQ2_XXS":
+		return 1.1
+	case "Q4_K_M", "Q4_0", "mlx-4bit":
 		return 1.15
-	case "Q3_K_M":
+	case "Q4_K_S":
+		return 1.18
+	case "Q3_K_M", "mlx-3bit":
 		return 1.25
+	case "Q3_K_S":
+		return 1.28
 	case "Q2_K":
 		return 1.35
 	default:
@@ -53,18 +202,41 @@ func QuantSpeedMultiplier(quant string) float64 {
 // QuantQualityPenalty returns the quality score penalty for the quantization (used in scoring).
 func QuantQualityPenalty(quant string) float64 {
 	switch quant {
-	case "F16", "BF16", "Q8_0":
+	case "F16", "BF16", "Q8_0", "mlx-8bit":
 		return 0.0
-	case "Q6_K":
+	case "Q6_K", "mlx-6bit":
 		return -1.0
 	case "Q5_K_M":
 		return -2.0
+	case "Q5_K_S":
+		return -3.0
 	case "Q4_K_M", "Q4_0":
 		return -5.0
+	case "Q4_K_S":
+		return -6.0
+	case "IQ4_XS":
+		return -6.5
 	case "Q3_K_M":
 		return -8.0
+	case "IQ3_M":
+		return -8.5
+	case "Q3_K_S":
+		return -9.5
+	case "IQ3_XXS":
+		return -11.0
+	// Plain affine MLX quants lack the K-quants' higher-precision layers, so lose a little more.
+	case "mlx-4bit":
+		return -6.0
+	case "mlx-3bit":
+		return -10.0
 	case "Q2_K":
 		return -12.0
+	case "IQ2_M":
+		return -14.0
+	case "IQ2_XS":
+		return -17.0
+	case "IQ2_XXS":
+		return -20.0
 	default:
 		return -5.0
 	}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"strconv"
 	"strings"
 )
@@ -38,38 +39,62 @@ func (u UseCase) String() string {
 
 // LlmModel is a single model entry (fields align with hf_models.json and cache).
 type LlmModel struct {
-	Name               string   `json:"name"`
-	Provider           string   `json:"provider"`
-	ParameterCount     string   `json:"parameter_count"`
-	ParametersRaw      *uint64  `json:"parameters_raw,omitempty"`
-	MinRAMGB           float64  `json:"min_ram_gb"`
-	RecommendedRAMGB   float64  `json:"recommended_ram_gb"`
-	MinVRAMGB          *float64 `json:"min_vram_gb,omitempty"`
-	Quantization       string   `json:"quantization"`
-	ContextLength      uint32   `json:"context_length"`
-	UseCase            string   `json:"use_case"`
-	IsMoE              bool     `json:"is_moe"`
-	NumExperts         *uint32  `json:"num_experts,omitempty"`
-	ActiveExperts      *uint32  `json:"active_experts,omitempty"`
-	ActiveParameters   *uint64  `json:"active_parameters,omitempty"`
+	Name              string   `json:"name"`
+	Provider          string   `json:"provider"`
+	ParameterCount    string   `json:"parameter_count"`
+	ParametersRaw     *uint64  `json:"parameters_raw,omitempty"`
+	MinRAMGB          float64  `json:"min_ram_gb"`
+	RecommendedRAMGB  float64  `json:"recommended_ram_gb"`
+	MinVRAMGB         *float64 `json:"min_vram_gb,omitempty"`
+	Quantization      string   `json:"quantization"`
+	ContextLength     uint32   `json:"context_length"`
+	UseCase           string   `json:"use_case"`
+	IsMoE             bool     `json:"is_moe"`
+	NumExperts        *uint32  `json:"num_experts,omitempty"`
+	ActiveExperts     *uint32  `json:"active_experts,omitempty"`
+	ActiveParameters  *uint64  `json:"active_parameters,omitempty"`
+	QuantAvailability *uint32  `json:"quant_availability,omitempty"`
+	Architecture      string   `json:"architecture,omitempty"`
+	// AvailableQuants lists the quantizations known to be published for the model (from the GGUF
+	// file listing); empty when unknown. Best-quant picks are restricted to it when set.
+	AvailableQuants []string `json:"available_quants,omitempty"`
+	// License is the model's license identifier from its HF model card (e.g. "apache-2.0"); empty when unknown.
+	License string `json:"license,omitempty"`
+
+	// UnknownSize is set when neither ParametersRaw nor ParameterCount gives a size;
+	// ParamsB then falls back to an estimate from MinRAMGB.
+	UnknownSize bool `json:"-"`
 }
 
 // hfModelEntry for JSON decode (extra fields ignored).
 type hfModelEntry struct {
-	Name             string   `json:"name"`
-	Provider         string   `json:"provider"`
-	ParameterCount   string   `json:"parameter_count"`
-	ParametersRaw    *uint64  `json:"parameters_raw"`
-	MinRAMGB         float64  `json:"min_ram_gb"`
-	RecommendedRAMGB float64  `json:"recommended_ram_gb"`
-	MinVRAMGB        *float64 `json:"min_vram_gb"`
-	Quantization     string   `json:"quantization"`
-	ContextLength    uint32   `json:"context_length"`
-	UseCase          string   `json:"use_case"`
-	IsMoE            bool     `json:"is_moe"`
-	NumExperts       *uint32  `json:"num_experts"`
-	ActiveExperts    *uint32  `json:"active_experts"`
-	ActiveParameters *uint64  `json:"active_parameters"`
+	Name              string   `json:"name"`
+	Provider          string   `json:"provider"`
+	ParameterCount    string   `json:"parameter_count"`
+	ParametersRaw     *uint64  `json:"parameters_raw"`
+	MinRAMGB          float64  `json:"min_ram_gb"`
+	RecommendedRAMGB  float64  `json:"recommended_ram_gb"`
+	MinVRAMGB         *float64 `json:"min_vram_gb"`
+	Quantization      string   `json:"quantization"`
+	ContextLength     uint32   `json:"context_length"`
+	UseCase           string   `json:"use_case"`
+	IsMoE             bool     `json:"is_moe"`
+	NumExperts        *uint32  `json:"num_experts"`
+	ActiveExperts     *uint32  `json:"active_experts"`
+	ActiveParameters  *uint64  `json:"active_parameters"`
+	QuantAvailability *uint32  `json:"quant_availability"`
+	Architecture      string   `json:"architecture"`
+	AvailableQuants   []string `json:"available_quants"`
+	License           string   `json:"license"`
+}
+
+// HasCommunityQuants reports whether any community GGUF quants are known for the model.
+// The second value is false when no availability data exists (e.g. entries not fetched from HF).
+func (m *LlmModel) HasCommunityQuants() (has bool, known bool) {
+	if m.QuantAvailability == nil {
+		return false, false
+	}
+	return *m.QuantAvailability > 0, true
 }
 
 // ModelDatabase holds the merged model list (embedded + user cache).
@@ -78,50 +103,184 @@ type ModelDatabase struct {
 }
 
 // ParamsB returns parameter count in billions for scoring and memory estimates.
+// It prefers ParametersRaw, then ParameterCount ("7B", "1.5B", "600M", "8x7B"); when neither
+// gives a size it estimates from MinRAMGB at the model's quantization instead of guessing.
 func (m *LlmModel) ParamsB() float64 {
-	if m.ParametersRaw != nil {
+	if m.ParametersRaw != nil && *m.ParametersRaw > 0 {
 		return float64(*m.ParametersRaw) / 1e9
 	}
-	s := strings.TrimSpace(strings.ToUpper(m.ParameterCount))
-	if strings.HasSuffix(s, "B") {
-		n, _ := strconv.ParseFloat(strings.TrimSpace(s[:len(s)-1]), 64)
+	if n, ok := ParseParamCount(m.ParameterCount); ok {
 		return n
 	}
-	if strings.HasSuffix(s, "M") {
-		n, _ := strconv.ParseFloat(strings.TrimSpace(s[:len(s)-1]), 64)
-		return n / 1000
+	overhead := architectureProfile(m.Architecture).overheadGB
+	if m.MinRAMGB > overhead {
+		return (m.MinRAMGB - overhead) / QuantBPP(m.Quantization)
+	}
+	return 0
+}
+
+// ParseParamCount parses a parameter count label into billions: "7B", "1.5 B", "600M", "1.2T",
+// or MoE "8x7B" (experts times per-expert size). The second value is false when s has no size.
+func ParseParamCount(s string) (float64, bool) {
+	s = strings.ReplaceAll(strings.ToUpper(strings.Join(strings.Fields(s), "")), "×", "X")
+	if s == "" {
+		return 0, false
 	}
-	return 7.0
+	scale := 1.0
+	switch s[len(s)-1] {
+	case 'B':
+	case 'M':
+		scale = 1e-3
+	case 'K':
+		scale = 1e-6
+	case 'T':
+		scale = 1e3
+	default:
+		return 0, false
+	}
+	s = s[:len(s)-1]
+	mult := 1.0
+	if experts, size, ok := strings.Cut(s, "X"); ok {
+		k, err := strconv.ParseFloat(experts, 64)
+		if err != nil || k <= 0 {
+			return 0, false
+		}
+		mult, s = k, size
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return mult * n * scale, true
+}
+
+// EstimateMemoryForParams returns estimated memory in GB to run a model of paramsB billion
+// parameters at quant with a ctx-token context, treated as a standard transformer. It is the
+// estimate behind LlmModel.EstimateMemoryGB, for callers without a catalog entry.
+func EstimateMemoryForParams(paramsB float64, quant string, ctx uint32) float64 {
+	weights, kv, overhead := memoryBreakdown(paramsB, quant, ctx, KVCacheF16, defaultArchProfile)
+	return weights + kv + overhead
+}
+
+// EstimateVRAMForParams returns estimated VRAM in GB for the weights of paramsB billion
+// parameters at quant, plus 10% for GPU runtime buffers and at least 0.5 GB. The KV cache is not
+// included. MoE active-expert VRAM uses the same estimate.
+func EstimateVRAMForParams(paramsB float64, quant string) float64 {
+	sizeGB := paramsB * 1e9 * QuantBPP(quant) / float64(1024*1024*1024)
+	return math.Max(sizeGB*1.1, 0.5)
+}
+
+// memoryBreakdown is the memory estimate shared by EstimateMemoryForParams and the LlmModel methods.
+func memoryBreakdown(paramsB float64, quant string, ctx uint32, kvType KVCacheType, p archProfile) (weights, kv, overhead float64) {
+	return paramsB * QuantBPP(quant), kvCacheGB(paramsB, ctx, kvType, p), p.overheadGB
+}
+
+// kvCacheGB is the F16 KV-cache heuristic, scaled by the architecture and the cache type.
+func kvCacheGB(paramsB float64, ctx uint32, kvType KVCacheType, p archProfile) float64 {
+	return 0.000008 * paramsB * float64(ctx) * p.kvScale * kvType.Factor()
 }
 
-// EstimateMemoryGB returns estimated memory in GB for the given quant and context length.
-func (m *LlmModel) EstimateMemoryGB(quant string, ctx uint32) float64 {
-	bpp := QuantBPP(quant)
-	params := m.ParamsB()
-	modelMem := params * bpp
-	kvCache := 0.000008 * params * float64(ctx)
-	overhead := 0.5
-	return modelMem + kvCache + overhead
+// EstimateMemoryGB returns estimated memory in GB for the given quant and context length, with
+// a KV cache of type kv.
+func (m *LlmModel) EstimateMemoryGB(quant string, ctx uint32, kv KVCacheType) float64 {
+	weights, kvGB, overhead := m.MemoryBreakdownGB(quant, ctx, kv)
+	return weights + kvGB + overhead
+}
+
+// MemoryBreakdownGB splits EstimateMemoryGB into weights at quant, KV cache of type kv at ctx
+// tokens, and the architecture's fixed runtime overhead.
+func (m *LlmModel) MemoryBreakdownGB(quant string, ctx uint32, kv KVCacheType) (weights, kvGB, overhead float64) {
+	return memoryBreakdown(m.ParamsB(), quant, ctx, kv, architectureProfile(m.Architecture))
+}
+
+// KVCacheGB returns the estimated memory in GB of a kv-type KV cache for one sequence of ctx tokens.
+func (m *LlmModel) KVCacheGB(ctx uint32, kv KVCacheType) float64 {
+	return kvCacheGB(m.ParamsB(), ctx, kv, architectureProfile(m.Architecture))
 }
 
 // BestQuantForBudget returns the best quantization that fits the given memory budget, and its memory GB.
+// Only quants the model is known to ship in are considered (see QuantCandidates).
 func (m *LlmModel) BestQuantForBudget(budgetGB float64, ctx uint32) (string, float64) {
-	for _, q := range QuantHierarchy {
-		mem := m.EstimateMemoryGB(q, ctx)
+	return m.BestQuantAmong(m.QuantCandidates(""), budgetGB, ctx, KVCacheF16)
+}
+
+// BestQuantAmong returns the first of quants (best first) that fits the budget at ctx with a kv
+// KV cache, retrying at half the context, and its memory GB. When none fits it returns the
+// model's own quantization, or the lightest of quants when that is not among them.
+func (m *LlmModel) BestQuantAmong(quants []string, budgetGB float64, ctx uint32, kv KVCacheType) (string, float64) {
+	for _, q := range quants {
+		mem := m.EstimateMemoryGB(q, ctx, kv)
 		if mem <= budgetGB {
 			return q, mem
 		}
 	}
 	halfCtx := ctx / 2
 	if halfCtx >= 1024 {
-		for _, q := range QuantHierarchy {
-			mem := m.EstimateMemoryGB(q, halfCtx)
+		for _, q := range quants {
+			mem := m.EstimateMemoryGB(q, halfCtx, kv)
 			if mem <= budgetGB {
 				return q, mem
 			}
 		}
 	}
-	return m.Quantization, m.EstimateMemoryGB(m.Quantization, ctx)
+	fallback := m.Quantization
+	if len(quants) > 0 && !containsQuant(quants, fallback) {
+		fallback = quants[len(quants)-1]
+	}
+	return fallback, m.EstimateMemoryGB(fallback, ctx, kv)
+}
+
+// QuantCandidates returns the QuantHierarchy entries a best-quant pick may use, best first.
+// Known AvailableQuants restrict the list. Quants heavier than maxQuant are dropped; an empty
+// maxQuant means no cap, except for models known to have no community quants, whose stated
+// Quantization is then the only artifact and caps the list.
+func (m *LlmModel) QuantCandidates(maxQuant string) []string {
+	quants := QuantHierarchy
+	if len(m.AvailableQuants) > 0 {
+		quants = nil
+		for _, q := range QuantHierarchy {
+			if containsQuant(m.AvailableQuants, q) {
+				quants = append(quants, q)
+			}
+		}
+	}
+	if maxQuant == "" {
+		if has, known := m.HasCommunityQuants(); known && !has {
+			maxQuant = m.Quantization
+		}
+	}
+	quants = capQuants(quants, maxQuant)
+	if len(quants) == 0 {
+		return []string{m.Quantization}
+	}
+	return quants
+}
+
+// RuntimeQuantCandidates is QuantCandidates for the given runtime. MLX picks come from
+// MLXQuantHierarchy, capped by maxQuant; GGUF availability data does not apply to them.
+func (m *LlmModel) RuntimeQuantCandidates(rt Runtime, maxQuant string) []string {
+	if rt != RuntimeMLX {
+		return m.QuantCandidates(maxQuant)
+	}
+	if quants := capQuants(MLXQuantHierarchy, maxQuant); len(quants) > 0 {
+		return quants
+	}
+	return MLXQuantHierarchy[len(MLXQuantHierarchy)-1:]
+}
+
+// capQuants drops the quants heavier than maxQuant; an empty maxQuant keeps them all.
+func capQuants(quants []string, maxQuant string) []string {
+	if maxQuant == "" {
+		return quants
+	}
+	limit := QuantBPP(maxQuant)
+	var capped []string
+	for _, q := range quants {
+		if QuantBPP(q) <= limit {
+			capped = append(capped, q)
+		}
+	}
+	return capped
 }
 
 func (m *LlmModel) quantBPP() float64 {
@@ -133,13 +292,18 @@ func (m *LlmModel) MoeActiveVRAMGB() *float64 {
 	if !m.IsMoE || m.ActiveParameters == nil {
 		return nil
 	}
-	activeParams := float64(*m.ActiveParameters)
-	bpp := m.quantBPP()
-	sizeGB := (activeParams * bpp) / float64(1024*1024*1024)
-	v := sizeGB * 1.1
-	if v < 0.5 {
-		v = 0.5
+	v := EstimateVRAMForParams(float64(*m.ActiveParameters)/1e9, m.Quantization)
+	return &v
+}
+
+// MoeExpertGB returns the memory of one inactive expert (the offloaded experts' RAM split evenly),
+// or nil if not MoE or the expert counts are unknown.
+func (m *LlmModel) MoeExpertGB() *float64 {
+	offload := m.MoeOffloadedRAMGB()
+	if offload == nil || m.NumExperts == nil || m.ActiveExperts == nil || *m.NumExperts <= *m.ActiveExperts {
+		return nil
 	}
+	v := *offload / float64(*m.NumExperts-*m.ActiveExperts)
 	return &v
 }
 
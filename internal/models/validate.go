@@ -0,0 +1,107 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Issue is one problem found in a catalog entry.
+type Issue struct {
+	Index   int    `json:"index"` // 1-based position in the list
+	Name    string `json:"name"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("entry %d (%q): %s: %s", i.Index, i.Name, i.Field, i.Message)
+}
+
+// Validate checks catalog entries for missing, impossible, or mutually inconsistent fields and
+// returns every issue found, in list order. It does not modify the models.
+func Validate(ms []*LlmModel) []Issue {
+	var issues []Issue
+	seen := make(map[string]int)
+	for i, m := range ms {
+		add := func(field, format string, args ...interface{}) {
+			issues = append(issues, Issue{Index: i + 1, Name: m.Name, Field: field, Message: fmt.Sprintf(format, args...)})
+		}
+		if m.Name == "" {
+			add("name", "missing")
+		} else if first, dup := seen[m.Name]; dup {
+			add("name", "duplicate of entry %d", first)
+		} else {
+			seen[m.Name] = i + 1
+		}
+		if m.ParameterCount == "" && m.ParametersRaw == nil {
+			add("parameter_count", "missing (and no parameters_raw)")
+		} else if _, ok := ParseParamCount(m.ParameterCount); !ok && m.ParametersRaw == nil {
+			add("parameter_count", "%q is not a size like 7B, 600M, or 8x7B (and no parameters_raw)", m.ParameterCount)
+		}
+		if m.ContextLength == 0 {
+			add("context_length", "is zero")
+		}
+		if m.MinRAMGB <= 0 {
+			add("min_ram_gb", "must be positive, got %g", m.MinRAMGB)
+		}
+		if m.RecommendedRAMGB < m.MinRAMGB {
+			add("recommended_ram_gb", "%g is below min_ram_gb %g", m.RecommendedRAMGB, m.MinRAMGB)
+		}
+		if m.MinVRAMGB != nil {
+			if *m.MinVRAMGB <= 0 {
+				add("min_vram_gb", "must be positive when set, got %g", *m.MinVRAMGB)
+			} else if *m.MinVRAMGB > m.MinRAMGB {
+				add("min_vram_gb", "%g exceeds min_ram_gb %g", *m.MinVRAMGB, m.MinRAMGB)
+			}
+		}
+		for _, q := range m.AvailableQuants {
+			if !IsQuantName(q) {
+				add("available_quants", "unknown quantization %q", q)
+			}
+		}
+		validateMoE(m, add)
+	}
+	return issues
+}
+
+func validateMoE(m *LlmModel, add func(field, format string, args ...interface{})) {
+	if !m.IsMoE {
+		if m.NumExperts != nil || m.ActiveExperts != nil {
+			add("is_moe", "false but expert counts are set")
+		}
+		return
+	}
+	if m.NumExperts == nil || m.ActiveExperts == nil {
+		add("num_experts", "is_moe is set but num_experts/active_experts are missing")
+	} else if *m.ActiveExperts == 0 || *m.ActiveExperts > *m.NumExperts {
+		add("active_experts", "%d active of %d experts", *m.ActiveExperts, *m.NumExperts)
+	}
+	if m.ActiveParameters == nil {
+		add("active_parameters", "missing for a MoE model")
+	} else if m.ParametersRaw != nil && *m.ActiveParameters >= *m.ParametersRaw {
+		add("active_parameters", "%d is not below parameters_raw %d", *m.ActiveParameters, *m.ParametersRaw)
+	}
+}
+
+// ReadModelsFile parses a catalog-format JSON file into models, without defaults or validation.
+func ReadModelsFile(path string) ([]*LlmModel, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []hfModelEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	out := make([]*LlmModel, 0, len(entries))
+	for i := range entries {
+		out = append(out, entryToModel(&entries[i]))
+	}
+	return out, nil
+}
+
+// EmbeddedModels returns the catalog compiled into the binary, without the user cache.
+func EmbeddedModels() ([]*LlmModel, error) {
+	return loadEmbedded()
+}
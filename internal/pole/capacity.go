@@ -0,0 +1,59 @@
+package pole
+
+import "math"
+
+// Capacity is how many simultaneous requests of one context length fit in the memory a fit
+// runs from, after loading the weights at its best quant.
+type Capacity struct {
+	Quant          string  `json:"quant"`
+	Context        uint32  `json:"context"`
+	MemoryGB       float64 `json:"memory_gb"` // usable memory after the safety margin
+	WeightsGB      float64 `json:"weights_gb"`
+	KVPerRequestGB float64 `json:"kv_per_request_gb"`
+	Concurrent     int     `json:"concurrent"`
+}
+
+// EstimateCapacity returns how many ctx-token KV caches fit beside fit's weights in its memory
+// pool (VRAM for GPU run modes). Too Tight fits have no capacity.
+func EstimateCapacity(fit *ModelFit, ctx uint32, opts Options) Capacity {
+	m := fit.Model
+	c := Capacity{
+		Quant:          fit.BestQuant,
+		Context:        ctx,
+		MemoryGB:       opts.usable(fit.MemoryAvailableGB),
+		WeightsGB:      m.EstimateMemoryGB(fit.BestQuant, 0, opts.kvCache()),
+		KVPerRequestGB: m.KVCacheGB(ctx, opts.kvCache()),
+	}
+	free := c.MemoryGB - c.WeightsGB
+	if fit.FitLevel == FitTooTight || free <= 0 || c.KVPerRequestGB <= 0 {
+		return c
+	}
+	c.Concurrent = int(math.Floor(free / c.KVPerRequestGB))
+	return c
+}
+
+// MemoryEstimate is the memory a fit's model needs at its best quant and analysis context.
+type MemoryEstimate struct {
+	Quant      string  `json:"quant"`
+	Context    uint32  `json:"context"`
+	WeightsGB  float64 `json:"weights_gb"`
+	KVCacheGB  float64 `json:"kv_cache_gb"`
+	OverheadGB float64 `json:"overhead_gb"`
+	TotalGB    float64 `json:"total_gb"`
+}
+
+// EstimateMemory returns the memory fit's model needs at fit.BestQuant, at the context length
+// analysis sizes for (the model's full context, or the workload's).
+func EstimateMemory(fit *ModelFit, opts Options) MemoryEstimate {
+	m := fit.Model
+	ctx := opts.analysisContext(m.ContextLength)
+	weights, kv, overhead := m.MemoryBreakdownGB(fit.BestQuant, ctx, opts.kvCache())
+	return MemoryEstimate{
+		Quant:      fit.BestQuant,
+		Context:    ctx,
+		WeightsGB:  weights,
+		KVCacheGB:  kv,
+		OverheadGB: overhead,
+		TotalGB:    m.EstimateMemoryGB(fit.BestQuant, ctx, opts.kvCache()),
+	}
+}
@@ -0,0 +1,75 @@
+package pole
+
+import (
+	"fmt"
+	"math"
+)
+
+// Sides of a two-model comparison; a third model is "c", and so on (see Side).
+const (
+	WinnerA   = "a"
+	WinnerB   = "b"
+	WinnerTie = "tie"
+)
+
+// CompareDimensions are the score components a comparison reports winners for, in display order.
+var CompareDimensions = []string{"quality", "speed", "fit", "context"}
+
+// Comparison says which of the compared fits is ahead on each score component and overall.
+type Comparison struct {
+	Winners map[string]string // CompareDimensions entry -> the leader's Side, or WinnerTie
+	Overall string
+}
+
+// compareTolerance is how close two scores must be to tie: they print the same at one decimal.
+const compareTolerance = 0.05
+
+// Side labels the i-th of the compared fits: "a", "b", "c", …, then "#27" and on.
+func Side(i int) string {
+	if i < 26 {
+		return string(rune('a' + i))
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
+
+// CompareFits compares a and b on each ScoreComponents dimension and on the overall score.
+func CompareFits(a, b *ModelFit) Comparison {
+	return CompareAll([]*ModelFit{a, b})
+}
+
+// CompareAll compares any number of fits on each ScoreComponents dimension and on the overall
+// score. A dimension goes to the Side of the highest fit, or is a tie when the runner-up is
+// within rounding of it.
+func CompareAll(fits []*ModelFit) Comparison {
+	pick := func(score func(f *ModelFit) float64) string {
+		vals := make([]float64, len(fits))
+		for i, f := range fits {
+			vals[i] = score(f)
+		}
+		return leader(vals)
+	}
+	return Comparison{
+		Winners: map[string]string{
+			"quality": pick(func(f *ModelFit) float64 { return f.ScoreComponents.Quality }),
+			"speed":   pick(func(f *ModelFit) float64 { return f.ScoreComponents.Speed }),
+			"fit":     pick(func(f *ModelFit) float64 { return f.ScoreComponents.Fit }),
+			"context": pick(func(f *ModelFit) float64 { return f.ScoreComponents.Context }),
+		},
+		Overall: pick(func(f *ModelFit) float64 { return f.Score }),
+	}
+}
+
+func leader(vals []float64) string {
+	best := 0
+	for i, v := range vals {
+		if v > vals[best] {
+			best = i
+		}
+	}
+	for i, v := range vals {
+		if i != best && math.Abs(vals[best]-v) < compareTolerance {
+			return WinnerTie
+		}
+	}
+	return Side(best)
+}
@@ -0,0 +1,103 @@
+package pole
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FitCounts tallies fits by level. Runnable is everything except Too Tight.
+type FitCounts struct {
+	Runnable int `json:"runnable"`
+	Perfect  int `json:"perfect"`
+	Good     int `json:"good"`
+	Marginal int `json:"marginal"`
+	TooTight int `json:"too_tight"`
+}
+
+// CountFits tallies fits by fit level.
+func CountFits(fits []*ModelFit) FitCounts {
+	var c FitCounts
+	for _, f := range fits {
+		switch f.FitLevel {
+		case FitPerfect:
+			c.Perfect++
+		case FitGood:
+			c.Good++
+		case FitMarginal:
+			c.Marginal++
+		case FitTooTight:
+			c.TooTight++
+		}
+	}
+	c.Runnable = len(fits) - c.TooTight
+	return c
+}
+
+// NewlyRunnable returns the fits in after whose model is Too Tight (or absent) in before,
+// best score first.
+func NewlyRunnable(before, after []*ModelFit) []*ModelFit {
+	runnable := make(map[string]bool, len(before))
+	for _, f := range before {
+		if f.FitLevel != FitTooTight {
+			runnable[f.Model.Name] = true
+		}
+	}
+	var out []*ModelFit
+	for _, f := range after {
+		if f.FitLevel != FitTooTight && !runnable[f.Model.Name] {
+			out = append(out, f)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+// Limit caps a ranked result list at a fixed count, or at a percentage of the runnable fits
+// so the list scales with the hardware. The zero Limit keeps everything.
+type Limit struct {
+	Count   uint
+	Percent float64 // when > 0, keep this share of runnable fits instead of Count
+}
+
+// ParseLimit parses a --limit value: a count ("5"; "0" means no limit) or a percentage of
+// runnable models ("20%").
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if pct, ok := strings.CutSuffix(s, "%"); ok {
+		p, err := strconv.ParseFloat(strings.TrimSpace(pct), 64)
+		if err != nil || p <= 0 || p > 100 {
+			return Limit{}, fmt.Errorf("invalid limit %q: percentage must be in (0, 100]", s)
+		}
+		return Limit{Percent: p}, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 0)
+	if err != nil {
+		return Limit{}, fmt.Errorf("invalid limit %q: want a count like 5 or a percentage like 20%%", s)
+	}
+	return Limit{Count: uint(n)}, nil
+}
+
+func (l Limit) String() string {
+	if l.Percent > 0 {
+		return strconv.FormatFloat(l.Percent, 'f', -1, 64) + "%"
+	}
+	return strconv.FormatUint(uint64(l.Count), 10)
+}
+
+// Apply truncates ranked fits (runnable first, as from RankModelsByFit) to the limit. A
+// percentage keeps the top ceil(p% of runnable) fits, so at least one whenever anything runs.
+func (l Limit) Apply(fits []*ModelFit) []*ModelFit {
+	n := int(l.Count)
+	if l.Percent > 0 {
+		n = int(math.Ceil(l.Percent * float64(CountFits(fits).Runnable) / 100))
+	} else if n == 0 {
+		return fits
+	}
+	if len(fits) > n {
+		return fits[:n]
+	}
+	return fits
+}
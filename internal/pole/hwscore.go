@@ -0,0 +1,74 @@
+package pole
+
+import (
+	"math"
+
+	"github.com/shayne-snap/llmpole/internal/hardware"
+)
+
+// Hardware score weights; they sum to 100.
+const (
+	hwFastMemoryPoints = 35 // VRAM, or the unified pool: the largest model that runs at GPU speed
+	hwRAMPoints        = 15 // system RAM, for CPU runs and offload
+	hwBackendPoints    = 25 // backend throughput constant relative to CUDA
+	hwBandwidthPoints  = 15 // memory bandwidth class of the fast memory
+	hwCorePoints       = 10 // CPU cores, for CPU runs and prompt processing
+)
+
+// Reference sizes that earn full memory points; larger machines score past them only slightly.
+const (
+	hwFullFastMemoryGB = 192
+	hwFullRAMGB        = 256
+	hwFullCores        = 32
+)
+
+// HardwareScore summarizes a machine's inference capability as a 0–100 index for comparing
+// machines. It depends only on the specs, so the same machine always scores the same:
+//
+//	fast memory  35 × log2(1+GB) / log2(1+192)   VRAM, or the whole pool with unified memory
+//	RAM          15 × log2(1+GB) / log2(1+256)   total system RAM
+//	backend      25 × k / 220                    tok/s constant of the backend; CUDA is 220
+//	bandwidth    15 × class                      discrete VRAM 1.0, unified 0.6, CPU RAM 0.2
+//	cores        10 × cores / 32
+//
+// Memory terms are logarithmic, since each doubling lets the next model size class fit; every
+// term is capped at its weight. Bandwidth is not measured: the class stands in for it.
+func HardwareScore(system *hardware.SystemSpecs) float64 {
+	fast := 0.0
+	if system.HasGPU && system.GpuVRAMGB != nil {
+		fast = *system.GpuVRAMGB
+	}
+	bandwidth := 0.2
+	switch {
+	case fast > 0 && system.UnifiedMemory:
+		bandwidth = 0.6
+	case fast > 0:
+		bandwidth = 1.0
+	}
+	backend := system.Backend
+	if fast == 0 && !isCPUBackend(backend) {
+		// A GPU without usable VRAM runs models on the CPU.
+		backend = hardware.BackendCpuX86
+		if system.Backend == hardware.BackendMetal {
+			backend = hardware.BackendCpuArm
+		}
+	}
+	score := hwFastMemoryPoints*logShare(fast, hwFullFastMemoryGB) +
+		hwRAMPoints*logShare(system.TotalRAMGB, hwFullRAMGB) +
+		hwBackendPoints*math.Min(1, backendSpeedK(backend)/backendSpeedK(hardware.BackendCuda)) +
+		hwBandwidthPoints*bandwidth +
+		hwCorePoints*math.Min(1, float64(system.TotalCPUCores)/hwFullCores)
+	return math.Round(score*10) / 10
+}
+
+// logShare is log2(1+v)/log2(1+full), capped at 1.
+func logShare(v, full float64) float64 {
+	if v <= 0 {
+		return 0
+	}
+	return math.Min(1, math.Log2(1+v)/math.Log2(1+full))
+}
+
+func isCPUBackend(b hardware.GpuBackend) bool {
+	return b == hardware.BackendCpuX86 || b == hardware.BackendCpuArm
+}
@@ -0,0 +1,252 @@
+package pole
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/shayne-snap/llmpole/internal/hardware"
+	"github.com/shayne-snap/llmpole/internal/models"
+)
+
+// DefaultSafetyMargin is the fraction of available memory held back for fragmentation and activations.
+const DefaultSafetyMargin = 0.10
+
+// MaxSafetyMargin caps Options.SafetyMargin: some memory must be left for the model.
+const MaxSafetyMargin = 0.9
+
+// Default fit-label headroom: Good needs 20% more usable memory than required, Marginal just enough.
+const (
+	DefaultGoodHeadroom     = 1.2
+	DefaultMarginalHeadroom = 1.0
+)
+
+// FitThresholds are the headroom multipliers that decide fit labels: a fit is Good when usable
+// memory is at least GoodHeadroom × required, Marginal when at least MarginalHeadroom × required,
+// and Too Tight below that. GPU fits that also cover the recommended memory are Perfect.
+type FitThresholds struct {
+	GoodHeadroom     float64
+	MarginalHeadroom float64
+}
+
+// RunPreference biases run-mode selection in borderline cases.
+type RunPreference int
+
+const (
+	PreferAuto RunPreference = iota // pick the fastest mode that fits within the safety margin
+	PreferGPU                       // load into VRAM even when that eats into the safety margin
+	PreferCPU                       // run CPU-only rather than split a model across VRAM and RAM
+)
+
+// Options tunes the fit analysis. Use DefaultOptions for the standard settings.
+type Options struct {
+	// SafetyMargin is the fraction (0–1) of available memory reserved before fit decisions.
+	SafetyMargin float64
+	// Workload, when set, overrides context targets and the analysis context length (see WithWorkload).
+	Workload *Workload
+	// Fit holds the fit-label headroom multipliers; zero fields use the defaults.
+	Fit FitThresholds
+	// MaxQuant, when set, is the heaviest quantization a best-quant pick may suggest (see WithMaxQuant).
+	MaxQuant string
+	// Runtime selects the quant set and speed model; "" is llama.cpp (see WithRuntime).
+	Runtime models.Runtime
+	// Prefer biases borderline run-mode decisions toward the GPU or the CPU.
+	Prefer RunPreference
+	// SuggestQuants makes Too Tight fits probe lighter quants and name one that would fit.
+	SuggestQuants bool
+	// UsabilityPenalty, when > 0, lowers the score of offloaded and CPU-only runs by up to this
+	// fraction, for their latency (see WithUsabilityPenalty). 0, the default, scores them like GPU runs.
+	UsabilityPenalty float64
+	// DenseOnlyScore makes MoE quality scores start from the active-parameter size tier, plus
+	// MoETotalParamsBonus of the step up to the total-parameter tier. Off, MoE models score by total size.
+	DenseOnlyScore bool
+	// Weights, when set, replaces the per-use-case score weights; nil uses DefaultScoreWeights.
+	Weights *ScoreWeights
+	// PromptTokens is the prompt length first-token latency is estimated for; 0 uses DefaultPromptTokens.
+	PromptTokens uint32
+	// Context, when set, is the context length memory is estimated at, overriding the workload's
+	// (see WithContext). It is capped at each model's own context length.
+	Context uint32
+	// KVCache is the KV cache type memory is estimated for; "" is F16 (see WithKVCache).
+	KVCache models.KVCacheType
+}
+
+// Bounds of Options.Context: below MinContext a model is of little use, and MaxContext keeps the
+// KV-cache estimate within what any runtime can allocate.
+const (
+	MinContext = 512
+	MaxContext = 1 << 20
+)
+
+// DefaultPromptTokens is the representative prompt length for the first-token latency estimate.
+const DefaultPromptTokens = 512
+
+// MoETotalParamsBonus is the share of the quality gap between a MoE model's active-size and
+// total-size tiers that DenseOnlyScore keeps: the extra experts help, but less than dense weights.
+const MoETotalParamsBonus = 0.25
+
+// MaxUsabilityPenalty caps Options.UsabilityPenalty: it tempers scores, it does not replace them.
+const MaxUsabilityPenalty = 0.5
+
+// DefaultOptions returns the options Analyze uses.
+func DefaultOptions() Options {
+	return Options{
+		SafetyMargin: DefaultSafetyMargin,
+		Fit:          FitThresholds{GoodHeadroom: DefaultGoodHeadroom, MarginalHeadroom: DefaultMarginalHeadroom},
+	}
+}
+
+// WithFitThresholds returns o with the given headroom multipliers. Marginal must be at least 1
+// (a model that does not fit cannot be Marginal) and Good at least Marginal.
+func (o Options) WithFitThresholds(good, marginal float64) (Options, error) {
+	if marginal < 1 {
+		return o, fmt.Errorf("marginal headroom %g must be at least 1", marginal)
+	}
+	if good < marginal {
+		return o, fmt.Errorf("good headroom %g must be at least the marginal headroom %g", good, marginal)
+	}
+	o.Fit = FitThresholds{GoodHeadroom: good, MarginalHeadroom: marginal}
+	return o, nil
+}
+
+// WithSafetyMargin returns o reserving margin, a fraction from 0 to MaxSafetyMargin, of
+// available memory before fit decisions.
+func (o Options) WithSafetyMargin(margin float64) (Options, error) {
+	if margin < 0 || margin > MaxSafetyMargin {
+		return o, fmt.Errorf("safety margin %g%% must be between 0%% and %g%%", margin*100, MaxSafetyMargin*100)
+	}
+	o.SafetyMargin = margin
+	return o, nil
+}
+
+// WithMaxQuant returns o with best-quant picks capped at quant (e.g. Q4_K_M); "" removes the cap.
+func (o Options) WithMaxQuant(quant string) (Options, error) {
+	if quant == "" {
+		o.MaxQuant = ""
+		return o, nil
+	}
+	canonical := models.CanonicalQuant(quant)
+	if canonical == "" {
+		return o, fmt.Errorf("unknown quantization %q (want one of %s)", quant, strings.Join(models.QuantHierarchy, ", ")+", "+strings.Join(models.MLXQuantHierarchy, ", "))
+	}
+	o.MaxQuant = canonical
+	return o, nil
+}
+
+// WithUsabilityPenalty returns o with offloaded and CPU-only scores lowered by up to penalty, a
+// fraction from 0 (off) to MaxUsabilityPenalty.
+func (o Options) WithUsabilityPenalty(penalty float64) (Options, error) {
+	if penalty < 0 || penalty > MaxUsabilityPenalty {
+		return o, fmt.Errorf("usability penalty %g%% must be between 0%% and %g%%", penalty*100, MaxUsabilityPenalty*100)
+	}
+	o.UsabilityPenalty = penalty
+	return o, nil
+}
+
+// WithPromptTokens returns o estimating first-token latency for an n-token prompt.
+func (o Options) WithPromptTokens(n int) (Options, error) {
+	if n <= 0 || n > math.MaxUint32 {
+		return o, fmt.Errorf("prompt length %d must be a positive number of tokens", n)
+	}
+	o.PromptTokens = uint32(n)
+	return o, nil
+}
+
+// WithContext returns o estimating memory at an n-token context. n must be at least MinContext;
+// larger values than MaxContext are clamped to it.
+func (o Options) WithContext(n int) (Options, error) {
+	if n < MinContext {
+		return o, fmt.Errorf("context length %d must be at least %d tokens", n, MinContext)
+	}
+	o.Context = uint32(min(n, MaxContext))
+	return o, nil
+}
+
+// WithKVCache returns o estimating a KV cache of the named type (f16, q8_0, or q4_0; "" is f16).
+func (o Options) WithKVCache(name string) (Options, error) {
+	kv, err := models.ParseKVCacheType(name)
+	if err != nil {
+		return o, err
+	}
+	o.KVCache = kv
+	return o, nil
+}
+
+// WithRuntime returns o estimating for the named runtime (llama.cpp or mlx; "" is llama.cpp).
+func (o Options) WithRuntime(name string) (Options, error) {
+	rt, err := models.ParseRuntime(name)
+	if err != nil {
+		return o, err
+	}
+	o.Runtime = rt
+	return o, nil
+}
+
+// runtimeFor returns the runtime estimates use on system: MLX only runs on Apple's Metal
+// backend, so anything else falls back to llama.cpp.
+func (o Options) runtimeFor(system *hardware.SystemSpecs) models.Runtime {
+	if o.Runtime == models.RuntimeMLX && system.Backend == hardware.BackendMetal {
+		return models.RuntimeMLX
+	}
+	return models.RuntimeLlamaCpp
+}
+
+// fitThresholds returns o.Fit with zero fields replaced by the defaults.
+func (o Options) fitThresholds() FitThresholds {
+	t := o.Fit
+	if t.GoodHeadroom == 0 {
+		t.GoodHeadroom = DefaultGoodHeadroom
+	}
+	if t.MarginalHeadroom == 0 {
+		t.MarginalHeadroom = DefaultMarginalHeadroom
+	}
+	return t
+}
+
+// promptTokens returns the prompt length for the first-token latency estimate.
+func (o Options) promptTokens() uint32 {
+	if o.PromptTokens == 0 {
+		return DefaultPromptTokens
+	}
+	return o.PromptTokens
+}
+
+// kvCache returns the KV cache type in effect.
+func (o Options) kvCache() models.KVCacheType {
+	if o.KVCache == "" {
+		return models.KVCacheF16
+	}
+	return o.KVCache
+}
+
+// scoreWeights returns the score weights in effect.
+func (o Options) scoreWeights() *ScoreWeights {
+	if o.Weights == nil {
+		sw := DefaultScoreWeights()
+		return &sw
+	}
+	return o.Weights
+}
+
+// usable returns the memory left for the model after the safety margin.
+func (o Options) usable(gb float64) float64 {
+	m := o.SafetyMargin
+	if m < 0 {
+		m = 0
+	}
+	if m > MaxSafetyMargin {
+		m = MaxSafetyMargin
+	}
+	return gb * (1 - m)
+}
+
+// analysisContext returns the context length memory is estimated at for model.
+func (o Options) analysisContext(modelCtx uint32) uint32 {
+	if o.Context > 0 {
+		return min(o.Context, modelCtx)
+	}
+	if o.Workload == nil || o.Workload.AnalysisContext == 0 || o.Workload.AnalysisContext > modelCtx {
+		return modelCtx
+	}
+	return o.Workload.AnalysisContext
+}
@@ -0,0 +1,102 @@
+package pole
+
+import (
+	"sort"
+
+	"github.com/shayne-snap/llmpole/internal/hardware"
+)
+
+// Memory pools a Plan places models in.
+const (
+	PoolVRAM = "vram"
+	PoolRAM  = "ram"
+)
+
+// PlanEntry is one model of a Plan: its best quant, the memory that takes, and where it goes.
+type PlanEntry struct {
+	Model    string  `json:"model"`
+	Quant    string  `json:"quant"`
+	MemoryGB float64 `json:"memory_gb"`
+	Pool     string  `json:"pool,omitempty"` // PoolVRAM or PoolRAM; empty when it does not fit
+}
+
+// Plan is whether several models can be loaded at the same time, e.g. the coder, embedder,
+// and reranker of an agent stack.
+type Plan struct {
+	Models       []PlanEntry `json:"models"`
+	VRAMGB       float64     `json:"vram_gb"` // usable (free, when detected) after the safety margin; the shared pool on unified memory
+	RAMGB        float64     `json:"ram_gb"`  // usable system RAM; 0 on unified memory
+	TotalGB      float64     `json:"total_gb"`
+	HeadroomGB   float64     `json:"headroom_gb"` // VRAMGB + RAMGB - TotalGB; negative when over
+	Fits         bool        `json:"fits"`
+	OffloadFirst string      `json:"offload_first,omitempty"` // model to move off this machine first when it does not fit
+}
+
+// PlanTogether checks whether fits' models co-fit, each at its best quant with the memory its fit
+// was judged by (RecommendedQuant, the MemoryRequiredGB figure at BestQuant). Largest models are placed first, in VRAM while it has room and in RAM after that.
+// When they do not all fit, OffloadFirst is the smallest model whose removal lets the rest fit,
+// or the largest when no single removal is enough.
+func PlanTogether(fits []*ModelFit, system *hardware.SystemSpecs, opts Options) Plan {
+	p := Plan{RAMGB: opts.usable(system.AvailableRAMGB)}
+	if system.HasGPU && system.GpuVRAMGB != nil {
+		p.VRAMGB = opts.usable(*system.GpuVRAMGB)
+		if !system.UnifiedMemory {
+			p.VRAMGB = opts.usable(*system.FitVRAMGB())
+		}
+		if system.UnifiedMemory {
+			p.RAMGB = 0
+		}
+	}
+	for _, f := range fits {
+		gb := f.RecommendedQuant.MemoryGB
+		p.Models = append(p.Models, PlanEntry{Model: f.Model.Name, Quant: f.BestQuant, MemoryGB: gb})
+		p.TotalGB += gb
+	}
+	p.HeadroomGB = p.VRAMGB + p.RAMGB - p.TotalGB
+	p.Fits = placeModels(p.Models, p.VRAMGB, p.RAMGB)
+	if p.Fits || len(p.Models) == 0 {
+		return p
+	}
+	order := bySize(p.Models)
+	p.OffloadFirst = p.Models[order[0]].Model
+	for i := len(order) - 1; i >= 0; i-- {
+		rest := make([]PlanEntry, 0, len(p.Models)-1)
+		for j, e := range p.Models {
+			if j != order[i] {
+				rest = append(rest, e)
+			}
+		}
+		if placeModels(rest, p.VRAMGB, p.RAMGB) {
+			p.OffloadFirst = p.Models[order[i]].Model
+			break
+		}
+	}
+	return p
+}
+
+// placeModels sets each entry's Pool, largest first, and reports whether all of them fit.
+func placeModels(entries []PlanEntry, vram, ram float64) bool {
+	all := true
+	for _, i := range bySize(entries) {
+		e := &entries[i]
+		switch {
+		case e.MemoryGB <= vram:
+			e.Pool, vram = PoolVRAM, vram-e.MemoryGB
+		case e.MemoryGB <= ram:
+			e.Pool, ram = PoolRAM, ram-e.MemoryGB
+		default:
+			e.Pool, all = "", false
+		}
+	}
+	return all
+}
+
+// bySize returns the indices of entries from the largest footprint to the smallest.
+func bySize(entries []PlanEntry) []int {
+	idx := make([]int, len(entries))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return entries[idx[a]].MemoryGB > entries[idx[b]].MemoryGB })
+	return idx
+}
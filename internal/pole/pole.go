@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"math"
 	"runtime"
-	"sort"
 	"strings"
 
 	"github.com/shayne-snap/llmpole/internal/hardware"
@@ -79,12 +78,88 @@ type ModelFit struct {
 	MemoryAvailableGB  float64          `json:"memory_available_gb"`
 	UtilizationPct     float64          `json:"utilization_pct"`
 	Notes              []string         `json:"notes"`
+	NoteSeverities     []NoteSeverity   `json:"-"`
 	MoeOffloadedGB     *float64         `json:"moe_offloaded_gb,omitempty"`
+	MoeResidentExperts *uint32          `json:"moe_resident_experts,omitempty"` // MoE offload: experts kept in VRAM, active ones included
 	Score              float64          `json:"score"`
 	ScoreComponents    ScoreComponents  `json:"score_components"`
 	EstimatedTPS       float64          `json:"estimated_tps"`
+	EstimatedTPSLow    float64          `json:"estimated_tps_low"`
+	EstimatedTPSHigh   float64          `json:"estimated_tps_high"`
 	BestQuant          string           `json:"best_quant"`
 	UseCase            models.UseCase   `json:"use_case"`
+	UsableContext      uint32           `json:"usable_context"`
+	SuggestedQuant     string           `json:"suggested_quant,omitempty"` // Too Tight: a lighter quant that would fit (Options.SuggestQuants)
+	VariantCount       int              `json:"variant_count,omitempty"`
+	// DefaultQuant is the catalog quantization and RecommendedQuant is BestQuant, each sized at
+	// the analysis context and judged against the same memory, to show the tradeoff between them.
+	DefaultQuant     QuantOption `json:"default_quant"`
+	RecommendedQuant QuantOption `json:"recommended_quant"`
+	// EstimatedPrefillTPS is the prompt processing speed; EstimatedTTFTms is the time to the first
+	// token for an Options.PromptTokens prompt: prefilling it, then generating one token.
+	EstimatedPrefillTPS float64 `json:"estimated_prefill_tps"`
+	EstimatedTTFTms     float64 `json:"estimated_ttft_ms"`
+	// AnalysisContext is the context length memory was estimated at: Options.Context when set
+	// (capped at the model's), a workload's when longer than the catalog's 4096, else 4096.
+	AnalysisContext uint32 `json:"analysis_context"`
+	// KVCacheType is the KV cache type memory was estimated for (Options.KVCache).
+	KVCacheType models.KVCacheType `json:"kv_cache_type"`
+}
+
+// QuantOption is one quantization of a fit's model with its estimated memory and the fit level
+// that memory gives in the fit's memory pool.
+type QuantOption struct {
+	Quant    string   `json:"quant"`
+	MemoryGB float64  `json:"memory_gb"`
+	FitLevel FitLevel `json:"fit_level"`
+}
+
+// NoteSeverity classifies an analysis note: background detail or a warning worth acting on.
+type NoteSeverity int
+
+const (
+	NoteInfo NoteSeverity = iota
+	NoteWarning
+)
+
+// noteList accumulates notes and their severities during analysis.
+type noteList struct {
+	text     []string
+	severity []NoteSeverity
+}
+
+func (n *noteList) info(s string) {
+	n.text = append(n.text, s)
+	n.severity = append(n.severity, NoteInfo)
+}
+
+func (n *noteList) warn(s string) {
+	n.text = append(n.text, s)
+	n.severity = append(n.severity, NoteWarning)
+}
+
+// Warnings returns the notes marked NoteWarning. Notes without a recorded severity count as info.
+func (f *ModelFit) Warnings() []string {
+	var out []string
+	for i, n := range f.Notes {
+		if i < len(f.NoteSeverities) && f.NoteSeverities[i] == NoteWarning {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// ReasoningContextShare is the fraction of a reasoning model's context left for the prompt and
+// answer once hidden chain-of-thought ("thinking") tokens are accounted for.
+const ReasoningContextShare = 0.6
+
+// UsableContext returns the context a use case can actually fill: the full window, or
+// ReasoningContextShare of it for reasoning models.
+func UsableContext(model *models.LlmModel, useCase models.UseCase) uint32 {
+	if useCase == models.UseCaseReasoning {
+		return uint32(float64(model.ContextLength) * ReasoningContextShare)
+	}
+	return model.ContextLength
 }
 
 // FitEmoji returns the status emoji for the fit level (e.g. green for Perfect).
@@ -115,135 +190,395 @@ func (f *ModelFit) RunModeText() string {
 
 // Analyze analyzes one model against system specs and returns fit level, run mode, score, and notes.
 func Analyze(model *models.LlmModel, system *hardware.SystemSpecs) *ModelFit {
-	minVram := model.MinRAMGB
+	return AnalyzeWithOptions(model, system, DefaultOptions())
+}
+
+// AnalyzeWithOptions is Analyze with tunable options (safety margin, etc.).
+func AnalyzeWithOptions(model *models.LlmModel, system *hardware.SystemSpecs, opts Options) *ModelFit {
+	useCase := models.UseCaseFromModel(model)
+	ctx := opts.analysisContext(model.ContextLength)
+	if useCase == models.UseCaseReasoning && opts.Workload != nil && opts.Context == 0 && ctx < model.ContextLength {
+		// Leave room for thinking tokens on top of the workload's context.
+		ctx = uint32(math.Min(float64(ctx)/ReasoningContextShare, float64(model.ContextLength)))
+	}
+	// Catalog requirements include an F16 KV cache at baseKVContext; kvExtra adjusts them to the
+	// analysis context and KV cache type. kvCtx is the context everything is sized for: an
+	// explicit --context as given, a workload's only when longer than the catalog's.
+	kvCtx := uint32(baseKVContext)
+	if opts.Context > 0 || (opts.Workload != nil && ctx > baseKVContext) {
+		kvCtx = ctx
+	}
+	kvExtra := model.KVCacheGB(kvCtx, opts.kvCache()) - model.KVCacheGB(baseKVContext, models.KVCacheF16)
+	minRAM := model.MinRAMGB + kvExtra
+	minVram := minRAM
 	if model.MinVRAMGB != nil {
-		minVram = *model.MinVRAMGB
+		minVram = *model.MinVRAMGB + kvExtra
+	} else if system.GpuVRAMGB != nil {
+		pooled, _ := tensorParallelVRAM(system, *system.GpuVRAMGB, false)
+		minVram = estimateVRAMRequirement(model, opts.usable(pooled), kvExtra, model.RuntimeQuantCandidates(opts.runtimeFor(system), opts.MaxQuant))
+	}
+	var notes noteList
+	usableCtx := UsableContext(model, useCase)
+	if useCase == models.UseCaseReasoning {
+		notes.info(fmt.Sprintf("Reasoning model: thinking tokens use part of the context, about %d of %d tokens usable", usableCtx, model.ContextLength))
+	}
+	switch {
+	case opts.Context > model.ContextLength:
+		notes.info(fmt.Sprintf("Context: %d tokens requested, capped at the model's maximum of %d (+%.1f GB KV cache)", opts.Context, ctx, kvExtra))
+	case opts.Context > 0:
+		notes.info(fmt.Sprintf("Context: sized for %d tokens, model default %d (+%.1f GB KV cache)", kvCtx, model.ContextLength, kvExtra))
+	case opts.Workload != nil:
+		notes.info(fmt.Sprintf("Workload %s: sized for %d-token context (+%.1f GB KV cache)", opts.Workload.Name, kvCtx, kvExtra))
+	}
+	if kv := opts.kvCache(); kv != models.KVCacheF16 {
+		notes.info(fmt.Sprintf("KV cache: %s, %.0f%% of the F16 size (%.1f GB saved at %d tokens)", kv, kv.Factor()*100, model.KVCacheGB(kvCtx, models.KVCacheF16)-model.KVCacheGB(kvCtx, kv), kvCtx))
 	}
-	useCase := models.UseCaseFromModel(model)
-	var notes []string
 
 	var runMode RunMode
 	var memRequired, memAvailable float64
+	gpuInMargin := false // --prefer-gpu placed the model in VRAM held back by the safety margin
+	var moeResident uint32
+	var moeRAM float64 // MoE offload: system RAM held alongside the VRAM in memRequired
 
 	if system.HasGPU {
 		if system.UnifiedMemory {
 			if system.GpuVRAMGB != nil {
-				notes = append(notes, "Unified memory: GPU and CPU share the same pool")
+				notes.info("Unified memory: GPU and CPU share the same pool")
 				if model.IsMoE && model.NumExperts != nil {
 					ne := uint32(0)
 					if model.ActiveExperts != nil {
 						ne = *model.ActiveExperts
 					}
-					notes = append(notes, fmt.Sprintf("MoE: %d/%d experts active (all share unified memory pool)", ne, *model.NumExperts))
+					notes.info(fmt.Sprintf("MoE: %d/%d experts active (all share unified memory pool)", ne, *model.NumExperts))
 				}
 				runMode = RunModeGpu
 				memRequired = minVram
 				memAvailable = *system.GpuVRAMGB
 			} else {
-				runMode, memRequired, memAvailable = cpuPath(model, system, &notes)
+				runMode, memRequired, memAvailable = cpuPath(model, system, minRAM, &notes)
 			}
 		} else if system.GpuVRAMGB != nil {
-			sysVram := *system.GpuVRAMGB
-			if minVram <= sysVram {
-				notes = append(notes, "GPU: model loaded into VRAM")
+			sysVram, gpus := fitVRAM(system, &notes)
+			vramLimit := opts.usable(sysVram)
+			if opts.Prefer == PreferGPU {
+				vramLimit = sysVram
+			}
+			if minVram <= vramLimit {
+				if minVram > opts.usable(sysVram) {
+					gpuInMargin = true
+					notes.warn("Preferring GPU: loaded into VRAM inside the safety margin, so long contexts may run out of memory")
+				}
+				notes.info("GPU: model loaded into VRAM")
+				if gpus > 1 {
+					notes.info(fmt.Sprintf("GPU: split across %d GPUs (tensor parallel), %.1f GB VRAM pooled", gpus, sysVram))
+				}
 				if model.IsMoE && model.NumExperts != nil {
-					notes = append(notes, fmt.Sprintf("MoE: all %d experts loaded in VRAM (optimal)", *model.NumExperts))
+					notes.info(fmt.Sprintf("MoE: all %d experts loaded in VRAM (optimal)", *model.NumExperts))
 				}
 				runMode = RunModeGpu
 				memRequired = minVram
 				memAvailable = sysVram
+			} else if opts.Prefer == PreferCPU && minRAM <= opts.usable(system.AvailableRAMGB) {
+				notes.info("Preferring CPU: running CPU-only instead of splitting the model across VRAM and RAM")
+				runMode, memRequired, memAvailable = cpuPath(model, system, minRAM, &notes)
 			} else if model.IsMoE {
-				runMode, memRequired, memAvailable = moeOffloadPath(model, system, sysVram, minVram, &notes)
-			} else if model.MinRAMGB <= system.AvailableRAMGB {
-				notes = append(notes, "GPU: insufficient VRAM, spilling to system RAM")
-				notes = append(notes, "Performance will be significantly reduced")
+				runMode, memRequired, memAvailable, moeResident, moeRAM = moeOffloadPath(model, system, sysVram, minVram, minRAM, kvExtra, opts, &notes)
+			} else if minRAM <= opts.usable(system.AvailableRAMGB) {
+				notes.warn("GPU: insufficient VRAM, spilling to system RAM")
+				notes.warn("Performance will be significantly reduced")
 				runMode = RunModeCpuOffload
-				memRequired = model.MinRAMGB
+				memRequired = minRAM
 				memAvailable = system.AvailableRAMGB
 			} else {
-				notes = append(notes, "Insufficient VRAM and system RAM")
-				notes = append(notes, fmt.Sprintf("Need %.1f GB VRAM or %.1f GB system RAM", minVram, model.MinRAMGB))
+				notes.warn("Insufficient VRAM and system RAM")
+				notes.warn(fmt.Sprintf("Need %.1f GB VRAM or %.1f GB system RAM", minVram, minRAM))
 				runMode = RunModeGpu
 				memRequired = minVram
 				memAvailable = sysVram
 			}
 		} else {
-			notes = append(notes, "GPU detected but VRAM unknown")
-			runMode, memRequired, memAvailable = cpuPath(model, system, &notes)
+			notes.warn("GPU detected but VRAM unknown")
+			runMode, memRequired, memAvailable = cpuPath(model, system, minRAM, &notes)
 		}
 	} else {
-		runMode, memRequired, memAvailable = cpuPath(model, system, &notes)
+		runMode, memRequired, memAvailable = cpuPath(model, system, minRAM, &notes)
 	}
 
-	fitLevel := scoreFit(memRequired, memAvailable, model.RecommendedRAMGB, runMode)
+	// judge rates a requirement of mem (and ramGB of RAM for MoE offload) in the chosen run mode.
+	judge := func(mem, recommended, ramGB float64) FitLevel {
+		level := scoreFit(mem, opts.usable(memAvailable), recommended, runMode, opts.fitThresholds())
+		if gpuInMargin {
+			// Judged against all of VRAM, but no better than Marginal: there is no headroom left.
+			level = scoreFit(mem, memAvailable, recommended, runMode, opts.fitThresholds())
+			if level != FitTooTight {
+				level = FitMarginal
+			}
+		}
+		if runMode == RunModeMoeOffload {
+			// The inactive experts sit in RAM while the active ones fill VRAM: judge both pools.
+			level = max(level, scoreFit(ramGB, opts.usable(system.AvailableRAMGB), 0, runMode, opts.fitThresholds()))
+		}
+		return level
+	}
+	fitLevel := judge(memRequired, model.RecommendedRAMGB+kvExtra, moeRAM)
 	utilPct := math.MaxFloat64
 	if memAvailable > 0 {
 		utilPct = (memRequired / memAvailable) * 100
 	}
 
 	if runMode == RunModeCpuOnly {
-		notes = append(notes, "No GPU -- inference will be slow")
+		notes.warn("No GPU -- inference will be slow")
 	}
 	if (runMode == RunModeCpuOffload || runMode == RunModeCpuOnly) && system.TotalCPUCores < 4 {
-		notes = append(notes, "Low CPU core count may bottleneck inference")
+		notes.warn("Low CPU core count may bottleneck inference")
+	}
+	if (runMode == RunModeCpuOffload || runMode == RunModeCpuOnly) && system.NumaNodes > 1 {
+		notes.warn(fmt.Sprintf("CPU spans %d NUMA nodes: pin inference to one node (e.g. numactl --cpunodebind=0 --membind=0) to avoid slow cross-node memory access", system.NumaNodes))
+	}
+
+	if runMode != RunModeCpuOnly {
+		gpuKernelNotes(system, &notes)
+	}
+
+	if model.UnknownSize {
+		notes.warn(fmt.Sprintf("Parameter count %q is not a size: estimated %.1fB from memory requirements, so speed and quality scores are approximate", model.ParameterCount, model.ParamsB()))
 	}
 
 	var moeOffloaded *float64
+	var moeResidentExperts *uint32
 	if runMode == RunModeMoeOffload {
 		moeOffloaded = model.MoeOffloadedRAMGB()
+		if moeResident > 0 {
+			moeResidentExperts = &moeResident
+			if extra := moeResident - *model.ActiveExperts; moeOffloaded != nil && extra > 0 {
+				v := math.Max(0, *moeOffloaded-float64(extra)**model.MoeExpertGB())
+				moeOffloaded = &v
+			}
+		}
 	}
 
-	bestQuant, _ := model.BestQuantForBudget(memAvailable, model.ContextLength)
-	if bestQuant != model.Quantization {
-		notes = append(notes, "Best quantization for hardware: "+bestQuant+" (model default: "+model.Quantization+")")
+	rt := opts.runtimeFor(system)
+	switch {
+	case rt == models.RuntimeMLX:
+		notes.info("Runtime MLX: memory and speed estimated for MLX group quantization instead of GGUF")
+	case opts.Runtime == models.RuntimeMLX:
+		notes.info("Runtime MLX needs Apple Silicon (Metal backend): estimated for llama.cpp instead")
+	}
+	// Other quants are sized like memRequired: the stated quant's requirement with its weights
+	// swapped for theirs. Under MoE offload only the active experts' share of the weights is in
+	// VRAM, and the rest shifts the RAM held alongside it.
+	weightShare := 1.0
+	if runMode == RunModeMoeOffload && model.ActiveParameters != nil && model.ParamsB() > 0 {
+		weightShare = math.Min(1, float64(*model.ActiveParameters)/1e9/model.ParamsB())
+	}
+	statedEst := model.EstimateMemoryGB(model.Quantization, kvCtx, opts.kvCache())
+	quantMem := func(q string) (mem, ramGB float64) {
+		delta := model.EstimateMemoryGB(q, kvCtx, opts.kvCache()) - statedEst
+		return math.Max(0, memRequired+delta*weightShare), math.Max(0, moeRAM+delta*(1-weightShare))
+	}
+	// quantMem(q) fits the usable memory exactly when the formula estimate of q fits quantBudget.
+	quantBudget := statedEst + (opts.usable(memAvailable)-memRequired)/weightShare
+	bestQuant, _ := model.BestQuantAmong(model.RuntimeQuantCandidates(rt, opts.MaxQuant), quantBudget, kvCtx, opts.kvCache())
+	if uncapped, _ := model.BestQuantAmong(rt.Quants(), quantBudget, kvCtx, opts.kvCache()); models.QuantBPP(uncapped) > models.QuantBPP(bestQuant) {
+		notes.info(quantCapNote(model, bestQuant, uncapped, opts.MaxQuant))
+	} else if bestQuant != model.Quantization {
+		notes.info(quantChoiceNote(model, bestQuant, rt.Quants(), quantMem, opts.usable(memAvailable), memoryLabel(system, runMode)))
+	}
+	var suggestedQuant string
+	if fitLevel == FitTooTight && opts.SuggestQuants {
+		budget := math.Max(opts.usable(memAvailable), opts.usable(system.AvailableRAMGB))
+		// memRequired is sized for the model's stated quant, so that is the one to go below.
+		if q := lighterQuantThatFits(model, model.Quantization, rt.Quants(), memRequired, kvCtx, opts.kvCache(), budget); q != "" {
+			suggestedQuant = q
+			notes.warn(fmt.Sprintf("Too Tight at %s, but runnable at %s (reduced quality)", model.Quantization, q))
+		}
 	}
 	estimatedTPS := estimateTPS(model, bestQuant, system, runMode)
-	sc := computeScores(model, bestQuant, useCase, estimatedTPS, memRequired, memAvailable)
-	score := weightedScore(sc, useCase)
+	if frac := moeResidentFraction(model, moeResident); runMode == RunModeMoeOffload && frac > 0 {
+		full := estimateTPS(model, bestQuant, system, RunModeGpu)
+		estimatedTPS += frac * (full - estimatedTPS)
+	}
+	tpsLow, tpsHigh := tpsBand(estimatedTPS, runMode)
+	prefillTPS := estimatePrefillTPS(model, system, runMode)
+	quantOption := func(q string) QuantOption {
+		if q == model.Quantization {
+			return QuantOption{q, memRequired, fitLevel}
+		}
+		mem, ramGB := quantMem(q)
+		return QuantOption{q, mem, judge(mem, model.RecommendedRAMGB+kvExtra+mem-memRequired, ramGB)}
+	}
+	sc := computeScores(model, bestQuant, useCase, estimatedTPS, memRequired, memAvailable, opts)
+	score := weightedScore(sc, opts.scoreWeights().For(useCase), opts.Workload)
+	if p := usabilityPenalty(runMode, sc.Speed, opts.UsabilityPenalty); p > 0 {
+		score = math.Round(score*(1-p)*10) / 10
+		notes.info(fmt.Sprintf("Usability: score lowered %.1f%% for %s latency at %.1f tok/s", p*100, runMode, estimatedTPS))
+	}
 	if estimatedTPS > 0 {
-		notes = append(notes, fmt.Sprintf("Estimated speed: %.1f tok/s", estimatedTPS))
+		notes.info(fmt.Sprintf("Estimated speed: %.1f tok/s", estimatedTPS))
 	}
 
 	return &ModelFit{
-		Model:             model,
-		FitLevel:          fitLevel,
-		RunMode:           runMode,
-		MemoryRequiredGB:  memRequired,
-		MemoryAvailableGB: memAvailable,
-		UtilizationPct:    utilPct,
-		Notes:             notes,
-		MoeOffloadedGB:    moeOffloaded,
-		Score:             score,
-		ScoreComponents:   sc,
-		EstimatedTPS:      estimatedTPS,
-		BestQuant:         bestQuant,
-		UseCase:           useCase,
+		Model:               model,
+		FitLevel:            fitLevel,
+		RunMode:             runMode,
+		MemoryRequiredGB:    memRequired,
+		MemoryAvailableGB:   memAvailable,
+		UtilizationPct:      utilPct,
+		Notes:               notes.text,
+		NoteSeverities:      notes.severity,
+		MoeOffloadedGB:      moeOffloaded,
+		MoeResidentExperts:  moeResidentExperts,
+		Score:               score,
+		ScoreComponents:     sc,
+		EstimatedTPS:        estimatedTPS,
+		EstimatedTPSLow:     tpsLow,
+		EstimatedTPSHigh:    tpsHigh,
+		EstimatedPrefillTPS: prefillTPS,
+		EstimatedTTFTms:     ttftMs(opts.promptTokens(), prefillTPS, estimatedTPS),
+		BestQuant:           bestQuant,
+		UseCase:             useCase,
+		UsableContext:       usableCtx,
+		SuggestedQuant:      suggestedQuant,
+		DefaultQuant:        quantOption(model.Quantization),
+		RecommendedQuant:    quantOption(bestQuant),
+		AnalysisContext:     kvCtx,
+		KVCacheType:         opts.kvCache(),
 	}
 }
 
 // AnalyzeAll runs Analyze for each model.
 func AnalyzeAll(models []*models.LlmModel, system *hardware.SystemSpecs) []*ModelFit {
+	return AnalyzeAllWithOptions(models, system, DefaultOptions())
+}
+
+// AnalyzeAllWithOptions runs AnalyzeWithOptions for each model.
+func AnalyzeAllWithOptions(models []*models.LlmModel, system *hardware.SystemSpecs, opts Options) []*ModelFit {
 	out := make([]*ModelFit, 0, len(models))
 	for _, m := range models {
-		out = append(out, Analyze(m, system))
+		out = append(out, AnalyzeWithOptions(m, system, opts))
 	}
 	return out
 }
 
 // RankModelsByFit sorts by score descending, with Too Tight entries last.
 func RankModelsByFit(fits []*ModelFit) []*ModelFit {
-	out := make([]*ModelFit, len(fits))
-	copy(out, fits)
-	sort.Slice(out, func(i, j int) bool {
-		ar, br := out[i].FitLevel != FitTooTight, out[j].FitLevel != FitTooTight
-		if ar && !br {
-			return true
-		}
-		if !ar && br {
-			return false
-		}
-		return out[i].Score > out[j].Score
-	})
+	return RankModelsBy(fits, RankByScore)
+}
+
+// BestRunnable returns the highest-scoring fit that is not Too Tight, or nil when nothing runs.
+func BestRunnable(fits []*ModelFit) *ModelFit {
+	var best *ModelFit
+	for _, f := range fits {
+		if f.FitLevel != FitTooTight && (best == nil || f.Score > best.Score) {
+			best = f
+		}
+	}
+	return best
+}
+
+// fitVRAM returns the discrete GPU memory a model is fitted against, and how many GPUs it is
+// pooled from: the primary GPU's free VRAM when detected, noting how much other processes
+// already hold, else its total VRAM, pooled across GPUs by tensorParallelVRAM.
+func fitVRAM(system *hardware.SystemSpecs, notes *noteList) (float64, uint32) {
+	vram := *system.FitVRAMGB()
+	if used := *system.GpuVRAMGB - vram; used >= 0.05 {
+		notes.info(fmt.Sprintf("%.1f GB VRAM already in use by other processes: fitting against the %.1f GB free", used, vram))
+	}
+	return tensorParallelVRAM(system, vram, system.GpuFreeVRAMGB != nil)
+}
+
+// tensorParallelVRAM pools primaryGB, the primary GPU group's VRAM, with the other discrete GPUs
+// on the same backend, as llama.cpp and vLLM split a model across CUDA or ROCm cards. Every
+// device counts as much as the smallest one, so mixed cards pool conservatively; when that
+// does not beat the primary group alone, it is used as before. With free, other GPUs count
+// their free VRAM when known. It returns the VRAM and the number of devices it spans.
+func tensorParallelVRAM(system *hardware.SystemSpecs, primaryGB float64, free bool) (float64, uint32) {
+	if system.UnifiedMemory || len(system.Gpus) == 0 ||
+		(system.Backend != hardware.BackendCuda && system.Backend != hardware.BackendRocm) {
+		return primaryGB, 1
+	}
+	primaryCount := max(system.Gpus[0].Count, 1)
+	devices := primaryCount
+	smallest := primaryGB / float64(primaryCount)
+	for _, g := range system.Gpus[1:] {
+		if g.Backend != system.Backend || g.Integrated() || g.VRAMGB == nil || g.Count == 0 {
+			continue
+		}
+		vram := *g.VRAMGB
+		if free && g.FreeVRAMGB != nil {
+			vram = *g.FreeVRAMGB
+		}
+		devices += g.Count
+		smallest = math.Min(smallest, vram/float64(g.Count))
+	}
+	if pooled := smallest * float64(devices); pooled > primaryGB {
+		return pooled, devices
+	}
+	return primaryGB, primaryCount
+}
+
+// Minimum NVIDIA levels for the fast CUDA paths of llama.cpp and the runtimes built on it: the
+// tensor-core kernels for quantized matmul and flash attention need Turing (compute 7.5), and
+// current CUDA 12 builds need driver 525 or newer.
+const (
+	ModernKernelsCompute = 7.5
+	MinCUDA12Driver      = 525
+)
+
+// gpuKernelNotes adds notes when the primary NVIDIA GPU's compute capability or driver is too old
+// for modern kernels, so the speed estimate is optimistic. Unknown values add nothing.
+func gpuKernelNotes(system *hardware.SystemSpecs, notes *noteList) {
+	if system.Backend != hardware.BackendCuda || len(system.Gpus) == 0 {
+		return
+	}
+	g := system.Gpus[0]
+	if cc, ok := g.ComputeCapabilityValue(); ok && cc < ModernKernelsCompute {
+		notes.info(fmt.Sprintf("%s has CUDA compute capability %s (below %.1f): no tensor-core kernels for quantized matmul or flash attention, so expect less than the estimated speed; Q4_0 and Q8_0 use simpler kernels that also run well on CPU",
+			g.Name, g.ComputeCapability, ModernKernelsCompute))
+	}
+	if major := g.DriverMajor(); major > 0 && major < MinCUDA12Driver {
+		notes.warn(fmt.Sprintf("NVIDIA driver %s is older than %d, which current CUDA 12 builds need: update the driver, or the runtime may fall back to CPU",
+			g.DriverVersion, MinCUDA12Driver))
+	}
+}
+
+// ClosestAlternative returns the highest-quality runnable fit with target's use case, for when
+// target is Too Tight; ties go to the better-ranked fit. Target and its likely duplicates are
+// skipped. It returns nil when no such model runs.
+func ClosestAlternative(target *ModelFit, fits []*ModelFit) *ModelFit {
+	key := models.DuplicateKey(target.Model)
+	var candidates []*ModelFit
+	for _, f := range fits {
+		if f.UseCase == target.UseCase && f.FitLevel != FitTooTight && models.DuplicateKey(f.Model) != key {
+			candidates = append(candidates, f)
+		}
+	}
+	var best *ModelFit
+	for _, f := range RankModelsByFit(candidates) {
+		if best == nil || f.ScoreComponents.Quality > best.ScoreComponents.Quality {
+			best = f
+		}
+	}
+	return best
+}
+
+// CollapseDuplicates keeps the first (best-ranked) fit of each likely-duplicate group and records
+// how many variants were hidden behind it in VariantCount. The kept fits are copies, so fits is
+// left as it was. Call after RankModelsByFit and after any filters, so that a variant the
+// filters keep is not hidden behind one they drop.
+func CollapseDuplicates(fits []*ModelFit) []*ModelFit {
+	index := make(map[string]int)
+	var out []*ModelFit
+	for _, f := range fits {
+		key := models.DuplicateKey(f.Model)
+		if i, ok := index[key]; ok {
+			out[i].VariantCount += 1 + f.VariantCount
+			continue
+		}
+		index[key] = len(out)
+		kept := *f
+		out = append(out, &kept)
+	}
 	return out
 }
 
@@ -258,6 +593,17 @@ func FilterPerfectOnly(fits []*ModelFit) []*ModelFit {
 	return out
 }
 
+// FilterRunnable drops Too Tight fits.
+func FilterRunnable(fits []*ModelFit) []*ModelFit {
+	var out []*ModelFit
+	for _, f := range fits {
+		if f.FitLevel != FitTooTight {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
 // FilterByUseCase keeps fits matching use case (general, coding, reasoning, chat, multimodal, embedding).
 func FilterByUseCase(fits []*ModelFit, useCase string) []*ModelFit {
 	uc, ok := useCaseFromString(useCase)
@@ -292,52 +638,178 @@ func useCaseFromString(s string) (models.UseCase, bool) {
 	}
 }
 
-func cpuPath(model *models.LlmModel, system *hardware.SystemSpecs, notes *[]string) (RunMode, float64, float64) {
-	*notes = append(*notes, "CPU-only: model loaded into system RAM")
+func cpuPath(model *models.LlmModel, system *hardware.SystemSpecs, minRAM float64, notes *noteList) (RunMode, float64, float64) {
+	notes.info("CPU-only: model loaded into system RAM")
 	if model.IsMoE {
-		*notes = append(*notes, "MoE architecture, but expert offloading requires a GPU")
+		notes.warn("MoE architecture, but expert offloading requires a GPU")
 	}
-	return RunModeCpuOnly, model.MinRAMGB, system.AvailableRAMGB
+	return RunModeCpuOnly, minRAM, system.AvailableRAMGB
 }
 
-func moeOffloadPath(model *models.LlmModel, system *hardware.SystemSpecs, systemVram, totalVram float64, notes *[]string) (RunMode, float64, float64) {
+// moeOffloadPath places a MoE model that does not fit VRAM whole: active experts in VRAM and
+// inactive ones in system RAM, both at once. Besides the run mode, required and available memory,
+// and resident experts, it returns the system RAM the offload holds (0 when it does not offload).
+func moeOffloadPath(model *models.LlmModel, system *hardware.SystemSpecs, systemVram, totalVram, minRAM, kvExtra float64, opts Options, notes *noteList) (RunMode, float64, float64, uint32, float64) {
 	moeVram := model.MoeActiveVRAMGB()
 	if moeVram != nil {
+		v := *moeVram + kvExtra
+		moeVram = &v
 		offload := model.MoeOffloadedRAMGB()
 		offloadGB := 0.0
 		if offload != nil {
 			offloadGB = *offload
 		}
-		if *moeVram <= systemVram && offloadGB <= system.AvailableRAMGB {
-			ne, nn := uint32(0), uint32(0)
-			if model.ActiveExperts != nil {
-				ne = *model.ActiveExperts
+		ne, nn := uint32(0), uint32(0)
+		if model.ActiveExperts != nil {
+			ne = *model.ActiveExperts
+		}
+		if model.NumExperts != nil {
+			nn = *model.NumExperts
+		}
+		if *moeVram <= opts.usable(systemVram) && offloadGB <= opts.usable(system.AvailableRAMGB) {
+			notes.info(fmt.Sprintf("MoE: %d/%d experts active in VRAM (%.1f GB)", ne, nn, *moeVram))
+			extra := moeExtraResident(model, opts.usable(systemVram)-*moeVram)
+			if extra > 0 {
+				offloadGB = math.Max(0, offloadGB-float64(extra)**model.MoeExpertGB())
+				notes.info(fmt.Sprintf("MoE: %d/%d experts resident in VRAM (%d more in spare VRAM); speed sits between offload and full GPU", ne+extra, nn, extra))
 			}
-			if model.NumExperts != nil {
-				nn = *model.NumExperts
+			notes.info(fmt.Sprintf("Inactive experts offloaded to system RAM (%.1f GB)", offloadGB))
+			notes.info(combinedMoENote(*moeVram, offloadGB))
+			resident := ne + extra
+			return RunModeMoeOffload, *moeVram, systemVram, resident, offloadGB
+		}
+		// iGPU + dGPU laptops: active experts that overflow the dGPU can sit in the iGPU's
+		// shared memory, which is carved out of the same system RAM as the inactive experts.
+		if igpu := system.IntegratedGPU(); igpu != nil {
+			inVram := opts.usable(systemVram)
+			overflow := *moeVram - inVram
+			if overflow <= opts.usable(system.SharedGPUMemoryGB()) && offloadGB+overflow <= opts.usable(system.AvailableRAMGB) {
+				notes.info(fmt.Sprintf("MoE: %d/%d experts active: %.1f GB in dGPU VRAM, %.1f GB in %s shared memory", ne, nn, inVram, overflow, igpu.Name))
+				notes.info(fmt.Sprintf("Inactive experts offloaded to system RAM (%.1f GB); %.1f GB of system RAM in use including the iGPU share", offloadGB, offloadGB+overflow))
+				notes.warn("Active experts split across two GPUs: slower than a single-GPU fit")
+				notes.info(combinedMoENote(inVram, offloadGB+overflow))
+				pool := systemVram + math.Min(system.SharedGPUMemoryGB(), system.AvailableRAMGB-offloadGB)
+				return RunModeMoeOffload, *moeVram, pool, ne, offloadGB + overflow
 			}
-			*notes = append(*notes, fmt.Sprintf("MoE: %d/%d experts active in VRAM (%.1f GB)", ne, nn, *moeVram))
-			*notes = append(*notes, fmt.Sprintf("Inactive experts offloaded to system RAM (%.1f GB)", offloadGB))
-			return RunModeMoeOffload, *moeVram, systemVram
+		}
+		if *moeVram <= opts.usable(systemVram) {
+			notes.warn(fmt.Sprintf("MoE offload: active experts fit in VRAM, but the inactive experts need %.1f GB of system RAM and only %.1f GB is usable", offloadGB, opts.usable(system.AvailableRAMGB)))
+		}
+	}
+	if minRAM <= opts.usable(system.AvailableRAMGB) {
+		notes.warn("MoE: insufficient VRAM for expert offloading")
+		notes.warn("Spilling entire model to system RAM")
+		notes.warn("Performance will be significantly reduced")
+		return RunModeCpuOffload, minRAM, system.AvailableRAMGB, 0, 0
+	}
+	notes.warn("Insufficient VRAM and system RAM")
+	if offload := model.MoeOffloadedRAMGB(); moeVram != nil && offload != nil {
+		notes.warn(fmt.Sprintf("Need %.1f GB VRAM (full GPU), or %.1f GB VRAM and %.1f GB system RAM at the same time (MoE offload)", totalVram, *moeVram, *offload))
+	} else {
+		notes.warn(fmt.Sprintf("Need %.1f GB VRAM (full GPU)", totalVram))
+	}
+	return RunModeGpu, totalVram, systemVram, 0, 0
+}
+
+// combinedMoENote states that MoE offload needs its VRAM and system RAM at the same time.
+func combinedMoENote(vramGB, ramGB float64) string {
+	return fmt.Sprintf("MoE offload needs %.1f GB VRAM and %.1f GB system RAM at the same time (%.1f GB combined)", vramGB, ramGB, vramGB+ramGB)
+}
+
+// lighterQuantThatFits returns the heaviest of quants lighter than current whose requirement fits
+// budget, or "" when none does. The requirement is required (sized for current) with the weights
+// re-estimated at the lighter quant, so catalog overheads carry over.
+func lighterQuantThatFits(model *models.LlmModel, current string, quants []string, required float64, ctx uint32, kv models.KVCacheType, budget float64) string {
+	base := model.EstimateMemoryGB(current, ctx, kv)
+	for _, q := range quants {
+		if models.QuantBPP(q) >= models.QuantBPP(current) {
+			continue
+		}
+		est := model.EstimateMemoryGB(q, ctx, kv)
+		if math.Max(required-base+est, est) <= budget {
+			return q
 		}
 	}
-	if model.MinRAMGB <= system.AvailableRAMGB {
-		*notes = append(*notes, "MoE: insufficient VRAM for expert offloading")
-		*notes = append(*notes, "Spilling entire model to system RAM")
-		*notes = append(*notes, "Performance will be significantly reduced")
-		return RunModeCpuOffload, model.MinRAMGB, system.AvailableRAMGB
+	return ""
+}
+
+// moeExtraResident is how many inactive experts also fit in spareVRAM, so runtimes can keep
+// them resident instead of streaming them from RAM.
+func moeExtraResident(model *models.LlmModel, spareVRAM float64) uint32 {
+	per := model.MoeExpertGB()
+	if per == nil || *per <= 0 || spareVRAM <= 0 {
+		return 0
+	}
+	inactive := *model.NumExperts - *model.ActiveExperts
+	return uint32(math.Min(float64(inactive), math.Floor(spareVRAM / *per)))
+}
+
+// moeResidentFraction is the share of inactive experts held in VRAM given resident experts in
+// total: 0 is the plain MoE offload estimate, 1 is as fast as a full-GPU load.
+func moeResidentFraction(model *models.LlmModel, resident uint32) float64 {
+	if model.NumExperts == nil || model.ActiveExperts == nil || resident <= *model.ActiveExperts || *model.NumExperts <= *model.ActiveExperts {
+		return 0
+	}
+	return float64(resident-*model.ActiveExperts) / float64(*model.NumExperts-*model.ActiveExperts)
+}
+
+// estimateVRAMRequirement derives a VRAM requirement for models without MinVRAMGB: weights at the
+// best quant that fits the usable VRAM plus short-context KV cache, instead of the RAM figure
+// (which carries CPU runtime overhead). It never exceeds the RAM requirement.
+func estimateVRAMRequirement(model *models.LlmModel, usableVRAM, kvExtra float64, quants []string) float64 {
+	_, est := model.BestQuantAmong(quants, usableVRAM-kvExtra, baseKVContext, models.KVCacheF16)
+	est += kvExtra
+	if ram := model.MinRAMGB + kvExtra; ram < est {
+		return ram
+	}
+	return est
+}
+
+// memoryLabel names the memory pool the run mode is limited by (for notes).
+func memoryLabel(system *hardware.SystemSpecs, runMode RunMode) string {
+	switch {
+	case runMode == RunModeCpuOnly || runMode == RunModeCpuOffload:
+		return "RAM"
+	case system.UnifiedMemory:
+		return "unified memory"
+	default:
+		return "VRAM"
+	}
+}
+
+// quantChoiceNote explains why BestQuantForBudget picked bestQuant instead of the model default.
+func quantChoiceNote(model *models.LlmModel, bestQuant string, hierarchy []string, quantMem func(string) (float64, float64), memAvailable float64, memLabel string) string {
+	if models.QuantBPP(bestQuant) > models.QuantBPP(model.Quantization) {
+		mem, _ := quantMem(bestQuant)
+		return fmt.Sprintf("Best quantization for hardware: upgraded to %s from model default %s because you have ample %s (%.1f GB needed of %.1f GB usable)",
+			bestQuant, model.Quantization, memLabel, mem, memAvailable)
+	}
+	rejected := model.Quantization
+	for i, q := range hierarchy {
+		if q == bestQuant && i > 0 {
+			rejected = hierarchy[i-1]
+			break
+		}
 	}
-	*notes = append(*notes, "Insufficient VRAM and system RAM")
-	mav := totalVram
-	if model.MoeActiveVRAMGB() != nil {
-		mav = *model.MoeActiveVRAMGB()
+	rejectedMem, _ := quantMem(rejected)
+	return fmt.Sprintf("Best quantization for hardware: chose %s over %s because %s (%.1f GB) exceeds your %.1f GB usable %s (model default: %s)",
+		bestQuant, rejected, rejected, rejectedMem, memAvailable, memLabel, model.Quantization)
+}
+
+// quantCapNote explains why bestQuant was suggested although the heavier uncapped quant would fit.
+func quantCapNote(model *models.LlmModel, bestQuant, uncapped, maxQuant string) string {
+	why := "it is not published for this model"
+	switch {
+	case maxQuant != "" && models.QuantBPP(uncapped) > models.QuantBPP(maxQuant):
+		why = "it exceeds the " + maxQuant + " cap"
+	case len(model.AvailableQuants) == 0:
+		why = "the model is only distributed as " + model.Quantization
 	}
-	*notes = append(*notes, fmt.Sprintf("Need %.1f GB VRAM (full) or %.1f GB (MoE offload) + RAM", totalVram, mav))
-	return RunModeGpu, totalVram, systemVram
+	return fmt.Sprintf("Best quantization for hardware: %s (%s would fit, but %s)", bestQuant, uncapped, why)
 }
 
-func scoreFit(memRequired, memAvailable, recommended float64, runMode RunMode) FitLevel {
-	if memRequired > memAvailable {
+func scoreFit(memRequired, memAvailable, recommended float64, runMode RunMode, t FitThresholds) FitLevel {
+	if memRequired*t.MarginalHeadroom > memAvailable {
 		return FitTooTight
 	}
 	switch runMode {
@@ -345,12 +817,12 @@ func scoreFit(memRequired, memAvailable, recommended float64, runMode RunMode) F
 		if recommended <= memAvailable {
 			return FitPerfect
 		}
-		if memAvailable >= memRequired*1.2 {
+		if memAvailable >= memRequired*t.GoodHeadroom {
 			return FitGood
 		}
 		return FitMarginal
 	case RunModeMoeOffload, RunModeCpuOffload:
-		if memAvailable >= memRequired*1.2 {
+		if memAvailable >= memRequired*t.GoodHeadroom {
 			return FitGood
 		}
 		return FitMarginal
@@ -362,31 +834,16 @@ func scoreFit(memRequired, memAvailable, recommended float64, runMode RunMode) F
 }
 
 func estimateTPS(model *models.LlmModel, quant string, system *hardware.SystemSpecs, runMode RunMode) float64 {
-	k := 70.0
-	switch system.Backend {
-	case hardware.BackendCuda:
-		k = 220
-	case hardware.BackendMetal:
-		k = 160
-	case hardware.BackendRocm:
-		k = 180
-	case hardware.BackendVulkan:
-		k = 150
-	case hardware.BackendSycl:
-		k = 100
-	case hardware.BackendCpuArm:
-		k = 90
-	case hardware.BackendCpuX86:
-		k = 70
-	}
+	k := backendSpeedK(system.Backend)
 	params := model.ParamsB()
 	if params < 0.1 {
 		params = 0.1
 	}
 	base := k / params * models.QuantSpeedMultiplier(quant)
-	if system.TotalCPUCores >= 8 {
-		base *= 1.1
+	if models.IsMLXQuant(quant) {
+		base *= mlxSpeedup
 	}
+	base *= cpuCoreBonus(system)
 	switch runMode {
 	case RunModeMoeOffload:
 		base *= 0.8
@@ -401,9 +858,7 @@ func estimateTPS(model *models.LlmModel, quant string, system *hardware.SystemSp
 			cpuK = 90
 		}
 		base = (cpuK / params) * models.QuantSpeedMultiplier(quant)
-		if system.TotalCPUCores >= 8 {
-			base *= 1.1
-		}
+		base *= cpuCoreBonus(system)
 	}
 	if base < 0.1 {
 		base = 0.1
@@ -411,32 +866,138 @@ func estimateTPS(model *models.LlmModel, quant string, system *hardware.SystemSp
 	return base
 }
 
-func computeScores(model *models.LlmModel, quant string, useCase models.UseCase, estimatedTPS, memRequired, memAvailable float64) ScoreComponents {
+// backendSpeedK is a backend's throughput constant: estimated tok/s for a 1B-parameter model
+// at Q5_K_M, before quantization and CPU core adjustments.
+func backendSpeedK(b hardware.GpuBackend) float64 {
+	switch b {
+	case hardware.BackendCuda:
+		return 220
+	case hardware.BackendMetal:
+		return 160
+	case hardware.BackendRocm:
+		return 180
+	case hardware.BackendVulkan:
+		return 150
+	case hardware.BackendSycl:
+		return 100
+	case hardware.BackendCpuArm:
+		return 90
+	case hardware.BackendCpuX86:
+		return 70
+	}
+	return 70
+}
+
+// estimatePrefillTPS estimates prompt processing speed in tokens per second. Prefill pushes the
+// whole prompt through the weights in batches, so it is compute-bound: it runs many times faster
+// than generation and depends on the backend's compute rather than on the quantization. A MoE
+// model only computes with its active experts, so its active parameters set the pace.
+func estimatePrefillTPS(model *models.LlmModel, system *hardware.SystemSpecs, runMode RunMode) float64 {
+	params := model.ParamsB()
+	if model.IsMoE && model.ActiveParameters != nil {
+		params = float64(*model.ActiveParameters) / 1e9
+	}
+	if params < 0.1 {
+		params = 0.1
+	}
+	k := backendPrefillK(system.Backend)
+	switch runMode {
+	case RunModeMoeOffload:
+		k *= 0.7
+	case RunModeCpuOffload:
+		k *= 0.4
+	case RunModeCpuOnly:
+		k = backendPrefillK(hardware.BackendCpuX86)
+		if runtime.GOARCH == "arm64" {
+			k = backendPrefillK(hardware.BackendCpuArm)
+		}
+	}
+	tps := k / params * cpuCoreBonus(system)
+	if tps < 1 {
+		tps = 1
+	}
+	return tps
+}
+
+// backendPrefillK is a backend's prompt processing constant: estimated prefill tok/s for a
+// 1B-parameter model.
+func backendPrefillK(b hardware.GpuBackend) float64 {
+	switch b {
+	case hardware.BackendCuda:
+		return 12000
+	case hardware.BackendMetal:
+		return 4000
+	case hardware.BackendRocm:
+		return 8000
+	case hardware.BackendVulkan:
+		return 5000
+	case hardware.BackendSycl:
+		return 3000
+	case hardware.BackendCpuArm:
+		return 400
+	case hardware.BackendCpuX86:
+		return 300
+	}
+	return 300
+}
+
+// ttftMs is the time to first token in milliseconds: prefilling promptTokens, then generating one.
+func ttftMs(promptTokens uint32, prefillTPS, tps float64) float64 {
+	return (float64(promptTokens)/prefillTPS + 1/tps) * 1000
+}
+
+// mlxSpeedup is MLX's token-generation advantage over llama.cpp on Apple Silicon.
+const mlxSpeedup = 1.15
+
+// cpuCoreBonus is the speed multiplier for a many-core CPU. Cores spread over several NUMA
+// nodes help less: unpinned threads pay for cross-node memory access, so the bonus is
+// divided by the node count.
+func cpuCoreBonus(system *hardware.SystemSpecs) float64 {
+	if system.TotalCPUCores < 8 {
+		return 1
+	}
+	if system.NumaNodes > 1 {
+		return 1 + 0.1/float64(system.NumaNodes)
+	}
+	return 1.1
+}
+
+// tpsBand returns a low/high range around the tok/s estimate. The band is narrowest for
+// full-GPU runs and widens for offload and CPU modes, where the estimate is least reliable.
+func tpsBand(tps float64, runMode RunMode) (float64, float64) {
+	spread := 0.2
+	switch runMode {
+	case RunModeMoeOffload:
+		spread = 0.3
+	case RunModeCpuOffload:
+		spread = 0.4
+	case RunModeCpuOnly:
+		spread = 0.45
+	}
+	low := tps * (1 - spread)
+	if low < 0.1 {
+		low = 0.1
+	}
+	return low, tps * (1 + spread)
+}
+
+func computeScores(model *models.LlmModel, quant string, useCase models.UseCase, estimatedTPS, memRequired, memAvailable float64, opts Options) ScoreComponents {
 	return ScoreComponents{
-		Quality: qualityScore(model, quant, useCase),
+		Quality: qualityScore(model, quant, useCase, opts.DenseOnlyScore),
 		Speed:   speedScore(estimatedTPS, useCase),
 		Fit:     fitScore(memRequired, memAvailable),
-		Context: contextScore(model, useCase),
+		Context: contextScore(model, useCase, opts.Workload),
 	}
 }
 
-func qualityScore(model *models.LlmModel, quant string, useCase models.UseCase) float64 {
+// qualityScore rates model's expected output quality for useCase at quant. With denseOnly, a
+// MoE model's size tier comes from its active parameters (see Options.DenseOnlyScore).
+func qualityScore(model *models.LlmModel, quant string, useCase models.UseCase, denseOnly bool) float64 {
 	params := model.ParamsB()
-	base := 30.0
-	if params < 1 {
-		base = 30
-	} else if params < 3 {
-		base = 45
-	} else if params < 7 {
-		base = 60
-	} else if params < 10 {
-		base = 75
-	} else if params < 20 {
-		base = 82
-	} else if params < 40 {
-		base = 89
-	} else {
-		base = 95
+	base := sizeQualityBase(params)
+	if denseOnly && model.IsMoE && model.ActiveParameters != nil {
+		active := sizeQualityBase(float64(*model.ActiveParameters) / 1e9)
+		base = active + MoETotalParamsBonus*(base-active)
 	}
 	nameLower := strings.ToLower(model.Name)
 	familyBump := 0.0
@@ -454,6 +1015,10 @@ func qualityScore(model *models.LlmModel, quant string, useCase models.UseCase)
 		familyBump = 1
 	}
 	qPenalty := models.QuantQualityPenalty(quant)
+	availPenalty := 0.0
+	if has, known := model.HasCommunityQuants(); known && !has {
+		availPenalty = -3
+	}
 	taskBump := 0.0
 	switch useCase {
 	case models.UseCaseCoding:
@@ -469,7 +1034,7 @@ func qualityScore(model *models.LlmModel, quant string, useCase models.UseCase)
 			taskBump = 6
 		}
 	}
-	v := base + familyBump + qPenalty + taskBump
+	v := base + familyBump + qPenalty + availPenalty + taskBump
 	if v < 0 {
 		v = 0
 	}
@@ -479,6 +1044,26 @@ func qualityScore(model *models.LlmModel, quant string, useCase models.UseCase)
 	return v
 }
 
+// sizeQualityBase is the base quality score of a model with paramsB billion parameters.
+func sizeQualityBase(paramsB float64) float64 {
+	switch {
+	case paramsB < 1:
+		return 30
+	case paramsB < 3:
+		return 45
+	case paramsB < 7:
+		return 60
+	case paramsB < 10:
+		return 75
+	case paramsB < 20:
+		return 82
+	case paramsB < 40:
+		return 89
+	default:
+		return 95
+	}
+}
+
 func speedScore(tps float64, useCase models.UseCase) float64 {
 	target := 40.0
 	if useCase == models.UseCaseReasoning {
@@ -496,6 +1081,16 @@ func speedScore(tps float64, useCase models.UseCase) float64 {
 	return v
 }
 
+// usabilityPenalty returns the fraction to take off an offloaded or CPU-only run's score: the
+// full maxPenalty when it is unusably slow, shrinking to 0 as its speed score (0–100) reaches the
+// use case's target, so a fast CPU run of a small model is left alone. GPU runs are never penalized.
+func usabilityPenalty(mode RunMode, speedScore, maxPenalty float64) float64 {
+	if maxPenalty <= 0 || mode == RunModeGpu {
+		return 0
+	}
+	return maxPenalty * (1 - math.Min(speedScore, 100)/100)
+}
+
 func fitScore(required, available float64) float64 {
 	if available <= 0 || required > available {
 		return 0
@@ -513,7 +1108,7 @@ func fitScore(required, available float64) float64 {
 	return 50
 }
 
-func contextScore(model *models.LlmModel, useCase models.UseCase) float64 {
+func contextScore(model *models.LlmModel, useCase models.UseCase, w *Workload) float64 {
 	target := uint32(4096)
 	switch useCase {
 	case models.UseCaseCoding, models.UseCaseReasoning:
@@ -521,32 +1116,26 @@ func contextScore(model *models.LlmModel, useCase models.UseCase) float64 {
 	case models.UseCaseEmbedding:
 		target = 512
 	}
-	if model.ContextLength >= target {
+	if w != nil && w.ContextTarget > 0 && useCase != models.UseCaseEmbedding {
+		target = w.ContextTarget
+	}
+	ctx := UsableContext(model, useCase)
+	if ctx >= target {
 		return 100
 	}
-	if model.ContextLength >= target/2 {
+	if ctx >= target/2 {
 		return 70
 	}
 	return 30
 }
 
-func weightedScore(sc ScoreComponents, useCase models.UseCase) float64 {
-	var wq, ws, wf, wc float64
-	switch useCase {
-	case models.UseCaseGeneral:
-		wq, ws, wf, wc = 0.45, 0.30, 0.15, 0.10
-	case models.UseCaseCoding:
-		wq, ws, wf, wc = 0.50, 0.20, 0.15, 0.15
-	case models.UseCaseReasoning:
-		wq, ws, wf, wc = 0.55, 0.15, 0.15, 0.15
-	case models.UseCaseChat:
-		wq, ws, wf, wc = 0.40, 0.35, 0.15, 0.10
-	case models.UseCaseMultimodal:
-		wq, ws, wf, wc = 0.50, 0.20, 0.15, 0.15
-	case models.UseCaseEmbedding:
-		wq, ws, wf, wc = 0.30, 0.40, 0.20, 0.10
-	default:
-		wq, ws, wf, wc = 0.45, 0.30, 0.15, 0.10
+// weightedScore blends sc by weights; a workload's ContextWeight replaces the context share and
+// scales the other three to keep the total.
+func weightedScore(sc ScoreComponents, weights Weights, w *Workload) float64 {
+	wq, ws, wf, wc := weights.Quality, weights.Speed, weights.Fit, weights.Context
+	if w != nil && w.ContextWeight > 0 && w.ContextWeight < 1 && wc < 1 {
+		scale := (1 - w.ContextWeight) / (1 - wc)
+		wq, ws, wf, wc = wq*scale, ws*scale, wf*scale, w.ContextWeight
 	}
 	raw := sc.Quality*wq + sc.Speed*ws + sc.Fit*wf + sc.Context*wc
 	return math.Round(raw*10) / 10
@@ -1,6 +1,10 @@
 package pole
 
 import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/shayne-snap/llmpole/internal/hardware"
@@ -246,3 +250,1349 @@ func TestAnalyzeAll(t *testing.T) {
 		}
 	}
 }
+
+func TestAnalyze_TPSBandByRunMode(t *testing.T) {
+	width := func(f *ModelFit) float64 {
+		return (f.EstimatedTPSHigh - f.EstimatedTPSLow) / f.EstimatedTPS
+	}
+	gpuFit := Analyze(model7B(), specWithGPU(8, 32, false))
+	if gpuFit.RunMode != RunModeGpu {
+		t.Fatalf("RunMode = %v, want RunModeGpu", gpuFit.RunMode)
+	}
+	cpuFit := Analyze(model7B(), specNoGPU(32, 8))
+	if cpuFit.RunMode != RunModeCpuOnly {
+		t.Fatalf("RunMode = %v, want RunModeCpuOnly", cpuFit.RunMode)
+	}
+	offSpec := specWithGPU(2, 32, false)
+	offSpec.AvailableRAMGB = 16
+	offFit := Analyze(model7B(), offSpec)
+	if offFit.RunMode != RunModeCpuOffload {
+		t.Fatalf("RunMode = %v, want RunModeCpuOffload", offFit.RunMode)
+	}
+	for _, f := range []*ModelFit{gpuFit, cpuFit, offFit} {
+		if f.EstimatedTPSLow > f.EstimatedTPS || f.EstimatedTPSHigh < f.EstimatedTPS {
+			t.Errorf("%v: band (%v, %v) does not contain estimate %v", f.RunMode, f.EstimatedTPSLow, f.EstimatedTPSHigh, f.EstimatedTPS)
+		}
+	}
+	if width(gpuFit) >= width(offFit) {
+		t.Errorf("GPU band width %v should be narrower than CPU+GPU %v", width(gpuFit), width(offFit))
+	}
+	if width(gpuFit) >= width(cpuFit) {
+		t.Errorf("GPU band width %v should be narrower than CPU %v", width(gpuFit), width(cpuFit))
+	}
+}
+
+func TestAnalyze_QuantAvailabilityPenalty(t *testing.T) {
+	spec := specWithGPU(8, 32, false)
+	none, some := uint32(0), uint32(5)
+	noQuants := model7B()
+	noQuants.QuantAvailability = &none
+	withQuants := model7B()
+	withQuants.QuantAvailability = &some
+	unknown := model7B()
+	// Pin the published quants so all three pick Q4_K_M (noQuants is capped at its stated quant).
+	withQuants.AvailableQuants = []string{"Q4_K_M"}
+	unknown.AvailableQuants = []string{"Q4_K_M"}
+
+	fNone := Analyze(noQuants, spec)
+	fSome := Analyze(withQuants, spec)
+	fUnknown := Analyze(unknown, spec)
+	if fNone.Score >= fSome.Score {
+		t.Errorf("score without quants = %v, want below score with quants %v", fNone.Score, fSome.Score)
+	}
+	if fUnknown.Score != fSome.Score {
+		t.Errorf("score with unknown availability = %v, want unadjusted %v", fUnknown.Score, fSome.Score)
+	}
+}
+
+func TestAnalyze_QuantChoiceNotes(t *testing.T) {
+	hasNote := func(f *ModelFit, parts ...string) bool {
+		for _, n := range f.Notes {
+			ok := true
+			for _, p := range parts {
+				if !strings.Contains(n, p) {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				return true
+			}
+		}
+		return false
+	}
+	// Abundant VRAM: Q4_K_M default upgraded to Q8_0.
+	rich := Analyze(model7B(), specWithGPU(48, 64, false))
+	if rich.BestQuant != "Q8_0" {
+		t.Fatalf("BestQuant = %q, want Q8_0", rich.BestQuant)
+	}
+	if !hasNote(rich, "upgraded to Q8_0", "ample VRAM") {
+		t.Errorf("missing upgrade-reason note: %v", rich.Notes)
+	}
+	// Tight VRAM with an F16 default: downgrade note names the rejected quant and the budget.
+	m := model7B()
+	m.Quantization = "F16"
+	f16VRAM := 15.0
+	m.MinVRAMGB, m.MinRAMGB = &f16VRAM, 16
+	tight := Analyze(m, specWithGPU(7, 8, false))
+	if tight.BestQuant == "F16" || tight.BestQuant == "Q8_0" {
+		t.Fatalf("BestQuant = %q, want something below Q8_0", tight.BestQuant)
+	}
+	if !hasNote(tight, "chose "+tight.BestQuant+" over", "exceeds your 6.3 GB usable VRAM") {
+		t.Errorf("missing downgrade-reason note: %v", tight.Notes)
+	}
+}
+
+func TestQuantOptions_SizedLikeTheFit(t *testing.T) {
+	m := model7B()
+	m.ContextLength = 131072
+	minVram := 3.6
+	m.MinVRAMGB = &minVram
+	for _, vram := range []float64{4, 6, 24} {
+		f := Analyze(m, specWithGPU(vram, 32, false))
+		if f.DefaultQuant.MemoryGB != f.MemoryRequiredGB || f.DefaultQuant.FitLevel != f.FitLevel {
+			t.Errorf("%.0f GB: default %s at %.1f GB (%s), want the fit's %.1f GB (%s)", vram, f.DefaultQuant.Quant,
+				f.DefaultQuant.MemoryGB, f.DefaultQuant.FitLevel, f.MemoryRequiredGB, f.FitLevel)
+		}
+		rec := f.RecommendedQuant
+		if f.FitLevel != FitTooTight && (rec.FitLevel == FitTooTight || rec.MemoryGB > f.MemoryAvailableGB) {
+			t.Errorf("%.0f GB: recommended %s at %.1f GB (%s) does not fit, but the model does", vram, rec.Quant, rec.MemoryGB, rec.FitLevel)
+		}
+		if models.QuantBPP(rec.Quant) > models.QuantBPP(m.Quantization) && rec.MemoryGB <= f.MemoryRequiredGB {
+			t.Errorf("%.0f GB: recommended %s at %.1f GB, want more than the default's %.1f GB", vram, rec.Quant, rec.MemoryGB, f.MemoryRequiredGB)
+		}
+	}
+}
+
+func TestCollapseDuplicates(t *testing.T) {
+	base := model7B()
+	base.Name = "org/Model-7B"
+	gguf := model7B()
+	gguf.Name = "other/Model-7B-GGUF"
+	distinct := model7B()
+	distinct.Name = "org/Other-7B"
+	fits := []*ModelFit{{Model: base, Score: 80}, {Model: distinct, Score: 70}, {Model: gguf, Score: 60}}
+	out := CollapseDuplicates(fits)
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+	if out[0].Model != base || out[0].VariantCount != 1 {
+		t.Errorf("out[0] = %s (+%d), want %s (+1)", out[0].Model.Name, out[0].VariantCount, base.Name)
+	}
+	if out[1].VariantCount != 0 {
+		t.Errorf("distinct model VariantCount = %d, want 0", out[1].VariantCount)
+	}
+}
+
+func TestCollapseDuplicates_DoesNotMutateInput(t *testing.T) {
+	base := model7B()
+	base.Name = "org/Model-7B"
+	gguf := model7B()
+	gguf.Name = "other/Model-7B-GGUF"
+	fits := []*ModelFit{{Model: base, Score: 80}, {Model: gguf, Score: 60}}
+	CollapseDuplicates(fits)
+	out := CollapseDuplicates(fits)
+	if out[0].VariantCount != 1 {
+		t.Errorf("second call VariantCount = %d, want 1", out[0].VariantCount)
+	}
+	if fits[0].VariantCount != 0 {
+		t.Errorf("input VariantCount = %d after collapsing, want 0", fits[0].VariantCount)
+	}
+}
+
+func TestCollapseDuplicates_AfterPerfectFilterKeepsPerfectVariant(t *testing.T) {
+	base := model7B()
+	base.Name = "org/Model-7B"
+	gguf := model7B()
+	gguf.Name = "other/Model-7B-GGUF"
+	fits := []*ModelFit{
+		{Model: base, Score: 80, FitLevel: FitGood},
+		{Model: gguf, Score: 60, FitLevel: FitPerfect},
+	}
+	out := CollapseDuplicates(FilterPerfectOnly(fits))
+	if len(out) != 1 || out[0].Model != gguf {
+		t.Fatalf("perfect variant hidden: got %d fits", len(out))
+	}
+	if out[0].VariantCount != 0 {
+		t.Errorf("VariantCount = %d, want 0 (the Good variant was filtered)", out[0].VariantCount)
+	}
+}
+
+func TestWithSafetyMargin(t *testing.T) {
+	for _, margin := range []float64{0, 0.25, MaxSafetyMargin} {
+		opts, err := DefaultOptions().WithSafetyMargin(margin)
+		if err != nil || opts.SafetyMargin != margin {
+			t.Errorf("WithSafetyMargin(%g) = %g, %v; want it kept", margin, opts.SafetyMargin, err)
+		}
+	}
+	for _, margin := range []float64{-0.1, 0.95, 2} {
+		if _, err := DefaultOptions().WithSafetyMargin(margin); err == nil {
+			t.Errorf("WithSafetyMargin(%g) = nil error, want out-of-range error", margin)
+		}
+	}
+}
+
+func TestAnalyze_FitThresholds(t *testing.T) {
+	// A 2 GB GPU spills the 8 GB model to RAM; 9 GB usable is 1.125x headroom, short of 1.2x for Good.
+	spec := specWithGPU(2, 16, false)
+	spec.AvailableRAMGB = 10
+	opts := DefaultOptions()
+	if f := AnalyzeWithOptions(model7B(), spec, opts); f.FitLevel != FitMarginal {
+		t.Fatalf("default thresholds: FitLevel = %v, want FitMarginal", f.FitLevel)
+	}
+	loose, err := opts.WithFitThresholds(1.1, 1.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := AnalyzeWithOptions(model7B(), spec, loose); f.FitLevel != FitGood {
+		t.Errorf("good headroom 1.1: FitLevel = %v, want FitGood", f.FitLevel)
+	}
+	strict, _ := opts.WithFitThresholds(1.5, 1.2)
+	if f := AnalyzeWithOptions(model7B(), spec, strict); f.FitLevel != FitTooTight {
+		t.Errorf("marginal headroom 1.2: FitLevel = %v, want FitTooTight", f.FitLevel)
+	}
+	if _, err := opts.WithFitThresholds(1.2, 0.9); err == nil {
+		t.Error("marginal headroom below 1 should be rejected")
+	}
+	if _, err := opts.WithFitThresholds(1.0, 1.1); err == nil {
+		t.Error("good headroom below marginal should be rejected")
+	}
+}
+
+func TestAnalyze_SafetyMargin(t *testing.T) {
+	// Available RAM exactly equals the model's MinRAMGB: a fit with no margin, too tight with the default.
+	spec := specNoGPU(10, 8)
+	spec.AvailableRAMGB = 8
+	noMargin := AnalyzeWithOptions(model7B(), spec, Options{SafetyMargin: 0})
+	if noMargin.FitLevel == FitTooTight {
+		t.Errorf("0%% margin: FitLevel = %v, want a fit", noMargin.FitLevel)
+	}
+	withMargin := Analyze(model7B(), spec)
+	if withMargin.FitLevel != FitTooTight {
+		t.Errorf("default margin: FitLevel = %v, want FitTooTight", withMargin.FitLevel)
+	}
+	// VRAM exactly equals MinVRAMGB: GPU at 0%, spills to RAM at the default margin.
+	gpuSpec := specWithGPU(6, 32, false)
+	if f := AnalyzeWithOptions(model7B(), gpuSpec, Options{}); f.RunMode != RunModeGpu {
+		t.Errorf("0%% margin: RunMode = %v, want RunModeGpu", f.RunMode)
+	}
+	if f := Analyze(model7B(), gpuSpec); f.RunMode != RunModeCpuOffload {
+		t.Errorf("default margin: RunMode = %v, want RunModeCpuOffload", f.RunMode)
+	}
+}
+
+func TestWorkload_RAGFavorsLongContext(t *testing.T) {
+	short := model7B()
+	short.Name = "llama-short-7b" // family bump: ahead on quality
+	long := model7B()
+	long.Name = "long-7b"
+	long.ContextLength = 32768
+	spec := specWithGPU(24, 64, false)
+	all := []*models.LlmModel{short, long}
+
+	def := RankModelsByFit(AnalyzeAll(all, spec))
+	if def[0].Model != short {
+		t.Errorf("default: top = %s, want %s", def[0].Model.Name, short.Name)
+	}
+	opts, err := DefaultOptions().WithWorkload("rag")
+	if err != nil {
+		t.Fatalf("WithWorkload(rag): %v", err)
+	}
+	rag := RankModelsByFit(AnalyzeAllWithOptions(all, spec, opts))
+	if rag[0].Model != long {
+		t.Errorf("rag: top = %s, want %s", rag[0].Model.Name, long.Name)
+	}
+}
+
+func TestWorkload_AnalysisContext(t *testing.T) {
+	m := model7B()
+	m.ContextLength = 32768
+	spec := specWithGPU(24, 64, false)
+	def := Analyze(m, spec)
+	opts, _ := DefaultOptions().WithWorkload("agentic")
+	agentic := AnalyzeWithOptions(m, spec, opts)
+	if agentic.MemoryRequiredGB <= def.MemoryRequiredGB {
+		t.Errorf("agentic MemoryRequiredGB = %.2f, want more than default %.2f", agentic.MemoryRequiredGB, def.MemoryRequiredGB)
+	}
+	if _, err := DefaultOptions().WithWorkload("batch"); err == nil {
+		t.Error("WithWorkload(batch) = nil error, want unknown workload error")
+	}
+	if o, err := DefaultOptions().WithWorkload(""); err != nil || o.Workload != nil {
+		t.Errorf("WithWorkload(\"\") = %v, %v; want no workload", o.Workload, err)
+	}
+}
+
+func TestContext_LongerContextLowersFit(t *testing.T) {
+	m := model7B()
+	m.ContextLength = 131072
+	spec := specWithGPU(16, 64, false)
+	at := func(n int) *ModelFit {
+		opts, err := DefaultOptions().WithContext(n)
+		if err != nil {
+			t.Fatalf("WithContext(%d): %v", n, err)
+		}
+		return AnalyzeWithOptions(m, spec, opts)
+	}
+	short, long := at(4096), at(131072)
+	if short.AnalysisContext != 4096 || long.AnalysisContext != 131072 {
+		t.Errorf("AnalysisContext = %d, %d; want 4096, 131072", short.AnalysisContext, long.AnalysisContext)
+	}
+	if long.MemoryRequiredGB <= short.MemoryRequiredGB {
+		t.Errorf("MemoryRequiredGB at 131072 = %.2f, want more than %.2f at 4096", long.MemoryRequiredGB, short.MemoryRequiredGB)
+	}
+	if long.FitLevel <= short.FitLevel {
+		t.Errorf("FitLevel at 131072 = %v, want worse than %v at 4096", long.FitLevel, short.FitLevel)
+	}
+	if def := Analyze(m, spec); def.AnalysisContext != 4096 || def.MemoryRequiredGB != short.MemoryRequiredGB {
+		t.Errorf("default: analyzed at %d for %.2f GB, want the catalog's 4096 at %.2f GB like --context 4096", def.AnalysisContext, def.MemoryRequiredGB, short.MemoryRequiredGB)
+	}
+	if shorter := at(2048); shorter.AnalysisContext != 2048 || shorter.MemoryRequiredGB >= short.MemoryRequiredGB {
+		t.Errorf("--context 2048: analyzed at %d for %.2f GB, want 2048 at less than %.2f GB", shorter.AnalysisContext, shorter.MemoryRequiredGB, short.MemoryRequiredGB)
+	}
+	if capped := at(1 << 19); capped.AnalysisContext != m.ContextLength {
+		t.Errorf("AnalysisContext past the model's maximum = %d, want %d", capped.AnalysisContext, m.ContextLength)
+	}
+	if _, err := DefaultOptions().WithContext(256); err == nil {
+		t.Error("WithContext(256) = nil error, want below-minimum error")
+	}
+	if o, _ := DefaultOptions().WithContext(1 << 30); o.Context != MaxContext {
+		t.Errorf("WithContext(1<<30).Context = %d, want %d", o.Context, MaxContext)
+	}
+}
+
+func TestKVCache_QuantizedCacheLowersMemory(t *testing.T) {
+	m := model7B()
+	m.ContextLength = 131072
+	spec := specWithGPU(16, 64, false)
+	at := func(kv string) *ModelFit {
+		opts, _ := DefaultOptions().WithContext(131072)
+		opts, err := opts.WithKVCache(kv)
+		if err != nil {
+			t.Fatalf("WithKVCache(%s): %v", kv, err)
+		}
+		return AnalyzeWithOptions(m, spec, opts)
+	}
+	f16, q4 := at("f16"), at("q4_0")
+	if q4.MemoryRequiredGB >= f16.MemoryRequiredGB {
+		t.Errorf("MemoryRequiredGB with q4_0 = %.2f, want less than %.2f with f16", q4.MemoryRequiredGB, f16.MemoryRequiredGB)
+	}
+	if q4.FitLevel >= f16.FitLevel {
+		t.Errorf("FitLevel with q4_0 = %v, want better than %v with f16", q4.FitLevel, f16.FitLevel)
+	}
+	found := false
+	for _, n := range q4.Notes {
+		found = found || strings.Contains(n, "KV cache: q4_0")
+	}
+	if q4.KVCacheType != models.KVCacheQ4 || !found {
+		t.Errorf("q4_0 fit: KVCacheType %q, notes %v; want q4_0 and a KV cache note", q4.KVCacheType, q4.Notes)
+	}
+	if def := Analyze(model7B(), spec); def.KVCacheType != models.KVCacheF16 {
+		t.Errorf("default KVCacheType = %q, want f16", def.KVCacheType)
+	}
+}
+
+func TestUpgradePath_RunnableDeltaGrows(t *testing.T) {
+	var catalog []*models.LlmModel
+	for _, p := range []struct {
+		name string
+		ram  float64
+	}{{"s", 4}, {"m", 10}, {"l", 20}, {"xl", 40}} {
+		catalog = append(catalog, &models.LlmModel{Name: p.name, ParameterCount: "7B", MinRAMGB: p.ram, RecommendedRAMGB: p.ram * 1.5, Quantization: "Q4_K_M", ContextLength: 4096})
+	}
+	spec := specNoGPU(16, 8)
+	before := AnalyzeAll(catalog, spec)
+	base := CountFits(before)
+	if base.Runnable+base.TooTight != len(catalog) {
+		t.Fatalf("CountFits = %+v, want runnable+too tight = %d", base, len(catalog))
+	}
+	prev := base.Runnable
+	for _, extra := range []float64{16, 32} {
+		after := AnalyzeAll(catalog, spec.WithExtraMemory(extra, 0))
+		c := CountFits(after)
+		if c.Runnable <= prev {
+			t.Errorf("+%.0f GB RAM: runnable = %d, want more than %d", extra, c.Runnable, prev)
+		}
+		if got := len(NewlyRunnable(before, after)); got != c.Runnable-base.Runnable {
+			t.Errorf("+%.0f GB RAM: NewlyRunnable = %d, want %d", extra, got, c.Runnable-base.Runnable)
+		}
+		prev = c.Runnable
+	}
+}
+
+func TestAnalyze_NoMinVRAMDerivesEstimate(t *testing.T) {
+	m := model7B()
+	m.MinVRAMGB = nil // RAM figure (8 GB) exceeds the 7.2 GB usable VRAM
+	fit := Analyze(m, specWithGPU(8, 32, false))
+	if fit.RunMode != RunModeGpu {
+		t.Errorf("RunMode = %v, want RunModeGpu (weights fit VRAM once estimated without RAM overhead)", fit.RunMode)
+	}
+	if fit.MemoryRequiredGB >= m.MinRAMGB {
+		t.Errorf("MemoryRequiredGB = %.2f, want below MinRAMGB %.1f", fit.MemoryRequiredGB, m.MinRAMGB)
+	}
+	// Far too large for VRAM: still spills rather than claiming a GPU fit.
+	big := model7B()
+	big.Name, big.ParameterCount, big.MinRAMGB, big.RecommendedRAMGB, big.MinVRAMGB = "test-70b", "70B", 48, 64, nil
+	if f := Analyze(big, specWithGPU(8, 128, false)); f.RunMode == RunModeGpu {
+		t.Errorf("70B on 8 GB VRAM: RunMode = %v, want a RAM path", f.RunMode)
+	}
+}
+
+func TestReasoningContextDiscount(t *testing.T) {
+	chat := model7B()
+	chat.Name, chat.UseCase, chat.ContextLength = "test-chat", "chat", 8192
+	reasoning := model7B()
+	reasoning.Name, reasoning.UseCase, reasoning.ContextLength = "test-r1", "Advanced reasoning, chain-of-thought", 8192
+	spec := specWithGPU(24, 64, false)
+
+	cf, rf := Analyze(chat, spec), Analyze(reasoning, spec)
+	if rf.UseCase != models.UseCaseReasoning {
+		t.Fatalf("UseCase = %v, want reasoning", rf.UseCase)
+	}
+	if rf.UsableContext >= cf.UsableContext {
+		t.Errorf("UsableContext: reasoning %d, chat %d; want reasoning lower", rf.UsableContext, cf.UsableContext)
+	}
+	if rf.UsableContext != 4915 {
+		t.Errorf("reasoning UsableContext = %d, want 4915", rf.UsableContext)
+	}
+	// Same 8k window against the same 8k target: the reasoning model scores lower on context.
+	w := &Workload{ContextTarget: 8192}
+	if r, c := contextScore(reasoning, models.UseCaseReasoning, w), contextScore(chat, models.UseCaseChat, w); r >= c {
+		t.Errorf("contextScore: reasoning %.0f, chat %.0f; want reasoning lower", r, c)
+	}
+	found := false
+	for _, n := range rf.Notes {
+		found = found || strings.Contains(n, "thinking tokens")
+	}
+	if !found {
+		t.Errorf("reasoning notes %v: want a thinking-tokens note", rf.Notes)
+	}
+}
+
+func TestEstimateTPS_NUMATempersCoreBonus(t *testing.T) {
+	m := model7B()
+	single := specNoGPU(64, 32)
+	single.NumaNodes = 1
+	dual := specNoGPU(64, 32)
+	dual.NumaNodes = 2
+
+	s := estimateTPS(m, "Q4_K_M", single, RunModeCpuOnly)
+	d := estimateTPS(m, "Q4_K_M", dual, RunModeCpuOnly)
+	if d >= s {
+		t.Errorf("estimateTPS: dual-node %.2f, single-node %.2f; want dual-node lower", d, s)
+	}
+	if few := estimateTPS(m, "Q4_K_M", specNoGPU(64, 4), RunModeCpuOnly); d <= few {
+		t.Errorf("estimateTPS: dual-node %.2f, 4-core %.2f; want a reduced, not removed, core bonus", d, few)
+	}
+
+	hasNote := func(f *ModelFit) bool {
+		for _, n := range f.Notes {
+			if strings.Contains(n, "NUMA") {
+				return true
+			}
+		}
+		return false
+	}
+	if hasNote(Analyze(m, single)) {
+		t.Error("single-node system got a NUMA note")
+	}
+	if !hasNote(Analyze(m, dual)) {
+		t.Error("dual-node system missing NUMA pinning note")
+	}
+}
+
+func TestBestRunnable(t *testing.T) {
+	small := model7B()
+	big := model7B()
+	big.Name, big.MinRAMGB, big.RecommendedRAMGB = "test-huge", 500, 600
+	fits := AnalyzeAll([]*models.LlmModel{big, small}, specNoGPU(16, 8))
+	if best := BestRunnable(fits); best == nil || best.Model.Name != small.Name {
+		t.Errorf("BestRunnable = %v, want %s", best, small.Name)
+	}
+	if best := BestRunnable(AnalyzeAll([]*models.LlmModel{big}, specNoGPU(16, 8))); best != nil {
+		t.Errorf("BestRunnable(all Too Tight) = %s, want nil", best.Model.Name)
+	}
+}
+
+func TestAnalyze_NoteSeverities(t *testing.T) {
+	f := Analyze(model7B(), specNoGPU(16, 2))
+	if len(f.NoteSeverities) != len(f.Notes) {
+		t.Fatalf("NoteSeverities has %d entries for %d notes", len(f.NoteSeverities), len(f.Notes))
+	}
+	w := f.Warnings()
+	want := []string{"No GPU -- inference will be slow", "Low CPU core count may bottleneck inference"}
+	if len(w) != len(want) || w[0] != want[0] || w[1] != want[1] {
+		t.Errorf("Warnings() = %q, want %q", w, want)
+	}
+}
+
+func TestAnalyze_UnknownSizeWarns(t *testing.T) {
+	m := model7B()
+	m.ParameterCount, m.UnknownSize = "?", true
+	f := Analyze(m, specWithGPU(24, 64, false))
+	w := f.Warnings()
+	if len(w) != 1 || !strings.Contains(w[0], "not a size") {
+		t.Errorf("Warnings() = %q, want one unknown-size warning", w)
+	}
+	if f.EstimatedTPS <= 0 {
+		t.Errorf("EstimatedTPS = %v, want a size-based estimate", f.EstimatedTPS)
+	}
+}
+
+// laptopSpec is a 6 GB dGPU + 32 GB RAM laptop, optionally with its Intel iGPU listed.
+func laptopSpec(withIGPU bool) *hardware.SystemSpecs {
+	s := specWithGPU(6, 32, false)
+	if withIGPU {
+		s.Gpus = append(s.Gpus, hardware.GpuInfo{Name: "Intel(R) UHD Graphics", Backend: hardware.BackendSycl, Count: 1})
+	}
+	return s
+}
+
+func moeModel(name string, totalB, activeB float64, minRAM, minVRAM float64) *models.LlmModel {
+	total, active := uint64(totalB*1e9), uint64(activeB*1e9)
+	numExp, activeExp := uint32(8), uint32(2)
+	return &models.LlmModel{
+		Name: name, Provider: "Test", ParameterCount: fmt.Sprintf("%.0fB", totalB), ParametersRaw: &total,
+		MinRAMGB: minRAM, RecommendedRAMGB: minRAM * 1.5, MinVRAMGB: &minVRAM, Quantization: "Q4_K_M",
+		ContextLength: 32768, UseCase: "chat",
+		IsMoE: true, NumExperts: &numExp, ActiveExperts: &activeExp, ActiveParameters: &active,
+	}
+}
+
+func TestMoEOffload_LaptopDGPU(t *testing.T) {
+	// Small active set: fits the 6 GB dGPU, inactive experts in RAM.
+	small := moeModel("test-moe-30b", 30, 3, 18, 18)
+	f := Analyze(small, laptopSpec(false))
+	if f.RunMode != RunModeMoeOffload || f.FitLevel == FitTooTight {
+		t.Fatalf("30B-A3B on 6 GB dGPU: run mode %s, fit %s; want runnable MoE offload", f.RunModeText(), f.FitText())
+	}
+
+	// Active experts overflow the dGPU: only runnable by spilling them into the iGPU's shared memory.
+	big := moeModel("test-moe-47b", 46.7, 12.9, 26, 24)
+	if f := Analyze(big, laptopSpec(false)); f.RunMode == RunModeMoeOffload {
+		t.Errorf("47B-A13B without iGPU: run mode %s, want no MoE offload", f.RunModeText())
+	}
+	f = Analyze(big, laptopSpec(true))
+	if f.RunMode != RunModeMoeOffload || f.FitLevel == FitTooTight {
+		t.Fatalf("47B-A13B with iGPU: run mode %s, fit %s; want runnable MoE offload\nnotes: %q", f.RunModeText(), f.FitText(), f.Notes)
+	}
+	var split, ram bool
+	for _, n := range f.Notes {
+		split = split || (strings.Contains(n, "dGPU VRAM") && strings.Contains(n, "Intel(R) UHD Graphics shared memory"))
+		ram = ram || strings.Contains(n, "Inactive experts offloaded to system RAM")
+	}
+	if !split || !ram {
+		t.Errorf("notes %q: want where active and inactive experts live", f.Notes)
+	}
+}
+
+func TestMoEOffload_NeedsVRAMAndRAMTogether(t *testing.T) {
+	m := moeModel("test-moe-30b", 30, 3, 18, 18)
+	hasNote := func(f *ModelFit, part string) bool {
+		for _, n := range f.Notes {
+			if strings.Contains(n, part) {
+				return true
+			}
+		}
+		return false
+	}
+
+	f := Analyze(m, specWithGPU(3, 64, false))
+	if f.RunMode != RunModeMoeOffload || f.FitLevel == FitTooTight {
+		t.Fatalf("3 GB VRAM, 64 GB RAM: run mode %s, fit %s; want runnable MoE offload", f.RunModeText(), f.FitText())
+	}
+	want := fmt.Sprintf("MoE offload needs %.1f GB VRAM and %.1f GB system RAM at the same time", f.MemoryRequiredGB, *f.MoeOffloadedGB)
+	if !hasNote(f, want) {
+		t.Errorf("notes %q: want %q", f.Notes, want)
+	}
+
+	// RAM that only just holds the offloaded experts caps the fit, however roomy the VRAM.
+	tight := specWithGPU(3, 0, false)
+	tight.AvailableRAMGB = *f.MoeOffloadedGB * 1.1 / (1 - DefaultSafetyMargin)
+	if f := Analyze(m, tight); f.RunMode != RunModeMoeOffload || f.FitLevel != FitMarginal {
+		t.Errorf("RAM just above the offload: run mode %s, fit %s; want Marginal MoE offload", f.RunModeText(), f.FitText())
+	}
+
+	// The active experts fit in VRAM, but the inactive ones do not fit in RAM: no offload.
+	short := specWithGPU(3, 0, false)
+	short.AvailableRAMGB = *m.MoeOffloadedRAMGB() * 0.8
+	f = Analyze(m, short)
+	if f.RunMode == RunModeMoeOffload || f.FitLevel != FitTooTight {
+		t.Errorf("RAM below the offload: run mode %s, fit %s; want Too Tight without MoE offload", f.RunModeText(), f.FitText())
+	}
+	if !hasNote(f, "active experts fit in VRAM, but the inactive experts need") || !hasNote(f, "at the same time (MoE offload)") {
+		t.Errorf("notes %q: want why offload was rejected and the combined requirement", f.Notes)
+	}
+}
+
+func TestMoEOffload_PartialExpertResidency(t *testing.T) {
+	m := moeModel("test-moe-30b", 30, 3, 18, 18)
+	full := Analyze(m, specWithGPU(24, 64, false))
+	if full.RunMode != RunModeGpu {
+		t.Fatalf("24 GB: run mode %s, want GPU", full.RunModeText())
+	}
+	prevTPS, prevResident := 0.0, uint32(0)
+	for _, vram := range []float64{6, 10, 14, 18} {
+		f := Analyze(m, specWithGPU(vram, 64, false))
+		if f.RunMode != RunModeMoeOffload || f.MoeResidentExperts == nil {
+			t.Fatalf("%.0f GB: run mode %s, resident %v; want MoE offload with a resident count", vram, f.RunModeText(), f.MoeResidentExperts)
+		}
+		resident := *f.MoeResidentExperts
+		if resident <= prevResident || resident > *m.NumExperts {
+			t.Errorf("%.0f GB: %d experts resident, want more than %d and at most %d", vram, resident, prevResident, *m.NumExperts)
+		}
+		if f.EstimatedTPS <= prevTPS || f.EstimatedTPS >= full.EstimatedTPS {
+			t.Errorf("%.0f GB: %.1f tok/s, want above %.1f and below full GPU %.1f", vram, f.EstimatedTPS, prevTPS, full.EstimatedTPS)
+		}
+		prevTPS, prevResident = f.EstimatedTPS, resident
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{"5", Limit{Count: 5}, false},
+		{"0", Limit{}, false},
+		{"20%", Limit{Percent: 20}, false},
+		{" 12.5 % ", Limit{Percent: 12.5}, false},
+		{"100%", Limit{Percent: 100}, false},
+		{"0%", Limit{}, true},
+		{"150%", Limit{}, true},
+		{"-3", Limit{}, true},
+		{"ten", Limit{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLimit(%q) = %+v, %v; want %+v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+	if s := (Limit{Percent: 12.5}).String(); s != "12.5%" {
+		t.Errorf("Limit.String() = %q, want 12.5%%", s)
+	}
+}
+
+func TestLimit_Apply(t *testing.T) {
+	// ranked builds a RankModelsByFit-style list: runnable fits first, then Too Tight ones.
+	ranked := func(runnable, tooTight int) []*ModelFit {
+		var fits []*ModelFit
+		for i := 0; i < runnable; i++ {
+			fits = append(fits, &ModelFit{FitLevel: FitGood})
+		}
+		for i := 0; i < tooTight; i++ {
+			fits = append(fits, &ModelFit{FitLevel: FitTooTight})
+		}
+		return fits
+	}
+	tests := []struct {
+		name               string
+		limit              Limit
+		runnable, tooTight int
+		want               int
+	}{
+		{"20% of a big machine", Limit{Percent: 20}, 40, 10, 8},
+		{"20% of a small machine rounds up", Limit{Percent: 20}, 3, 47, 1},
+		{"percent of nothing runnable", Limit{Percent: 20}, 0, 50, 0},
+		{"100% keeps only runnable", Limit{Percent: 100}, 7, 3, 7},
+		{"count", Limit{Count: 5}, 40, 10, 5},
+		{"count above total", Limit{Count: 99}, 4, 1, 5},
+		{"zero is no limit", Limit{}, 4, 1, 5},
+	}
+	for _, tt := range tests {
+		if got := len(tt.limit.Apply(ranked(tt.runnable, tt.tooTight))); got != tt.want {
+			t.Errorf("%s: Apply kept %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRankModelsBy(t *testing.T) {
+	fit := func(name string, level FitLevel, score, quality, tps, memGB float64) *ModelFit {
+		return &ModelFit{
+			Model:            &models.LlmModel{Name: name},
+			FitLevel:         level,
+			Score:            score,
+			ScoreComponents:  ScoreComponents{Quality: quality},
+			EstimatedTPS:     tps,
+			MemoryRequiredGB: memGB,
+		}
+	}
+	fits := []*ModelFit{
+		fit("big", FitGood, 80, 90, 20, 40),     // best score; 2.25 quality/GB; 0.5 tps/GB
+		fit("small", FitPerfect, 60, 50, 90, 5), // 10 quality/GB; 18 tps/GB
+		fit("mid", FitGood, 70, 75, 100, 15),    // fastest; 5 quality/GB; 6.7 tps/GB
+		fit("huge", FitTooTight, 95, 99, 500, 1),
+	}
+	tests := []struct {
+		by   RankBy
+		want []string
+	}{
+		{RankByScore, []string{"big", "mid", "small", "huge"}},
+		{RankByQualityPerGB, []string{"small", "mid", "big", "huge"}},
+		{RankBySpeed, []string{"mid", "small", "big", "huge"}},
+		{RankByTPSPerGB, []string{"small", "mid", "big", "huge"}},
+	}
+	for _, tt := range tests {
+		got := RankModelsBy(fits, tt.by)
+		var names []string
+		for _, f := range got {
+			names = append(names, f.Model.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("RankModelsBy(%s) = %v, want %v", tt.by, names, tt.want)
+		}
+	}
+	if fits[0].Model.Name != "big" {
+		t.Error("RankModelsBy must not reorder its input")
+	}
+	if _, err := ParseRankBy("value"); err == nil {
+		t.Error("ParseRankBy(value) = nil error, want error")
+	}
+	if by, err := ParseRankBy(" TPS-per-GB "); err != nil || by != RankByTPSPerGB {
+		t.Errorf("ParseRankBy(TPS-per-GB) = %v, %v", by, err)
+	}
+}
+
+func TestCompareFits(t *testing.T) {
+	a := &ModelFit{Score: 72, ScoreComponents: ScoreComponents{Quality: 80, Speed: 40, Fit: 90, Context: 100}}
+	b := &ModelFit{Score: 72.02, ScoreComponents: ScoreComponents{Quality: 60, Speed: 95, Fit: 90.01, Context: 100}}
+	c := CompareFits(a, b)
+	want := map[string]string{"quality": WinnerA, "speed": WinnerB, "fit": WinnerTie, "context": WinnerTie}
+	for dim, w := range want {
+		if c.Winners[dim] != w {
+			t.Errorf("Winners[%s] = %q, want %q", dim, c.Winners[dim], w)
+		}
+	}
+	if len(c.Winners) != len(CompareDimensions) {
+		t.Errorf("Winners has %d dimensions, want %d", len(c.Winners), len(CompareDimensions))
+	}
+	if c.Overall != WinnerTie {
+		t.Errorf("Overall = %q, want tie for scores within rounding", c.Overall)
+	}
+	b.Score = 71
+	if c := CompareFits(a, b); c.Overall != WinnerA {
+		t.Errorf("Overall = %q, want a", c.Overall)
+	}
+	if c := CompareFits(b, a); c.Overall != WinnerB || c.Winners["quality"] != WinnerB {
+		t.Errorf("swapped: Overall = %q, quality = %q; want b, b", c.Overall, c.Winners["quality"])
+	}
+}
+
+func TestCompareAll_ThreeModels(t *testing.T) {
+	a := &ModelFit{Score: 60, ScoreComponents: ScoreComponents{Quality: 80, Speed: 40, Fit: 90, Context: 100}}
+	b := &ModelFit{Score: 70, ScoreComponents: ScoreComponents{Quality: 60, Speed: 95, Fit: 50, Context: 100}}
+	c := &ModelFit{Score: 75, ScoreComponents: ScoreComponents{Quality: 85, Speed: 30, Fit: 89.99, Context: 50}}
+	cmp := CompareAll([]*ModelFit{a, b, c})
+	want := map[string]string{"quality": "c", "speed": WinnerB, "fit": WinnerTie, "context": WinnerTie}
+	for dim, w := range want {
+		if cmp.Winners[dim] != w {
+			t.Errorf("Winners[%s] = %q, want %q", dim, cmp.Winners[dim], w)
+		}
+	}
+	if cmp.Overall != "c" {
+		t.Errorf("Overall = %q, want c", cmp.Overall)
+	}
+	if Side(26) != "#27" {
+		t.Errorf("Side(26) = %q, want #27", Side(26))
+	}
+}
+
+func TestEstimateCapacity_ScalesWithVRAM(t *testing.T) {
+	opts := DefaultOptions()
+	const ctx = 8192
+	small := EstimateCapacity(Analyze(model7B(), specWithGPU(24, 64, false)), ctx, opts)
+	large := EstimateCapacity(Analyze(model7B(), specWithGPU(48, 64, false)), ctx, opts)
+	if small.Quant != large.Quant {
+		t.Fatalf("quant changed with VRAM (%s vs %s); pick sizes that share a best quant", small.Quant, large.Quant)
+	}
+	if small.Concurrent <= 0 {
+		t.Fatalf("24 GB: Concurrent = %d, want > 0", small.Concurrent)
+	}
+	// Every extra GB beyond the weights holds 1/KVPerRequestGB more requests.
+	want := (large.MemoryGB - small.MemoryGB) / small.KVPerRequestGB
+	if got := float64(large.Concurrent - small.Concurrent); math.Abs(got-want) > 1 {
+		t.Errorf("extra concurrency = %.0f, want ~%.1f", got, want)
+	}
+	if tight := EstimateCapacity(Analyze(model7B(), specNoGPU(4, 4)), ctx, opts); tight.Concurrent != 0 {
+		t.Errorf("too tight: Concurrent = %d, want 0", tight.Concurrent)
+	}
+}
+
+func TestAnalyze_MaxQuantCapsBestQuant(t *testing.T) {
+	opts, err := DefaultOptions().WithMaxQuant("q5_k_m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := AnalyzeWithOptions(model7B(), specWithGPU(48, 64, false), opts)
+	if f.BestQuant != "Q5_K_M" {
+		t.Fatalf("BestQuant = %q, want Q5_K_M", f.BestQuant)
+	}
+	if !strings.Contains(strings.Join(f.Notes, "\n"), "Q8_0 would fit, but it exceeds the Q5_K_M cap") {
+		t.Errorf("notes missing cap explanation: %v", f.Notes)
+	}
+	if _, err := DefaultOptions().WithMaxQuant("Q9_X"); err == nil {
+		t.Error("unknown quant should be rejected")
+	}
+}
+
+func TestAnalyze_MLXRuntimeOnMetal(t *testing.T) {
+	metal := specWithGPU(32, 32, true)
+	metal.Backend = hardware.BackendMetal
+	mlx, err := DefaultOptions().WithRuntime("mlx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gguf := AnalyzeWithOptions(model7B(), metal, DefaultOptions())
+	fit := AnalyzeWithOptions(model7B(), metal, mlx)
+	if gguf.BestQuant != "Q8_0" || fit.BestQuant != "mlx-8bit" {
+		t.Fatalf("best quants = %s (gguf), %s (mlx); want Q8_0, mlx-8bit", gguf.BestQuant, fit.BestQuant)
+	}
+	if fit.EstimatedTPS <= gguf.EstimatedTPS {
+		t.Errorf("MLX tok/s %.1f should beat llama.cpp %.1f at the same bit width", fit.EstimatedTPS, gguf.EstimatedTPS)
+	}
+	m := model7B()
+	if m.EstimateMemoryGB("mlx-4bit", 4096, models.KVCacheF16) >= m.EstimateMemoryGB("Q4_K_M", 4096, models.KVCacheF16) {
+		t.Error("MLX 4-bit (4.5 bits/weight) should need less memory than Q4_K_M")
+	}
+	if m.EstimateMemoryGB("mlx-8bit", 4096, models.KVCacheF16) <= m.EstimateMemoryGB("Q8_0", 4096, models.KVCacheF16) {
+		t.Error("MLX 8-bit (8.5 bits/weight) should need more memory than Q8_0")
+	}
+	// Off Apple Silicon the MLX runtime does not apply.
+	cuda := AnalyzeWithOptions(model7B(), specWithGPU(32, 32, false), mlx)
+	if models.IsMLXQuant(cuda.BestQuant) {
+		t.Errorf("CUDA best quant = %s, want a GGUF quant", cuda.BestQuant)
+	}
+	if _, err := DefaultOptions().WithRuntime("vllm"); err == nil {
+		t.Error("unknown runtime should be rejected")
+	}
+}
+
+func TestAnalyze_RunPreference(t *testing.T) {
+	// 6 GB needed, 6.5 GB VRAM: fits the card but not inside the 10% safety margin.
+	spec := specWithGPU(6.5, 32, false)
+	tests := []struct {
+		prefer RunPreference
+		mode   RunMode
+		note   string
+	}{
+		{PreferAuto, RunModeCpuOffload, "spilling to system RAM"},
+		{PreferGPU, RunModeGpu, "Preferring GPU"},
+		{PreferCPU, RunModeCpuOnly, "Preferring CPU"},
+	}
+	for _, tt := range tests {
+		opts := DefaultOptions()
+		opts.Prefer = tt.prefer
+		f := AnalyzeWithOptions(model7B(), spec, opts)
+		if f.RunMode != tt.mode {
+			t.Errorf("prefer %d: RunMode = %v, want %v", tt.prefer, f.RunMode, tt.mode)
+		}
+		if !strings.Contains(strings.Join(f.Notes, "\n"), tt.note) {
+			t.Errorf("prefer %d: notes missing %q: %v", tt.prefer, tt.note, f.Notes)
+		}
+		if tt.prefer == PreferGPU && f.FitLevel != FitMarginal {
+			t.Errorf("prefer gpu inside the margin: FitLevel = %v, want FitMarginal", f.FitLevel)
+		}
+	}
+	// A model that fits VRAM comfortably is unaffected by --prefer-cpu.
+	opts := DefaultOptions()
+	opts.Prefer = PreferCPU
+	if f := AnalyzeWithOptions(model7B(), specWithGPU(24, 32, false), opts); f.RunMode != RunModeGpu {
+		t.Errorf("prefer cpu with ample VRAM: RunMode = %v, want GPU", f.RunMode)
+	}
+}
+
+func TestHardwareScore_MonotonicAndStable(t *testing.T) {
+	prev := HardwareScore(specNoGPU(32, 8))
+	for _, vram := range []float64{8, 16, 24, 48, 96} {
+		s := HardwareScore(specWithGPU(vram, 32, false))
+		if s <= prev {
+			t.Errorf("%.0f GB VRAM: score %.1f, want above %.1f", vram, s, prev)
+		}
+		prev = s
+	}
+	backends := []hardware.GpuBackend{hardware.BackendSycl, hardware.BackendVulkan, hardware.BackendRocm, hardware.BackendCuda}
+	prev = 0
+	for _, b := range backends {
+		spec := specWithGPU(24, 64, false)
+		spec.Backend = b
+		s := HardwareScore(spec)
+		if s <= prev {
+			t.Errorf("%s backend: score %.1f, want above %.1f", b, s, prev)
+		}
+		prev = s
+	}
+	if more, fewer := HardwareScore(specNoGPU(64, 16)), HardwareScore(specNoGPU(32, 16)); more <= fewer {
+		t.Errorf("64 GB RAM scored %.1f, 32 GB %.1f; want more RAM higher", more, fewer)
+	}
+	if more, fewer := HardwareScore(specNoGPU(32, 16)), HardwareScore(specNoGPU(32, 4)); more <= fewer {
+		t.Errorf("16 cores scored %.1f, 4 cores %.1f; want more cores higher", more, fewer)
+	}
+
+	spec := specWithGPU(24, 64, false)
+	first := HardwareScore(spec)
+	for i := 0; i < 3; i++ {
+		if s := HardwareScore(spec); s != first {
+			t.Fatalf("run %d: score %.1f, want the same %.1f every time", i, s, first)
+		}
+	}
+	if first <= 0 || first > 100 {
+		t.Errorf("score %.1f, want within 0–100", first)
+	}
+}
+
+func TestAnalyze_SuggestsLighterQuantWhenTooTight(t *testing.T) {
+	m := &models.LlmModel{Name: "test-32b", Provider: "Test", ParameterCount: "32B", Quantization: "Q4_K_M", ContextLength: 4096, UseCase: "general"}
+	m.MinRAMGB = m.EstimateMemoryGB("Q4_K_M", baseKVContext, models.KVCacheF16)
+	m.RecommendedRAMGB = m.MinRAMGB * 1.2
+	// Usable RAM lands between the Q2_K and IQ3_XXS requirements.
+	usable := (m.EstimateMemoryGB("Q2_K", baseKVContext, models.KVCacheF16) + m.EstimateMemoryGB("IQ3_XXS", baseKVContext, models.KVCacheF16)) / 2
+	spec := specNoGPU(64, 8)
+	spec.AvailableRAMGB = usable / (1 - DefaultSafetyMargin)
+
+	plain := AnalyzeWithOptions(m, spec, DefaultOptions())
+	if plain.FitLevel != FitTooTight || plain.SuggestedQuant != "" {
+		t.Fatalf("without SuggestQuants: fit %s, suggestion %q; want Too Tight and none", plain.FitText(), plain.SuggestedQuant)
+	}
+	opts := DefaultOptions()
+	opts.SuggestQuants = true
+	f := AnalyzeWithOptions(m, spec, opts)
+	if f.FitLevel != FitTooTight || f.SuggestedQuant != "Q2_K" {
+		t.Fatalf("with SuggestQuants: fit %s, suggestion %q; want Too Tight with Q2_K", f.FitText(), f.SuggestedQuant)
+	}
+	want := "Too Tight at Q4_K_M, but runnable at Q2_K (reduced quality)"
+	if w := f.Warnings(); !slices.Contains(w, want) {
+		t.Errorf("warnings %q, want %q", w, want)
+	}
+
+	spec.AvailableRAMGB = 2
+	if f := AnalyzeWithOptions(m, spec, opts); f.SuggestedQuant != "" {
+		t.Errorf("2 GB RAM: suggestion %q, want none when no quant fits", f.SuggestedQuant)
+	}
+}
+
+func TestPlanTogether(t *testing.T) {
+	sized := func(name, params string, minRAM float64) *models.LlmModel {
+		return &models.LlmModel{Name: name, Provider: "Test", ParameterCount: params, MinRAMGB: minRAM, RecommendedRAMGB: 2 * minRAM, Quantization: "Q4_K_M", ContextLength: 4096, UseCase: "general"}
+	}
+	coder, embedder, reranker := sized("coder-14b", "14B", 9), sized("embedder-0.6b", "0.6B", 1), sized("reranker-1.5b", "1.5B", 1.5)
+	plan := func(spec *hardware.SystemSpecs, ms ...*models.LlmModel) Plan {
+		opts := DefaultOptions()
+		var fits []*ModelFit
+		for _, m := range ms {
+			fits = append(fits, AnalyzeWithOptions(m, spec, opts))
+		}
+		return PlanTogether(fits, spec, opts)
+	}
+
+	p := plan(specWithGPU(24, 64, false), coder, embedder, reranker)
+	if !p.Fits || p.OffloadFirst != "" || p.HeadroomGB <= 0 {
+		t.Fatalf("24 GB GPU + 64 GB RAM: %+v, want the stack to fit with headroom", p)
+	}
+	for i, e := range p.Models {
+		if e.Pool == "" {
+			t.Errorf("%s was not placed", e.Model)
+		}
+		// The same figure pole, compare and the fit level use.
+		if fit := AnalyzeWithOptions([]*models.LlmModel{coder, embedder, reranker}[i], specWithGPU(24, 64, false), DefaultOptions()); e.MemoryGB != fit.RecommendedQuant.MemoryGB {
+			t.Errorf("%s: plan memory %.2f GB, want the fit's %.2f GB", e.Model, e.MemoryGB, fit.RecommendedQuant.MemoryGB)
+		}
+	}
+	if math.Abs(p.TotalGB+p.HeadroomGB-(p.VRAMGB+p.RAMGB)) > 1e-9 {
+		t.Errorf("total %.2f + headroom %.2f != usable %.2f", p.TotalGB, p.HeadroomGB, p.VRAMGB+p.RAMGB)
+	}
+
+	// Only the small models fit in 4 GB of RAM: the coder overflows even at IQ2_XXS and goes first.
+	small := specNoGPU(8, 8)
+	small.AvailableRAMGB = 4
+	p = plan(small, embedder, coder, reranker)
+	if p.Fits || p.OffloadFirst != "coder-14b" {
+		t.Fatalf("4 GB RAM: fits %v, offload first %q; want the coder identified as the overflow", p.Fits, p.OffloadFirst)
+	}
+	if p.Models[1].Pool != "" || p.Models[0].Pool != PoolRAM {
+		t.Errorf("4 GB RAM placements = %+v, want the coder unplaced and the embedder in RAM", p.Models)
+	}
+}
+
+func TestAnalyzeAcrossProfiles(t *testing.T) {
+	minVram := 20.0
+	m := &models.LlmModel{
+		Name: "test-32b", ParameterCount: "32B", MinRAMGB: 24, RecommendedRAMGB: 32, MinVRAMGB: &minVram,
+		Quantization: "Q4_K_M", ContextLength: 32768, UseCase: "general",
+	}
+	rows := AnalyzeAcrossProfiles(m, DefaultOptions())
+	if len(rows) != len(hardware.Profiles) {
+		t.Fatalf("%d rows, want one per profile (%d)", len(rows), len(hardware.Profiles))
+	}
+	lastMac := FitTooTight
+	for i, r := range rows {
+		p := hardware.Profiles[i]
+		if r.Profile != p.Name || r.Fit == nil {
+			t.Fatalf("row %d = %q, want %q with a fit", i, r.Profile, p.Name)
+		}
+		if want := AnalyzeWithOptions(m, p.Specs(), DefaultOptions()); r.Fit.FitLevel != want.FitLevel || r.Fit.RunMode != want.RunMode {
+			t.Errorf("%s: %s/%s, want %s/%s as when analyzed on the profile directly", p.Name, r.Fit.FitLevel, r.Fit.RunModeText(), want.FitLevel, want.RunModeText())
+		}
+		// Unified memory has no offload path, so a bigger Mac never fits worse.
+		if p.Backend == hardware.BackendMetal {
+			if r.Fit.FitLevel > lastMac {
+				t.Errorf("%s: %s is worse than a smaller Mac's %s", p.Name, r.Fit.FitLevel, lastMac)
+			}
+			lastMac = r.Fit.FitLevel
+		}
+	}
+	if rows[0].Fit.FitLevel != FitTooTight {
+		t.Errorf("%s: %s, want Too Tight for a 32B model", rows[0].Profile, rows[0].Fit.FitLevel)
+	}
+	if end := rows[len(rows)-1]; end.Fit.FitLevel != FitPerfect {
+		t.Errorf("%s: %s, want Perfect", end.Profile, end.Fit.FitLevel)
+	}
+}
+
+func TestUsabilityPenalty_RanksFastGPUAboveSlowOffload(t *testing.T) {
+	// Reasoning weighs quality over speed, so a 32B offloaded at ~3 tok/s outscores a 3B on the GPU.
+	spec := specWithGPU(4, 64, false)
+	bigVram, smallVram := 19.2, 1.8
+	big := &models.LlmModel{
+		Name: "test-32b", ParameterCount: "32B", MinRAMGB: 21.2, RecommendedRAMGB: 27.6, MinVRAMGB: &bigVram,
+		Quantization: "Q4_K_M", ContextLength: 8192, UseCase: "reasoning",
+	}
+	small := &models.LlmModel{
+		Name: "test-3b", ParameterCount: "3B", MinRAMGB: 3.8, RecommendedRAMGB: 4.9, MinVRAMGB: &smallVram,
+		Quantization: "Q4_K_M", ContextLength: 8192, UseCase: "reasoning",
+	}
+
+	off := DefaultOptions()
+	slow, fast := AnalyzeWithOptions(big, spec, off), AnalyzeWithOptions(small, spec, off)
+	if slow.RunMode == RunModeGpu || fast.RunMode != RunModeGpu {
+		t.Fatalf("run modes %s / %s, want the 32B offloaded and the 3B on GPU", slow.RunModeText(), fast.RunModeText())
+	}
+	if slow.Score <= fast.Score {
+		t.Fatalf("without the penalty the 32B (%.1f) should outscore the 3B (%.1f) on quality", slow.Score, fast.Score)
+	}
+	if slices.ContainsFunc(slow.Notes, func(n string) bool { return strings.HasPrefix(n, "Usability:") }) {
+		t.Error("the penalty is off by default, but a usability note was added")
+	}
+
+	on, err := DefaultOptions().WithUsabilityPenalty(0.2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slowOn, fastOn := AnalyzeWithOptions(big, spec, on), AnalyzeWithOptions(small, spec, on)
+	if fastOn.Score != fast.Score {
+		t.Errorf("GPU run score changed from %.1f to %.1f", fast.Score, fastOn.Score)
+	}
+	if slowOn.Score >= fastOn.Score {
+		t.Errorf("with the penalty the offloaded 32B (%.1f) should rank below the 3B on GPU (%.1f)", slowOn.Score, fastOn.Score)
+	}
+	if !slices.ContainsFunc(slowOn.Notes, func(n string) bool { return strings.HasPrefix(n, "Usability: score lowered") }) {
+		t.Errorf("notes %q, want a usability note", slowOn.Notes)
+	}
+	if _, err := DefaultOptions().WithUsabilityPenalty(0.8); err == nil {
+		t.Error("a penalty above MaxUsabilityPenalty should be rejected")
+	}
+}
+
+func TestClosestAlternative(t *testing.T) {
+	spec := specWithGPU(8, 16, false)
+	chatModel := func(name, params string, ram, vram float64) *models.LlmModel {
+		return &models.LlmModel{
+			Name: name, Provider: "Test", ParameterCount: params, MinRAMGB: ram, RecommendedRAMGB: ram * 1.5,
+			MinVRAMGB: &vram, Quantization: "Q4_K_M", ContextLength: 8192, UseCase: "Instruction following, chat",
+		}
+	}
+	coder := chatModel("test-coder-14b", "14B", 9, 7)
+	coder.UseCase = "Code generation and completion"
+	opts := DefaultOptions()
+	target := AnalyzeWithOptions(chatModel("test-chat-70b", "70B", 42, 40), spec, opts)
+	if target.FitLevel != FitTooTight {
+		t.Fatalf("target fit = %s, want Too Tight", target.FitText())
+	}
+	fits := AnalyzeAllWithOptions([]*models.LlmModel{
+		target.Model,
+		chatModel("other/test-chat-70b-GGUF", "70B", 42, 40), // a re-upload of the target
+		chatModel("test-chat-3b", "3B", 3, 2),
+		chatModel("test-chat-8b", "8B", 6, 5),
+		coder, // runnable and higher quality, but a different use case
+	}, spec, opts)
+
+	alt := ClosestAlternative(target, fits)
+	if alt == nil || alt.Model.Name != "test-chat-8b" {
+		t.Fatalf("alternative = %v, want test-chat-8b (highest-quality runnable chat model)", alt)
+	}
+	if alt.UseCase != target.UseCase || alt.FitLevel == FitTooTight {
+		t.Errorf("alternative %s: use case %s, fit %s", alt.Model.Name, alt.UseCase, alt.FitText())
+	}
+
+	if alt := ClosestAlternative(target, []*ModelFit{target, fits[1], fits[4]}); alt != nil {
+		t.Errorf("alternative = %s, want none when no other chat model runs", alt.Model.Name)
+	}
+}
+
+func TestAnalyze_OldGPUKernelNotes(t *testing.T) {
+	hasNote := func(fit *ModelFit, substr string) bool {
+		return slices.ContainsFunc(fit.Notes, func(n string) bool { return strings.Contains(n, substr) })
+	}
+	pascal := specWithGPU(8, 32, false)
+	pascal.Gpus[0].ComputeCapability, pascal.Gpus[0].DriverVersion = "6.1", "470.256.02"
+	fit := Analyze(model7BSmallVram(), pascal)
+	if fit.RunMode != RunModeGpu || !hasNote(fit, "compute capability 6.1") {
+		t.Errorf("compute 6.1 on %s: notes %q, want a compute capability note", fit.RunModeText(), fit.Notes)
+	}
+	if !slices.ContainsFunc(fit.Warnings(), func(w string) bool { return strings.Contains(w, "driver 470") }) {
+		t.Errorf("driver 470: warnings %q, want an old-driver warning", fit.Warnings())
+	}
+
+	ada := specWithGPU(8, 32, false)
+	ada.Gpus[0].ComputeCapability, ada.Gpus[0].DriverVersion = "8.9", "550.54.14"
+	if fit := Analyze(model7BSmallVram(), ada); hasNote(fit, "compute capability") || hasNote(fit, "NVIDIA driver") {
+		t.Errorf("modern GPU: notes %q, want no kernel notes", fit.Notes)
+	}
+	if fit := Analyze(model7BSmallVram(), specWithGPU(8, 32, false)); hasNote(fit, "compute capability") {
+		t.Errorf("unknown compute capability: notes %q, want no kernel note", fit.Notes)
+	}
+}
+
+func TestTiers(t *testing.T) {
+	var fits []*ModelFit
+	for i, level := range []FitLevel{FitMarginal, FitPerfect, FitTooTight, FitGood, FitPerfect, FitMarginal, FitGood, FitPerfect} {
+		fits = append(fits, &ModelFit{Model: &models.LlmModel{Name: fmt.Sprintf("m%d", i)}, FitLevel: level, Score: float64(10 + (i*37)%50)})
+	}
+	tiers := Tiers(fits, RankByScore)
+	if len(tiers) != 3 {
+		t.Fatalf("got %d tiers, want best, great, and tight", len(tiers))
+	}
+	total := 0
+	for _, tier := range tiers {
+		for i, f := range tier.Fits {
+			if f.FitLevel != tier.Level {
+				t.Errorf("tier %s holds %s at fit %s", tier.Key, f.Model.Name, f.FitText())
+			}
+			if i > 0 && f.Score > tier.Fits[i-1].Score {
+				t.Errorf("tier %s not ranked: %s (%.0f) after %s (%.0f)", tier.Key, f.Model.Name, f.Score, tier.Fits[i-1].Model.Name, tier.Fits[i-1].Score)
+			}
+		}
+		total += len(tier.Fits)
+	}
+	if want := []int{3, 2, 2}; len(tiers[0].Fits) != want[0] || len(tiers[1].Fits) != want[1] || len(tiers[2].Fits) != want[2] || total != 7 {
+		t.Errorf("tier sizes %d/%d/%d, want %v with the Too Tight model dropped", len(tiers[0].Fits), len(tiers[1].Fits), len(tiers[2].Fits), want)
+	}
+}
+
+func TestDenseOnlyScore_MoEQualityTowardActiveTier(t *testing.T) {
+	moe := moeModel("test-moe-235b", 235, 22, 140, 140)
+	dense := moeModel("test-dense-22b", 22, 22, 14, 14)
+	dense.IsMoE, dense.NumExperts, dense.ActiveExperts, dense.ActiveParameters = false, nil, nil, nil
+
+	byTotal := qualityScore(moe, "Q4_K_M", models.UseCaseGeneral, false)
+	byActive := qualityScore(moe, "Q4_K_M", models.UseCaseGeneral, true)
+	denseQuality := qualityScore(dense, "Q4_K_M", models.UseCaseGeneral, true)
+	if !(denseQuality < byActive && byActive < byTotal) {
+		t.Errorf("quality: dense 22B %.1f, 235B-A22B dense-only %.1f, by total %.1f; want dense < dense-only < total", denseQuality, byActive, byTotal)
+	}
+	if want := denseQuality + MoETotalParamsBonus*(byTotal-denseQuality); math.Abs(byActive-want) > 0.01 {
+		t.Errorf("dense-only quality %.2f, want %.2f (active tier plus %.0f%% of the gap)", byActive, want, MoETotalParamsBonus*100)
+	}
+	if q := qualityScore(dense, "Q4_K_M", models.UseCaseGeneral, false); q != denseQuality {
+		t.Errorf("dense model quality %.1f with dense-only, %.1f without; want unchanged", denseQuality, q)
+	}
+
+	opts := DefaultOptions()
+	opts.DenseOnlyScore = true
+	spec := specWithGPU(24, 256, false)
+	if on, off := AnalyzeWithOptions(moe, spec, opts), Analyze(moe, spec); on.ScoreComponents.Quality >= off.ScoreComponents.Quality {
+		t.Errorf("Analyze quality %.1f with DenseOnlyScore, %.1f without; want lower", on.ScoreComponents.Quality, off.ScoreComponents.Quality)
+	}
+}
+
+func TestAnalyze_PrefersFreeVRAM(t *testing.T) {
+	spec := specWithGPU(8, 32, false)
+	m := model7B()
+	if f := Analyze(m, spec); f.RunMode != RunModeGpu {
+		t.Fatalf("8 GB total: run mode %s, want GPU", f.RunModeText())
+	}
+	free := 4.8
+	spec.GpuFreeVRAMGB = &free
+	spec.Gpus[0].FreeVRAMGB = &free
+	f := Analyze(m, spec)
+	if f.RunMode == RunModeGpu {
+		t.Errorf("4.8 of 8 GB free: run mode %s, want the model no longer loaded fully into VRAM", f.RunModeText())
+	}
+	if !slices.ContainsFunc(f.Notes, func(n string) bool { return strings.Contains(n, "3.2 GB VRAM already in use by other processes") }) {
+		t.Errorf("notes %q: want the VRAM held by other processes", f.Notes)
+	}
+}
+
+func TestFilterByMinContext_ComposesWithUseCase(t *testing.T) {
+	mk := func(name, useCase string, ctx uint32) *models.LlmModel {
+		m := model7B()
+		m.Name, m.UseCase, m.ContextLength = name, useCase, ctx
+		return m
+	}
+	catalog := []*models.LlmModel{
+		mk("coder-short", "code generation", 4096),
+		mk("coder-long", "code generation", 65536),
+		mk("chat-long", "chat", 65536),
+	}
+	spec := specWithGPU(16, 32, false)
+	for _, tt := range []struct {
+		minCtx  uint32
+		useCase string
+		want    []string
+	}{
+		{0, "coding", []string{"coder-short", "coder-long"}},
+		{16384, "coding", []string{"coder-long"}},
+		{16384, "", []string{"coder-long", "chat-long"}},
+		{131072, "coding", nil},
+	} {
+		fits := AnalyzeAll(models.FilterByMinContext(catalog, tt.minCtx), spec)
+		if tt.useCase != "" {
+			fits = FilterByUseCase(fits, tt.useCase)
+		}
+		var got []string
+		for _, f := range fits {
+			got = append(got, f.Model.Name)
+		}
+		slices.Sort(got)
+		want := slices.Clone(tt.want)
+		slices.Sort(want)
+		if !slices.Equal(got, want) {
+			t.Errorf("min context %d, use case %q: got %v, want %v", tt.minCtx, tt.useCase, got, want)
+		}
+	}
+}
+
+func TestScoreWeights_CustomFileChangesRanking(t *testing.T) {
+	mk := func(name, params string) *models.LlmModel {
+		return &models.LlmModel{Name: name, ParameterCount: params, Quantization: "Q4_K_M", ContextLength: 32768, UseCase: "general"}
+	}
+	catalog := []*models.LlmModel{mk("small-3b", "3B"), mk("big-14b", "14B")}
+	spec := specWithGPU(24, 64, false)
+	order := func(opts Options) []string {
+		var names []string
+		for _, f := range RankModelsBy(AnalyzeAllWithOptions(catalog, spec, opts), RankByScore) {
+			names = append(names, f.Model.Name)
+		}
+		return names
+	}
+
+	defaults := DefaultScoreWeights()
+	explicit := DefaultOptions()
+	explicit.Weights = &defaults
+	for _, m := range catalog {
+		if a, b := AnalyzeWithOptions(m, spec, DefaultOptions()).Score, AnalyzeWithOptions(m, spec, explicit).Score; a != b {
+			t.Errorf("%s: score %.1f with no weights, %.1f with DefaultScoreWeights; want identical", m.Name, a, b)
+		}
+	}
+	if got := order(DefaultOptions()); !slices.Equal(got, []string{"small-3b", "big-14b"}) {
+		t.Fatalf("default order = %v, want the faster 3B first", got)
+	}
+
+	sw, warnings, err := ParseScoreWeights([]byte(`{"general": {"quality": 0.85, "speed": 0.05, "fit": 0.05, "context": 0.05}}`))
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("ParseScoreWeights: %v, warnings %v", err, warnings)
+	}
+	if sw.Coding != defaults.Coding {
+		t.Errorf("coding weights = %+v, want the defaults when the file does not list coding", sw.Coding)
+	}
+	opts := DefaultOptions()
+	opts.Weights = &sw
+	if got := order(opts); !slices.Equal(got, []string{"big-14b", "small-3b"}) {
+		t.Errorf("quality-heavy order = %v, want the higher-quality 14B first", got)
+	}
+
+	if _, warnings, err := ParseScoreWeights([]byte(`{"code": {"quality": 0.9, "speed": 0.3}}`)); err != nil || len(warnings) != 1 {
+		t.Errorf("weights summing to 1.2: err %v, warnings %v; want one warning", err, warnings)
+	}
+	if _, _, err := ParseScoreWeights([]byte(`{"poetry": {"quality": 1}}`)); err == nil {
+		t.Error("expected an error for an unknown use case")
+	}
+}
+
+func TestEstimatedTTFT_BackendAndPromptLength(t *testing.T) {
+	ttft := func(backend hardware.GpuBackend, promptTokens int) float64 {
+		spec := specWithGPU(24, 64, false)
+		spec.Backend = backend
+		opts, err := DefaultOptions().WithPromptTokens(promptTokens)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fit := AnalyzeWithOptions(model7B(), spec, opts)
+		if fit.RunMode != RunModeGpu || fit.EstimatedTTFTms <= 0 || fit.EstimatedPrefillTPS <= fit.EstimatedTPS {
+			t.Fatalf("%v: mode %v, TTFT %.1f ms, prefill %.1f tok/s vs %.1f tok/s generation; want a GPU fit prefilling faster than it generates",
+				backend, fit.RunMode, fit.EstimatedTTFTms, fit.EstimatedPrefillTPS, fit.EstimatedTPS)
+		}
+		return fit.EstimatedTTFTms
+	}
+	cuda, vulkan, sycl := ttft(hardware.BackendCuda, 512), ttft(hardware.BackendVulkan, 512), ttft(hardware.BackendSycl, 512)
+	if !(cuda < vulkan && vulkan < sycl) {
+		t.Errorf("TTFT CUDA %.1f, Vulkan %.1f, SYCL %.1f ms; want faster backends to reach the first token sooner", cuda, vulkan, sycl)
+	}
+	if short, long := ttft(hardware.BackendCuda, 128), ttft(hardware.BackendCuda, 4096); !(short < cuda && cuda < long) {
+		t.Errorf("TTFT for 128/512/4096-token prompts = %.1f/%.1f/%.1f ms; want longer prompts to take longer", short, cuda, long)
+	}
+	if got := AnalyzeWithOptions(model7B(), specWithGPU(24, 64, false), DefaultOptions()).EstimatedTTFTms; got != cuda {
+		t.Errorf("default TTFT = %.1f ms, want the %d-token estimate %.1f ms", got, DefaultPromptTokens, cuda)
+	}
+	if _, err := DefaultOptions().WithPromptTokens(0); err == nil {
+		t.Error("expected an error for a zero-token prompt")
+	}
+}
+
+func TestAnalyze_TensorParallelPoolsVRAM(t *testing.T) {
+	gpu := func(name string, vramGB float64, count uint32, backend hardware.GpuBackend) hardware.GpuInfo {
+		return hardware.GpuInfo{Name: name, VRAMGB: &vramGB, Backend: backend, Count: count}
+	}
+	spec := func(backend hardware.GpuBackend, gpus ...hardware.GpuInfo) *hardware.SystemSpecs {
+		s := specWithGPU(*gpus[0].VRAMGB, 32, false)
+		s.Backend = backend
+		s.GpuCount = gpus[0].Count
+		s.Gpus = gpus
+		return s
+	}
+	model := func(vramGB float64) *models.LlmModel {
+		m := model7B()
+		m.Name, m.ParameterCount = "test-70b", "70B"
+		m.MinVRAMGB, m.MinRAMGB, m.RecommendedRAMGB = &vramGB, vramGB+2, vramGB+8
+		return m
+	}
+	hasSplitNote := func(f *ModelFit, gpus string) bool {
+		for _, n := range f.Notes {
+			if strings.Contains(n, "split across "+gpus+" GPUs (tensor parallel)") {
+				return true
+			}
+		}
+		return false
+	}
+
+	for name, s := range map[string]*hardware.SystemSpecs{
+		"two 24 GB cards listed apart": spec(hardware.BackendCuda, gpu("RTX 3090", 24, 1, hardware.BackendCuda), gpu("RTX 3090", 24, 1, hardware.BackendCuda)),
+		"one group of two 24 GB cards": spec(hardware.BackendCuda, gpu("RTX 3090", 48, 2, hardware.BackendCuda)),
+		"two 24 GB ROCm cards":         spec(hardware.BackendRocm, gpu("RX 7900 XTX", 24, 1, hardware.BackendRocm), gpu("RX 7900 XTX", 24, 1, hardware.BackendRocm)),
+	} {
+		f := Analyze(model(40), s)
+		if f.RunMode != RunModeGpu || f.FitLevel == FitTooTight || f.MemoryAvailableGB != 48 || !hasSplitNote(f, "2") {
+			t.Errorf("%s: 40 GB model = %v %v against %.1f GB, notes %v; want it on the GPU against 48 GB pooled, split across 2 GPUs",
+				name, f.RunMode, f.FitLevel, f.MemoryAvailableGB, f.Notes)
+		}
+	}
+
+	// Mixed cards pool as the smaller card times the count: 24 + 16 GB counts as 2 × 16 GB.
+	mixed := spec(hardware.BackendCuda, gpu("RTX 4090", 24, 1, hardware.BackendCuda), gpu("RTX 4080", 16, 1, hardware.BackendCuda))
+	if f := Analyze(model(28), mixed); f.RunMode != RunModeGpu || f.MemoryAvailableGB != 32 || !hasSplitNote(f, "2") {
+		t.Errorf("24+16 GB, 28 GB model = %v against %.1f GB, notes %v; want GPU against 32 GB pooled", f.RunMode, f.MemoryAvailableGB, f.Notes)
+	}
+	if f := Analyze(model(40), mixed); f.RunMode == RunModeGpu && f.FitLevel != FitTooTight {
+		t.Errorf("24+16 GB, 40 GB model = %v %v; want it not to fit on the GPUs", f.RunMode, f.FitLevel)
+	}
+	// A small second card never makes things worse than the primary alone.
+	if f := Analyze(model(20), spec(hardware.BackendCuda, gpu("RTX 4090", 24, 1, hardware.BackendCuda), gpu("GT 1030", 2, 1, hardware.BackendCuda))); f.MemoryAvailableGB != 24 || hasSplitNote(f, "2") {
+		t.Errorf("24+2 GB = %.1f GB, notes %v; want the 24 GB card alone", f.MemoryAvailableGB, f.Notes)
+	}
+
+	// Only CUDA and ROCm pool; other backends keep the primary GPU alone.
+	vulkan := spec(hardware.BackendVulkan, gpu("Arc A770", 16, 1, hardware.BackendVulkan), gpu("Arc A770", 16, 1, hardware.BackendVulkan))
+	if f := Analyze(model(20), vulkan); f.RunMode == RunModeGpu || f.MemoryAvailableGB == 32 {
+		t.Errorf("two Vulkan cards = %v against %.1f GB; want no pooling", f.RunMode, f.MemoryAvailableGB)
+	}
+}
@@ -0,0 +1,22 @@
+package pole
+
+import (
+	"github.com/shayne-snap/llmpole/internal/hardware"
+	"github.com/shayne-snap/llmpole/internal/models"
+)
+
+// ProfileFit is a model's fit on one built-in hardware profile.
+type ProfileFit struct {
+	Profile string
+	Fit     *ModelFit
+}
+
+// AnalyzeAcrossProfiles analyzes m on every hardware.Profiles machine, in that order, for
+// "where would this run well" answers independent of this machine.
+func AnalyzeAcrossProfiles(m *models.LlmModel, opts Options) []ProfileFit {
+	out := make([]ProfileFit, 0, len(hardware.Profiles))
+	for _, p := range hardware.Profiles {
+		out = append(out, ProfileFit{Profile: p.Name, Fit: AnalyzeWithOptions(m, p.Specs(), opts)})
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package pole
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RankBy names the ordering applied among fits by RankModelsBy.
+type RankBy string
+
+const (
+	RankByScore        RankBy = "score"          // weighted overall score
+	RankByQualityPerGB RankBy = "quality-per-gb" // quality score per GB of memory required
+	RankBySpeed        RankBy = "speed"          // estimated tokens per second
+	RankByTPSPerGB     RankBy = "tps-per-gb"     // estimated tokens per second per GB required
+)
+
+// fitLess reports whether a ranks ahead of b.
+type fitLess func(a, b *ModelFit) bool
+
+var rankComparators = map[RankBy]fitLess{
+	RankByScore:        byScore,
+	RankByQualityPerGB: byQualityPerGB,
+	RankBySpeed:        bySpeed,
+	RankByTPSPerGB:     byTPSPerGB,
+}
+
+// RankByNames returns the ranking mode names in flag-help order.
+func RankByNames() []string {
+	return []string{string(RankByScore), string(RankByQualityPerGB), string(RankBySpeed), string(RankByTPSPerGB)}
+}
+
+// ParseRankBy parses a --rank-by value. An empty string means RankByScore.
+func ParseRankBy(s string) (RankBy, error) {
+	r := RankBy(strings.ToLower(strings.TrimSpace(s)))
+	if r == "" {
+		return RankByScore, nil
+	}
+	if _, ok := rankComparators[r]; !ok {
+		return RankByScore, fmt.Errorf("unknown ranking %q (want one of: %s)", s, strings.Join(RankByNames(), ", "))
+	}
+	return r, nil
+}
+
+func byScore(a, b *ModelFit) bool {
+	return a.Score > b.Score
+}
+
+func byQualityPerGB(a, b *ModelFit) bool {
+	qa, qb := perGB(a.ScoreComponents.Quality, a), perGB(b.ScoreComponents.Quality, b)
+	if qa != qb {
+		return qa > qb
+	}
+	return byScore(a, b)
+}
+
+func bySpeed(a, b *ModelFit) bool {
+	if a.EstimatedTPS != b.EstimatedTPS {
+		return a.EstimatedTPS > b.EstimatedTPS
+	}
+	return byScore(a, b)
+}
+
+func byTPSPerGB(a, b *ModelFit) bool {
+	ta, tb := perGB(a.EstimatedTPS, a), perGB(b.EstimatedTPS, b)
+	if ta != tb {
+		return ta > tb
+	}
+	return byScore(a, b)
+}
+
+// perGB divides v by the fit's required memory (v itself when the requirement is unknown).
+func perGB(v float64, f *ModelFit) float64 {
+	if f.MemoryRequiredGB <= 0 {
+		return v
+	}
+	return v / f.MemoryRequiredGB
+}
+
+// RankModelsBy sorts fits by the given ordering, with Too Tight entries last. It returns a new slice.
+func RankModelsBy(fits []*ModelFit, by RankBy) []*ModelFit {
+	less, ok := rankComparators[by]
+	if !ok {
+		less = byScore
+	}
+	out := make([]*ModelFit, len(fits))
+	copy(out, fits)
+	sort.SliceStable(out, func(i, j int) bool {
+		ar, br := out[i].FitLevel != FitTooTight, out[j].FitLevel != FitTooTight
+		if ar != br {
+			return ar
+		}
+		return less(out[i], out[j])
+	})
+	return out
+}
@@ -0,0 +1,33 @@
+package pole
+
+// Summary aggregates a result set for dashboards: counts by provider, fit level, and use case.
+type Summary struct {
+	Total     int            `json:"total"`
+	Runnable  int            `json:"runnable"`
+	Providers map[string]int `json:"providers"`
+	FitLevels map[string]int `json:"fit_levels"`
+	UseCases  map[string]int `json:"use_cases"`
+}
+
+// Summarize computes the Summary of fits in one pass.
+func Summarize(fits []*ModelFit) Summary {
+	s := Summary{
+		Total:     len(fits),
+		Providers: make(map[string]int),
+		FitLevels: make(map[string]int),
+		UseCases:  make(map[string]int),
+	}
+	for _, f := range fits {
+		if f.FitLevel != FitTooTight {
+			s.Runnable++
+		}
+		provider := f.Model.Provider
+		if provider == "" {
+			provider = "Unknown"
+		}
+		s.Providers[provider]++
+		s.FitLevels[f.FitText()]++
+		s.UseCases[f.UseCase.String()]++
+	}
+	return s
+}
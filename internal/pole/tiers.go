@@ -0,0 +1,33 @@
+package pole
+
+// Tier is one "download now" bucket for recommend --tiers: the runnable fits of one fit level.
+type Tier struct {
+	Key   string // JSON key: "best", "great", or "tight"
+	Label string // heading, e.g. "Best (Perfect fit)"
+	Level FitLevel
+	Fits  []*ModelFit
+}
+
+// tierDefs lists the tiers best first; Too Tight models belong to none.
+var tierDefs = []Tier{
+	{Key: "best", Label: "Best (Perfect fit)", Level: FitPerfect},
+	{Key: "great", Label: "Great (Good fit)", Level: FitGood},
+	{Key: "tight", Label: "Works but tight (Marginal)", Level: FitMarginal},
+}
+
+// Tiers buckets fits by fit level into the Best, Great, and Works-but-tight tiers, each ranked
+// by by. Every tier is returned, possibly empty; Too Tight fits are dropped.
+func Tiers(fits []*ModelFit, by RankBy) []Tier {
+	out := make([]Tier, len(tierDefs))
+	for i, t := range tierDefs {
+		var level []*ModelFit
+		for _, f := range fits {
+			if f.FitLevel == t.Level {
+				level = append(level, f)
+			}
+		}
+		t.Fits = RankModelsBy(level, by)
+		out[i] = t
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package pole
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/shayne-snap/llmpole/internal/models"
+)
+
+// Weights are the shares of one use case's score taken by each component; they should sum to 1.
+type Weights struct {
+	Quality float64 `json:"quality"`
+	Speed   float64 `json:"speed"`
+	Fit     float64 `json:"fit"`
+	Context float64 `json:"context"`
+}
+
+// Sum is the total of the four weights.
+func (w Weights) Sum() float64 {
+	return w.Quality + w.Speed + w.Fit + w.Context
+}
+
+// ScoreWeights holds the component weights of every use case. The zero value is not usable;
+// start from DefaultScoreWeights.
+type ScoreWeights struct {
+	General    Weights
+	Coding     Weights
+	Reasoning  Weights
+	Chat       Weights
+	Multimodal Weights
+	Embedding  Weights
+}
+
+// DefaultScoreWeights returns the built-in weights: quality leads everywhere except embeddings,
+// where throughput matters most.
+func DefaultScoreWeights() ScoreWeights {
+	return ScoreWeights{
+		General:    Weights{Quality: 0.45, Speed: 0.30, Fit: 0.15, Context: 0.10},
+		Coding:     Weights{Quality: 0.50, Speed: 0.20, Fit: 0.15, Context: 0.15},
+		Reasoning:  Weights{Quality: 0.55, Speed: 0.15, Fit: 0.15, Context: 0.15},
+		Chat:       Weights{Quality: 0.40, Speed: 0.35, Fit: 0.15, Context: 0.10},
+		Multimodal: Weights{Quality: 0.50, Speed: 0.20, Fit: 0.15, Context: 0.15},
+		Embedding:  Weights{Quality: 0.30, Speed: 0.40, Fit: 0.20, Context: 0.10},
+	}
+}
+
+// For returns the weights of useCase; unknown use cases get the General weights.
+func (sw *ScoreWeights) For(useCase models.UseCase) Weights {
+	return *sw.slot(useCase)
+}
+
+func (sw *ScoreWeights) slot(useCase models.UseCase) *Weights {
+	switch useCase {
+	case models.UseCaseCoding:
+		return &sw.Coding
+	case models.UseCaseReasoning:
+		return &sw.Reasoning
+	case models.UseCaseChat:
+		return &sw.Chat
+	case models.UseCaseMultimodal:
+		return &sw.Multimodal
+	case models.UseCaseEmbedding:
+		return &sw.Embedding
+	default:
+		return &sw.General
+	}
+}
+
+// weightSumTolerance is how far a use case's weights may stray from 1 before ParseScoreWeights warns.
+const weightSumTolerance = 0.01
+
+// ParseScoreWeights reads a weights file: a JSON object keyed by use case (general, coding,
+// reasoning, chat, multimodal, embedding, or their short forms), each value giving quality, speed,
+// fit, and context weights. Listed use cases replace their defaults whole; the rest keep them.
+// Weights that do not sum to about 1 are accepted but reported, one warning per use case.
+func ParseScoreWeights(data []byte) (ScoreWeights, []string, error) {
+	sw := DefaultScoreWeights()
+	var raw map[string]Weights
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return sw, nil, err
+	}
+	var warnings []string
+	for _, name := range slices.Sorted(maps.Keys(raw)) {
+		w := raw[name]
+		uc, ok := useCaseFromString(name)
+		if !ok {
+			return sw, nil, fmt.Errorf("unknown use case %q (want general, coding, reasoning, chat, multimodal, or embedding)", name)
+		}
+		if w.Quality < 0 || w.Speed < 0 || w.Fit < 0 || w.Context < 0 {
+			return sw, nil, fmt.Errorf("%s: weights must not be negative", name)
+		}
+		if sum := w.Sum(); math.Abs(sum-1) > weightSumTolerance {
+			warnings = append(warnings, fmt.Sprintf("%s weights sum to %.2f, not 1: scores will not be on the usual 0-100 scale", name, sum))
+		}
+		*sw.slot(uc) = w
+	}
+	return sw, warnings, nil
+}
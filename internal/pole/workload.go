@@ -0,0 +1,52 @@
+package pole
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// baseKVContext is the context length the catalog memory figures are assumed to include KV cache for.
+const baseKVContext = 4096
+
+// Workload is a named preset for how the models will be used. It sets the context length
+// that earns a full context score, how much that score weighs, and the context memory is sized for.
+type Workload struct {
+	Name string
+	// ContextTarget is the context length (tokens) that earns a full context score.
+	ContextTarget uint32
+	// ContextWeight replaces the use case's context weight; the other weights are rescaled to sum to 1.
+	ContextWeight float64
+	// AnalysisContext is the context length (tokens) memory requirements are estimated at, capped by the model's maximum.
+	AnalysisContext uint32
+}
+
+var workloads = map[string]Workload{
+	"chat":    {Name: "chat", ContextTarget: 4096, ContextWeight: 0.10, AnalysisContext: 4096},
+	"rag":     {Name: "rag", ContextTarget: 32768, ContextWeight: 0.30, AnalysisContext: 16384},
+	"agentic": {Name: "agentic", ContextTarget: 32768, ContextWeight: 0.25, AnalysisContext: 32768},
+}
+
+// WorkloadNames returns the preset names, sorted.
+func WorkloadNames() []string {
+	names := make([]string, 0, len(workloads))
+	for n := range workloads {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithWorkload returns o with the named preset applied. An empty name leaves o unchanged.
+func (o Options) WithWorkload(name string) (Options, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return o, nil
+	}
+	w, ok := workloads[name]
+	if !ok {
+		return o, fmt.Errorf("unknown workload %q (want one of: %s)", name, strings.Join(WorkloadNames(), ", "))
+	}
+	o.Workload = &w
+	return o, nil
+}
@@ -0,0 +1,128 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Action is a named TUI command. Normal-mode keys and the command palette both run actions from
+// the registry, so every keybinding is also discoverable by name.
+type Action struct {
+	Name string   // palette label
+	Keys []string // normal-mode keys, as tea.KeyMsg.String() reports them; none for palette-only actions
+	Run  func(a *App)
+}
+
+// actions is the registry, in palette order.
+var actions = []Action{
+	{Name: "Search models", Keys: []string{"/"}, Run: (*App).EnterSearch},
+	{Name: "Cycle fit filter", Keys: []string{"f"}, Run: (*App).CycleFitFilter},
+	{Name: "Select providers", Keys: []string{"p"}, Run: (*App).OpenProviderPopup},
+	{Name: "Toggle detail view", Keys: []string{"enter"}, Run: (*App).ToggleDetail},
+	{Name: "Toggle system panel", Keys: []string{"i"}, Run: (*App).ToggleSystemPanel},
+	{Name: "Toggle memory view (% / GB)", Keys: []string{"m"}, Run: (*App).ToggleMemoryView},
+	{Name: "Toggle top model per provider", Keys: []string{"t"}, Run: (*App).ToggleTopByProvider},
+	{Name: "Sort providers alphabetically", Run: func(a *App) { a.SortProviders(ProviderSortAlpha, nil) }},
+	{Name: "Sort providers by model count", Run: func(a *App) { a.SortProviders(ProviderSortCount, nil) }},
+	{Name: "Move up", Keys: []string{"up", "k"}, Run: (*App).MoveUp},
+	{Name: "Move down", Keys: []string{"down", "j"}, Run: (*App).MoveDown},
+	{Name: "Page up", Keys: []string{"pgup"}, Run: (*App).PageUp},
+	{Name: "Page down", Keys: []string{"pgdown"}, Run: (*App).PageDown},
+	{Name: "Go to first model", Keys: []string{"home", "g"}, Run: (*App).Home},
+	{Name: "Go to last model", Keys: []string{"end", "G"}, Run: (*App).End},
+	{Name: "Close view / quit", Keys: []string{"q", "esc"}, Run: (*App).Back},
+}
+
+// paletteKeys open the command palette from normal mode.
+var paletteKeys = []string{":", "ctrl+p"}
+
+// actionForKey returns the registered action bound to key, or nil.
+func actionForKey(key string) *Action {
+	for i := range actions {
+		for _, k := range actions[i].Keys {
+			if k == key {
+				return &actions[i]
+			}
+		}
+	}
+	return nil
+}
+
+// fuzzyScore reports whether query's runes appear in name in order (case-insensitive), and how
+// loosely: the number of skipped runes before and between the matches. Lower is a better match.
+func fuzzyScore(query, name string) (int, bool) {
+	query, name = strings.ToLower(query), strings.ToLower(name)
+	score := 0
+	for _, q := range query {
+		i := strings.IndexRune(name, q)
+		if i < 0 {
+			return 0, false
+		}
+		score += utf8.RuneCountInString(name[:i])
+		name = name[i+utf8.RuneLen(q):]
+	}
+	return score, true
+}
+
+// PaletteMatches returns the indices into the action registry matching PaletteQuery, best match
+// first (registry order on ties). An empty query lists every action.
+func (a *App) PaletteMatches() []int {
+	type match struct{ idx, score int }
+	var ms []match
+	for i, act := range actions {
+		if score, ok := fuzzyScore(a.PaletteQuery, act.Name); ok {
+			ms = append(ms, match{i, score})
+		}
+	}
+	sort.SliceStable(ms, func(i, j int) bool { return ms[i].score < ms[j].score })
+	out := make([]int, len(ms))
+	for i, m := range ms {
+		out[i] = m.idx
+	}
+	return out
+}
+
+func (a *App) OpenPalette() {
+	a.InputMode = InputModePalette
+	a.PaletteQuery, a.PaletteCursor = "", 0
+}
+
+func (a *App) ClosePalette() {
+	a.InputMode = InputModeNormal
+}
+
+// PaletteInsert appends typed runes to the palette query and moves the cursor to the best match.
+func (a *App) PaletteInsert(runes []rune) {
+	a.PaletteQuery += string(runes)
+	a.PaletteCursor = 0
+}
+
+func (a *App) PaletteBackspace() {
+	if a.PaletteQuery != "" {
+		_, size := utf8.DecodeLastRuneInString(a.PaletteQuery)
+		a.PaletteQuery = a.PaletteQuery[:len(a.PaletteQuery)-size]
+		a.PaletteCursor = 0
+	}
+}
+
+func (a *App) PaletteUp() {
+	if a.PaletteCursor > 0 {
+		a.PaletteCursor--
+	}
+}
+
+func (a *App) PaletteDown() {
+	if a.PaletteCursor+1 < len(a.PaletteMatches()) {
+		a.PaletteCursor++
+	}
+}
+
+// RunPaletteSelection closes the palette and runs the highlighted action, if any matches.
+func (a *App) RunPaletteSelection() {
+	matches := a.PaletteMatches()
+	a.ClosePalette()
+	if a.PaletteCursor < len(matches) {
+		actions[matches[a.PaletteCursor]].Run(a)
+	}
+}
@@ -1,20 +1,25 @@
 package tui
 
 import (
+	"fmt"
+	"math"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/shayne-snap/llmpole/internal/hardware"
 	"github.com/shayne-snap/llmpole/internal/pole"
 )
 
-// InputMode is the current TUI input mode (normal, search, or provider popup).
+// InputMode is the current TUI input mode (normal, search, provider popup, or command palette).
 type InputMode int
 
 const (
 	InputModeNormal InputMode = iota
 	InputModeSearch
 	InputModeProviderPopup
+	InputModePalette
 )
 
 // FitFilter filters the model list by fit level (All, Runnable, Perfect, Good, Marginal; cycle with same key).
@@ -62,11 +67,19 @@ func (f FitFilter) Next() FitFilter {
 	}
 }
 
+// MemoryView selects how memory use is emphasized in the table's memory column and the detail view.
+type MemoryView int
+
+const (
+	MemoryViewPercent MemoryView = iota // percent of available memory first
+	MemoryViewGB                        // absolute GB first
+)
+
 // App holds the TUI state (specs, fits, filters, selection, providers).
 type App struct {
-	ShouldQuit   bool
-	InputMode    InputMode
-	SearchQuery  string
+	ShouldQuit     bool
+	InputMode      InputMode
+	SearchQuery    string
 	CursorPosition int
 
 	Specs             *hardware.SystemSpecs
@@ -75,13 +88,39 @@ type App struct {
 	Providers         []string
 	SelectedProviders []bool
 
-	FitFilter   FitFilter
-	SelectedRow int
-	ShowDetail  bool
+	FitFilter      FitFilter
+	SelectedRow    int
+	ShowDetail     bool
+	ShowSystem     bool
 	ProviderCursor int
+	ProviderSort   ProviderSort // order of Providers in the popup
+	PaletteQuery   string       // command palette filter
+	PaletteCursor  int          // index into PaletteMatches
+	MemoryView     MemoryView
+	TopByProvider  bool // collapse the list to the best runnable model per provider
 
 	Width  int
 	Height int
+
+	Live []hardware.GpuLiveStats // latest GPU utilization/temperature sample, if any
+
+	Theme *Theme // styles to draw with; nil is DarkTheme
+
+	// Filter cache: ApplyFilters recomputes FilteredFits only when filterKey changes.
+	filterKey   filterKey
+	filterValid bool
+	filterRuns  int
+	searchText  []string // lowercased name/provider/params/use case per AllFits entry
+	providerIdx map[string]int
+}
+
+// filterKey captures every input to ApplyFilters.
+type filterKey struct {
+	query     string
+	fitFilter FitFilter
+	providers string // SelectedProviders as a 0/1 string
+	numFits   int
+	topOnly   bool
 }
 
 // NewApp builds app state from specs and pre-analyzed fits (caller must have run RankModelsByFit).
@@ -116,24 +155,65 @@ func NewApp(specs *hardware.SystemSpecs, allFits []*pole.ModelFit) *App {
 }
 
 // ApplyFilters updates FilteredFits from search, provider, and fit filters; clamps SelectedRow.
+// The scan over AllFits is skipped when none of the filter inputs changed since the last call.
 func (a *App) ApplyFilters() {
-	query := strings.ToLower(a.SearchQuery)
+	key := a.currentFilterKey()
+	if !a.filterValid || key != a.filterKey {
+		a.filterKey, a.filterValid = key, true
+		a.FilteredFits = a.filterFits()
+	}
+	if len(a.FilteredFits) == 0 {
+		a.SelectedRow = 0
+	} else if a.SelectedRow >= len(a.FilteredFits) {
+		a.SelectedRow = len(a.FilteredFits) - 1
+	}
+}
+
+func (a *App) currentFilterKey() filterKey {
+	var sel strings.Builder
+	for _, s := range a.SelectedProviders {
+		if s {
+			sel.WriteByte('1')
+		} else {
+			sel.WriteByte('0')
+		}
+	}
+	return filterKey{query: a.SearchQuery, fitFilter: a.FitFilter, providers: sel.String(), numFits: len(a.AllFits), topOnly: a.TopByProvider}
+}
+
+// buildIndex precomputes the lowercased search text and provider index for AllFits.
+func (a *App) buildIndex() {
+	a.searchText = make([]string, len(a.AllFits))
+	for i, fit := range a.AllFits {
+		m := fit.Model
+		a.searchText[i] = strings.ToLower(m.Name + "\x00" + m.Provider + "\x00" + m.ParameterCount + "\x00" + m.UseCase)
+	}
+	a.indexProviders()
+}
+
+// indexProviders maps each provider to its position in Providers (and SelectedProviders).
+func (a *App) indexProviders() {
+	a.providerIdx = make(map[string]int, len(a.Providers))
+	for j, p := range a.Providers {
+		a.providerIdx[p] = j
+	}
+}
+
+func (a *App) filterFits() []int {
+	a.filterRuns++
+	if len(a.searchText) != len(a.AllFits) {
+		a.buildIndex()
+	}
+	query, moeOnly, denseOnly, minCtx := parseQualifiers(strings.ToLower(a.SearchQuery))
 	var out []int
 	for i, fit := range a.AllFits {
 		m := fit.Model
-		matchesSearch := query == "" ||
-			strings.Contains(strings.ToLower(m.Name), query) ||
-			strings.Contains(strings.ToLower(m.Provider), query) ||
-			strings.Contains(strings.ToLower(m.ParameterCount), query) ||
-			strings.Contains(strings.ToLower(m.UseCase), query)
-		providerIdx := -1
-		for j, p := range a.Providers {
-			if p == m.Provider {
-				providerIdx = j
-				break
-			}
+		if (moeOnly && !m.IsMoE) || (denseOnly && m.IsMoE) || m.ContextLength < minCtx {
+			continue
 		}
-		matchesProvider := providerIdx < 0 || (providerIdx < len(a.SelectedProviders) && a.SelectedProviders[providerIdx])
+		matchesSearch := query == "" || strings.Contains(a.searchText[i], query)
+		providerIdx, ok := a.providerIdx[m.Provider]
+		matchesProvider := !ok || (providerIdx < len(a.SelectedProviders) && a.SelectedProviders[providerIdx])
 		matchesFit := true
 		switch a.FitFilter {
 		case FitFilterAll:
@@ -151,12 +231,71 @@ func (a *App) ApplyFilters() {
 			out = append(out, i)
 		}
 	}
-	a.FilteredFits = out
-	if len(a.FilteredFits) == 0 {
-		a.SelectedRow = 0
-	} else if a.SelectedRow >= len(a.FilteredFits) {
-		a.SelectedRow = len(a.FilteredFits) - 1
+	if a.TopByProvider {
+		out = a.topPerProvider(out)
+	}
+	return out
+}
+
+// topPerProvider keeps, in list order, the highest-scoring runnable fit of each provider in idx
+// (the first one on ties). Providers with nothing runnable drop out.
+func (a *App) topPerProvider(idx []int) []int {
+	best := make(map[int]int, len(a.Providers)) // provider index -> AllFits index
+	for _, i := range idx {
+		fit := a.AllFits[i]
+		if fit.FitLevel == pole.FitTooTight {
+			continue
+		}
+		p := a.providerIdx[fit.Model.Provider]
+		if j, ok := best[p]; !ok || fit.Score > a.AllFits[j].Score {
+			best[p] = i
+		}
+	}
+	out := make([]int, 0, len(best))
+	for _, i := range idx {
+		if best[a.providerIdx[a.AllFits[i].Model.Provider]] == i && a.AllFits[i].FitLevel != pole.FitTooTight {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// parseQualifiers strips "is:moe" / "is:dense" and "ctx:N" (minimum context; "ctx:32k" is 32768
+// tokens) qualifiers from a search query. A "ctx:" word that is not a number stays in the query.
+func parseQualifiers(query string) (rest string, moeOnly, denseOnly bool, minCtx uint32) {
+	var words []string
+	for _, w := range strings.Fields(query) {
+		switch w {
+		case "is:moe":
+			moeOnly = true
+		case "is:dense":
+			denseOnly = true
+		default:
+			if n, ok := parseContextQualifier(w); ok {
+				minCtx = n
+				continue
+			}
+			words = append(words, w)
+		}
 	}
+	return strings.Join(words, " "), moeOnly, denseOnly, minCtx
+}
+
+// parseContextQualifier parses a "ctx:N" or "ctx:Nk" search word into a token count.
+func parseContextQualifier(w string) (uint32, bool) {
+	v, ok := strings.CutPrefix(w, "ctx:")
+	if !ok {
+		return 0, false
+	}
+	mult := uint64(1)
+	if k, isK := strings.CutSuffix(v, "k"); isK {
+		v, mult = k, 1024
+	}
+	n, err := strconv.ParseUint(v, 10, 32)
+	if err != nil || n*mult > math.MaxUint32 {
+		return 0, false
+	}
+	return uint32(n * mult), true
 }
 
 // SelectedFit returns the currently selected fit or nil.
@@ -215,6 +354,22 @@ func (a *App) CycleFitFilter() {
 	a.ApplyFilters()
 }
 
+// ToggleTopByProvider switches between the full list and one champion per provider.
+func (a *App) ToggleTopByProvider() {
+	a.TopByProvider = !a.TopByProvider
+	a.ApplyFilters()
+}
+
+// cardLayoutWidth is the terminal width below which the column table no longer fits legibly, so
+// the model list is drawn as stacked cards and the system bar is shortened.
+const cardLayoutWidth = 60
+
+// UseCardLayout reports whether the terminal is too narrow for the column table. An unknown width
+// (before the first resize message) keeps the table.
+func (a *App) UseCardLayout() bool {
+	return a.Width > 0 && a.Width < cardLayoutWidth
+}
+
 func (a *App) EnterSearch() {
 	a.InputMode = InputModeSearch
 }
@@ -224,16 +379,50 @@ func (a *App) ExitSearch() {
 }
 
 func (a *App) SearchInput(r rune) {
-	runes := []rune(a.SearchQuery)
-	if a.CursorPosition > len(runes) {
-		a.CursorPosition = len(runes)
+	a.SearchInsert([]rune{r})
+}
+
+// SearchInsert inserts rs at the cursor (e.g. a pasted or IME-composed multi-rune sequence)
+// and moves the cursor past them. CursorPosition counts runes, not bytes.
+func (a *App) SearchInsert(rs []rune) {
+	if len(rs) == 0 {
+		return
 	}
-	runes = append(runes[:a.CursorPosition], append([]rune{r}, runes[a.CursorPosition:]...)...)
-	a.SearchQuery = string(runes)
-	a.CursorPosition++
+	runes := []rune(a.SearchQuery)
+	a.CursorPosition = max(0, min(a.CursorPosition, len(runes)))
+	out := make([]rune, 0, len(runes)+len(rs))
+	out = append(out, runes[:a.CursorPosition]...)
+	out = append(out, rs...)
+	out = append(out, runes[a.CursorPosition:]...)
+	a.SearchQuery = string(out)
+	a.CursorPosition += len(rs)
 	a.ApplyFilters()
 }
 
+// SearchCursorLeft moves the search cursor one rune left.
+func (a *App) SearchCursorLeft() {
+	if a.CursorPosition > 0 {
+		a.CursorPosition--
+	}
+}
+
+// SearchCursorRight moves the search cursor one rune right.
+func (a *App) SearchCursorRight() {
+	if a.CursorPosition < len([]rune(a.SearchQuery)) {
+		a.CursorPosition++
+	}
+}
+
+// SearchCursorHome moves the search cursor to the start of the query.
+func (a *App) SearchCursorHome() {
+	a.CursorPosition = 0
+}
+
+// SearchCursorEnd moves the search cursor past the last rune of the query.
+func (a *App) SearchCursorEnd() {
+	a.CursorPosition = len([]rune(a.SearchQuery))
+}
+
 func (a *App) SearchBackspace() {
 	runes := []rune(a.SearchQuery)
 	if a.CursorPosition <= 0 || a.CursorPosition > len(runes) {
@@ -261,10 +450,125 @@ func (a *App) ClearSearch() {
 	a.ApplyFilters()
 }
 
+// Back closes the system panel or detail view, or quits from the model list.
+func (a *App) Back() {
+	if a.ShowSystem {
+		a.ShowSystem = false
+	} else if a.ShowDetail {
+		a.ShowDetail = false
+	} else {
+		a.ShouldQuit = true
+	}
+}
+
 func (a *App) ToggleDetail() {
 	a.ShowDetail = !a.ShowDetail
 }
 
+// ToggleSystemPanel switches between the model view and the full system details panel.
+func (a *App) ToggleSystemPanel() {
+	a.ShowSystem = !a.ShowSystem
+}
+
+// ToggleMemoryView switches memory figures between percent-primary and GB-primary presentation.
+func (a *App) ToggleMemoryView() {
+	if a.MemoryView == MemoryViewPercent {
+		a.MemoryView = MemoryViewGB
+	} else {
+		a.MemoryView = MemoryViewPercent
+	}
+}
+
+// ProviderSort selects the order of the provider popup (and of Providers/SelectedProviders).
+type ProviderSort int
+
+const (
+	ProviderSortAlpha  ProviderSort = iota // alphabetical
+	ProviderSortCount                      // most models first, then alphabetical
+	ProviderSortPinned                     // pinned providers first in the given order, then alphabetical
+)
+
+// ParseProviderSort parses --sort-providers: "alpha" (or ""), "count", or a comma-separated list
+// of providers to pin to the top, which it returns as pinned.
+func ParseProviderSort(s string) (by ProviderSort, pinned []string, err error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "alpha":
+		return ProviderSortAlpha, nil, nil
+	case "count":
+		return ProviderSortCount, nil, nil
+	}
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			pinned = append(pinned, p)
+		}
+	}
+	if len(pinned) == 0 {
+		return ProviderSortAlpha, nil, fmt.Errorf("invalid --sort-providers %q (use alpha, count, or a comma-separated list of providers)", s)
+	}
+	return ProviderSortPinned, pinned, nil
+}
+
+// SortProviders reorders Providers; pinned (matched case-insensitively, unknown names ignored)
+// only matters for ProviderSortPinned. Each provider keeps its selection, and the popup cursor
+// stays on the provider it was on.
+func (a *App) SortProviders(by ProviderSort, pinned []string) {
+	selected := make(map[string]bool, len(a.Providers))
+	for i, p := range a.Providers {
+		selected[p] = i < len(a.SelectedProviders) && a.SelectedProviders[i]
+	}
+	cursor := ""
+	if a.ProviderCursor < len(a.Providers) {
+		cursor = a.Providers[a.ProviderCursor]
+	}
+
+	rank := make(map[string]int, len(a.Providers)) // lower sorts first
+	switch by {
+	case ProviderSortCount:
+		for _, f := range a.AllFits {
+			rank[f.Model.Provider]--
+		}
+	case ProviderSortPinned:
+		for _, p := range a.Providers {
+			rank[p] = len(pinned)
+			for j, want := range pinned {
+				if strings.EqualFold(p, want) {
+					rank[p] = j
+					break
+				}
+			}
+		}
+	}
+	sort.SliceStable(a.Providers, func(i, j int) bool {
+		pi, pj := a.Providers[i], a.Providers[j]
+		if rank[pi] != rank[pj] {
+			return rank[pi] < rank[pj]
+		}
+		return pi < pj
+	})
+
+	a.ProviderSort = by
+	for i, p := range a.Providers {
+		a.SelectedProviders[i] = selected[p]
+		if p == cursor {
+			a.ProviderCursor = i
+		}
+	}
+	a.indexProviders()
+	a.ApplyFilters()
+}
+
+// MoveProvider shifts the provider under the popup cursor up (delta < 0) or down, taking the cursor
+// along; the list then counts as a pinned order.
+func (a *App) MoveProvider(delta int) {
+	to := a.ProviderCursor + delta
+	if a.ProviderCursor >= len(a.Providers) || to < 0 || to >= len(a.Providers) {
+		return
+	}
+	pinned := slices.Clone(a.Providers)
+	pinned[a.ProviderCursor], pinned[to] = pinned[to], pinned[a.ProviderCursor]
+	a.SortProviders(ProviderSortPinned, pinned)
+}
+
 func (a *App) OpenProviderPopup() {
 	a.InputMode = InputModeProviderPopup
 }
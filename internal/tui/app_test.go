@@ -0,0 +1,341 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/shayne-snap/llmpole/internal/hardware"
+	"github.com/shayne-snap/llmpole/internal/models"
+	"github.com/shayne-snap/llmpole/internal/pole"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func testFits() []*pole.ModelFit {
+	return []*pole.ModelFit{
+		{Model: &models.LlmModel{Name: "dense-7b", Provider: "A", ParameterCount: "7B"}},
+		{Model: &models.LlmModel{Name: "moe-30b", Provider: "B", ParameterCount: "30B", IsMoE: true}},
+	}
+}
+
+func TestApplyFilters_MoEQualifier(t *testing.T) {
+	app := NewApp(&hardware.SystemSpecs{}, testFits())
+	app.SearchQuery = "is:moe"
+	app.ApplyFilters()
+	if len(app.FilteredFits) != 1 || app.AllFits[app.FilteredFits[0]].Model.Name != "moe-30b" {
+		t.Errorf("is:moe filtered = %v", app.FilteredFits)
+	}
+	app.SearchQuery = "is:dense 7b"
+	app.ApplyFilters()
+	if len(app.FilteredFits) != 1 || app.AllFits[app.FilteredFits[0]].Model.Name != "dense-7b" {
+		t.Errorf("is:dense 7b filtered = %v", app.FilteredFits)
+	}
+}
+
+func TestApplyFilters_ContextQualifier(t *testing.T) {
+	fits := testFits()
+	fits[0].Model.ContextLength = 8192
+	fits[1].Model.ContextLength = 32768
+	app := NewApp(&hardware.SystemSpecs{}, fits)
+	for _, tt := range []struct {
+		query string
+		want  []int
+	}{
+		{"ctx:32k", []int{1}},
+		{"ctx:8192", []int{0, 1}},
+		{"ctx:32769", nil},
+		{"ctx:8k is:dense", []int{0}},
+		{"ctx:lots", nil}, // not a number: searched for as text
+	} {
+		app.SearchQuery = tt.query
+		app.ApplyFilters()
+		if !slices.Equal(app.FilteredFits, tt.want) {
+			t.Errorf("%q filtered = %v, want %v", tt.query, app.FilteredFits, tt.want)
+		}
+	}
+}
+
+func largeFits(n int) []*pole.ModelFit {
+	fits := make([]*pole.ModelFit, n)
+	for i := range fits {
+		fits[i] = &pole.ModelFit{
+			Model:    &models.LlmModel{Name: fmt.Sprintf("org%d/model-%d", i%50, i), Provider: fmt.Sprintf("P%d", i%50), ParameterCount: "7B", UseCase: "chat"},
+			FitLevel: pole.FitLevel(i % 4),
+		}
+	}
+	return fits
+}
+
+func TestApplyFilters_SkipsUnchangedInputs(t *testing.T) {
+	app := NewApp(&hardware.SystemSpecs{}, largeFits(100))
+	runs := app.filterRuns
+	app.ApplyFilters()
+	app.MoveDown()
+	app.ApplyFilters()
+	if app.filterRuns != runs {
+		t.Errorf("ApplyFilters recomputed %d times with unchanged inputs", app.filterRuns-runs)
+	}
+	app.SearchInput('7')
+	if app.filterRuns != runs+1 {
+		t.Errorf("ApplyFilters after a search edit: %d runs, want %d", app.filterRuns, runs+1)
+	}
+	app.ProviderPopupToggle()
+	app.CycleFitFilter()
+	if app.filterRuns != runs+3 {
+		t.Errorf("ApplyFilters after provider and fit changes: %d runs, want %d", app.filterRuns, runs+3)
+	}
+	app.SearchQuery = "model-42"
+	app.ApplyFilters()
+	if len(app.FilteredFits) == 0 || app.AllFits[app.FilteredFits[0]].Model.Name != "org42/model-42" {
+		t.Errorf("direct SearchQuery edit not picked up: %v", app.FilteredFits)
+	}
+}
+
+func TestVisibleWindow(t *testing.T) {
+	tests := []struct{ selected, total, visible, start, end int }{
+		{0, 5, 10, 0, 5},
+		{0, 100, 10, 0, 10},
+		{42, 100, 10, 42, 52},
+		{95, 100, 10, 90, 100},
+		{3, 100, 0, 3, 4},
+	}
+	for _, tt := range tests {
+		if s, e := visibleWindow(tt.selected, tt.total, tt.visible); s != tt.start || e != tt.end {
+			t.Errorf("visibleWindow(%d, %d, %d) = %d, %d; want %d, %d", tt.selected, tt.total, tt.visible, s, e, tt.start, tt.end)
+		}
+	}
+}
+
+func BenchmarkApplyFilters_Search(b *testing.B) {
+	app := NewApp(&hardware.SystemSpecs{}, largeFits(5000))
+	queries := []string{"model-4", "model-42", "p7"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		app.SearchQuery = queries[i%len(queries)]
+		app.ApplyFilters()
+	}
+}
+
+func BenchmarkRenderTable(b *testing.B) {
+	app := NewApp(&hardware.SystemSpecs{}, largeFits(5000))
+	app.SelectedRow = 2500
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		app.ApplyFilters()
+		_ = renderTable(app, 120, 30)
+	}
+}
+
+func TestSearchInsert_MultiRunePaste(t *testing.T) {
+	app := NewApp(&hardware.SystemSpecs{}, testFits())
+	m := &model{app: app}
+	app.EnterSearch()
+	m.handleSearch(keyMsg("qw"))
+	m.handleSearch(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("通义🚀"), Paste: true})
+	if app.SearchQuery != "qw通义🚀" || app.CursorPosition != 5 {
+		t.Fatalf("after paste: query %q, cursor %d; want %q, 5", app.SearchQuery, app.CursorPosition, "qw通义🚀")
+	}
+	if got := searchWithCursor(app.SearchQuery, app.CursorPosition); got != "qw通义🚀│" && got != "qw通义🚀|" {
+		t.Errorf("rendered cursor = %q, want it after the last rune", got)
+	}
+}
+
+func TestSearchCursor_MultibyteMovement(t *testing.T) {
+	app := NewApp(&hardware.SystemSpecs{}, testFits())
+	m := &model{app: app}
+	app.EnterSearch()
+	app.SearchInsert([]rune("a通🚀b"))
+	m.handleSearch(tea.KeyMsg{Type: tea.KeyLeft})
+	m.handleSearch(tea.KeyMsg{Type: tea.KeyLeft})
+	if app.CursorPosition != 2 {
+		t.Fatalf("after two lefts: cursor %d, want 2 (before the rocket)", app.CursorPosition)
+	}
+	app.SearchBackspace() // removes 通
+	if app.SearchQuery != "a🚀b" || app.CursorPosition != 1 {
+		t.Errorf("after backspace: query %q, cursor %d; want %q, 1", app.SearchQuery, app.CursorPosition, "a🚀b")
+	}
+	app.SearchDelete() // removes 🚀
+	if app.SearchQuery != "ab" {
+		t.Errorf("after delete: query %q, want %q", app.SearchQuery, "ab")
+	}
+	m.handleSearch(tea.KeyMsg{Type: tea.KeyHome})
+	app.SearchInput('é')
+	m.handleSearch(tea.KeyMsg{Type: tea.KeyEnd})
+	m.handleSearch(tea.KeyMsg{Type: tea.KeyRight})
+	if app.SearchQuery != "éab" || app.CursorPosition != 3 {
+		t.Errorf("after home/insert/end/right: query %q, cursor %d; want %q, 3", app.SearchQuery, app.CursorPosition, "éab")
+	}
+	if got := searchWithCursor("a🚀b", 1); got != "a│🚀b" && got != "a|🚀b" {
+		t.Errorf("searchWithCursor(a🚀b, 1) = %q, want the cursor before the rocket", got)
+	}
+}
+
+func TestToggleTopByProvider(t *testing.T) {
+	fits := []*pole.ModelFit{
+		{Model: &models.LlmModel{Name: "a-small", Provider: "A"}, Score: 60, FitLevel: pole.FitPerfect},
+		{Model: &models.LlmModel{Name: "a-big", Provider: "A"}, Score: 90, FitLevel: pole.FitTooTight},
+		{Model: &models.LlmModel{Name: "a-mid", Provider: "A"}, Score: 75, FitLevel: pole.FitGood},
+		{Model: &models.LlmModel{Name: "b-one", Provider: "B"}, Score: 50, FitLevel: pole.FitMarginal},
+		{Model: &models.LlmModel{Name: "b-two", Provider: "B"}, Score: 70, FitLevel: pole.FitGood},
+		{Model: &models.LlmModel{Name: "c-huge", Provider: "C"}, Score: 95, FitLevel: pole.FitTooTight},
+	}
+	app := NewApp(&hardware.SystemSpecs{}, fits)
+	m := &model{app: app}
+	m.handleNormal(keyMsg("t"))
+	var got []string
+	for _, i := range app.FilteredFits {
+		got = append(got, app.AllFits[i].Model.Name)
+	}
+	if fmt.Sprint(got) != "[a-mid b-two]" {
+		t.Errorf("top by provider = %v, want [a-mid b-two] (one runnable champion per provider)", got)
+	}
+	app.SearchQuery = "b-one"
+	app.ApplyFilters()
+	if len(app.FilteredFits) != 1 || app.AllFits[app.FilteredFits[0]].Model.Name != "b-one" {
+		t.Errorf("collapsed view should pick champions among search matches, got %v", app.FilteredFits)
+	}
+	app.ClearSearch()
+	m.handleNormal(keyMsg("t"))
+	if len(app.FilteredFits) != len(fits) {
+		t.Errorf("t again: %d models, want all %d", len(app.FilteredFits), len(fits))
+	}
+}
+
+func TestSortProviders(t *testing.T) {
+	var fits []*pole.ModelFit
+	for _, p := range []string{"Meta", "Qwen", "Qwen", "Qwen", "Alibaba", "Mistral", "Mistral"} {
+		fits = append(fits, &pole.ModelFit{Model: &models.LlmModel{Name: p + "-model", Provider: p}})
+	}
+	tests := []struct {
+		by     ProviderSort
+		pinned []string
+		want   []string
+	}{
+		{ProviderSortAlpha, nil, []string{"Alibaba", "Meta", "Mistral", "Qwen"}},
+		{ProviderSortCount, nil, []string{"Qwen", "Mistral", "Alibaba", "Meta"}},
+		{ProviderSortPinned, []string{"meta", "Unknown", "Mistral"}, []string{"Meta", "Mistral", "Alibaba", "Qwen"}},
+	}
+	for _, tt := range tests {
+		app := NewApp(&hardware.SystemSpecs{}, fits)
+		app.ProviderCursor = 1 // Meta
+		app.ProviderPopupToggle()
+		app.SortProviders(tt.by, tt.pinned)
+		if !slices.Equal(app.Providers, tt.want) {
+			t.Errorf("sort %d: Providers = %v, want %v", tt.by, app.Providers, tt.want)
+		}
+		for i, p := range app.Providers {
+			if app.SelectedProviders[i] != (p != "Meta") {
+				t.Errorf("sort %d: %s selected = %v after reorder", tt.by, p, app.SelectedProviders[i])
+			}
+		}
+		if app.Providers[app.ProviderCursor] != "Meta" {
+			t.Errorf("sort %d: cursor on %s, want Meta", tt.by, app.Providers[app.ProviderCursor])
+		}
+		// Toggling at the cursor must still hit Meta, and filtering must follow the names.
+		app.ProviderPopupToggle()
+		app.ProviderCursor = slices.Index(app.Providers, "Qwen")
+		app.ProviderPopupToggle()
+		for _, i := range app.FilteredFits {
+			if app.AllFits[i].Model.Provider == "Qwen" {
+				t.Errorf("sort %d: Qwen still listed after deselecting it", tt.by)
+			}
+		}
+		if len(app.FilteredFits) != 4 {
+			t.Errorf("sort %d: %d fits listed, want 4 (all but Qwen's)", tt.by, len(app.FilteredFits))
+		}
+	}
+}
+
+func TestMoveProvider(t *testing.T) {
+	app := NewApp(&hardware.SystemSpecs{}, testFits()) // providers A, B
+	app.ProviderCursor = 1
+	app.ProviderPopupToggle() // deselect B
+	app.MoveProvider(-1)
+	if !slices.Equal(app.Providers, []string{"B", "A"}) || app.ProviderSort != ProviderSortPinned {
+		t.Fatalf("Providers = %v (sort %d), want [B A] pinned", app.Providers, app.ProviderSort)
+	}
+	if app.ProviderCursor != 0 || app.SelectedProviders[0] || !app.SelectedProviders[1] {
+		t.Errorf("cursor %d, selected %v; want cursor on B, B deselected", app.ProviderCursor, app.SelectedProviders)
+	}
+	app.MoveProvider(-1) // already first
+	if !slices.Equal(app.Providers, []string{"B", "A"}) {
+		t.Errorf("moving past the top changed the order: %v", app.Providers)
+	}
+}
+
+func TestParseProviderSort(t *testing.T) {
+	if by, _, err := ParseProviderSort("count"); err != nil || by != ProviderSortCount {
+		t.Errorf("count = %d, %v", by, err)
+	}
+	if by, pinned, err := ParseProviderSort("Meta, Qwen"); err != nil || by != ProviderSortPinned || !slices.Equal(pinned, []string{"Meta", "Qwen"}) {
+		t.Errorf("pinned = %d, %v, %v", by, pinned, err)
+	}
+	if _, _, err := ParseProviderSort(" , "); err == nil {
+		t.Error("an empty pin list should be an error")
+	}
+}
+
+func TestPaletteMatches_Fuzzy(t *testing.T) {
+	app := NewApp(&hardware.SystemSpecs{}, testFits())
+	if got := app.PaletteMatches(); len(got) != len(actions) {
+		t.Errorf("empty query lists %d actions, want all %d", len(got), len(actions))
+	}
+	tests := []struct {
+		query string
+		first string
+	}{
+		{"sysp", "Toggle system panel"},
+		{"MEM", "Toggle memory view (% / GB)"},
+		{"sort count", "Sort providers by model count"},
+		{"last", "Go to last model"},
+	}
+	for _, tt := range tests {
+		app.PaletteQuery = tt.query
+		got := app.PaletteMatches()
+		if len(got) == 0 || actions[got[0]].Name != tt.first {
+			var names []string
+			for _, i := range got {
+				names = append(names, actions[i].Name)
+			}
+			t.Errorf("query %q: matches %q, want %q first", tt.query, names, tt.first)
+		}
+	}
+	app.PaletteQuery = "zzz"
+	if got := app.PaletteMatches(); len(got) != 0 {
+		t.Errorf("query zzz matched %d actions, want none", len(got))
+	}
+}
+
+func TestPalette_RunsSelectedAction(t *testing.T) {
+	app := NewApp(&hardware.SystemSpecs{}, testFits())
+	m := &model{app: app}
+	m.handleNormal(keyMsg(":"))
+	if app.InputMode != InputModePalette {
+		t.Fatal(": should open the command palette")
+	}
+	for _, r := range "top prov" {
+		m.handlePalette(keyMsg(string(r)))
+	}
+	m.handlePalette(keyMsg("enter"))
+	if !app.TopByProvider || app.InputMode != InputModeNormal {
+		t.Errorf("running %q: TopByProvider = %v, mode = %d; want the toggle run and the palette closed", "top prov", app.TopByProvider, app.InputMode)
+	}
+
+	// Moving the cursor picks a later match; every key-bound action is reachable by name.
+	app.OpenPalette()
+	app.PaletteInsert([]rune("toggle"))
+	app.PaletteDown()
+	want := actions[app.PaletteMatches()[1]].Name
+	app.RunPaletteSelection()
+	if want != "Toggle system panel" || !app.ShowSystem {
+		t.Errorf("second match %q ran; ShowSystem = %v", want, app.ShowSystem)
+	}
+	for _, act := range actions {
+		for _, k := range act.Keys {
+			if got := actionForKey(k); got == nil || got.Name != act.Name {
+				t.Errorf("key %q is not bound to %q", k, act.Name)
+			}
+		}
+	}
+}
@@ -1,27 +1,76 @@
 package tui
 
 import (
+	"context"
+	"slices"
+	"time"
+
 	"github.com/shayne-snap/llmpole/internal/hardware"
 	"github.com/shayne-snap/llmpole/internal/pole"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// liveInterval is how often the system bar refreshes live GPU utilization and temperature.
+const liveInterval = 2 * time.Second
+
+// Options tunes how Run starts the TUI. The zero value starts the full list, providers sorted
+// alphabetically, with no live GPU sampling and a theme picked for the terminal.
+type Options struct {
+	// Live samples GPU utilization and temperature in the background for the system bar; leave
+	// it off when specs describe another machine.
+	Live bool
+	// TopByProvider starts the list collapsed to the best runnable model per provider (t expands it).
+	TopByProvider bool
+	// HideUnrunnable starts on the Runnable fit filter (f cycles it).
+	HideUnrunnable bool
+	// ProviderSort and Pinned order the provider popup (see App.SortProviders).
+	ProviderSort ProviderSort
+	Pinned       []string
+	// Theme styles the view; nil picks one for the terminal background (see DetectTheme).
+	Theme *Theme
+}
+
 // Run starts the TUI. specs and allFits must already be loaded (e.g. from main).
-func Run(specs *hardware.SystemSpecs, allFits []*pole.ModelFit) error {
+func Run(specs *hardware.SystemSpecs, allFits []*pole.ModelFit, opts Options) error {
 	app := NewApp(specs, allFits)
-	m := &model{app: app}
+	app.Theme = opts.Theme
+	if app.Theme == nil {
+		app.Theme = DetectTheme()
+	}
+	if opts.ProviderSort != ProviderSortAlpha {
+		app.SortProviders(opts.ProviderSort, opts.Pinned)
+	}
+	if opts.TopByProvider {
+		app.ToggleTopByProvider()
+	}
+	if opts.HideUnrunnable {
+		app.FitFilter = FitFilterRunnable
+		app.ApplyFilters()
+	}
+	m := &model{app: app, live: opts.Live}
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	_, err := p.Run()
 	return err
 }
 
 type model struct {
-	app *App
+	app  *App
+	live bool
+}
+
+// liveStatsMsg carries one background GPU sample.
+type liveStatsMsg []hardware.GpuLiveStats
+
+func sampleLive() tea.Msg {
+	return liveStatsMsg(hardware.SampleGPUStats(context.Background()))
 }
 
 func (m *model) Init() tea.Cmd {
-	return nil
+	if !m.live {
+		return nil
+	}
+	return sampleLive
 }
 
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -30,6 +79,12 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.app.Width = msg.Width
 		m.app.Height = msg.Height
 		return m, nil
+	case liveStatsMsg:
+		m.app.Live = msg
+		if len(msg) == 0 {
+			return m, nil // no nvidia-smi/rocm-smi: stop sampling
+		}
+		return m, tea.Tick(liveInterval, func(time.Time) tea.Msg { return sampleLive() })
 	case tea.KeyMsg:
 		switch m.app.InputMode {
 		case InputModeNormal:
@@ -38,6 +93,8 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.handleSearch(msg)
 		case InputModeProviderPopup:
 			m.handleProviderPopup(msg)
+		case InputModePalette:
+			m.handlePalette(msg)
 		}
 		if m.app.ShouldQuit {
 			return m, tea.Quit
@@ -49,33 +106,29 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m *model) handleNormal(msg tea.KeyMsg) {
 	s := msg.String()
-	switch s {
-	case "q", "esc":
-		if m.app.ShowDetail {
-			m.app.ShowDetail = false
-		} else {
-			m.app.ShouldQuit = true
-		}
-	case "up", "k":
-		m.app.MoveUp()
-	case "down", "j":
-		m.app.MoveDown()
-	case "pgup":
-		m.app.PageUp()
-	case "pgdown":
-		m.app.PageDown()
-	case "home", "g":
-		m.app.Home()
-	case "end", "G":
-		m.app.End()
-	case "/":
-		m.app.EnterSearch()
-	case "f":
-		m.app.CycleFitFilter()
-	case "p":
-		m.app.OpenProviderPopup()
+	if slices.Contains(paletteKeys, s) {
+		m.app.OpenPalette()
+	} else if act := actionForKey(s); act != nil {
+		act.Run(m.app)
+	}
+}
+
+func (m *model) handlePalette(msg tea.KeyMsg) {
+	switch msg.String() {
+	case "esc", "ctrl+p":
+		m.app.ClosePalette()
 	case "enter":
-		m.app.ToggleDetail()
+		m.app.RunPaletteSelection()
+	case "up", "ctrl+k":
+		m.app.PaletteUp()
+	case "down", "ctrl+j":
+		m.app.PaletteDown()
+	case "backspace":
+		m.app.PaletteBackspace()
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			m.app.PaletteInsert(msg.Runes)
+		}
 	}
 }
 
@@ -90,13 +143,21 @@ func (m *model) handleSearch(msg tea.KeyMsg) {
 		m.app.SearchDelete()
 	case "ctrl+u":
 		m.app.ClearSearch()
+	case "left":
+		m.app.SearchCursorLeft()
+	case "right":
+		m.app.SearchCursorRight()
+	case "home", "ctrl+a":
+		m.app.SearchCursorHome()
+	case "end", "ctrl+e":
+		m.app.SearchCursorEnd()
 	case "up", "k":
 		m.app.MoveUp()
 	case "down", "j":
 		m.app.MoveDown()
 	default:
-		if len(msg.Runes) == 1 {
-			m.app.SearchInput(msg.Runes[0])
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			m.app.SearchInsert(msg.Runes)
 		}
 	}
 }
@@ -114,6 +175,10 @@ func (m *model) handleProviderPopup(msg tea.KeyMsg) {
 		m.app.ProviderPopupToggle()
 	case "a":
 		m.app.ProviderPopupSelectAll()
+	case "K", "shift+up":
+		m.app.MoveProvider(-1)
+	case "J", "shift+down":
+		m.app.MoveProvider(1)
 	}
 }
 
@@ -0,0 +1,200 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/shayne-snap/llmpole/internal/pole"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ThemeEnv names the environment variable read when --theme is not given.
+const ThemeEnv = "LLMPOLE_THEME"
+
+// Theme bundles every style the TUI draws with. Styles are named for their role, not their
+// color, so a theme can map them to whatever reads well on its background.
+type Theme struct {
+	Name        string
+	Title       lipgloss.Style         // the llmpole title in the system bar
+	Dim         lipgloss.Style         // labels, keys, and secondary text
+	Normal      lipgloss.Style         // values and body text
+	Accent      lipgloss.Style         // section titles, headers, RAM, and MoE offload
+	Good        lipgloss.Style         // Perfect fits, GPU runs, high scores
+	Warn        lipgloss.Style         // Good fits, GPU names, popups, mid scores
+	Notice      lipgloss.Style         // Marginal fits
+	Bad         lipgloss.Style         // Too Tight fits, low scores, full memory
+	Status      lipgloss.Style         // the mode badge in the status bar
+	Cursor      lipgloss.Style         // the selected table row
+	Border      lipgloss.TerminalColor // panel borders
+	PopupBorder lipgloss.TerminalColor // provider popup and command palette borders
+}
+
+// DarkTheme uses the bright ANSI colors, for dark terminal backgrounds. It is the default.
+func DarkTheme() *Theme {
+	fg := func(c string) lipgloss.Style { return lipgloss.NewStyle().Foreground(lipgloss.Color(c)) }
+	return &Theme{
+		Name:        "dark",
+		Title:       fg("10").Bold(true),
+		Dim:         fg("8"),
+		Normal:      fg("15"),
+		Accent:      fg("14"),
+		Good:        fg("10"),
+		Warn:        fg("11"),
+		Notice:      fg("13"),
+		Bad:         fg("9"),
+		Status:      lipgloss.NewStyle().Background(lipgloss.Color("10")).Foreground(lipgloss.Color("0")).Bold(true),
+		Cursor:      lipgloss.NewStyle().Background(lipgloss.Color("8")).Bold(true),
+		Border:      lipgloss.Color("8"),
+		PopupBorder: lipgloss.Color("11"),
+	}
+}
+
+// LightTheme uses darker 256-color shades that keep their contrast on light backgrounds, where
+// bright white text and yellow vanish.
+func LightTheme() *Theme {
+	fg := func(c string) lipgloss.Style { return lipgloss.NewStyle().Foreground(lipgloss.Color(c)) }
+	return &Theme{
+		Name:        "light",
+		Title:       fg("28").Bold(true),
+		Dim:         fg("244"),
+		Normal:      fg("235"),
+		Accent:      fg("31"),
+		Good:        fg("28"),
+		Warn:        fg("130"),
+		Notice:      fg("127"),
+		Bad:         fg("160"),
+		Status:      lipgloss.NewStyle().Background(lipgloss.Color("28")).Foreground(lipgloss.Color("231")).Bold(true),
+		Cursor:      lipgloss.NewStyle().Background(lipgloss.Color("253")).Bold(true),
+		Border:      lipgloss.Color("250"),
+		PopupBorder: lipgloss.Color("130"),
+	}
+}
+
+// MonoTheme draws without color, using bold, faint, and reverse video to set text apart.
+func MonoTheme() *Theme {
+	plain := lipgloss.NewStyle()
+	return &Theme{
+		Name:        "mono",
+		Title:       plain.Bold(true),
+		Dim:         plain.Faint(true),
+		Normal:      plain,
+		Accent:      plain.Bold(true),
+		Good:        plain,
+		Warn:        plain.Underline(true),
+		Notice:      plain.Italic(true),
+		Bad:         plain.Bold(true),
+		Status:      plain.Reverse(true).Bold(true),
+		Cursor:      plain.Reverse(true),
+		Border:      lipgloss.NoColor{},
+		PopupBorder: lipgloss.NoColor{},
+	}
+}
+
+// themes maps --theme names to their constructors.
+var themes = map[string]func() *Theme{
+	"dark":  DarkTheme,
+	"light": LightTheme,
+	"mono":  MonoTheme,
+}
+
+// ParseTheme parses --theme: dark, light, or mono. auto (or "") returns nil, for Run to pick
+// dark or light from the terminal background (see DetectTheme).
+func ParseTheme(s string) (*Theme, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "" || name == "auto" {
+		return nil, nil
+	}
+	if theme, ok := themes[name]; ok {
+		return theme(), nil
+	}
+	return nil, fmt.Errorf("invalid theme %q (use dark, light, mono, or auto)", s)
+}
+
+// DetectTheme picks the light theme on a light terminal background and dark otherwise. It
+// trusts COLORFGBG when the terminal sets it, else asks the terminal for its background.
+func DetectTheme() *Theme {
+	if dark, ok := colorFGBGIsDark(os.Getenv("COLORFGBG")); ok {
+		if dark {
+			return DarkTheme()
+		}
+		return LightTheme()
+	}
+	if lipgloss.HasDarkBackground() {
+		return DarkTheme()
+	}
+	return LightTheme()
+}
+
+// colorFGBGIsDark reads a COLORFGBG value such as "15;0" or "0;default;15": the last field is
+// the background's ANSI color, where 0–6 and 8 are dark. ok is false when it names no color.
+func colorFGBGIsDark(v string) (dark, ok bool) {
+	fields := strings.Split(v, ";")
+	bg, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || bg < 0 || bg > 15 {
+		return false, false
+	}
+	return bg <= 6 || bg == 8, true
+}
+
+// theme returns the app's theme, dark when none was set.
+func (a *App) theme() *Theme {
+	if a.Theme == nil {
+		return DarkTheme()
+	}
+	return a.Theme
+}
+
+// panel is the bordered box every TUI panel is drawn in.
+func (th *Theme) panel() lipgloss.Style {
+	return lipgloss.NewStyle().Border(border()).BorderForeground(th.Border).Padding(0, 1)
+}
+
+// popup is the bordered box of the provider popup and the command palette.
+func (th *Theme) popup() lipgloss.Style {
+	return lipgloss.NewStyle().Border(border()).BorderForeground(th.PopupBorder).Padding(0, 1)
+}
+
+func (th *Theme) fitColor(level pole.FitLevel) lipgloss.Style {
+	switch level {
+	case pole.FitPerfect:
+		return th.Good
+	case pole.FitGood:
+		return th.Warn
+	case pole.FitMarginal:
+		return th.Notice
+	case pole.FitTooTight:
+		return th.Bad
+	default:
+		return th.Normal
+	}
+}
+
+func (th *Theme) runModeColor(mode pole.RunMode) lipgloss.Style {
+	switch mode {
+	case pole.RunModeGpu:
+		return th.Good
+	case pole.RunModeMoeOffload:
+		return th.Accent
+	case pole.RunModeCpuOffload:
+		return th.Warn
+	case pole.RunModeCpuOnly:
+		return th.Dim
+	default:
+		return th.Normal
+	}
+}
+
+// scoreColor colors an overall score: good from 70, warning from 50, bad below.
+func (th *Theme) scoreColor(score float64) lipgloss.Style {
+	switch {
+	case score >= 70:
+		return th.Good
+	case score >= 50:
+		return th.Warn
+	default:
+		return th.Bad
+	}
+}
@@ -2,39 +2,73 @@ package tui
 
 import (
 	"fmt"
+	"math"
 	"strings"
 
+	"github.com/shayne-snap/llmpole/internal/display"
 	"github.com/shayne-snap/llmpole/internal/hardware"
 	"github.com/shayne-snap/llmpole/internal/pole"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
-var (
-	styleTitle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10"))
-	styleBorder  = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
-	styleDim     = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
-	styleNormal  = lipgloss.NewStyle().Foreground(lipgloss.Color("15"))
-	styleCyan    = lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
-	styleYellow  = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
-	styleGreen   = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
-	styleMagenta = lipgloss.NewStyle().Foreground(lipgloss.Color("13"))
-	styleRed     = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
-	styleStatus  = lipgloss.NewStyle().Background(lipgloss.Color("10")).Foreground(lipgloss.Color("0")).Bold(true)
+// asciiBorder replaces the rounded border on terminals that cannot render box-drawing glyphs.
+var asciiBorder = lipgloss.Border{
+	Top: "-", Bottom: "-", Left: "|", Right: "|",
+	TopLeft: "+", TopRight: "+", BottomLeft: "+", BottomRight: "+",
+}
+
+// border returns the panel border for the current charset mode.
+func border() lipgloss.Border {
+	if display.ASCII() {
+		return asciiBorder
+	}
+	return lipgloss.RoundedBorder()
+}
+
+// glyph returns unicode, or ascii when ASCII mode is on.
+func glyph(unicode, ascii string) string {
+	if display.ASCII() {
+		return ascii
+	}
+	return unicode
+}
+
+// sectionTitle renders a detail-view heading like "  ── Notes ──".
+func sectionTitle(title string) string {
+	rule := glyph("──", "--")
+	return "  " + rule + " " + title + " " + rule
+}
+
+// Bounds on the terminal size the view is laid out for. Multiplexers can briefly report 0x0,
+// 1x1, or bogus huge sizes; rendering for at least the minimum keeps the layout math positive,
+// and the maximum keeps a bogus size from building enormous frames.
+const (
+	minRenderWidth, minRenderHeight = 20, 10
+	maxRenderWidth, maxRenderHeight = 1000, 500
 )
 
-// Render returns the full TUI view for the app.
-func Render(app *App) string {
-	w := app.Width
-	if w <= 0 {
-		w = 80
+// clampSize returns the size to lay the view out for: 80x24 when a dimension is unknown (<= 0),
+// else the reported size within the min/max render bounds.
+func clampSize(width, height int) (int, int) {
+	if width <= 0 {
+		width = 80
 	}
-	h := app.Height
-	if h <= 0 {
-		h = 24
+	if height <= 0 {
+		height = 24
 	}
+	return min(max(width, minRenderWidth), maxRenderWidth), min(max(height, minRenderHeight), maxRenderHeight)
+}
 
+// Render returns the full TUI view for the app.
+func Render(app *App) string {
+	w, h := clampSize(app.Width, app.Height)
+
+	cards := app.UseCardLayout()
 	sysBar := renderSystemBar(app)
+	if cards {
+		sysBar = renderCompactSystemBar(app, w)
+	}
 	searchBar := renderSearchAndFilters(app)
 	mainArea := 3 + 3
 	statusHeight := 1
@@ -44,24 +78,34 @@ func Render(app *App) string {
 	}
 
 	var main string
-	if app.ShowDetail {
+	if app.ShowSystem {
+		main = renderSystemPanel(app, w, mainHeight)
+	} else if app.ShowDetail {
 		main = renderDetail(app, w, mainHeight)
+	} else if cards {
+		main = renderCards(app, w, mainHeight)
 	} else {
 		main = renderTable(app, w, mainHeight)
 	}
 	statusBar := renderStatusBar(app)
 
 	body := lipgloss.JoinVertical(lipgloss.Left, sysBar, searchBar, main, statusBar)
-	if app.InputMode == InputModeProviderPopup {
-		popup := renderProviderPopup(app, w, h)
+	var popup string
+	switch app.InputMode {
+	case InputModeProviderPopup:
+		popup = renderProviderPopup(app, w, h)
+	case InputModePalette:
+		popup = renderPalette(app, w, h)
+	}
+	if popup != "" {
 		bodyLines := strings.Split(body, "\n")
 		popupLines := strings.Split(popup, "\n")
 		if len(popupLines) > 0 && len(bodyLines) >= len(popupLines) {
 			startRow := (len(bodyLines) - len(popupLines)) / 2
 			popupW := 0
 			for _, l := range popupLines {
-				if len(l) > popupW {
-					popupW = len(l)
+				if lw := lipgloss.Width(l); lw > popupW {
+					popupW = lw
 				}
 			}
 			padLeft := (w - popupW) / 2
@@ -81,6 +125,7 @@ func Render(app *App) string {
 }
 
 func renderSystemBar(app *App) string {
+	th := app.theme()
 	specs := app.Specs
 	gpuInfo := "GPU: none (" + specs.Backend.String() + ")"
 	if len(specs.Gpus) > 0 {
@@ -92,13 +137,13 @@ func renderSystemBar(app *App) string {
 		}
 		var primaryStr string
 		if primary.UnifiedMemory {
-			primaryStr = fmt.Sprintf("%s (%.1f GB shared, %s)", primary.Name, vram, backend)
+			primaryStr = fmt.Sprintf("%s (%s GB shared, %s)", primary.Name, display.Num(vram, 1), backend)
 		} else {
 			if vram > 0 {
 				if primary.Count > 1 {
-					primaryStr = fmt.Sprintf("%s x%d (%.1f GB, %s)", primary.Name, primary.Count, vram, backend)
+					primaryStr = fmt.Sprintf("%s x%d (%s GB, %s)", primary.Name, primary.Count, display.Num(vram, 1), backend)
 				} else {
-					primaryStr = fmt.Sprintf("%s (%.1f GB, %s)", primary.Name, vram, backend)
+					primaryStr = fmt.Sprintf("%s (%s GB, %s)", primary.Name, display.Num(vram, 1), backend)
 				}
 			} else {
 				primaryStr = fmt.Sprintf("%s (shared, %s)", primary.Name, backend)
@@ -115,39 +160,63 @@ func renderSystemBar(app *App) string {
 	if hardware.IsRunningInWSL() {
 		wslSuffix = " (WSL)"
 	}
-	ramStr := fmt.Sprintf("%.1f GB avail / %.1f GB total%s", specs.AvailableRAMGB, specs.TotalRAMGB, wslSuffix)
-	line := styleDim.Render(" CPU: ") +
-		styleNormal.Render(fmt.Sprintf("%s (%d cores)", specs.CPUName, specs.TotalCPUCores)) +
-		styleDim.Render("  │  ") +
-		styleDim.Render("RAM: ") +
-		styleCyan.Render(ramStr) +
-		styleDim.Render("  │  ") +
-		styleYellow.Render(gpuInfo)
-	block := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("8")).
-		Padding(0, 1)
-	title := styleTitle.Render(" llmpole ")
+	ramStr := fmt.Sprintf("%s GB avail / %s GB total%s", display.Num(specs.AvailableRAMGB, 1), display.Num(specs.TotalRAMGB, 1), wslSuffix)
+	line := th.Dim.Render(" CPU: ") +
+		th.Normal.Render(fmt.Sprintf("%s (%d cores)", specs.CPUName, specs.TotalCPUCores)) +
+		th.Dim.Render(glyph("  │  ", "  |  ")) +
+		th.Dim.Render("RAM: ") +
+		th.Accent.Render(ramStr) +
+		th.Dim.Render(glyph("  │  ", "  |  ")) +
+		th.Warn.Render(gpuInfo)
+	if len(app.Live) > 0 {
+		line += th.Dim.Render("  Load: ") + th.Good.Render(display.GPULoad(app.Live))
+	}
+	block := th.panel()
+	title := th.Title.Render(" llmpole ")
 	return block.Render(title + " " + line)
 }
 
+// renderCompactSystemBar is the system bar for narrow terminals: RAM and the primary GPU's memory
+// only, truncated to width.
+func renderCompactSystemBar(app *App, width int) string {
+	th := app.theme()
+	specs := app.Specs
+	line := fmt.Sprintf("RAM %s/%s GB", display.Num(specs.AvailableRAMGB, 1), display.Num(specs.TotalRAMGB, 1))
+	if len(specs.Gpus) > 0 && specs.Gpus[0].VRAMGB != nil {
+		line += fmt.Sprintf("  GPU %s GB %s", display.Num(*specs.Gpus[0].VRAMGB, 1), specs.Gpus[0].Backend.String())
+	} else {
+		line += "  " + specs.Backend.String()
+	}
+	block := th.panel()
+	title := "llmpole "
+	line = display.Truncate(line, width-4-len(title))
+	return block.Render(th.Title.Render(title) + th.Accent.Render(line))
+}
+
+// searchWithCursor returns query with a cursor mark inserted before rune pos (clamped to the query).
+func searchWithCursor(query string, pos int) string {
+	runes := []rune(query)
+	pos = max(0, min(pos, len(runes)))
+	return string(runes[:pos]) + glyph("│", "|") + string(runes[pos:])
+}
+
 func renderSearchAndFilters(app *App) string {
+	th := app.theme()
 	searchTitle := " Search "
 	if app.InputMode == InputModeSearch {
-		searchTitle = styleYellow.Render(searchTitle)
+		searchTitle = th.Warn.Render(searchTitle)
 	} else {
-		searchTitle = styleDim.Render(searchTitle)
+		searchTitle = th.Dim.Render(searchTitle)
 	}
 	searchContent := "Press / to search..."
-	if app.InputMode == InputModeSearch || app.SearchQuery != "" {
-		searchContent = styleNormal.Render(app.SearchQuery)
+	if app.InputMode == InputModeSearch {
+		searchContent = th.Normal.Render(searchWithCursor(app.SearchQuery, app.CursorPosition))
+	} else if app.SearchQuery != "" {
+		searchContent = th.Normal.Render(app.SearchQuery)
 	} else {
-		searchContent = styleDim.Render(searchContent)
+		searchContent = th.Dim.Render(searchContent)
 	}
-	searchBlock := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("8")).
-		Padding(0, 1)
+	searchBlock := th.panel()
 	searchBox := searchBlock.Render(searchTitle + " " + searchContent)
 
 	activeCount := 0
@@ -161,136 +230,173 @@ func renderSearchAndFilters(app *App) string {
 	if activeCount != totalCount {
 		providerText = fmt.Sprintf("%d/%d", activeCount, totalCount)
 	}
-	providerStyle := styleGreen
+	providerStyle := th.Good
 	if activeCount == 0 {
-		providerStyle = styleRed
+		providerStyle = th.Bad
 	} else if activeCount < totalCount {
-		providerStyle = styleYellow
+		providerStyle = th.Warn
 	}
-	providerBlock := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("8")).
-		Padding(0, 1).
-		Width(22)
-	providerBox := providerBlock.Render(styleDim.Render(" Providers (p) ") + " " + providerStyle.Render(providerText))
+	providerBlock := th.panel().Width(22)
+	providerBox := providerBlock.Render(th.Dim.Render(" Providers (p) ") + " " + providerStyle.Render(providerText))
 
 	fitLabel := app.FitFilter.Label()
-	fitStyle := styleNormal
+	fitStyle := th.Normal
 	switch app.FitFilter {
 	case FitFilterRunnable, FitFilterPerfect:
-		fitStyle = styleGreen
+		fitStyle = th.Good
 	case FitFilterGood:
-		fitStyle = styleYellow
+		fitStyle = th.Warn
 	case FitFilterMarginal:
-		fitStyle = styleMagenta
+		fitStyle = th.Notice
 	}
-	fitBlock := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("8")).
-		Padding(0, 1).
-		Width(18)
-	fitBox := fitBlock.Render(styleDim.Render(" Fit [f] ") + " " + fitStyle.Render(fitLabel))
+	fitBlock := th.panel().Width(18)
+	fitBox := fitBlock.Render(th.Dim.Render(" Fit [f] ") + " " + fitStyle.Render(fitLabel))
 
+	if app.UseCardLayout() {
+		// One box: the search line, then the provider and fit filters, each cut to the terminal.
+		width, _ := clampSize(app.Width, app.Height)
+		inner := width - 4
+		filters := display.Truncate(fmt.Sprintf("Providers %s  Fit %s", providerText, fitLabel), inner)
+		search := th.Dim.Render(display.Truncate("/ to search", inner))
+		if app.InputMode == InputModeSearch {
+			search = th.Normal.Render(display.Truncate(searchWithCursor(app.SearchQuery, app.CursorPosition), inner))
+		} else if app.SearchQuery != "" {
+			search = th.Normal.Render(display.Truncate(app.SearchQuery, inner))
+		}
+		return searchBlock.Render(search + "\n" + th.Dim.Render(filters))
+	}
 	return lipgloss.JoinHorizontal(lipgloss.Top, searchBox, " ", providerBox, " ", fitBox)
 }
 
-func fitColor(level pole.FitLevel) lipgloss.Style {
-	switch level {
-	case pole.FitPerfect:
-		return styleGreen
-	case pole.FitGood:
-		return styleYellow
-	case pole.FitMarginal:
-		return styleMagenta
-	case pole.FitTooTight:
-		return styleRed
-	default:
-		return styleNormal
+// visibleWindow returns the [start, end) rows of total to draw in visible lines so that
+// selected is on screen. Only this window is rendered, however large the list.
+func visibleWindow(selected, total, visible int) (start, end int) {
+	if visible < 1 {
+		visible = 1
+	}
+	if total <= visible {
+		return 0, total
+	}
+	if selected >= total-visible {
+		start = total - visible
+	} else if selected > 0 {
+		start = selected
+	}
+	return start, start + visible
+}
+
+// memoryHeader is the table's memory column header for the given view.
+func memoryHeader(view MemoryView) string {
+	if view == MemoryViewGB {
+		return "Mem GB"
 	}
+	return "Mem%"
 }
 
-func runModeColor(mode pole.RunMode) lipgloss.Style {
-	switch mode {
-	case pole.RunModeGpu:
-		return styleGreen
-	case pole.RunModeMoeOffload:
-		return styleCyan
-	case pole.RunModeCpuOffload:
-		return styleYellow
-	case pole.RunModeCpuOnly:
-		return styleDim
+// memoryCell formats a fit's memory use for the table column: percent of available, or required GB.
+func memoryCell(fit *pole.ModelFit, view MemoryView) string {
+	if view == MemoryViewGB {
+		return display.Num(fit.MemoryRequiredGB, 1)
+	}
+	return display.Num(fit.UtilizationPct, 0) + "%"
+}
+
+// memoryUsage formats the detail view's memory line as (primary, secondary) for the given view.
+func memoryUsage(fit *pole.ModelFit, view MemoryView) (string, string) {
+	pct := display.Num(fit.UtilizationPct, 1) + "%"
+	gb := display.Num(fit.MemoryRequiredGB, 1) + " / " + display.Num(fit.MemoryAvailableGB, 1) + " GB"
+	if view == MemoryViewGB {
+		return gb, "  (" + pct + ")"
+	}
+	return pct, "  (" + gb + ")"
+}
+
+// memBarWidth is the cell width of the memory utilization bar.
+const memBarWidth = 10
+
+// memBarFill returns how many of width cells a utilization bar fills for pct, clamped to the bar,
+// and whether pct overflows the available memory.
+func memBarFill(pct float64, width int) (filled int, overflow bool) {
+	overflow = pct > 100
+	if pct < 0 {
+		pct = 0
+	}
+	if pct > 100 {
+		pct = 100
+	}
+	return int(math.Round(pct / 100 * float64(width))), overflow
+}
+
+// memBarStyle colors a utilization bar: good with headroom, a warning when nearly full, bad when
+// full or overflowing.
+func (th *Theme) memBarStyle(pct float64) lipgloss.Style {
+	switch {
+	case pct >= 90:
+		return th.Bad
+	case pct >= 70:
+		return th.Warn
 	default:
-		return styleNormal
+		return th.Good
 	}
 }
 
+// memoryBar renders a fit's memory utilization as a colored bar with its percentage.
+func (th *Theme) memoryBar(pct float64) string {
+	filled, overflow := memBarFill(pct, memBarWidth)
+	bar := strings.Repeat(glyph("█", "#"), filled) + strings.Repeat(glyph("░", "-"), memBarWidth-filled)
+	label := " " + display.Num(pct, 0) + "%"
+	if overflow {
+		label += " overflow"
+	}
+	style := th.memBarStyle(pct)
+	return style.Render(bar) + style.Bold(overflow).Render(label)
+}
+
 func renderTable(app *App, width, height int) string {
-	headers := []string{"", "Model", "Provider", "Params", "Score", "tok/s", "Quant", "Mode", "Mem%", "Ctx", "Fit", "Use Case"}
+	th := app.theme()
+	headers := []string{"", "Model", "Provider", "Params", "Score", "tok/s", "Quant", "Mode", memoryHeader(app.MemoryView), "Ctx", "Fit", "Use Case"}
 	colWidths := []int{2, 20, 12, 8, 6, 6, 7, 7, 6, 5, 10, 12}
 	headerLine := ""
 	for i, h := range headers {
 		w := colWidths[i]
 		if i < len(colWidths) {
-			headerLine += truncPad(h, w) + " "
+			headerLine += display.TruncatePad(h, w) + " "
 		}
 	}
-	headerLine = styleCyan.Bold(true).Render(headerLine)
+	headerLine = th.Accent.Bold(true).Render(headerLine)
 
 	var rows []string
-	start := 0
-	end := len(app.FilteredFits)
-	visible := height - 2
-	if visible < 1 {
-		visible = 1
-	}
-	if end > visible {
-		if app.SelectedRow >= end-visible {
-			start = end - visible
-		} else if app.SelectedRow > 0 {
-			start = app.SelectedRow
-		}
-		end = start + visible
-		if end > len(app.FilteredFits) {
-			end = len(app.FilteredFits)
-		}
-	}
+	start, end := visibleWindow(app.SelectedRow, len(app.FilteredFits), height-2)
 	for rowIdx := start; rowIdx < end; rowIdx++ {
 		idx := app.FilteredFits[rowIdx]
 		fit := app.AllFits[idx]
-		indicator := "●"
-		cellStyle := fitColor(fit.FitLevel)
-		scoreStyle := styleNormal
-		if fit.Score >= 70 {
-			scoreStyle = styleGreen
-		} else if fit.Score >= 50 {
-			scoreStyle = styleYellow
-		} else {
-			scoreStyle = styleRed
-		}
-		tpsStr := fmt.Sprintf("%.1f", fit.EstimatedTPS)
+		indicator := glyph("●", "*")
+		cellStyle := th.fitColor(fit.FitLevel)
+		scoreStyle := th.scoreColor(fit.Score)
+		tpsStr := display.Num(fit.EstimatedTPS, 1)
 		if fit.EstimatedTPS >= 100 {
-			tpsStr = fmt.Sprintf("%.0f", fit.EstimatedTPS)
+			tpsStr = display.Num(fit.EstimatedTPS, 0)
 		}
 		cells := []string{
 			cellStyle.Render(indicator),
-			styleNormal.Render(truncPad(fit.Model.Name, colWidths[1])),
-			styleDim.Render(truncPad(fit.Model.Provider, colWidths[2])),
-			styleNormal.Render(truncPad(fit.Model.ParameterCount, colWidths[3])),
-			scoreStyle.Render(truncPad(fmt.Sprintf("%.0f", fit.Score), colWidths[4])),
-			styleNormal.Render(truncPad(tpsStr, colWidths[5])),
-			styleDim.Render(truncPad(fit.BestQuant, colWidths[6])),
-			runModeColor(fit.RunMode).Render(truncPad(fit.RunModeText(), colWidths[7])),
-			cellStyle.Render(truncPad(fmt.Sprintf("%.0f%%", fit.UtilizationPct), colWidths[8])),
-			styleDim.Render(truncPad(fmt.Sprintf("%dk", fit.Model.ContextLength/1000), colWidths[9])),
-			cellStyle.Render(truncPad(fit.FitText(), colWidths[10])),
-			styleDim.Render(truncPad(fit.UseCase.String(), colWidths[11])),
+			th.Normal.Render(display.TruncatePad(fit.Model.Name, colWidths[1])),
+			th.Dim.Render(display.TruncatePad(fit.Model.Provider, colWidths[2])),
+			th.Normal.Render(display.TruncatePad(fit.Model.ParameterCount, colWidths[3])),
+			scoreStyle.Render(display.TruncatePad(display.Num(fit.Score, 0), colWidths[4])),
+			th.Normal.Render(display.TruncatePad(tpsStr, colWidths[5])),
+			th.Dim.Render(display.TruncatePad(fit.BestQuant, colWidths[6])),
+			th.runModeColor(fit.RunMode).Render(display.TruncatePad(fit.RunModeText(), colWidths[7])),
+			cellStyle.Render(display.TruncatePad(memoryCell(fit, app.MemoryView), colWidths[8])),
+			th.Dim.Render(display.TruncatePad(fmt.Sprintf("%dk", fit.Model.ContextLength/1000), colWidths[9])),
+			cellStyle.Render(display.TruncatePad(fit.FitText(), colWidths[10])),
+			th.Dim.Render(display.TruncatePad(fit.UseCase.String(), colWidths[11])),
 		}
 		line := ""
 		for i, c := range cells {
 			line += lipgloss.NewStyle().Width(colWidths[i]).Render(c) + " "
 		}
 		if rowIdx == app.SelectedRow {
-			line = lipgloss.NewStyle().Background(lipgloss.Color("8")).Bold(true).Render("▶ "+line) 
+			line = th.Cursor.Render(glyph("▶ ", "> ") + line)
 		} else {
 			line = "  " + line
 		}
@@ -298,23 +404,46 @@ func renderTable(app *App, width, height int) string {
 	}
 
 	title := fmt.Sprintf(" Models (%d/%d) ", len(app.FilteredFits), len(app.AllFits))
-	block := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("8")).
-		Padding(0, 1)
+	block := th.panel()
 	body := headerLine + "\n" + strings.Join(rows, "\n")
-	return block.Render(styleNormal.Render(title) + "\n" + body)
+	return block.Render(th.Normal.Render(title) + "\n" + body)
 }
 
-func truncPad(s string, w int) string {
-	runes := []rune(s)
-	if len(runes) <= w {
-		return s + strings.Repeat(" ", w-len(runes))
+// cardLines is how many lines one model takes in the card layout.
+const cardLines = 3
+
+// renderCards draws the model list as stacked cards for narrow terminals: the name on the first
+// line, then score, speed, quant and run mode, then fit, memory, context and use case.
+func renderCards(app *App, width, height int) string {
+	th := app.theme()
+	inner := width - 4 // border and padding
+	var rows []string
+	start, end := visibleWindow(app.SelectedRow, len(app.FilteredFits), (height-2)/cardLines)
+	for rowIdx := start; rowIdx < end; rowIdx++ {
+		fit := app.AllFits[app.FilteredFits[rowIdx]]
+		cellStyle := th.fitColor(fit.FitLevel)
+		marker := "  "
+		if rowIdx == app.SelectedRow {
+			marker = glyph("▶ ", "> ")
+		}
+		name := marker + cellStyle.Render(glyph("●", "*")) + " " + display.Truncate(fit.Model.Name, inner-4)
+		if rowIdx == app.SelectedRow {
+			name = lipgloss.NewStyle().Bold(true).Render(name)
+		}
+		speed := display.Truncate(fmt.Sprintf("Score %s  %s tok/s  %s  %s",
+			display.Num(fit.Score, 0), display.Num(fit.EstimatedTPS, 1), fit.BestQuant, fit.RunModeText()), inner-4)
+		detail := display.Truncate(fmt.Sprintf("%s  mem %s  %dk  %s",
+			fit.FitText(), memoryCell(fit, app.MemoryView), fit.Model.ContextLength/1000, fit.UseCase.String()), inner-4)
+		rows = append(rows, name, "    "+th.Normal.Render(speed), "    "+cellStyle.Render(detail))
 	}
-	return string(runes[:w-1]) + "…"
+
+	title := display.Truncate(fmt.Sprintf(" Models (%d/%d) ", len(app.FilteredFits), len(app.AllFits)), inner)
+	block := th.panel()
+	return block.Render(th.Normal.Render(title) + "\n" + strings.Join(rows, "\n"))
 }
 
 func renderStatusBar(app *App) string {
+	th := app.theme()
 	var keys, modeText string
 	switch app.InputMode {
 	case InputModeNormal:
@@ -322,122 +451,190 @@ func renderStatusBar(app *App) string {
 		if app.ShowDetail {
 			detailKey = "Enter:table"
 		}
-		keys = fmt.Sprintf(" ↑↓/jk:navigate  %s  /:search  f:fit filter  p:providers  q:quit", detailKey)
+		systemKey := "i:system"
+		if app.ShowSystem {
+			systemKey = "i:models"
+		}
+		memKey := "m:mem GB"
+		if app.MemoryView == MemoryViewGB {
+			memKey = "m:mem %"
+		}
+		topKey := "t:top/provider"
+		if app.TopByProvider {
+			topKey = "t:all models"
+		}
+		keys = fmt.Sprintf(" %s/jk:navigate  %s  %s  /:search  f:fit filter  p:providers  %s  %s  ::commands  q:quit", glyph("↑↓", "up/dn"), detailKey, systemKey, memKey, topKey)
+		if app.UseCardLayout() {
+			width, _ := clampSize(app.Width, app.Height)
+			keys = display.Truncate(" jk Enter / f : q", width-len(" NORMAL "))
+		}
 		modeText = "NORMAL"
 	case InputModeSearch:
-		keys = "  Type to search  Esc:done  Ctrl-U:clear"
+		keys = "  Type to search  " + glyph("←→", "left/right") + ":move cursor  Esc:done  Ctrl-U:clear"
 		modeText = "SEARCH"
 	case InputModeProviderPopup:
-		keys = "  ↑↓/jk:navigate  Space:toggle  a:all/none  Esc:close"
+		keys = "  " + glyph("↑↓", "up/dn") + "/jk:navigate  Space:toggle  a:all/none  J/K:move  Esc:close"
 		modeText = "PROVIDERS"
+	case InputModePalette:
+		keys = "  Type to filter  " + glyph("↑↓", "up/dn") + ":select  Enter:run  Esc:close"
+		modeText = "COMMAND"
+	}
+	bar := ""
+	if fit := app.SelectedFit(); fit != nil && app.InputMode == InputModeNormal && !app.UseCardLayout() {
+		bar = " " + th.Dim.Render("mem ") + th.memoryBar(fit.UtilizationPct) + " "
 	}
-	return styleStatus.Render(" "+modeText+" ") + styleDim.Render(keys)
+	return th.Status.Render(" "+modeText+" ") + bar + th.Dim.Render(keys)
 }
 
 func renderDetail(app *App, width, height int) string {
+	th := app.theme()
 	fit := app.SelectedFit()
 	if fit == nil {
-		block := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+		block := th.panel()
 		return block.Render(" No model selected ")
 	}
-	cellStyle := fitColor(fit.FitLevel)
+	cellStyle := th.fitColor(fit.FitLevel)
 	var lines []string
 	lines = append(lines, "")
-	lines = append(lines, styleDim.Render("  Model:       ")+styleNormal.Bold(true).Render(fit.Model.Name))
-	lines = append(lines, styleDim.Render("  Provider:    ")+styleNormal.Render(fit.Model.Provider))
-	lines = append(lines, styleDim.Render("  Parameters:  ")+styleNormal.Render(fit.Model.ParameterCount))
-	lines = append(lines, styleDim.Render("  Quantization:")+styleNormal.Render(" "+fit.Model.Quantization))
-	lines = append(lines, styleDim.Render("  Best Quant:  ")+styleGreen.Render(fmt.Sprintf(" %s (for this hardware)", fit.BestQuant)))
-	lines = append(lines, styleDim.Render("  Context:     ")+styleNormal.Render(fmt.Sprintf("%d tokens", fit.Model.ContextLength)))
-	lines = append(lines, styleDim.Render("  Use Case:    ")+styleNormal.Render(fit.Model.UseCase))
-	lines = append(lines, styleDim.Render("  Category:    ")+styleCyan.Render(fit.UseCase.String()))
+	lines = append(lines, th.Dim.Render("  Model:       ")+th.Normal.Bold(true).Render(fit.Model.Name))
+	lines = append(lines, th.Dim.Render("  Provider:    ")+th.Normal.Render(fit.Model.Provider))
+	lines = append(lines, th.Dim.Render("  Parameters:  ")+th.Normal.Render(fit.Model.ParameterCount))
+	lines = append(lines, th.Dim.Render("  Quantization:")+th.Normal.Render(" "+fit.Model.Quantization))
+	lines = append(lines, th.Dim.Render("  Best Quant:  ")+th.Good.Render(fmt.Sprintf(" %s (for this hardware)", fit.BestQuant)))
+	lines = append(lines, th.Dim.Render("  Context:     ")+th.Normal.Render(fmt.Sprintf("%d tokens", fit.Model.ContextLength)))
+	lines = append(lines, th.Dim.Render("  Use Case:    ")+th.Normal.Render(fit.Model.UseCase))
+	lines = append(lines, th.Dim.Render("  Category:    ")+th.Accent.Render(fit.UseCase.String()))
 	lines = append(lines, "")
-	lines = append(lines, styleCyan.Render("  ── Score Breakdown ──"))
+	lines = append(lines, th.Accent.Render(sectionTitle("Score Breakdown")))
 	lines = append(lines, "")
-	scoreStyle := styleNormal
-	if fit.Score >= 70 {
-		scoreStyle = styleGreen
-	} else if fit.Score >= 50 {
-		scoreStyle = styleYellow
-	} else {
-		scoreStyle = styleRed
-	}
-	lines = append(lines, styleDim.Render("  Overall:     ")+scoreStyle.Bold(true).Render(fmt.Sprintf("%.1f / 100", fit.Score)))
-	lines = append(lines, styleDim.Render("  Quality:     ")+styleNormal.Render(fmt.Sprintf("%.0f", fit.ScoreComponents.Quality))+
-		styleDim.Render("  Speed: ")+styleNormal.Render(fmt.Sprintf("%.0f", fit.ScoreComponents.Speed))+
-		styleDim.Render("  Fit: ")+styleNormal.Render(fmt.Sprintf("%.0f", fit.ScoreComponents.Fit))+
-		styleDim.Render("  Context: ")+styleNormal.Render(fmt.Sprintf("%.0f", fit.ScoreComponents.Context)))
-	lines = append(lines, styleDim.Render("  Est. Speed:  ")+styleNormal.Render(fmt.Sprintf("%.1f tok/s", fit.EstimatedTPS)))
+	scoreStyle := th.scoreColor(fit.Score)
+	lines = append(lines, th.Dim.Render("  Overall:     ")+scoreStyle.Bold(true).Render(display.Num(fit.Score, 1)+" / 100"))
+	lines = append(lines, th.Dim.Render("  Quality:     ")+th.Normal.Render(display.Num(fit.ScoreComponents.Quality, 0))+
+		th.Dim.Render("  Speed: ")+th.Normal.Render(display.Num(fit.ScoreComponents.Speed, 0))+
+		th.Dim.Render("  Fit: ")+th.Normal.Render(display.Num(fit.ScoreComponents.Fit, 0))+
+		th.Dim.Render("  Context: ")+th.Normal.Render(display.Num(fit.ScoreComponents.Context, 0)))
+	lines = append(lines, th.Dim.Render("  Est. Speed:  ")+th.Normal.Render(glyph("≈", "~")+display.Num(fit.EstimatedTPS, 1)+" tok/s")+th.Dim.Render("  ("+display.Num(fit.EstimatedTPSLow, 0)+glyph("–", "-")+display.Num(fit.EstimatedTPSHigh, 0)+")"))
 
 	if fit.Model.IsMoE {
 		lines = append(lines, "")
-		lines = append(lines, styleCyan.Render("  ── MoE Architecture ──"))
+		lines = append(lines, th.Accent.Render(sectionTitle("MoE Architecture")))
 		lines = append(lines, "")
 		if fit.Model.NumExperts != nil && fit.Model.ActiveExperts != nil {
-			lines = append(lines, styleDim.Render("  Experts:     ")+styleCyan.Render(fmt.Sprintf("%d active / %d total per token", *fit.Model.ActiveExperts, *fit.Model.NumExperts)))
+			lines = append(lines, th.Dim.Render("  Experts:     ")+th.Accent.Render(fmt.Sprintf("%d active / %d total per token", *fit.Model.ActiveExperts, *fit.Model.NumExperts)))
 		}
 		if v := fit.Model.MoeActiveVRAMGB(); v != nil {
 			minV := 0.0
 			if fit.Model.MinVRAMGB != nil {
 				minV = *fit.Model.MinVRAMGB
 			}
-			lines = append(lines, styleDim.Render("  Active VRAM: ")+styleCyan.Render(fmt.Sprintf("%.1f GB", *v))+styleDim.Render(fmt.Sprintf("  (vs %.1f GB full model)", minV)))
+			lines = append(lines, th.Dim.Render("  Active VRAM: ")+th.Accent.Render(display.Num(*v, 1)+" GB")+th.Dim.Render("  (vs "+display.Num(minV, 1)+" GB full model)"))
+		}
+		if fit.MoeResidentExperts != nil && fit.Model.NumExperts != nil {
+			lines = append(lines, th.Dim.Render("  Resident:    ")+th.Accent.Render(fmt.Sprintf("%d / %d experts in VRAM", *fit.MoeResidentExperts, *fit.Model.NumExperts)))
 		}
 		if fit.MoeOffloadedGB != nil {
-			lines = append(lines, styleDim.Render("  Offloaded:   ")+styleYellow.Render(fmt.Sprintf("%.1f GB inactive experts in RAM", *fit.MoeOffloadedGB)))
+			lines = append(lines, th.Dim.Render("  Offloaded:   ")+th.Warn.Render(display.Num(*fit.MoeOffloadedGB, 1)+" GB inactive experts in RAM"))
 		}
 		if fit.RunMode == pole.RunModeMoeOffload {
-			lines = append(lines, styleDim.Render("  Strategy:    ")+styleGreen.Render("Expert offloading (active in VRAM, inactive in RAM)"))
+			lines = append(lines, th.Dim.Render("  Strategy:    ")+th.Good.Render("Expert offloading (active in VRAM, inactive in RAM)"))
 		} else if fit.RunMode == pole.RunModeGpu {
-			lines = append(lines, styleDim.Render("  Strategy:    ")+styleGreen.Render("All experts loaded in VRAM (optimal)"))
+			lines = append(lines, th.Dim.Render("  Strategy:    ")+th.Good.Render("All experts loaded in VRAM (optimal)"))
 		}
 	}
 
 	lines = append(lines, "")
-	lines = append(lines, styleCyan.Render("  ── System Fit ──"))
+	lines = append(lines, th.Accent.Render(sectionTitle("System Fit")))
 	lines = append(lines, "")
-	lines = append(lines, styleDim.Render("  Fit Level:   ")+cellStyle.Bold(true).Render(fmt.Sprintf("● %s", fit.FitText())))
-	lines = append(lines, styleDim.Render("  Run Mode:    ")+styleNormal.Bold(true).Render(fit.RunModeText()))
+	lines = append(lines, th.Dim.Render("  Fit Level:   ")+cellStyle.Bold(true).Render(fmt.Sprintf("%s %s", glyph("●", "*"), fit.FitText())))
+	lines = append(lines, th.Dim.Render("  Run Mode:    ")+th.Normal.Bold(true).Render(fit.RunModeText()))
 	lines = append(lines, "")
-	lines = append(lines, styleCyan.Render("  -- Memory --"))
+	lines = append(lines, th.Accent.Render(sectionTitle("Memory")))
 	lines = append(lines, "")
 	if fit.Model.MinVRAMGB != nil {
 		vramLabel := "  (no GPU)"
 		if app.Specs.HasGPU {
 			if app.Specs.UnifiedMemory {
 				if app.Specs.GpuVRAMGB != nil {
-					vramLabel = fmt.Sprintf("  (shared: %.1f GB)", *app.Specs.GpuVRAMGB)
+					vramLabel = "  (shared: " + display.Num(*app.Specs.GpuVRAMGB, 1) + " GB)"
 				} else {
 					vramLabel = "  (shared memory)"
 				}
 			} else if app.Specs.GpuVRAMGB != nil {
-				vramLabel = fmt.Sprintf("  (system: %.1f GB)", *app.Specs.GpuVRAMGB)
+				vramLabel = "  (system: " + display.Num(*app.Specs.GpuVRAMGB, 1) + " GB)"
 			} else {
 				vramLabel = "  (system: unknown)"
 			}
 		}
-		lines = append(lines, styleDim.Render("  Min VRAM:    ")+styleNormal.Render(fmt.Sprintf("%.1f GB", *fit.Model.MinVRAMGB))+styleDim.Render(vramLabel))
+		lines = append(lines, th.Dim.Render("  Min VRAM:    ")+th.Normal.Render(display.Num(*fit.Model.MinVRAMGB, 1)+" GB")+th.Dim.Render(vramLabel))
 	}
-	lines = append(lines, styleDim.Render("  Min RAM:     ")+styleNormal.Render(fmt.Sprintf("%.1f GB", fit.Model.MinRAMGB))+styleDim.Render(fmt.Sprintf("  (system: %.1f GB avail)", app.Specs.AvailableRAMGB)))
-	lines = append(lines, styleDim.Render("  Rec RAM:     ")+styleNormal.Render(fmt.Sprintf("%.1f GB", fit.Model.RecommendedRAMGB)))
-	lines = append(lines, styleDim.Render("  Mem Usage:   ")+cellStyle.Render(fmt.Sprintf("%.1f%%", fit.UtilizationPct))+styleDim.Render(fmt.Sprintf("  (%.1f / %.1f GB)", fit.MemoryRequiredGB, fit.MemoryAvailableGB)))
+	lines = append(lines, th.Dim.Render("  Min RAM:     ")+th.Normal.Render(display.Num(fit.Model.MinRAMGB, 1)+" GB")+th.Dim.Render("  (system: "+display.Num(app.Specs.AvailableRAMGB, 1)+" GB avail)"))
+	lines = append(lines, th.Dim.Render("  Rec RAM:     ")+th.Normal.Render(display.Num(fit.Model.RecommendedRAMGB, 1)+" GB"))
+	memPrimary, memSecondary := memoryUsage(fit, app.MemoryView)
+	lines = append(lines, th.Dim.Render("  Mem Usage:   ")+cellStyle.Render(memPrimary)+th.Dim.Render(memSecondary))
+	lines = append(lines, th.Dim.Render("               ")+th.memoryBar(fit.UtilizationPct))
 	lines = append(lines, "")
 	if len(fit.Notes) > 0 {
-		lines = append(lines, styleCyan.Render("  ── Notes ──"))
+		lines = append(lines, th.Accent.Render(sectionTitle("Notes")))
 		lines = append(lines, "")
 		for _, n := range fit.Notes {
-			lines = append(lines, styleNormal.Render("  "+n))
+			lines = append(lines, th.Normal.Render("  "+n))
+		}
+	}
+
+	block := th.panel()
+	return block.Render(th.Normal.Bold(true).Render(" "+fit.Model.Name+" ") + "\n" + strings.Join(lines, "\n"))
+}
+
+func renderSystemPanel(app *App, width, height int) string {
+	th := app.theme()
+	specs := app.Specs
+	var lines []string
+	lines = append(lines, "")
+	lines = append(lines, th.Accent.Render(sectionTitle("CPU")))
+	lines = append(lines, "")
+	lines = append(lines, th.Dim.Render("  CPU:         ")+th.Normal.Render(specs.CPUName))
+	lines = append(lines, th.Dim.Render("  Cores:       ")+th.Normal.Render(fmt.Sprintf("%d", specs.TotalCPUCores)))
+	lines = append(lines, th.Dim.Render("  Backend:     ")+th.Normal.Render(specs.Backend.String()))
+	lines = append(lines, "")
+	lines = append(lines, th.Accent.Render(sectionTitle("Memory")))
+	lines = append(lines, "")
+	lines = append(lines, th.Dim.Render("  Total RAM:   ")+th.Normal.Render(display.Num(specs.TotalRAMGB, 2)+" GB"))
+	lines = append(lines, th.Dim.Render("  Avail RAM:   ")+th.Accent.Render(display.Num(specs.AvailableRAMGB, 2)+" GB"))
+	if hardware.IsRunningInWSL() {
+		lines = append(lines, th.Dim.Render("  Environment: ")+th.Warn.Render("WSL"))
+	}
+	lines = append(lines, "")
+	lines = append(lines, th.Accent.Render(sectionTitle("GPUs")))
+	lines = append(lines, "")
+	if len(specs.Gpus) == 0 {
+		lines = append(lines, th.Dim.Render("  GPU:         ")+th.Normal.Render("Not detected"))
+	}
+	for i, g := range specs.Gpus {
+		label := fmt.Sprintf("  GPU %d:       ", i+1)
+		var mem string
+		switch {
+		case g.UnifiedMemory && g.VRAMGB != nil:
+			mem = "unified memory, " + display.Num(*g.VRAMGB, 2) + " GB shared"
+		case g.VRAMGB != nil && *g.VRAMGB > 0:
+			mem = display.Num(*g.VRAMGB, 2) + " GB VRAM"
+		case g.VRAMGB != nil:
+			mem = "shared system memory"
+		default:
+			mem = "VRAM unknown"
+		}
+		name := g.Name
+		if g.Count > 1 {
+			name = fmt.Sprintf("%s x%d", g.Name, g.Count)
 		}
+		lines = append(lines, th.Dim.Render(label)+th.Warn.Render(name)+th.Dim.Render(fmt.Sprintf("  (%s, %s)", mem, g.Backend.String())))
 	}
 
-	block := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("8")).
-		Padding(0, 1)
-	return block.Render(styleNormal.Bold(true).Render(" "+fit.Model.Name+" ") + "\n" + strings.Join(lines, "\n"))
+	block := th.panel()
+	return block.Render(th.Normal.Bold(true).Render(" System Details ") + "\n" + strings.Join(lines, "\n"))
 }
 
 func renderProviderPopup(app *App, width, height int) string {
+	th := app.theme()
 	maxNameLen := 10
 	for _, p := range app.Providers {
 		if len(p) > maxNameLen {
@@ -452,7 +649,7 @@ func renderProviderPopup(app *App, width, height int) string {
 	if popupH > height-4 {
 		popupH = height - 4
 	}
-	innerH := popupH - 2
+	popupW, innerH := max(popupW, 1), max(popupH-2, 1)
 	scrollOffset := 0
 	if app.ProviderCursor >= innerH {
 		scrollOffset = app.ProviderCursor - innerH + 1
@@ -464,11 +661,7 @@ func renderProviderPopup(app *App, width, height int) string {
 		}
 	}
 	title := fmt.Sprintf(" Providers (%d/%d) ", activeCount, len(app.Providers))
-	block := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("11")).
-		Padding(0, 1).
-		Width(popupW)
+	block := th.popup().Width(popupW)
 	var lines []string
 	for i := scrollOffset; i < len(app.Providers) && len(lines) < innerH; i++ {
 		cb := "[ ]"
@@ -477,13 +670,44 @@ func renderProviderPopup(app *App, width, height int) string {
 		}
 		line := cb + " " + app.Providers[i]
 		if i == app.ProviderCursor {
-			line = styleYellow.Bold(true).Render(line)
+			line = th.Warn.Bold(true).Render(line)
 		} else if app.SelectedProviders[i] {
-			line = styleGreen.Render(line)
+			line = th.Good.Render(line)
 		} else {
-			line = styleDim.Render(line)
+			line = th.Dim.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return block.Render(th.Warn.Bold(true).Render(title) + "\n" + strings.Join(lines, "\n"))
+}
+
+// renderPalette draws the command palette: the query, then the matching actions with their keys.
+func renderPalette(app *App, width, height int) string {
+	th := app.theme()
+	popupW := 44
+	if popupW > width-4 {
+		popupW = width - 4
+	}
+	popupW, innerH := max(popupW, 3), max(height-6, 1)
+	matches := app.PaletteMatches()
+	scrollOffset := 0
+	if app.PaletteCursor >= innerH {
+		scrollOffset = app.PaletteCursor - innerH + 1
+	}
+	lines := []string{th.Normal.Render(display.Truncate(": "+app.PaletteQuery, popupW-2))}
+	for i := scrollOffset; i < len(matches) && len(lines) <= innerH; i++ {
+		act := actions[matches[i]]
+		keys := strings.Join(act.Keys, " ")
+		name := display.TruncatePad(act.Name, max(popupW-2-len(keys)-1, 1))
+		line := name + " " + th.Dim.Render(keys)
+		if i == app.PaletteCursor {
+			line = th.Warn.Bold(true).Render(name) + " " + th.Dim.Render(keys)
 		}
 		lines = append(lines, line)
 	}
-	return block.Render(styleYellow.Bold(true).Render(title)+"\n"+strings.Join(lines, "\n"))
+	if len(matches) == 0 {
+		lines = append(lines, th.Dim.Render("no matching command"))
+	}
+	block := th.popup().Width(popupW)
+	return block.Render(th.Warn.Bold(true).Render(" Commands ") + "\n" + strings.Join(lines, "\n"))
 }
@@ -0,0 +1,306 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shayne-snap/llmpole/internal/display"
+	"github.com/shayne-snap/llmpole/internal/hardware"
+	"github.com/shayne-snap/llmpole/internal/models"
+	"github.com/shayne-snap/llmpole/internal/pole"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyMsg builds a key message whose String() matches s (named keys like "esc" or single runes).
+func keyMsg(s string) tea.KeyMsg {
+	switch s {
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestRenderSystemPanel(t *testing.T) {
+	vram1, vram2 := 24.0, 8.0
+	specs := &hardware.SystemSpecs{
+		TotalRAMGB:     64,
+		AvailableRAMGB: 48,
+		TotalCPUCores:  16,
+		CPUName:        "Test CPU",
+		HasGPU:         true,
+		GpuVRAMGB:      &vram1,
+		Backend:        hardware.BackendCuda,
+		Gpus: []hardware.GpuInfo{
+			{Name: "Big GPU", VRAMGB: &vram1, Backend: hardware.BackendCuda, Count: 1},
+			{Name: "Small GPU", VRAMGB: &vram2, Backend: hardware.BackendVulkan, Count: 1},
+		},
+	}
+	app := NewApp(specs, nil)
+	out := renderSystemPanel(app, 120, 40)
+	for _, want := range []string{"Test CPU", "Big GPU", "Small GPU", "Total RAM", "64.00 GB", "48.00 GB"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("system panel missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestToggleSystemPanel(t *testing.T) {
+	app := NewApp(&hardware.SystemSpecs{CPUName: "Test CPU"}, nil)
+	m := &model{app: app}
+	m.handleNormal(keyMsg("i"))
+	if !app.ShowSystem {
+		t.Fatal("i should open the system panel")
+	}
+	m.handleNormal(keyMsg("esc"))
+	if app.ShowSystem || app.ShouldQuit {
+		t.Error("esc should close the system panel without quitting")
+	}
+}
+
+func TestRender_ASCII(t *testing.T) {
+	display.SetASCII(true)
+	defer display.SetASCII(false)
+	vram := 8.0
+	specs := &hardware.SystemSpecs{
+		TotalRAMGB: 32, AvailableRAMGB: 24, TotalCPUCores: 8, CPUName: "Test CPU",
+		HasGPU: true, GpuVRAMGB: &vram, Backend: hardware.BackendCuda,
+		Gpus: []hardware.GpuInfo{{Name: "Test GPU", VRAMGB: &vram, Backend: hardware.BackendCuda, Count: 1}},
+	}
+	minVram := 4.0
+	m := &models.LlmModel{Name: "test-7b", Provider: "Test", ParameterCount: "7B", MinRAMGB: 8, RecommendedRAMGB: 12, MinVRAMGB: &minVram, Quantization: "Q4_K_M", ContextLength: 4096}
+	app := NewApp(specs, []*pole.ModelFit{pole.Analyze(m, specs)})
+	app.Width, app.Height = 120, 40
+	views := []string{Render(app)}
+	app.ShowDetail = true
+	views = append(views, Render(app))
+	app.ShowDetail = false
+	app.ShowSystem = true
+	views = append(views, Render(app))
+	for _, v := range views {
+		for _, r := range v {
+			if r >= 0x2500 && r <= 0x257f || r == '●' || r == '▶' || r == '…' {
+				t.Fatalf("ASCII render contains box-drawing rune %q:\n%s", r, v)
+			}
+		}
+	}
+}
+
+func TestSystemBar_LiveStats(t *testing.T) {
+	app := NewApp(&hardware.SystemSpecs{CPUName: "Test CPU"}, nil)
+	if out := renderSystemBar(app); strings.Contains(out, "Load:") {
+		t.Errorf("system bar shows Load without a sample:\n%s", out)
+	}
+	m := &model{app: app, live: true}
+	m.Update(liveStatsMsg{{Name: "GPU", UtilizationPct: 42, TemperatureC: 70}})
+	if out := renderSystemBar(app); !strings.Contains(out, "Load:") || !strings.Contains(out, "42%") {
+		t.Errorf("system bar missing live load:\n%s", out)
+	}
+}
+
+func TestMemoryView_Formatting(t *testing.T) {
+	fit := &pole.ModelFit{UtilizationPct: 62.5, MemoryRequiredGB: 5, MemoryAvailableGB: 8}
+	tests := []struct {
+		view                        MemoryView
+		header, cell, primary, rest string
+	}{
+		{MemoryViewPercent, "Mem%", "62%", "62.5%", "  (5.0 / 8.0 GB)"},
+		{MemoryViewGB, "Mem GB", "5.0", "5.0 / 8.0 GB", "  (62.5%)"},
+	}
+	for _, tt := range tests {
+		if got := memoryHeader(tt.view); got != tt.header {
+			t.Errorf("view %d header = %q, want %q", tt.view, got, tt.header)
+		}
+		if got := memoryCell(fit, tt.view); got != tt.cell {
+			t.Errorf("view %d cell = %q, want %q", tt.view, got, tt.cell)
+		}
+		primary, rest := memoryUsage(fit, tt.view)
+		if primary != tt.primary || rest != tt.rest {
+			t.Errorf("view %d usage = %q%q, want %q%q", tt.view, primary, rest, tt.primary, tt.rest)
+		}
+	}
+}
+
+func TestMemBarFill(t *testing.T) {
+	tests := []struct {
+		pct      float64
+		filled   int
+		overflow bool
+	}{
+		{0, 0, false},
+		{-5, 0, false},
+		{34, 3, false},
+		{55, 6, false},
+		{100, 10, false},
+		{112.5, 10, true},
+		{400, 10, true},
+	}
+	for _, tt := range tests {
+		filled, overflow := memBarFill(tt.pct, 10)
+		if filled != tt.filled || overflow != tt.overflow {
+			t.Errorf("memBarFill(%v) = %d, %v; want %d, %v", tt.pct, filled, overflow, tt.filled, tt.overflow)
+		}
+	}
+	if out := DarkTheme().memoryBar(130); !strings.Contains(out, "130% overflow") {
+		t.Errorf("memoryBar(130) = %q, want an overflow label", out)
+	}
+}
+
+func TestToggleMemoryView(t *testing.T) {
+	app := NewApp(&hardware.SystemSpecs{}, testFits())
+	m := &model{app: app}
+	m.handleNormal(keyMsg("m"))
+	if app.MemoryView != MemoryViewGB {
+		t.Fatal("m should switch to GB-primary memory view")
+	}
+	if out := renderTable(app, 120, 20); !strings.Contains(out, "Mem GB") {
+		t.Errorf("table should show Mem GB header:\n%s", out)
+	}
+	m.handleNormal(keyMsg("m"))
+	if app.MemoryView != MemoryViewPercent {
+		t.Error("m again should switch back to percent-primary")
+	}
+}
+
+func TestUseCardLayout_ByWidth(t *testing.T) {
+	tests := []struct {
+		width int
+		cards bool
+	}{
+		{0, false}, // unknown until the first resize
+		{30, true},
+		{59, true},
+		{60, false},
+		{120, false},
+	}
+	for _, tt := range tests {
+		app := NewApp(&hardware.SystemSpecs{CPUName: "Test CPU"}, testFits())
+		app.Width, app.Height = tt.width, 30
+		if got := app.UseCardLayout(); got != tt.cards {
+			t.Errorf("width %d: UseCardLayout = %v, want %v", tt.width, got, tt.cards)
+		}
+		out := Render(app)
+		if hasHeader := strings.Contains(out, "Use Case"); hasHeader == tt.cards {
+			t.Errorf("width %d: table header present = %v, want %v:\n%s", tt.width, hasHeader, !tt.cards, out)
+		}
+		if tt.cards {
+			for i, line := range strings.Split(out, "\n") {
+				if w := lipgloss.Width(line); w > tt.width {
+					t.Errorf("width %d: line %d is %d cells wide:\n%s", tt.width, i, w, line)
+				}
+			}
+		}
+	}
+}
+
+func TestRender_DegenerateSizes(t *testing.T) {
+	sizes := []struct{ w, h int }{{0, 0}, {1, 1}, {3, 2}, {-5, -5}, {1 << 20, 1 << 20}}
+	modes := []struct {
+		name  string
+		setup func(a *App)
+	}{
+		{"table", func(a *App) {}},
+		{"detail", func(a *App) { a.ShowDetail = true }},
+		{"system", func(a *App) { a.ShowSystem = true }},
+		{"providers", func(a *App) { a.OpenProviderPopup() }},
+		{"palette", func(a *App) { a.OpenPalette() }},
+		{"search", func(a *App) { a.EnterSearch(); a.SearchQuery = "dense" }},
+	}
+	for _, sz := range sizes {
+		for _, mode := range modes {
+			app := NewApp(&hardware.SystemSpecs{CPUName: "Test CPU"}, testFits())
+			app.Width, app.Height = sz.w, sz.h
+			mode.setup(app)
+			var out string
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Fatalf("%dx%d %s: Render panicked: %v", sz.w, sz.h, mode.name, r)
+					}
+				}()
+				out = Render(app)
+			}()
+			// Tiny sizes only need to render; huge ones must stay within the clamped bounds.
+			lines := strings.Split(out, "\n")
+			if len(lines) > maxRenderHeight {
+				t.Errorf("%dx%d %s: %d lines, want at most %d", sz.w, sz.h, mode.name, len(lines), maxRenderHeight)
+			}
+			for i, line := range lines {
+				if lw := lipgloss.Width(line); lw > maxRenderWidth {
+					t.Errorf("%dx%d %s: line %d is %d cells wide, want at most %d", sz.w, sz.h, mode.name, i, lw, maxRenderWidth)
+				}
+			}
+		}
+	}
+}
+
+func TestThemes_LightDiffersFromDark(t *testing.T) {
+	dark, light := DarkTheme(), LightTheme()
+	for _, el := range []struct {
+		name        string
+		dark, light lipgloss.Style
+	}{
+		{"title", dark.Title, light.Title},
+		{"dim", dark.Dim, light.Dim},
+		{"normal", dark.Normal, light.Normal},
+		{"accent", dark.Accent, light.Accent},
+		{"good", dark.Good, light.Good},
+		{"warn", dark.Warn, light.Warn},
+		{"notice", dark.Notice, light.Notice},
+		{"bad", dark.Bad, light.Bad},
+	} {
+		if d, l := el.dark.GetForeground(), el.light.GetForeground(); d == l {
+			t.Errorf("%s: light and dark themes both use color %v", el.name, d)
+		}
+	}
+	if dark.Status.GetBackground() == light.Status.GetBackground() || dark.Cursor.GetBackground() == light.Cursor.GetBackground() {
+		t.Error("light theme shares the dark theme's status or cursor background")
+	}
+	if dark.fitColor(pole.FitGood).GetForeground() == light.fitColor(pole.FitGood).GetForeground() {
+		t.Error("a Good fit is drawn in the same color by both themes")
+	}
+	if _, ok := MonoTheme().Good.GetForeground().(lipgloss.NoColor); !ok {
+		t.Error("mono theme sets a foreground color")
+	}
+
+	app := NewApp(&hardware.SystemSpecs{}, testFits())
+	app.Width, app.Height = 120, 30
+	app.Theme = LightTheme()
+	if out := Render(app); !strings.Contains(out, "dense-7b") {
+		t.Errorf("light theme render is missing the model list:\n%s", out)
+	}
+}
+
+func TestParseTheme(t *testing.T) {
+	for _, name := range []string{"dark", "Light", " mono "} {
+		if th, err := ParseTheme(name); err != nil || th == nil || th.Name != strings.ToLower(strings.TrimSpace(name)) {
+			t.Errorf("ParseTheme(%q) = %v, %v", name, th, err)
+		}
+	}
+	for _, name := range []string{"", "auto"} {
+		if th, err := ParseTheme(name); err != nil || th != nil {
+			t.Errorf("ParseTheme(%q) = %v, %v; want nil to detect from the terminal", name, th, err)
+		}
+	}
+	if _, err := ParseTheme("solarized"); err == nil {
+		t.Error("expected an error for an unknown theme")
+	}
+	for _, tt := range []struct {
+		colorFGBG string
+		dark, ok  bool
+	}{
+		{"15;0", true, true},
+		{"0;15", false, true},
+		{"0;default;7", false, true},
+		{"15;8", true, true},
+		{"default;default", false, false},
+		{"", false, false},
+	} {
+		if dark, ok := colorFGBGIsDark(tt.colorFGBG); dark != tt.dark || ok != tt.ok {
+			t.Errorf("colorFGBGIsDark(%q) = %v, %v; want %v, %v", tt.colorFGBG, dark, ok, tt.dark, tt.ok)
+		}
+	}
+}